@@ -21,12 +21,11 @@ package cast
 import (
 	"fmt"
 	"html/template"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/spf13/cast"
 )
 
 // ToBool casts an interface{} to a bool.
@@ -99,13 +98,13 @@ func ToInt64E(i interface{}) (int64, error) {
 	case *int8:
 		return int64(*s), nil
 	case uint:
-		return int64(s), nil
+		return uint64ToInt64(uint64(s))
 	case *uint:
-		return int64(*s), nil
+		return uint64ToInt64(uint64(*s))
 	case uint64:
-		return int64(s), nil
+		return uint64ToInt64(s)
 	case *uint64:
-		return int64(*s), nil
+		return uint64ToInt64(*s)
 	case uint32:
 		return int64(s), nil
 	case *uint32:
@@ -119,19 +118,19 @@ func ToInt64E(i interface{}) (int64, error) {
 	case *uint8:
 		return int64(*s), nil
 	case float64:
-		return int64(s), nil
+		return float64ToInt64(s)
 	case *float64:
-		return int64(*s), nil
+		return float64ToInt64(*s)
 	case float32:
-		return int64(s), nil
+		return float64ToInt64(float64(s))
 	case *float32:
-		return int64(*s), nil
+		return float64ToInt64(float64(*s))
 	case string, *string:
-		v, err := strconv.ParseInt(ToString(s), 0, 0)
+		v, err := strconv.ParseInt(ToString(s), 0, 64)
 		if err == nil {
 			return v, nil
 		}
-		return 0, fmt.Errorf("unable to cast %#v of type %T to int64", i, i)
+		return 0, fmt.Errorf("unable to cast %#v of type %T to int64: %w", i, i, err)
 	case bool, *bool:
 		if ToBool(i) {
 			return 1, nil
@@ -162,8 +161,16 @@ func ToUint64E(i interface{}) (uint64, error) {
 			return v, nil
 		}
 		return 0, fmt.Errorf("unable to cast %#v to uint64: %s", i, err)
-	case int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8,
-		*int, *int64, *int32, *int16, *int8, *uint, *uint64, *uint32, *uint16, *uint8:
+	case uint:
+		return uint64(s), nil
+	case *uint:
+		return uint64(*s), nil
+	case uint64:
+		return s, nil
+	case *uint64:
+		return *s, nil
+	case int, int64, int32, int16, int8, uint32, uint16, uint8,
+		*int, *int64, *int32, *int16, *int8, *uint32, *uint16, *uint8:
 		v := ToInt64(i)
 		if v < 0 {
 			return 0, fmt.Errorf("unable to cast negative value")
@@ -175,6 +182,9 @@ func ToUint64E(i interface{}) (uint64, error) {
 		if v < 0 {
 			return 0, fmt.Errorf("unable to cast negative value")
 		}
+		if v != math.Trunc(v) || v >= 1<<64 {
+			return 0, fmt.Errorf("unable to cast %v to uint64 without loss", v)
+		}
 		return uint64(v), nil
 	case bool, *bool:
 		if ToBool(s) {
@@ -209,13 +219,13 @@ func ToFloat64E(i interface{}) (float64, error) {
 	case *float32:
 		return float64(*s), nil
 	case int:
-		return float64(s), nil
+		return int64ToFloat64(int64(s))
 	case *int:
-		return float64(*s), nil
+		return int64ToFloat64(int64(*s))
 	case int64:
-		return float64(s), nil
+		return int64ToFloat64(s)
 	case *int64:
-		return float64(*s), nil
+		return int64ToFloat64(*s)
 	case int32:
 		return float64(s), nil
 	case *int32:
@@ -229,13 +239,13 @@ func ToFloat64E(i interface{}) (float64, error) {
 	case *int8:
 		return float64(*s), nil
 	case uint:
-		return float64(s), nil
+		return uint64ToFloat64(uint64(s))
 	case *uint:
-		return float64(*s), nil
+		return uint64ToFloat64(uint64(*s))
 	case uint64:
-		return float64(s), nil
+		return uint64ToFloat64(s)
 	case *uint64:
-		return float64(*s), nil
+		return uint64ToFloat64(*s)
 	case uint32:
 		return float64(s), nil
 	case *uint32:
@@ -267,6 +277,46 @@ func ToFloat64E(i interface{}) (float64, error) {
 	}
 }
 
+// uint64ToInt64 将 uint64 转换为 int64 ，超出 int64 范围时返回错误。
+func uint64ToInt64(v uint64) (int64, error) {
+	if v > math.MaxInt64 {
+		return 0, fmt.Errorf("value %d overflows int64", v)
+	}
+	return int64(v), nil
+}
+
+// float64ToInt64 将 float64 转换为 int64 ，有小数部分或者超出 int64 范围时返
+// 回错误，而不是截断。
+func float64ToInt64(v float64) (int64, error) {
+	if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+		return 0, fmt.Errorf("unable to cast %v to int64 without loss", v)
+	}
+	return int64(v), nil
+}
+
+// maxExactFloat64 float64 能够精确表示的最大整数。
+const maxExactFloat64 = 1 << 53
+
+// int64ToFloat64 将 int64 转换为 float64 ，不能精确表示时返回错误。
+func int64ToFloat64(v int64) (float64, error) {
+	if v > maxExactFloat64 || v < -maxExactFloat64 {
+		if f := float64(v); f >= math.MaxInt64 || int64(f) != v {
+			return 0, fmt.Errorf("value %d loses precision in float64", v)
+		}
+	}
+	return float64(v), nil
+}
+
+// uint64ToFloat64 将 uint64 转换为 float64 ，不能精确表示时返回错误。
+func uint64ToFloat64(v uint64) (float64, error) {
+	if v > maxExactFloat64 {
+		if f := float64(v); f >= 1<<64 || uint64(f) != v {
+			return 0, fmt.Errorf("value %d loses precision in float64", v)
+		}
+	}
+	return float64(v), nil
+}
+
 // ToString casts an interface{} to a string.
 func ToString(i interface{}) string {
 	// interface 转 string
@@ -381,26 +431,124 @@ func ToDurationE(i interface{}) (time.Duration, error) {
 		*float32, *float64:
 		return time.Duration(ToFloat64(s)), nil
 	case string, *string:
-		v := ToString(s)
-		if strings.ContainsAny(v, "nsuµmh") {
-			return time.ParseDuration(v)
-		} else {
-			return time.ParseDuration(v + "ns")
-		}
+		return parseDuration(strings.TrimSpace(ToString(s)))
 	default:
 		return 0, fmt.Errorf("unable to cast %#v of type %T to Duration", i, i)
 	}
 }
 
+// parseDuration 解析时间间隔字符串，没有单位时为纳秒，除了 time.ParseDuration
+// 支持的单位以外还支持以天为单位的前缀，例如 "7d" 和 "1d12h" 。
+func parseDuration(v string) (time.Duration, error) {
+	if !strings.ContainsAny(v, "nsuµmhd") {
+		return time.ParseDuration(v + "ns")
+	}
+	i := strings.IndexByte(v, 'd')
+	if i < 0 {
+		return time.ParseDuration(v)
+	}
+	days, err := strconv.ParseFloat(v[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q", v)
+	}
+	d := time.Duration(days * float64(24*time.Hour))
+	if rest := v[i+1:]; rest != "" {
+		r, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid duration %q", v)
+		}
+		if days < 0 {
+			r = -r
+		}
+		d += r
+	}
+	return d, nil
+}
+
 // ToTime casts an interface{} to a time.Time.
-func ToTime(i interface{}) time.Time {
-	v, _ := ToTimeE(i)
+func ToTime(i interface{}, layout ...string) time.Time {
+	v, _ := ToTimeE(i, layout...)
 	return v
 }
 
-// ToTimeE casts an interface{} to a time.Time.
-func ToTimeE(i interface{}) (time.Time, error) {
-	return cast.ToTimeE(i)
+// TimeLayouts 没有指定格式时解析时间字符串依次尝试的格式，没有时区信息的时间
+// 按照 UTC 时间解析。
+var TimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	"02 Jan 2006",
+}
+
+// unixMilliThreshold 绝对值不小于该值的整数时间戳作为毫秒解析，否则作为秒解析，
+// 对应的秒级时间戳在 33658 年之后，毫秒级时间戳在 2001 年之后。
+const unixMilliThreshold = 1e12
+
+// unixTime 将秒或者毫秒时间戳转换为时间。
+func unixTime(v int64) time.Time {
+	if v >= unixMilliThreshold || v <= -unixMilliThreshold {
+		return time.Unix(v/1000, v%1000*int64(time.Millisecond))
+	}
+	return time.Unix(v, 0)
+}
+
+// ToTimeE casts an interface{} to a time.Time. 整数和只包含数字的字符串作为
+// 秒或者毫秒时间戳解析，浮点数作为带小数的秒时间戳解析，其他字符串使用 layout
+// 指定的格式解析，没有指定格式时依次尝试 TimeLayouts 中的格式。
+func ToTimeE(i interface{}, layout ...string) (time.Time, error) {
+	if i == nil {
+		return time.Time{}, nil
+	}
+	switch s := i.(type) {
+	case time.Time:
+		return s, nil
+	case *time.Time:
+		return *s, nil
+	case int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8,
+		*int, *int64, *int32, *int16, *int8, *uint, *uint64, *uint32, *uint16, *uint8:
+		return unixTime(ToInt64(s)), nil
+	case float32, float64,
+		*float32, *float64:
+		f := ToFloat64(s)
+		sec := int64(f)
+		return time.Unix(sec, int64((f-float64(sec))*float64(time.Second))), nil
+	case string, *string:
+		return parseTime(strings.TrimSpace(ToString(s)), layout)
+	default:
+		return time.Time{}, fmt.Errorf("unable to cast %#v of type %T to Time", i, i)
+	}
+}
+
+func parseTime(v string, layouts []string) (time.Time, error) {
+	if len(layouts) == 0 {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return unixTime(n), nil
+		}
+		layouts = TimeLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", v)
 }
 
 // ToStringSlice casts an interface to a []string type.
@@ -411,15 +559,138 @@ func ToStringSlice(i interface{}) []string {
 
 // ToStringSliceE casts an interface to a []string type.
 func ToStringSliceE(i interface{}) ([]string, error) {
-	// TODO 使用具体的类型判断，看看是否有更好的性能。
+	switch s := i.(type) {
+	case []string:
+		return append([]string(nil), s...), nil
+	case []interface{}:
+		slice := make([]string, len(s))
+		for j, v := range s {
+			slice[j] = ToString(v)
+		}
+		return slice, nil
+	case []int:
+		slice := make([]string, len(s))
+		for j, v := range s {
+			slice[j] = strconv.Itoa(v)
+		}
+		return slice, nil
+	case []int64:
+		slice := make([]string, len(s))
+		for j, v := range s {
+			slice[j] = strconv.FormatInt(v, 10)
+		}
+		return slice, nil
+	case []bool:
+		slice := make([]string, len(s))
+		for j, v := range s {
+			slice[j] = strconv.FormatBool(v)
+		}
+		return slice, nil
+	case []float64:
+		slice := make([]string, len(s))
+		for j, v := range s {
+			slice[j] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return slice, nil
+	}
 	switch v := reflect.ValueOf(i); v.Kind() {
 	case reflect.Slice, reflect.Array:
-		var slice []string
+		slice := make([]string, v.Len())
 		for j := 0; j < v.Len(); j++ {
-			s := ToString(v.Index(j).Interface())
-			slice = append(slice, s)
+			slice[j] = ToString(v.Index(j).Interface())
 		}
 		return slice, nil
 	}
 	return nil, fmt.Errorf("unable to cast %#v of type %T to []string", i, i)
 }
+
+// ToIntSlice casts an interface to a []int type.
+func ToIntSlice(i interface{}) []int {
+	v, _ := ToIntSliceE(i)
+	return v
+}
+
+// ToIntSliceE casts an interface to a []int type, 元素转换失败时返回错误。
+func ToIntSliceE(i interface{}) ([]int, error) {
+	switch s := i.(type) {
+	case []int:
+		return append([]int(nil), s...), nil
+	case []string:
+		slice := make([]int, len(s))
+		for j, v := range s {
+			n, err := strconv.ParseInt(v, 0, 0)
+			if err != nil {
+				return nil, fmt.Errorf("unable to cast %q of type string to int", v)
+			}
+			slice[j] = int(n)
+		}
+		return slice, nil
+	case []interface{}:
+		slice := make([]int, len(s))
+		for j, v := range s {
+			n, err := ToInt64E(v)
+			if err != nil {
+				return nil, err
+			}
+			slice[j] = int(n)
+		}
+		return slice, nil
+	case []int64:
+		slice := make([]int, len(s))
+		for j, v := range s {
+			slice[j] = int(v)
+		}
+		return slice, nil
+	}
+	switch v := reflect.ValueOf(i); v.Kind() {
+	case reflect.Slice, reflect.Array:
+		slice := make([]int, v.Len())
+		for j := 0; j < v.Len(); j++ {
+			n, err := ToInt64E(v.Index(j).Interface())
+			if err != nil {
+				return nil, err
+			}
+			slice[j] = int(n)
+		}
+		return slice, nil
+	}
+	return nil, fmt.Errorf("unable to cast %#v of type %T to []int", i, i)
+}
+
+// ToStringMapString casts an interface to a map[string]string type.
+func ToStringMapString(i interface{}) map[string]string {
+	v, _ := ToStringMapStringE(i)
+	return v
+}
+
+// ToStringMapStringE casts an interface to a map[string]string type.
+func ToStringMapStringE(i interface{}) (map[string]string, error) {
+	switch m := i.(type) {
+	case map[string]string:
+		r := make(map[string]string, len(m))
+		for k, v := range m {
+			r[k] = v
+		}
+		return r, nil
+	case map[string]interface{}:
+		r := make(map[string]string, len(m))
+		for k, v := range m {
+			r[k] = ToString(v)
+		}
+		return r, nil
+	case map[interface{}]interface{}:
+		r := make(map[string]string, len(m))
+		for k, v := range m {
+			r[ToString(k)] = ToString(v)
+		}
+		return r, nil
+	}
+	if v := reflect.ValueOf(i); v.Kind() == reflect.Map {
+		r := make(map[string]string, v.Len())
+		for _, k := range v.MapKeys() {
+			r[ToString(k.Interface())] = ToString(v.MapIndex(k).Interface())
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("unable to cast %#v of type %T to map[string]string", i, i)
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cast_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/cast"
+)
+
+func TestToDurationE(t *testing.T) {
+	tests := []struct {
+		v interface{}
+		d time.Duration
+	}{
+		{"3s", 3 * time.Second},
+		{"100", 100},
+		{"7d", 7 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"-1d1h", -25 * time.Hour},
+		{int64(5), 5},
+	}
+	for _, tt := range tests {
+		d, err := cast.ToDurationE(tt.v)
+		assert.Nil(t, err)
+		assert.Equal(t, d, tt.d)
+	}
+	_, err := cast.ToDurationE("xd")
+	assert.Error(t, err, "time: invalid duration \"xd\"")
+}
+
+func TestToTimeE(t *testing.T) {
+	want := time.Date(2021, 6, 1, 8, 30, 0, 0, time.UTC)
+	tests := []struct {
+		v      interface{}
+		layout []string
+	}{
+		{"2021-06-01T08:30:00Z", nil},
+		{"2021-06-01 08:30:00", nil},
+		{"2021/06/01 08:30:00", nil},
+		{"01/06/2021 08:30", []string{"02/01/2006 15:04"}},
+		{want.Unix(), nil},
+		{want.UnixNano() / int64(time.Millisecond), nil},
+		{"1622536200", nil},
+		{"1622536200000", nil},
+		{float64(want.Unix()), nil},
+	}
+	for _, tt := range tests {
+		v, err := cast.ToTimeE(tt.v, tt.layout...)
+		assert.Nil(t, err)
+		assert.True(t, v.Equal(want))
+	}
+	_, err := cast.ToTimeE("2021-06-01", "2006/01/02")
+	assert.Error(t, err, "unable to parse date: 2021-06-01")
+	_, err = cast.ToTimeE([]int{1})
+	assert.Error(t, err, "unable to cast .* to Time")
+}
+
+func TestToStringSliceE(t *testing.T) {
+	tests := []interface{}{
+		[]string{"1", "2"},
+		[]interface{}{"1", 2},
+		[]int{1, 2},
+		[]int64{1, 2},
+		[]uint8{1, 2},
+		[2]int{1, 2},
+	}
+	for _, v := range tests {
+		s, err := cast.ToStringSliceE(v)
+		assert.Nil(t, err)
+		assert.Equal(t, s, []string{"1", "2"})
+	}
+	_, err := cast.ToStringSliceE(1)
+	assert.Error(t, err, "unable to cast 1 of type int to \\[\\]string")
+}
+
+func TestToIntSliceE(t *testing.T) {
+	tests := []interface{}{
+		[]int{1, 2},
+		[]string{"1", "0x2"},
+		[]interface{}{"1", int8(2)},
+		[]int64{1, 2},
+		[]float64{1, 2},
+	}
+	for _, v := range tests {
+		s, err := cast.ToIntSliceE(v)
+		assert.Nil(t, err)
+		assert.Equal(t, s, []int{1, 2})
+	}
+	_, err := cast.ToIntSliceE([]string{"1", "a"})
+	assert.Error(t, err, "unable to cast \"a\" of type string to int")
+}
+
+func TestToStringMapStringE(t *testing.T) {
+	tests := []interface{}{
+		map[string]string{"a": "1"},
+		map[string]interface{}{"a": 1},
+		map[interface{}]interface{}{"a": true, "b": 2},
+		map[string]int{"a": 1},
+	}
+	want := []map[string]string{
+		{"a": "1"},
+		{"a": "1"},
+		{"a": "true", "b": "2"},
+		{"a": "1"},
+	}
+	for i, v := range tests {
+		m, err := cast.ToStringMapStringE(v)
+		assert.Nil(t, err)
+		assert.Equal(t, m, want[i])
+	}
+	_, err := cast.ToStringMapStringE("a")
+	assert.Error(t, err, "unable to cast \"a\" of type string to map\\[string\\]string")
+}
+
+func BenchmarkToStringSlice(b *testing.B) {
+	v := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	b.Run("fast", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cast.ToStringSlice(v)
+		}
+	})
+	u := []uint{1, 2, 3, 4, 5, 6, 7, 8}
+	b.Run("reflect", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cast.ToStringSlice(u)
+		}
+	})
+}
+
+func TestNumericOverflow(t *testing.T) {
+
+	_, err := cast.ToInt64E(uint64(math.MaxUint64))
+	assert.Error(t, err, "overflows int64")
+
+	_, err = cast.ToInt64E(1.5)
+	assert.Error(t, err, "without loss")
+
+	_, err = cast.ToInt64E(1e19)
+	assert.Error(t, err, "without loss")
+
+	_, err = cast.ToInt64E("9223372036854775808")
+	assert.Error(t, err, "value out of range")
+
+	i, err := cast.ToInt64E("9223372036854775807")
+	assert.Nil(t, err)
+	assert.Equal(t, i, int64(math.MaxInt64))
+
+	u, err := cast.ToUint64E(uint64(math.MaxUint64))
+	assert.Nil(t, err)
+	assert.Equal(t, u, uint64(math.MaxUint64))
+
+	_, err = cast.ToUint64E(-1)
+	assert.Error(t, err, "unable to cast")
+
+	_, err = cast.ToFloat64E(int64(1<<53 + 1))
+	assert.Error(t, err, "loses precision")
+
+	f, err := cast.ToFloat64E(int64(1 << 53))
+	assert.Nil(t, err)
+	assert.Equal(t, f, float64(1<<53))
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cast
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrUnsupported 没有注册转换器并且不是基本类型时返回的错误。
+var ErrUnsupported = errors.New("unsupported cast type")
+
+var converters struct {
+	mutex sync.RWMutex
+	m     map[reflect.Type]reflect.Value
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func init() {
+	Register(func(i interface{}) (time.Time, error) { return ToTimeE(i) })
+	Register(func(i interface{}) (time.Duration, error) { return ToDurationE(i) })
+}
+
+// Register 注册类型转换器，转换器的函数原型为 func(string)(type,error) 或者
+// func(interface{})(type,error)，前者的参数会先转换为字符串。同一类型后注册的
+// 转换器覆盖先注册的转换器。
+func Register(fn interface{}) {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 2 ||
+		t.Out(1) != errorType || (t.In(0).Kind() != reflect.String && t.In(0).Kind() != reflect.Interface) {
+		panic(errors.New("fn must be func(string)(type,error) or func(interface{})(type,error)"))
+	}
+	converters.mutex.Lock()
+	defer converters.mutex.Unlock()
+	if converters.m == nil {
+		converters.m = make(map[reflect.Type]reflect.Value)
+	}
+	converters.m[t.Out(0)] = reflect.ValueOf(fn)
+}
+
+// HasConverter 返回 t 类型是否注册了转换器。
+func HasConverter(t reflect.Type) bool {
+	_, ok := getConverter(t)
+	return ok
+}
+
+func getConverter(t reflect.Type) (reflect.Value, bool) {
+	converters.mutex.RLock()
+	defer converters.mutex.RUnlock()
+	fn, ok := converters.m[t]
+	return fn, ok
+}
+
+// ToValueE 将 i 转换为 t 类型的值，优先使用注册的转换器，没有转换器时按照基本
+// 类型进行转换，基本类型包括 bool、整数、浮点数、字符串以及以它们为基础的自定义
+// 类型，t 为指针类型时转换为指向转换结果的指针。
+func ToValueE(i interface{}, t reflect.Type) (reflect.Value, error) {
+
+	if fn, ok := getConverter(t); ok {
+		in := reflect.ValueOf(i)
+		if fn.Type().In(0).Kind() == reflect.String {
+			s, err := ToStringE(i)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			in = reflect.ValueOf(s).Convert(fn.Type().In(0))
+		} else if i == nil {
+			in = reflect.Zero(fn.Type().In(0))
+		}
+		out := fn.Call([]reflect.Value{in})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return reflect.Value{}, err
+		}
+		return out[0], nil
+	}
+
+	if v := reflect.ValueOf(i); v.IsValid() && v.Type() == t {
+		return v, nil
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Ptr:
+		e, err := ToValueE(i, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(e)
+		return p, nil
+	case reflect.Bool:
+		b, err := ToBoolE(i)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := ToInt64E(i)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if v.OverflowInt(n) {
+			return reflect.Value{}, fmt.Errorf("value %d overflows %s", n, t)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := ToUint64E(i)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if v.OverflowUint(n) {
+			return reflect.Value{}, fmt.Errorf("value %d overflows %s", n, t)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := ToFloat64E(i)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if v.OverflowFloat(f) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", f, t)
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, err := ToStringE(i)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetString(s)
+	default:
+		return reflect.Value{}, fmt.Errorf("%w %s", ErrUnsupported, t)
+	}
+	return v, nil
+}
+
+// ToE 将 i 转换为 out 指向的类型并保存到 out 中，转换规则参见 ToValueE 。因为
+// 模块需要兼容 Go 1.14 ，所以使用指针参数代替泛型。
+func ToE(i interface{}, out interface{}) error {
+	ov := reflect.ValueOf(out)
+	if ov.Kind() != reflect.Ptr || ov.IsNil() {
+		return errors.New("out should be ptr and not nil")
+	}
+	v, err := ToValueE(i, ov.Type().Elem())
+	if err != nil {
+		return err
+	}
+	ov.Elem().Set(v)
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cast_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/cast"
+)
+
+type point struct{ X, Y string }
+
+func TestToE(t *testing.T) {
+
+	var i8 int8
+	assert.Nil(t, cast.ToE("12", &i8))
+	assert.Equal(t, i8, int8(12))
+
+	err := cast.ToE("300", &i8)
+	assert.Error(t, err, "value 300 overflows int8")
+
+	var d time.Duration
+	assert.Nil(t, cast.ToE("1d", &d))
+	assert.Equal(t, d, 24*time.Hour)
+
+	var p *float64
+	assert.Nil(t, cast.ToE("1.5", &p))
+	assert.Equal(t, *p, 1.5)
+
+	var m map[string]string
+	err = cast.ToE("a", &m)
+	assert.True(t, errors.Is(err, cast.ErrUnsupported))
+
+	cast.Register(func(s string) (point, error) {
+		ss := strings.Split(s, ",")
+		if len(ss) != 2 {
+			return point{}, errors.New("bad point")
+		}
+		return point{ss[0], ss[1]}, nil
+	})
+
+	var pt point
+	assert.Nil(t, cast.ToE("1,2", &pt))
+	assert.Equal(t, pt, point{"1", "2"})
+	assert.Error(t, cast.ToE("1", &pt), "bad point")
+}
@@ -75,9 +75,9 @@ func BindValue(p *Properties, v reflect.Value, param BindParam) error {
 		return bindSlice(p, v, param)
 	}
 
-	fn := converters[param.Type]
+	hasConverter := cast.HasConverter(param.Type)
 	if v.Kind() == reflect.Struct {
-		if fn == nil {
+		if !hasConverter {
 			return bindStruct(p, v, param)
 		}
 	}
@@ -87,52 +87,16 @@ func BindValue(p *Properties, v reflect.Value, param BindParam) error {
 		return util.Wrapf(err, code.Line(), "type %q bind error", param.Type)
 	}
 
-	if fn != nil {
-		fnValue := reflect.ValueOf(fn)
-		out := fnValue.Call([]reflect.Value{reflect.ValueOf(val)})
-		if !out[1].IsNil() {
-			return out[1].Interface().(error)
-		}
-		v.Set(out[0])
+	out, err := cast.ToValueE(val, v.Type())
+	if err == nil {
+		v.Set(out)
 		return nil
 	}
-
-	switch v.Kind() {
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		u, err := cast.ToUint64E(val)
-		if err == nil {
-			v.SetUint(u)
-			return nil
-		}
-		return util.Errorf(code.Line(), "%+v %w", param, err)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, err := cast.ToInt64E(val)
-		if err == nil {
-			v.SetInt(i)
-			return nil
-		}
-		return util.Errorf(code.Line(), "%+v %w", param, err)
-	case reflect.Float32, reflect.Float64:
-		f, err := cast.ToFloat64E(val)
-		if err == nil {
-			v.SetFloat(f)
-			return nil
-		}
-		return util.Errorf(code.Line(), "%+v %w", param, err)
-	case reflect.Bool:
-		b, err := cast.ToBoolE(val)
-		if err == nil {
-			v.SetBool(b)
-			return nil
-		}
-		return util.Errorf(code.Line(), "%+v %w", param, err)
-	case reflect.String:
-		s, err := cast.ToStringE(val)
-		if err == nil {
-			v.SetString(s)
-			return nil
-		}
-		return util.Errorf(code.Line(), "%+v %w", param, err)
+	if hasConverter {
+		return withOrigin(p, param.Key, err)
+	}
+	if !errors.Is(err, cast.ErrUnsupported) {
+		return withOrigin(p, param.Key, fmt.Errorf(code.Line()+" %+v %w", param, err))
 	}
 
 	return util.Errorf(code.Line(), "unsupported bind type %q", param.Type.String())
@@ -258,7 +222,7 @@ func bindMap(p *Properties, v reflect.Value, param BindParam) error {
 		for i, s := range keyPath {
 			vt, ok := t[s]
 			if !ok {
-				return util.Errorf(code.Line(), "property %q %w", param.Key, ErrNotExist)
+				return fmt.Errorf(code.Line()+" property %q %w", param.Key, ErrNotExist)
 			}
 			if _, ok = vt.(struct{}); ok {
 				oldKey := strings.Join(keyPath[:i+1], ".")
@@ -428,11 +392,33 @@ func resolveString(p *Properties, s string) (string, error) {
 // resolve 解析 ${key:=def} 字符串，返回 key 对应的属性值，如果没有找到则返回
 // def 值，如果 def 存在引用则递归解析直到获取最终的属性值。
 func resolve(p *Properties, param BindParam) (string, error) {
-	if val, ok := p.m[param.Key]; ok {
-		return resolveString(p, val)
+	if val, ok := p.m.Get(param.Key); ok {
+		p.markUsed(param.Key)
+		return resolveString(p, val.(string))
+	}
+	if r, key, ok := getResolver(param.Key); ok {
+		val, err := r(key)
+		if err == nil {
+			return val, nil
+		}
+		if !errors.Is(err, ErrNotExist) || !param.hasDef {
+			return "", util.Wrapf(err, code.Line(), "resolve property %q error", param.Key)
+		}
 	}
 	if param.hasDef {
 		return resolveString(p, param.def)
 	}
-	return "", util.Errorf(code.Line(), "property %q %w", param.Key, ErrNotExist)
+	return "", fmt.Errorf(code.Line()+" property %q %w", param.Key, ErrNotExist)
+}
+
+// withOrigin 为属性绑定的错误添加属性的来源 (例如文件名和行号)，方便定位需要修改
+// 的配置项，属性的来源未知时原样返回错误。
+func withOrigin(p *Properties, key string, err error) error {
+	if p == nil {
+		return err
+	}
+	if origin := p.Origin(key); origin != "" {
+		return fmt.Errorf("%w (property %q defined in %s)", err, key, origin)
+	}
+	return err
 }
@@ -25,25 +25,34 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/go-spring/spring-base/cast"
 	"github.com/go-spring/spring-base/util"
 )
 
-// Properties 提供创建和读取属性列表的方法。它使用扁平的 map[string]string 结
-// 构存储数据，属性的 key 可以是 a.b.c 或者 a[0].b 两种形式，a.b.c 表示从 map
+// Properties 提供创建和读取属性列表的方法。它使用扁平的有序 map 结构存储数
+// 据，属性的 key 可以是 a.b.c 或者 a[0].b 两种形式，a.b.c 表示从 map
 // 结构中获取属性值，a[0].b 表示从切片结构中获取属性值，并且 key 是大小写敏感的。
 type Properties struct {
-	m map[string]string      // 一维，存储 key 和 value。
+	m *util.OrderedMap       // 一维，按照插入顺序存储 key 和 value。
 	t map[string]interface{} // 树形，存储 key 的节点路由。
+
+	usedLock sync.Mutex
+	used     map[string]struct{} // 被读取过的 key 。
+
+	origins map[string]string // key 的来源，例如文件名、环境变量等。
 }
 
 // New 返回一个空的属性列表。
 func New() *Properties {
 	return &Properties{
-		m: make(map[string]string),
-		t: make(map[string]interface{}),
+		m:       util.NewOrderedMap(),
+		t:       make(map[string]interface{}),
+		used:    make(map[string]struct{}),
+		origins: make(map[string]string),
 	}
 }
 
@@ -108,22 +117,58 @@ func (p *Properties) Bytes(b []byte, ext string) error {
 	if err != nil {
 		return err
 	}
+	return p.setMap(m)
+}
 
-	for k, v := range m {
-		if err = p.Set(k, v); err != nil {
+// setMap 按照 key 的字典序将 map 数据添加到属性列表。
+func (p *Properties) setMap(m map[string]interface{}) error {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := p.Set(k, m[k]); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// Keys 返回所有属性 key 的列表。
-func (p *Properties) Keys() []string {
-	keys := make([]string, 0, len(p.m))
-	for k := range p.m {
-		keys = append(keys, k)
+// Documents 返回由 []byte 创建的多个属性列表，每个文档对应一个属性列表，ext 是
+// 文件扩展名。不支持多文档的文件格式只返回一个属性列表。
+func Documents(b []byte, ext string) ([]*Properties, error) {
+
+	r, ok := docReaders[ext]
+	if !ok {
+		p, err := Bytes(b, ext)
+		if err != nil {
+			return nil, err
+		}
+		return []*Properties{p}, nil
 	}
-	return keys
+
+	docs, err := r(b)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*Properties, 0, len(docs))
+	for _, m := range docs {
+		p := New()
+		if err = p.setMap(m); err != nil {
+			return nil, err
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// Keys 按照插入顺序返回所有属性 key 的列表。
+func (p *Properties) Keys() []string {
+	return p.m.Keys()
 }
 
 func (p *Properties) convertKey(key string) string {
@@ -236,8 +281,9 @@ func Def(v string) GetOption {
 // Get 方法的返回值是否为 nil 来判断 key 对应的属性值是否存在。
 func (p *Properties) Get(key string, opts ...GetOption) string {
 
-	if val, ok := p.m[key]; ok {
-		return val
+	if val, ok := p.m.Get(key); ok {
+		p.markUsed(key)
+		return val.(string)
 	}
 
 	arg := getArg{}
@@ -256,10 +302,14 @@ func (p *Properties) Set(key string, val interface{}) error {
 	switch v := reflect.ValueOf(val); v.Kind() {
 	case reflect.Map:
 		if v.Len() == 0 {
-			p.m[key] = ""
+			p.m.Set(key, "")
 			return p.checkKey(key, true)
 		}
-		for _, k := range v.MapKeys() {
+		mapKeys := v.MapKeys()
+		sort.Slice(mapKeys, func(i, j int) bool {
+			return cast.ToString(mapKeys[i].Interface()) < cast.ToString(mapKeys[j].Interface())
+		})
+		for _, k := range mapKeys {
 			mapValue := v.MapIndex(k).Interface()
 			mapKey := cast.ToString(k.Interface())
 			err := p.Set(key+"."+mapKey, mapValue)
@@ -267,12 +317,10 @@ func (p *Properties) Set(key string, val interface{}) error {
 				return err
 			}
 		}
-		if _, ok := p.m[key]; ok {
-			delete(p.m, key)
-		}
+		p.m.Delete(key)
 	case reflect.Array, reflect.Slice:
 		if v.Len() == 0 {
-			p.m[key] = ""
+			p.m.Set(key, "")
 			return p.checkKey(key, true)
 		}
 		if util.IsPrimitiveValueType(v.Type().Elem()) {
@@ -290,17 +338,76 @@ func (p *Properties) Set(key string, val interface{}) error {
 					return err
 				}
 			}
-			if _, ok := p.m[key]; ok {
-				delete(p.m, key)
-			}
+			p.m.Delete(key)
 		}
 	default:
-		p.m[key] = cast.ToString(val)
+		p.m.Set(key, cast.ToString(val))
 		return p.checkKey(key, false)
 	}
 	return nil
 }
 
+// markUsed 记录 key 被读取过。
+func (p *Properties) markUsed(key string) {
+	p.usedLock.Lock()
+	defer p.usedLock.Unlock()
+	p.used[key] = struct{}{}
+}
+
+// Used 按照插入顺序返回通过 Get 、Bind 或者属性引用读取过的属性 key 的列表。
+func (p *Properties) Used() []string {
+	return p.usage(true)
+}
+
+// Unused 按照插入顺序返回从未被读取过的属性 key 的列表，可以用于发现拼写错误
+// 或者已经废弃的配置项，例如把 server.port 写成了 server.prot 。
+func (p *Properties) Unused() []string {
+	return p.usage(false)
+}
+
+func (p *Properties) usage(used bool) []string {
+	p.usedLock.Lock()
+	defer p.usedLock.Unlock()
+	var keys []string
+	for _, key := range p.m.Keys() {
+		if _, ok := p.used[key]; ok == used {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// SetOrigin 设置 key 的来源，例如文件名、环境变量或者命令行参数。
+func (p *Properties) SetOrigin(key string, origin string) {
+	p.origins[key] = origin
+}
+
+// Origin 返回 key 的来源，未知时返回空字符串。
+func (p *Properties) Origin(key string) string {
+	return p.origins[key]
+}
+
+// Property 属性的值和来源。
+type Property struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// Dump 按照插入顺序返回所有属性的值和来源，不会把属性标记为被读取过。
+func (p *Properties) Dump() []Property {
+	var ret []Property
+	for _, key := range p.m.Keys() {
+		val, _ := p.m.Get(key)
+		ret = append(ret, Property{
+			Key:    key,
+			Value:  val.(string),
+			Origin: p.origins[key],
+		})
+	}
+	return ret
+}
+
 // Resolve 解析字符串中包含的所有属性引用即 ${key:=def} 的内容，并且支持递归引用。
 func (p *Properties) Resolve(s string) (string, error) {
 	return resolveString(p, s)
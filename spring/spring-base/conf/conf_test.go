@@ -17,9 +17,12 @@
 package conf_test
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -611,7 +614,7 @@ func TestBindMap(t *testing.T) {
 		p := conf.Map(map[string]interface{}{"a.b1": "ab1"})
 		var r map[string]string
 		err := p.Bind(&r)
-		assert.Error(t, err, "bind.go:87 type \"string\" bind error\nbind.go:437 property \"a\" not exist")
+		assert.Error(t, err, "bind.go:87 type \"string\" bind error\nbind.go:411 property \"a\" not exist")
 	})
 
 	t.Run("", func(t *testing.T) {
@@ -717,3 +720,359 @@ func TestProperties_Set(t *testing.T) {
 	assert.Equal(t, p.Get("b"), "1,11,111")
 	assert.Equal(t, p.Get("c"), "1,1.1,1.11")
 }
+
+func TestProperties_Keys(t *testing.T) {
+	p := conf.New()
+	_ = p.Set("z", 1)
+	_ = p.Set("a", map[string]interface{}{"y": 1, "b": []string{"1"}})
+	_ = p.Set("m", []map[string]int{{"k": 1}})
+	_ = p.Set("z", 2)
+	assert.Equal(t, p.Keys(), []string{"z", "a.b", "a.y", "m[0].k"})
+}
+
+func TestProperties_Merge(t *testing.T) {
+
+	newProperties := func() *conf.Properties {
+		p := conf.New()
+		_ = p.Set("servers[0].host", "a")
+		_ = p.Set("servers[0].port", "80")
+		_ = p.Set("servers[1].host", "b")
+		_ = p.Set("servers[2].host", "c")
+		_ = p.Set("tags", "x,y")
+		return p
+	}
+
+	src := conf.New()
+	_ = src.Set("servers[0].host", "d")
+	_ = src.Set("servers[1].host", "e")
+	_ = src.Set("name", "test")
+
+	t.Run("replace", func(t *testing.T) {
+		p := newProperties()
+		err := p.Merge(src)
+		assert.Nil(t, err)
+		assert.Equal(t, p.Keys(), []string{"tags", "servers[0].host", "servers[1].host", "name"})
+		assert.Equal(t, p.Get("servers[0].host"), "d")
+		assert.False(t, p.Has("servers[0].port"))
+		assert.False(t, p.Has("servers[2].host"))
+		var s []struct {
+			Host string `value:"${host}"`
+		}
+		err = p.Bind(&s, conf.Key("servers"))
+		assert.Nil(t, err)
+		assert.Equal(t, len(s), 2)
+	})
+
+	t.Run("index", func(t *testing.T) {
+		p := newProperties()
+		err := p.Merge(src, conf.MergeList(conf.ListByIndex))
+		assert.Nil(t, err)
+		assert.Equal(t, p.Get("servers[0].host"), "d")
+		assert.Equal(t, p.Get("servers[0].port"), "80")
+		assert.Equal(t, p.Get("servers[1].host"), "e")
+		assert.Equal(t, p.Get("servers[2].host"), "c")
+	})
+
+	t.Run("append", func(t *testing.T) {
+		p := newProperties()
+		err := p.Merge(src, conf.MergeList(conf.ListAppend))
+		assert.Nil(t, err)
+		assert.Equal(t, p.Get("servers[0].host"), "a")
+		assert.Equal(t, p.Get("servers[2].host"), "c")
+		assert.Equal(t, p.Get("servers[3].host"), "d")
+		assert.Equal(t, p.Get("servers[4].host"), "e")
+		assert.Equal(t, p.Get("name"), "test")
+	})
+
+	t.Run("list over value", func(t *testing.T) {
+		p := newProperties()
+		s := conf.New()
+		_ = s.Set("tags[0]", "z")
+		err := p.Merge(s, conf.MergeList(conf.ListAppend))
+		assert.Nil(t, err)
+		assert.Equal(t, p.Get("tags[0]"), "z")
+		assert.False(t, p.Has("tags[1]"))
+	})
+
+	t.Run("value over list", func(t *testing.T) {
+		p := newProperties()
+		s := conf.New()
+		_ = s.Set("servers", "")
+		err := p.Merge(s)
+		assert.Nil(t, err)
+		assert.False(t, p.Has("servers[0].host"))
+	})
+}
+
+func TestParseListMerge(t *testing.T) {
+	for _, m := range []conf.ListMerge{conf.ListReplace, conf.ListByIndex, conf.ListAppend} {
+		v, err := conf.ParseListMerge(m.String())
+		assert.Nil(t, err)
+		assert.Equal(t, v, m)
+	}
+	_, err := conf.ParseListMerge("merge")
+	assert.Error(t, err, "invalid list merge strategy \"merge\"")
+}
+
+type schemaServer struct {
+	Host string `value:"${host}" desc:"主机名"`
+	Port int    `value:"${port:=80}"`
+}
+
+type schemaNode struct {
+	Name     string       `value:"${name}"`
+	Children []schemaNode `value:"${children:=}"`
+}
+
+type schemaConfig struct {
+	Name    string                  `value:"${name:=app}" desc:"应用名称"`
+	Timeout time.Duration           `value:"${timeout:=3s}"`
+	Tags    []string                `value:"${tags:=}"`
+	Servers []schemaServer          `value:"${servers}"`
+	Zones   map[string]schemaServer `value:"${zones}"`
+	Labels  map[string]string       `value:"${labels:=}" desc:"a|b"`
+	Node    schemaNode              `value:"${node}"`
+	Owner   string
+}
+
+func TestSchema(t *testing.T) {
+
+	fields, err := conf.Schema(new(schemaConfig), conf.Key("app"))
+	assert.Nil(t, err)
+
+	var keys []string
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	assert.Equal(t, keys, []string{
+		"app.name",
+		"app.timeout",
+		"app.tags",
+		"app.servers[*].host",
+		"app.servers[*].port",
+		"app.zones.*.host",
+		"app.zones.*.port",
+		"app.labels",
+		"app.node.name",
+		"app.Owner",
+	})
+
+	assert.Equal(t, fields[0], conf.SchemaField{
+		Key:         "app.name",
+		Type:        "string",
+		Default:     "app",
+		HasDefault:  true,
+		Description: "应用名称",
+		Path:        "schemaConfig.Name",
+	})
+	assert.Equal(t, fields[1].Type, "time.Duration")
+	assert.Equal(t, fields[3].Path, "schemaConfig.Servers[*].Host")
+	assert.Equal(t, fields[3].Description, "主机名")
+	assert.False(t, fields[3].HasDefault)
+
+	md := conf.SchemaMarkdown(fields[:1])
+	assert.Equal(t, md, "| 属性 | 类型 | 默认值 | 说明 |\n| :--- | :--- | :--- | :--- |\n| app.name | string | app | 应用名称 |\n")
+	md = conf.SchemaMarkdown(fields[7:8])
+	assert.True(t, strings.Contains(md, "| app.labels | map[string]string |  | a\\|b |"))
+
+	_, err = conf.Schema(struct {
+		S schemaServer `value:"${s:=a}"`
+	}{})
+	assert.Error(t, err, "struct 类型不能指定非空默认值")
+}
+
+func TestResolver(t *testing.T) {
+
+	secrets := map[string]string{"db#password": "123456"}
+	conf.NewResolver("test-secret", func(key string) (string, error) {
+		if key == "error" {
+			return "", errors.New("connection refused")
+		}
+		if v, ok := secrets[key]; ok {
+			return v, nil
+		}
+		return "", conf.ErrNotExist
+	})
+
+	p := conf.New()
+	_ = p.Set("db.password", "${test-secret:db#password}")
+
+	var s string
+	err := p.Bind(&s, conf.Key("db.password"))
+	assert.Nil(t, err)
+	assert.Equal(t, s, "123456")
+
+	err = p.Bind(&s, conf.Tag("${test-secret:db#user:=root}"))
+	assert.Nil(t, err)
+	assert.Equal(t, s, "root")
+
+	err = p.Bind(&s, conf.Tag("${test-secret:db#user}"))
+	assert.Error(t, err, "resolve property \"test-secret:db#user\" error")
+
+	err = p.Bind(&s, conf.Tag("${test-secret:error:=root}"))
+	assert.Error(t, err, "connection refused")
+
+	err = p.Bind(&s, conf.Tag("${unknown:db#user:=root}"))
+	assert.Nil(t, err)
+	assert.Equal(t, s, "root")
+}
+
+func TestProperties_Unused(t *testing.T) {
+
+	p := conf.New()
+	_ = p.Set("server.port", "8080")
+	_ = p.Set("server.prot", "9090")
+	_ = p.Set("server.hosts", []string{"a", "b"})
+	_ = p.Set("db.url", "mysql://${db.host}")
+	_ = p.Set("db.host", "127.0.0.1")
+	_ = p.Set("db.user", "root")
+	_ = p.Set("labels.a", "1")
+	_ = p.Set("labels.b", "2")
+	_ = p.Set("routes[0].path", "/a")
+	_ = p.Set("routes[1].path", "/b")
+
+	var s struct {
+		Port   int               `value:"${server.port}"`
+		Hosts  []string          `value:"${server.hosts}"`
+		URL    string            `value:"${db.url}"`
+		Labels map[string]string `value:"${labels}"`
+		Routes []struct {
+			Path string `value:"${path}"`
+		} `value:"${routes}"`
+	}
+	err := p.Bind(&s)
+	assert.Nil(t, err)
+	assert.Equal(t, p.Unused(), []string{"server.prot", "db.user"})
+
+	assert.Equal(t, p.Get("db.user"), "root")
+	assert.Equal(t, p.Unused(), []string{"server.prot"})
+	assert.Equal(t, len(p.Used()), 9)
+}
+
+func TestProperties_Origin(t *testing.T) {
+
+	p := conf.New()
+	_ = p.Set("a", "1")
+	p.SetOrigin("a", "default")
+
+	src := conf.New()
+	_ = src.Set("b", "2")
+	_ = src.Set("c", "3")
+	src.SetOrigin("c", "env:C")
+	err := p.Merge(src, conf.MergeOrigin("application.yaml"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, p.Origin("a"), "default")
+	assert.Equal(t, p.Origin("b"), "application.yaml")
+	assert.Equal(t, p.Origin("c"), "env:C")
+	assert.Equal(t, p.Origin("d"), "")
+
+	assert.Equal(t, p.Dump(), []conf.Property{
+		{Key: "a", Value: "1", Origin: "default"},
+		{Key: "b", Value: "2", Origin: "application.yaml"},
+		{Key: "c", Value: "3", Origin: "env:C"},
+	})
+	assert.Equal(t, p.Unused(), []string{"a", "b", "c"})
+}
+
+func TestProperties_BindOverflow(t *testing.T) {
+
+	str := "max: 18446744073709551615\nbig: 9223372036854775808\nsmall: 300\n"
+	p, err := conf.Bytes([]byte(str), ".yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, p.Get("max"), "18446744073709551615")
+	assert.Equal(t, p.Get("big"), "9223372036854775808")
+
+	var u uint64
+	err = p.Bind(&u, conf.Key("max"))
+	assert.Nil(t, err)
+	assert.Equal(t, u, uint64(math.MaxUint64))
+
+	var i int64
+	err = p.Bind(&i, conf.Key("big"))
+	assert.Error(t, err, "value out of range")
+
+	var i8 int8
+	err = p.Bind(&i8, conf.Key("small"))
+	assert.Error(t, err, "overflow")
+}
+
+func TestDocuments(t *testing.T) {
+
+	str := "a: 1\n---\n---\nspring:\n  config:\n    activate:\n      on-profile: dev\na: 2\n"
+	docs, err := conf.Documents([]byte(str), ".yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, len(docs), 2)
+	assert.Equal(t, docs[0].Get("a"), "1")
+	assert.Equal(t, docs[1].Get("a"), "2")
+	assert.Equal(t, docs[1].Get("spring.config.activate.on-profile"), "dev")
+
+	docs, err = conf.Documents([]byte("a=1"), ".properties")
+	assert.Nil(t, err)
+	assert.Equal(t, len(docs), 1)
+	assert.Equal(t, docs[0].Get("a"), "1")
+
+	_, err = conf.Documents([]byte("a: [1"), ".yaml")
+	assert.Error(t, err, "yaml: line 1")
+}
+
+func TestLines(t *testing.T) {
+
+	str := `# servers
+server:
+  port: 8080
+  hosts:
+  - a
+  - b
+db:
+- name: d1
+  url: |
+    a: b
+  port: 3306
+- name: d2
+---
+a: "1"
+`
+	lines := conf.Lines([]byte(str), ".yaml")
+	assert.Equal(t, lines, []map[string]int{
+		{
+			"server":          2,
+			"server.port":     3,
+			"server.hosts":    4,
+			"server.hosts[0]": 5,
+			"server.hosts[1]": 6,
+			"db":              7,
+			"db[0]":           8,
+			"db[0].name":      8,
+			"db[0].url":       9,
+			"db[0].port":      11,
+			"db[1]":           12,
+			"db[1].name":      12,
+		},
+		{"a": 14},
+	})
+
+	lines = conf.Lines([]byte("# c\na=1\nb : 2 \\\n  c=3\nd\\=e 4\n"), ".properties")
+	assert.Equal(t, lines, []map[string]int{{"a": 2, "b": 3, "d=e": 5}})
+	assert.True(t, conf.Lines(nil, ".toml") == nil)
+
+	assert.Equal(t, conf.LineOf(lines[0], "b"), 3)
+	assert.Equal(t, conf.LineOf(map[string]int{"a": 1}, "a[0].b"), 1)
+	assert.Equal(t, conf.LineOf(map[string]int{"a": 1}, "c"), 0)
+}
+
+func TestBindOrigin(t *testing.T) {
+
+	p := conf.New()
+	_ = p.Set("server.port", "http")
+	p.SetOrigin("server.port", "application.yaml:3")
+
+	var port int
+	err := p.Bind(&port, conf.Key("server.port"))
+	assert.Error(t, err, `\(property "server.port" defined in application.yaml:3\)`)
+
+	_ = p.Set("server.timeout", "x")
+	var timeout int
+	err = p.Bind(&timeout, conf.Key("server.timeout"))
+	assert.Error(t, err, `invalid syntax$`)
+}
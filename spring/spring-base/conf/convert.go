@@ -19,23 +19,11 @@ package conf
 import (
 	"errors"
 	"reflect"
-	"time"
 
 	"github.com/go-spring/spring-base/cast"
 	"github.com/go-spring/spring-base/util"
 )
 
-var converters = map[reflect.Type]interface{}{}
-
-func init() {
-
-	// time.Time 转换函数，支持非常多的日期格式，参见 cast.StringToDate()。
-	Convert(func(s string) (time.Time, error) { return cast.ToTimeE(s) })
-
-	// time.Duration 转换函数，支持 "ns", "us" (or "µs"), "ms", "s", "m", "h" 等。
-	Convert(func(s string) (time.Duration, error) { return cast.ToDurationE(s) })
-}
-
 func validConverter(t reflect.Type) bool {
 	return t.Kind() == reflect.Func &&
 		t.NumIn() == 1 &&
@@ -45,11 +33,13 @@ func validConverter(t reflect.Type) bool {
 		util.IsErrorType(t.Out(1))
 }
 
-// Convert 注册类型转换器，转换器的函数原型为 func(string)(type,error) 。
+// Convert 注册类型转换器，转换器的函数原型为 func(string)(type,error) 。转换
+// 器注册到 cast 包中，和 cast.ToValueE 共用，time.Time 和 time.Duration 的转换
+// 器由 cast 包预先注册。
 func Convert(fn interface{}) {
 	t := reflect.TypeOf(fn)
 	if !validConverter(t) {
 		panic(errors.New("fn must be func(string)(type,error)"))
 	}
-	converters[t.Out(0)] = fn
+	cast.Register(fn)
 }
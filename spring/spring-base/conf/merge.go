@@ -0,0 +1,221 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ListMerge 合并属性列表时列表 (即 a[0] 形式的 key) 的合并策略。
+type ListMerge int
+
+const (
+	ListReplace = ListMerge(iota) // 新的列表整体替换旧的列表
+	ListByIndex                   // 按照下标逐项覆盖旧的列表
+	ListAppend                    // 新的列表追加到旧的列表之后
+)
+
+// String 返回合并策略的名称。
+func (m ListMerge) String() string {
+	switch m {
+	case ListReplace:
+		return "replace"
+	case ListByIndex:
+		return "index"
+	case ListAppend:
+		return "append"
+	}
+	return "ListMerge(" + strconv.Itoa(int(m)) + ")"
+}
+
+// ParseListMerge 解析合并策略的名称，可以是 replace、index 或者 append 。
+func ParseListMerge(s string) (ListMerge, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "replace":
+		return ListReplace, nil
+	case "index":
+		return ListByIndex, nil
+	case "append":
+		return ListAppend, nil
+	}
+	return ListReplace, fmt.Errorf("invalid list merge strategy %q", s)
+}
+
+type mergeArg struct {
+	list   ListMerge
+	origin string
+}
+
+type MergeOption func(arg *mergeArg)
+
+// MergeList 设置合并属性列表时列表的合并策略，默认为 ListReplace 。
+func MergeList(m ListMerge) MergeOption {
+	return func(arg *mergeArg) {
+		arg.list = m
+	}
+}
+
+// MergeOrigin 设置合并的属性的来源，src 中已经记录了来源的属性保持不变。
+func MergeOrigin(origin string) MergeOption {
+	return func(arg *mergeArg) {
+		arg.origin = origin
+	}
+}
+
+// Merge 将属性列表 src 合并到 p 中，src 的属性值覆盖 p 中已有的属性值。对于列
+// 表，ListReplace 策略先删除 p 中的同名列表再写入，ListByIndex 策略按照下标逐项
+// 覆盖 (旧的多余项会被保留) ，ListAppend 策略把 src 的列表项追加到 p 的列表之后。
+// 注意以逗号分隔的简单类型列表 (如 a=1,2,3) 是一个普通的属性值，总是整体替换。
+// 当新旧属性一个是列表一个是普通值时，除 ListByIndex 策略外旧的属性会被删除。
+func (p *Properties) Merge(src *Properties, opts ...MergeOption) error {
+
+	arg := mergeArg{list: ListReplace}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+
+	if arg.list == ListByIndex {
+		for _, key := range src.Keys() {
+			if err := p.merge(src, key, key, arg.origin); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// 首先确定 src 中每个列表的新起始下标，并删除需要被替换的旧属性。
+	offsets := make(map[string]int)
+	for _, key := range src.Keys() {
+		root, isList := listRoot(key)
+		if _, ok := offsets[root]; ok {
+			continue
+		}
+		offsets[root] = 0
+		if isList && arg.list == ListAppend {
+			if n := p.listLen(root); n > 0 {
+				offsets[root] = n
+				continue
+			}
+		}
+		if isList || p.listLen(root) > 0 {
+			p.remove(root)
+		}
+	}
+
+	for _, key := range src.Keys() {
+		newKey := key
+		if root, isList := listRoot(key); isList {
+			if n := offsets[root]; n > 0 {
+				newKey = shiftIndex(key, root, n)
+			}
+		}
+		if err := p.merge(src, key, newKey, arg.origin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge 把 src 中 key 的属性值和来源保存为 p 中的 newKey 。
+func (p *Properties) merge(src *Properties, key string, newKey string, origin string) error {
+	val, _ := src.m.Get(key)
+	if err := p.Set(newKey, val); err != nil {
+		return err
+	}
+	if o := src.origins[key]; o != "" {
+		origin = o
+	}
+	if origin != "" {
+		p.origins[newKey] = origin
+	} else {
+		delete(p.origins, newKey)
+	}
+	return nil
+}
+
+// listRoot 返回 key 所在的最外层列表的 key，如果 key 不属于任何列表则返回其本身。
+func listRoot(key string) (string, bool) {
+	if i := strings.IndexByte(key, '['); i >= 0 {
+		return key[:i], true
+	}
+	return key, false
+}
+
+// listIndex 返回 key 在列表 root 中的下标。
+func listIndex(key string, root string) (int, bool) {
+	if !strings.HasPrefix(key, root+"[") {
+		return 0, false
+	}
+	s := key[len(root)+1:]
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return 0, false
+	}
+	i, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// shiftIndex 将 key 在列表 root 中的下标增加 n 。
+func shiftIndex(key string, root string, n int) string {
+	i, ok := listIndex(key, root)
+	if !ok {
+		return key
+	}
+	rest := key[strings.IndexByte(key[len(root):], ']')+len(root)+1:]
+	return fmt.Sprintf("%s[%d]%s", root, i+n, rest)
+}
+
+// listLen 返回列表 root 的长度，即最大下标加一。
+func (p *Properties) listLen(root string) int {
+	n := 0
+	for _, key := range p.Keys() {
+		if i, ok := listIndex(key, root); ok && i+1 > n {
+			n = i + 1
+		}
+	}
+	return n
+}
+
+// remove 删除 key 以及它的所有子属性。
+func (p *Properties) remove(key string) {
+
+	for _, k := range p.Keys() {
+		if k == key || strings.HasPrefix(k, key+".") || strings.HasPrefix(k, key+"[") {
+			p.m.Delete(k)
+			delete(p.origins, k)
+		}
+	}
+
+	t := p.t
+	keyPath := strings.Split(p.convertKey(key), ".")
+	for i, s := range keyPath {
+		if i == len(keyPath)-1 {
+			delete(t, s)
+			return
+		}
+		m, ok := t[s].(map[string]interface{})
+		if !ok {
+			return
+		}
+		t = m
+	}
+}
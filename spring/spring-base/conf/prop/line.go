@@ -0,0 +1,71 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prop
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// Lines 返回 properties 格式的字节数组中每个属性名所在的行号，行号从 1 开始。
+func Lines(b []byte) []map[string]int {
+	lines := make(map[string]int)
+	continued := false
+	r := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; r.Scan(); n++ {
+		line := strings.TrimSpace(r.Text())
+		prev := continued
+		continued = endsWithBackslash(line)
+		if prev || line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		if key := readKey(line); key != "" {
+			if _, ok := lines[key]; !ok {
+				lines[key] = n
+			}
+		}
+	}
+	return []map[string]int{lines}
+}
+
+// endsWithBackslash 返回行尾是否有奇数个反斜杠，即下一行是否为当前行的延续。
+func endsWithBackslash(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// readKey 返回属性名，属性名以未转义的 '=' 、':' 或者空白字符结束。
+func readKey(line string) string {
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' && i+1 < len(line) {
+			i++
+			sb.WriteByte(line[i])
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' {
+			break
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -17,6 +17,8 @@
 package conf
 
 import (
+	"strings"
+
 	"github.com/go-spring/spring-base/conf/prop"
 	"github.com/go-spring/spring-base/conf/yaml"
 )
@@ -24,6 +26,9 @@ import (
 func init() {
 	NewReader(prop.Read, ".properties")
 	NewReader(yaml.Read, ".yaml", ".yml")
+	NewDocumentReader(yaml.ReadAll, ".yaml", ".yml")
+	NewLineReader(prop.Lines, ".properties")
+	NewLineReader(yaml.Lines, ".yaml", ".yml")
 }
 
 var readers = make(map[string]Reader)
@@ -37,3 +42,53 @@ func NewReader(r Reader, ext ...string) {
 		readers[s] = r
 	}
 }
+
+var docReaders = make(map[string]DocumentReader)
+
+// DocumentReader 多文档属性列表解析器，将字节数组按照文档的顺序解析成多个 map 数据。
+type DocumentReader func(b []byte) ([]map[string]interface{}, error)
+
+// NewDocumentReader 注册多文档属性列表解析器，ext 是解析器支持的文件扩展名。
+func NewDocumentReader(r DocumentReader, ext ...string) {
+	for _, s := range ext {
+		docReaders[s] = r
+	}
+}
+
+var lineReaders = make(map[string]LineReader)
+
+// LineReader 属性行号解析器，按照文档的顺序返回每个属性名所在的行号，返回值和
+// DocumentReader 的返回值一一对应。
+type LineReader func(b []byte) []map[string]int
+
+// NewLineReader 注册属性行号解析器，ext 是解析器支持的文件扩展名。
+func NewLineReader(r LineReader, ext ...string) {
+	for _, s := range ext {
+		lineReaders[s] = r
+	}
+}
+
+// Lines 返回 []byte 中每个文档的属性名所在的行号，ext 是文件扩展名，不支持的文
+// 件格式返回 nil 。
+func Lines(b []byte, ext string) []map[string]int {
+	if r, ok := lineReaders[ext]; ok {
+		return r(b)
+	}
+	return nil
+}
+
+// LineOf 返回属性 key 所在的行号，key 不存在时依次查找它的上级属性，例如 a[0].b
+// 不存在时查找 a[0] 和 a ，都不存在时返回 0 。
+func LineOf(lines map[string]int, key string) int {
+	for key != "" {
+		if n, ok := lines[key]; ok {
+			return n
+		}
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"strings"
+)
+
+var resolvers = make(map[string]Resolver)
+
+// Resolver 属性值解析器，用于解析 ${prefix:key} 形式的属性引用，例如从 Vault
+// 等密钥管理服务中获取 ${vault:secret/data/db#password} 的值。解析器返回的值
+// 不再进行属性引用的解析，找不到时应当返回 ErrNotExist 以便使用默认值。
+type Resolver func(key string) (string, error)
+
+// NewResolver 注册属性值解析器，prefix 是属性引用的前缀，不包含冒号。
+func NewResolver(prefix string, r Resolver) {
+	resolvers[prefix] = r
+}
+
+// getResolver 返回 key 的前缀对应的属性值解析器以及去掉前缀后的 key 。
+func getResolver(key string) (Resolver, string, bool) {
+	i := strings.IndexByte(key, ':')
+	if i <= 0 {
+		return nil, "", false
+	}
+	r, ok := resolvers[key[:i]]
+	if !ok {
+		return nil, "", false
+	}
+	return r, key[i+1:], true
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-spring/spring-base/cast"
+	"github.com/go-spring/spring-base/code"
+	"github.com/go-spring/spring-base/util"
+)
+
+// SchemaField 描述一个可以绑定的属性，用于生成配置参考文档和 IDE 元数据。
+type SchemaField struct {
+	Key         string `json:"key"`                   // 完整的属性名，列表元素使用 [*]，map 元素使用 *
+	Type        string `json:"type"`                  // 属性值的类型
+	Default     string `json:"default,omitempty"`     // 默认值
+	HasDefault  bool   `json:"hasDefault"`            // 是否具有默认值
+	Description string `json:"description,omitempty"` // 来自 desc 标签的描述
+	Path        string `json:"path"`                  // 绑定对象的路径
+}
+
+// Schema 按照 Bind 的规则遍历 i 的类型，返回其绑定的所有属性的描述。i 可以是
+// 对象、对象的指针或者 reflect.Type ，opts 的含义和 Bind 方法相同。结构体字段
+// 可以使用 desc 标签添加属性的描述，例如 `value:"${port:=8080}" desc:"端口"`。
+func Schema(i interface{}, opts ...BindOption) ([]SchemaField, error) {
+
+	t := util.TypeOf(i)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	arg := bindArg{tag: "${}"}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+
+	typeName := t.Name()
+	if typeName == "" { // 简单类型没有名字
+		typeName = t.String()
+	}
+
+	param := BindParam{Type: t, Path: typeName}
+	if err := param.BindTag(arg.tag); err != nil {
+		return nil, err
+	}
+
+	s := &schemaWalker{visiting: make(map[reflect.Type]bool)}
+	if err := s.walk(param, ""); err != nil {
+		return nil, err
+	}
+	return s.fields, nil
+}
+
+type schemaWalker struct {
+	fields   []SchemaField
+	visiting map[reflect.Type]bool
+}
+
+func (s *schemaWalker) walk(param BindParam, desc string) error {
+
+	if !util.IsValueType(param.Type) {
+		return util.Errorf(code.Line(), "%s 属性绑定的目标必须是值类型", param.Path)
+	}
+
+	switch param.Type.Kind() {
+	case reflect.Map:
+		if et := param.Type.Elem(); isSchemaStruct(et) {
+			return s.walkStruct(BindParam{
+				Type: et,
+				Key:  joinKey(param.Key, "*"),
+				Path: param.Path,
+			})
+		}
+		s.add(param, desc)
+		return nil
+	case reflect.Array, reflect.Slice:
+		if et := param.Type.Elem(); isSchemaStruct(et) {
+			return s.walkStruct(BindParam{
+				Type: et,
+				Key:  param.Key + "[*]",
+				Path: param.Path + "[*]",
+			})
+		}
+		s.add(param, desc)
+		return nil
+	}
+
+	if isSchemaStruct(param.Type) {
+		if param.hasDef && param.def != "" {
+			return util.Errorf(code.Line(), "%s struct 类型不能指定非空默认值", param.Path)
+		}
+		return s.walkStruct(param)
+	}
+	s.add(param, desc)
+	return nil
+}
+
+func (s *schemaWalker) add(param BindParam, desc string) {
+	s.fields = append(s.fields, SchemaField{
+		Key:         param.Key,
+		Type:        param.Type.String(),
+		Default:     param.def,
+		HasDefault:  param.hasDef,
+		Description: desc,
+		Path:        param.Path,
+	})
+}
+
+func (s *schemaWalker) walkStruct(param BindParam) error {
+
+	// 防止自引用的结构体造成无限递归。
+	if s.visiting[param.Type] {
+		return nil
+	}
+	s.visiting[param.Type] = true
+	defer delete(s.visiting, param.Type)
+
+	for i := 0; i < param.Type.NumField(); i++ {
+		ft := param.Type.Field(i)
+
+		subParam := BindParam{
+			Type: ft.Type,
+			Key:  param.Key,
+			Path: param.Path + "." + ft.Name,
+		}
+
+		if tag, ok := ft.Tag.Lookup("value"); ok {
+			if err := subParam.BindTag(tag); err != nil {
+				return err
+			}
+			if err := s.walk(subParam, ft.Tag.Get("desc")); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if ft.Anonymous {
+			if ft.Type.Kind() != reflect.Struct {
+				continue
+			}
+			if err := s.walkStruct(subParam); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if util.IsValueType(ft.Type) {
+			subParam.Key = joinKey(subParam.Key, ft.Name)
+			if err := s.walk(subParam, ft.Tag.Get("desc")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isSchemaStruct 返回 t 是否是需要展开字段的结构体，具有转换器的结构体被当作普通值。
+func isSchemaStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !cast.HasConverter(t)
+}
+
+func joinKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// SchemaMarkdown 返回属性描述的 Markdown 表格，可以直接用于配置参考文档。
+func SchemaMarkdown(fields []SchemaField) string {
+	var buf bytes.Buffer
+	buf.WriteString("| 属性 | 类型 | 默认值 | 说明 |\n")
+	buf.WriteString("| :--- | :--- | :--- | :--- |\n")
+	for _, f := range fields {
+		def := f.Default
+		if !f.HasDefault {
+			def = "(必填)"
+		}
+		fmt.Fprintf(&buf, "| %s | %s | %s | %s |\n", f.Key, f.Type,
+			escapeMarkdown(def), escapeMarkdown(f.Description))
+	}
+	return buf.String()
+}
+
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
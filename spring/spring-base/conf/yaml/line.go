@@ -0,0 +1,133 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// keyToken 映射的键的文本，支持引号括起来的键。
+var keyToken = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#'"\-\[{][^:#]*?|-[^\s:#][^:#]*?)\s*:(\s|$)`)
+
+// entry 已经扫描过的映射的键或者数组的元素。
+type entry struct {
+	col  int    // 所在的列
+	path string // 完整的属性名
+	item bool   // 是否为数组的元素
+	next int    // 下一个数组元素的索引
+}
+
+// lineScanner 按行扫描 yaml 文本，根据缩进计算每个属性名所在的行。
+type lineScanner struct {
+	stack []*entry
+	lines map[string]int
+}
+
+// Lines 返回多文档 yaml 中每个文档的属性名 (包括中间节点) 所在的行号，行号从 1
+// 开始。没有属性的文档会被忽略，和 ReadAll 的返回值一一对应。该方法只做简单的扫
+// 描，无法识别的行会被跳过。
+func Lines(b []byte) []map[string]int {
+	var ret []map[string]int
+	s := &lineScanner{lines: make(map[string]int)}
+	flush := func() {
+		if len(s.lines) > 0 {
+			ret = append(ret, s.lines)
+		}
+		s.stack = nil
+		s.lines = make(map[string]int)
+	}
+	block := -1 // 多行文本所属的键的列，-1 表示不在多行文本中
+	r := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; r.Scan(); n++ {
+		line := strings.TrimRight(r.Text(), " \t\r")
+		content := strings.TrimLeft(line, " ")
+		col := len(line) - len(content)
+		if block >= 0 {
+			if content == "" || col > block {
+				continue
+			}
+			block = -1
+		}
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+		if col == 0 && (strings.HasPrefix(line, "---") || strings.HasPrefix(line, "...")) {
+			flush()
+			continue
+		}
+		if c, ok := s.scan(n, col, content); ok {
+			block = c
+		}
+	}
+	flush()
+	return ret
+}
+
+// scan 处理从 col 列开始的内容，返回值表示该行是否开始了一个多行文本。
+func (s *lineScanner) scan(n int, col int, content string) (int, bool) {
+
+	if content == "-" || strings.HasPrefix(content, "- ") {
+		owner := s.parent(col, true)
+		if owner == nil {
+			return 0, false
+		}
+		path := fmt.Sprintf("%s[%d]", owner.path, owner.next)
+		owner.next++
+		s.lines[path] = n
+		s.stack = append(s.stack, &entry{col: col + 1, path: path, item: true})
+		rest := strings.TrimLeft(content[1:], " ")
+		if rest == "" {
+			return 0, false
+		}
+		return s.scan(n, col+len(content)-len(rest), rest)
+	}
+
+	m := keyToken.FindStringSubmatch(content)
+	if m == nil {
+		return 0, false
+	}
+
+	key := strings.Trim(m[1], `"'`)
+	if p := s.parent(col, false); p != nil {
+		key = p.path + "." + key
+	}
+	s.lines[key] = n
+	s.stack = append(s.stack, &entry{col: col, path: key})
+
+	value := strings.TrimSpace(content[len(m[0]):])
+	if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+		return col, true
+	}
+	return 0, false
+}
+
+// parent 返回从 col 列开始的内容所属的节点，dash 表示内容是否为数组的元素。数组
+// 的元素可以和所属的键处于同一列。
+func (s *lineScanner) parent(col int, dash bool) *entry {
+	for len(s.stack) > 0 {
+		e := s.stack[len(s.stack)-1]
+		if e.col < col || (dash && e.col == col && !e.item) {
+			return e
+		}
+		s.stack = s.stack[:len(s.stack)-1]
+	}
+	return nil
+}
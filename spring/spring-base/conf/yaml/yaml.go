@@ -17,15 +17,107 @@
 package yaml
 
 import (
+	"bytes"
+	"io"
+	"regexp"
+
 	"gopkg.in/yaml.v2"
 )
 
-// Read 将 yaml 格式的字节数组解析成 map 数据。
+// Read 将 yaml 格式的字节数组解析成 map 数据。超出 int64 和 uint64 范围的整数
+// 会被 yaml 解析为 float64 而丢失精度，这种情况下保留原始的文本，由属性绑定时
+// 进行检查的类型转换报告溢出错误。
 func Read(b []byte) (map[string]interface{}, error) {
-	m := make(map[string]interface{})
+	m := make(map[string]*node)
 	err := yaml.Unmarshal(b, &m)
 	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	ret := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		ret[k] = v.get()
+	}
+	return ret, nil
+}
+
+// ReadAll 将包含多个文档 (以 --- 分隔) 的 yaml 格式的字节数组按照文档的顺序解析
+// 成多个 map 数据，空文档会被忽略。
+func ReadAll(b []byte) ([]map[string]interface{}, error) {
+	var ret []map[string]interface{}
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		m := make(map[string]*node)
+		err := d.Decode(&m)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(m) == 0 {
+			continue
+		}
+		doc := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			doc[k] = v.get()
+		}
+		ret = append(ret, doc)
+	}
+	return ret, nil
+}
+
+// integerToken 十进制整数的文本。
+var integerToken = regexp.MustCompile(`^[-+]?[0-9]+$`)
+
+// node 保留了整数原始文本的 yaml 节点。
+type node struct {
+	value interface{}
+}
+
+func (n *node) get() interface{} {
+	if n == nil {
+		return nil
+	}
+	return n.value
+}
+
+func (n *node) UnmarshalYAML(unmarshal func(interface{}) error) error {
+
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+
+	switch v.(type) {
+	case map[interface{}]interface{}:
+		var m map[interface{}]*node
+		if err := unmarshal(&m); err != nil {
+			return err
+		}
+		r := make(map[interface{}]interface{}, len(m))
+		for k, e := range m {
+			r[k] = e.get()
+		}
+		n.value = r
+	case []interface{}:
+		var s []*node
+		if err := unmarshal(&s); err != nil {
+			return err
+		}
+		r := make([]interface{}, len(s))
+		for i, e := range s {
+			r[i] = e.get()
+		}
+		n.value = r
+	case float64:
+		var s string
+		if err := unmarshal(&s); err == nil && integerToken.MatchString(s) {
+			n.value = s
+			return nil
+		}
+		n.value = v
+	default:
+		n.value = v
+	}
+	return nil
 }
@@ -0,0 +1,236 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-spring/spring-base/fastdev/json"
+)
+
+// DiffOptions 比较选项，路径使用 . 分隔字段名称或者数组下标，* 匹配任意字段
+// 或者数组元素，例如 "data.items.*.id" 。
+type DiffOptions struct {
+	IgnorePaths []string // 忽略的路径，例如时间戳、请求 ID 等易变字段
+	Unordered   []string // 不考虑元素顺序的数组路径，"" 表示根节点
+	Tolerance   float64  // 数值比较允许的误差
+}
+
+const (
+	MismatchChanged    = "changed"    // 值不相同
+	MismatchMissing    = "missing"    // 实际数据缺少期望的值
+	MismatchUnexpected = "unexpected" // 实际数据多出期望之外的值
+)
+
+// Mismatch 一处差异，Path 为空表示根节点。
+type Mismatch struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Expect interface{} `json:"expect,omitempty"`
+	Actual interface{} `json:"actual,omitempty"`
+}
+
+func (m Mismatch) String() string {
+	b, _ := json.Marshal(m)
+	return string(b)
+}
+
+// Diff 比较期望的数据和实际的数据，返回按照路径排序的差异列表。数据应该是
+// JSON 反序列化的结果，数字可以是 float64 或者 json.Number 。
+func Diff(expect, actual interface{}, opts DiffOptions) []Mismatch {
+	d := &differ{opts: opts}
+	d.diff(nil, expect, actual)
+	sort.SliceStable(d.ret, func(i, j int) bool {
+		return d.ret[i].Path < d.ret[j].Path
+	})
+	return d.ret
+}
+
+// DiffJSON 比较两段 JSON 数据，数据不是 JSON 格式时返回错误。
+func DiffJSON(expect, actual []byte, opts DiffOptions) ([]Mismatch, error) {
+	var e, a interface{}
+	if err := decodeJSON(expect, &e); err != nil {
+		return nil, err
+	}
+	if err := decodeJSON(actual, &a); err != nil {
+		return nil, err
+	}
+	return Diff(e, a, opts), nil
+}
+
+type differ struct {
+	opts DiffOptions
+	ret  []Mismatch
+}
+
+func (d *differ) add(path []string, kind string, expect, actual interface{}) {
+	d.ret = append(d.ret, Mismatch{
+		Path:   strings.Join(path, "."),
+		Kind:   kind,
+		Expect: expect,
+		Actual: actual,
+	})
+}
+
+func (d *differ) diff(path []string, expect, actual interface{}) {
+
+	if matchAnyPath(d.opts.IgnorePaths, path) {
+		return
+	}
+
+	if e, ok := toFloat(expect); ok {
+		if a, ok := toFloat(actual); ok && math.Abs(e-a) <= d.opts.Tolerance {
+			return
+		}
+		d.add(path, MismatchChanged, expect, actual)
+		return
+	}
+
+	switch e := expect.(type) {
+	case map[string]interface{}:
+		if a, ok := actual.(map[string]interface{}); ok {
+			d.diffMap(path, e, a)
+			return
+		}
+	case []interface{}:
+		if a, ok := actual.([]interface{}); ok {
+			if matchAnyPath(d.opts.Unordered, path) {
+				d.diffUnordered(path, e, a)
+			} else {
+				d.diffOrdered(path, e, a)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(expect, actual) {
+		d.add(path, MismatchChanged, expect, actual)
+	}
+}
+
+func (d *differ) diffMap(path []string, expect, actual map[string]interface{}) {
+	for k, e := range expect {
+		p := appendPath(path, k)
+		if a, ok := actual[k]; ok {
+			d.diff(p, e, a)
+		} else if !matchAnyPath(d.opts.IgnorePaths, p) {
+			d.add(p, MismatchMissing, e, nil)
+		}
+	}
+	for k, a := range actual {
+		p := appendPath(path, k)
+		if _, ok := expect[k]; !ok && !matchAnyPath(d.opts.IgnorePaths, p) {
+			d.add(p, MismatchUnexpected, nil, a)
+		}
+	}
+}
+
+func (d *differ) diffOrdered(path []string, expect, actual []interface{}) {
+	for i := 0; i < len(expect) || i < len(actual); i++ {
+		p := appendPath(path, strconv.Itoa(i))
+		switch {
+		case i >= len(actual):
+			if !matchAnyPath(d.opts.IgnorePaths, p) {
+				d.add(p, MismatchMissing, expect[i], nil)
+			}
+		case i >= len(expect):
+			if !matchAnyPath(d.opts.IgnorePaths, p) {
+				d.add(p, MismatchUnexpected, nil, actual[i])
+			}
+		default:
+			d.diff(p, expect[i], actual[i])
+		}
+	}
+}
+
+// diffUnordered 为每个期望的元素查找一个没有差异的实际元素，找不到时报告缺少，
+// 剩余的实际元素报告多出。
+func (d *differ) diffUnordered(path []string, expect, actual []interface{}) {
+	used := make([]bool, len(actual))
+	for i, e := range expect {
+		p := appendPath(path, strconv.Itoa(i))
+		found := false
+		for j, a := range actual {
+			if used[j] {
+				continue
+			}
+			sub := &differ{opts: d.opts}
+			sub.diff(p, e, a)
+			if len(sub.ret) == 0 {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			d.add(p, MismatchMissing, e, nil)
+		}
+	}
+	for j, a := range actual {
+		if !used[j] {
+			d.add(appendPath(path, strconv.Itoa(j)), MismatchUnexpected, nil, a)
+		}
+	}
+}
+
+func appendPath(path []string, s string) []string {
+	p := make([]string, len(path)+1)
+	copy(p, path)
+	p[len(path)] = s
+	return p
+}
+
+// matchAnyPath 返回 path 是否匹配某一个路径模式。
+func matchAnyPath(patterns []string, path []string) bool {
+	for _, pattern := range patterns {
+		if matchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(pattern string, path []string) bool {
+	pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "$"), ".")
+	if pattern == "" {
+		return len(path) == 0
+	}
+	ss := strings.Split(pattern, ".")
+	if len(ss) != len(path) {
+		return false
+	}
+	for i, s := range ss {
+		if s != "*" && s != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+)
+
+func TestDiffJSON(t *testing.T) {
+
+	expect := `{"id":1,"price":9.99,"ts":1650000000,"tags":["a","b"],"items":[{"id":1,"rid":"x"},{"id":2,"rid":"y"}]}`
+
+	t.Run("equal", func(t *testing.T) {
+		actual := `{"items":[{"id":2,"rid":"q"},{"id":1,"rid":"p"}],"tags":["b","a"],"price":9.991,"ts":1650000001,"id":1}`
+		diffs, err := fastdev.DiffJSON([]byte(expect), []byte(actual), fastdev.DiffOptions{
+			IgnorePaths: []string{"ts", "items.*.rid"},
+			Unordered:   []string{"tags", "$.items"},
+			Tolerance:   0.01,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, len(diffs), 0)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		actual := `{"id":"1","price":9.99,"ts":1650000000,"tags":["b","a","c"],"items":[{"id":1,"rid":"x"}],"extra":true}`
+		diffs, err := fastdev.DiffJSON([]byte(expect), []byte(actual), fastdev.DiffOptions{
+			Unordered: []string{"tags"},
+		})
+		assert.Nil(t, err)
+		var ss []string
+		for _, d := range diffs {
+			ss = append(ss, d.String())
+		}
+		assert.Equal(t, ss, []string{
+			`{"path":"extra","kind":"unexpected","actual":true}`,
+			`{"path":"id","kind":"changed","expect":1,"actual":"1"}`,
+			`{"path":"items.1","kind":"missing","expect":{"id":2,"rid":"y"}}`,
+			`{"path":"tags.2","kind":"unexpected","actual":"c"}`,
+		})
+	})
+
+	_, err := fastdev.DiffJSON([]byte("{"), []byte("{}"), fastdev.DiffOptions{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev
+
+import (
+	"context"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+// detachedContext 保留父 Context 的值，但是不会随着父 Context 取消或者超时。
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}             { return nil }
+func (c detachedContext) Err() error                        { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// Detach 返回保留 ctx 上所有值 (包括录制和回放的会话 ID) 的新 Context 对象，
+// 新对象不会随着 ctx 的取消而取消，适合在上游请求结束后仍需继续执行的任务。
+func Detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+// Go 在新的 goroutine 中执行 fn ，fn 的 ctx 由 Detach(ctx) 得到，所以 fn 中的
+// 下游调用能够录制到上游请求的会话或者使用上游请求的回放数据。录制模式下录制
+// 会话会等待 fn 结束之后再保存。fn 发生 panic 时会被恢复，
+// panic 信息和调用栈以 Error 级别输出到日志。
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+
+	var s *recordSession
+	if Recording(ctx) {
+		s = getRecordSession(ctx)
+		atomic.AddInt32(&s.pending, 1)
+		s.wg.Add(1)
+	}
+
+	go func() {
+		defer func() {
+			if s != nil {
+				atomic.AddInt32(&s.pending, -1)
+				s.wg.Done()
+			}
+		}()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Ctx(ctx).Errorf("fastdev goroutine panic: %v\n%s", r, debug.Stack())
+			}
+		}()
+		fn(Detach(ctx))
+	}()
+}
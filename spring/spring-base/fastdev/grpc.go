@@ -0,0 +1,109 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev
+
+import (
+	"errors"
+	"strings"
+)
+
+// GrpcRequest 结构化的 gRPC 请求，Message 是请求消息的 JSON 格式，Proto 是
+// 请求消息的 protobuf 编码。回放时只比较 Method 和 Message 。
+type GrpcRequest struct {
+	Method   string              `json:"method"`
+	Metadata map[string][]string `json:"metadata,omitempty"`
+	Message  string              `json:"message,omitempty"`
+	Proto    []byte              `json:"proto,omitempty"`
+}
+
+// GrpcResponse 结构化的 gRPC 响应，Code 是 gRPC 状态码，Error 是错误信息，
+// Proto 为空时 (例如被脱敏) 回放使用 Message 反序列化响应消息。
+type GrpcResponse struct {
+	Code    uint32 `json:"code"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+	Proto   []byte `json:"proto,omitempty"`
+}
+
+// NewGrpcRequest 返回 GrpcRequest 对象，metadata 的键转换为小写，message
+// 使用规范的 JSON 编码。
+func NewGrpcRequest(method string, metadata map[string][]string, message, proto []byte) *GrpcRequest {
+	var md map[string][]string
+	if len(metadata) > 0 {
+		md = make(map[string][]string, len(metadata))
+		for k, v := range metadata {
+			k = strings.ToLower(k)
+			md[k] = append(md[k], v...)
+		}
+	}
+	return &GrpcRequest{
+		Method:   method,
+		Metadata: md,
+		Message:  canonicalBody(message),
+		Proto:    proto,
+	}
+}
+
+// NewGrpcResponse 返回 GrpcResponse 对象，message 使用规范的 JSON 编码。
+func NewGrpcResponse(code uint32, err string, message, proto []byte) *GrpcResponse {
+	return &GrpcResponse{
+		Code:    code,
+		Error:   err,
+		Message: canonicalBody(message),
+		Proto:   proto,
+	}
+}
+
+// Match 返回 r 和录制的请求是否匹配，录制的请求可以是 *GrpcRequest 对象或者
+// 反序列化得到的 map 。
+func (r *GrpcRequest) Match(recorded interface{}) bool {
+	req, err := ToGrpcRequest(recorded)
+	if err != nil {
+		return false
+	}
+	return req.Method == r.Method && req.Message == r.Message
+}
+
+// ToGrpcRequest 将录制数据转换为 *GrpcRequest 对象。
+func ToGrpcRequest(v interface{}) (*GrpcRequest, error) {
+	switch r := v.(type) {
+	case *GrpcRequest:
+		return r, nil
+	case nil:
+		return nil, errors.New("grpc request is nil")
+	}
+	r := new(GrpcRequest)
+	if err := convertJSON(v, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ToGrpcResponse 将录制数据转换为 *GrpcResponse 对象。
+func ToGrpcResponse(v interface{}) (*GrpcResponse, error) {
+	switch r := v.(type) {
+	case *GrpcResponse:
+		return r, nil
+	case nil:
+		return nil, errors.New("grpc response is nil")
+	}
+	r := new(GrpcResponse)
+	if err := convertJSON(v, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
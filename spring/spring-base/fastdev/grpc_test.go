@@ -0,0 +1,68 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/knife"
+)
+
+func TestGrpcReplay(t *testing.T) {
+
+	fastdev.SetReplayMode(true, false)
+	defer fastdev.SetReplayMode(false, false)
+
+	recorded := &fastdev.Session{
+		Session: fastdev.NewSessionID(),
+		Actions: []*fastdev.Action{{
+			Protocol: fastdev.GRPC,
+			Request: fastdev.NewGrpcRequest("/helloworld.Greeter/SayHello",
+				map[string][]string{"X-Trace-Id": {"1"}}, []byte(`{"name": "a"}`), []byte{0x0a, 0x01, 0x61}),
+			Response: fastdev.NewGrpcResponse(0, "", []byte(`{"message":"hello a"}`), []byte{0x0a, 0x07}),
+		}},
+	}
+	session, err := fastdev.ToSession([]byte(recorded.ToJson()), false)
+	assert.Nil(t, err)
+
+	fastdev.Store(session)
+	defer fastdev.Delete(session.Session)
+
+	ctx := knife.New(context.Background())
+	err = knife.Set(ctx, fastdev.ReplaySessionIDKey, session.Session)
+	assert.Nil(t, err)
+
+	req := fastdev.NewGrpcRequest("/helloworld.Greeter/SayHello",
+		map[string][]string{"x-trace-id": {"2"}}, []byte(`{"name":"a"}`), nil)
+	v, ok, err := fastdev.ReplayMatch(ctx, fastdev.GRPC, req.Match)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	resp, err := fastdev.ToGrpcResponse(v)
+	assert.Nil(t, err)
+	assert.Equal(t, resp, &fastdev.GrpcResponse{
+		Message: `{"message":"hello a"}`,
+		Proto:   []byte{0x0a, 0x07},
+	})
+
+	_, ok, err = fastdev.ReplayMatch(ctx, fastdev.GRPC, req.Match)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/go-spring/spring-base/fastdev/json"
+)
+
+// HttpRequest 结构化的 HTTP 请求，头部名称使用规范格式，JSON 格式的请求体
+// 按照字段名称排序并且去除空白，保证同样的请求总是得到同样的编码。
+type HttpRequest struct {
+	Method string              `json:"method"`
+	URL    string              `json:"url"`
+	Host   string              `json:"host,omitempty"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   string              `json:"body,omitempty"`
+}
+
+// HttpResponse 结构化的 HTTP 响应，编码规则同 HttpRequest 。
+type HttpResponse struct {
+	Status int                 `json:"status"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   string              `json:"body,omitempty"`
+}
+
+// NewHttpRequest 返回 r 对应的 HttpRequest 对象，body 是请求体。
+func NewHttpRequest(r *http.Request, body []byte) *HttpRequest {
+	return &HttpRequest{
+		Method: r.Method,
+		URL:    r.URL.RequestURI(),
+		Host:   r.Host,
+		Header: canonicalHeader(r.Header),
+		Body:   canonicalBody(body),
+	}
+}
+
+// NewHttpResponse 返回 HttpResponse 对象。
+func NewHttpResponse(status int, header http.Header, body []byte) *HttpResponse {
+	return &HttpResponse{
+		Status: status,
+		Header: canonicalHeader(header),
+		Body:   canonicalBody(body),
+	}
+}
+
+func canonicalHeader(h http.Header) map[string][]string {
+	if len(h) == 0 {
+		return nil
+	}
+	m := make(map[string][]string, len(h))
+	for k, v := range h {
+		k = http.CanonicalHeaderKey(k)
+		m[k] = append(m[k], v...)
+	}
+	return m
+}
+
+// canonicalBody JSON 格式的数据使用规范编码，其他数据保持不变。
+func canonicalBody(body []byte) string {
+	var v interface{}
+	if err := decodeJSON(body, &v); err == nil {
+		if _, ok := v.(string); !ok {
+			if b, err := json.Marshal(v); err == nil {
+				return string(b)
+			}
+		}
+	}
+	return string(body)
+}
+
+// Request 返回对应的 *http.Request 对象。
+func (r *HttpRequest) Request() (*http.Request, error) {
+	req, err := http.NewRequest(r.Method, r.URL, strings.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if r.Host != "" {
+		req.Host = r.Host
+	}
+	req.RequestURI = r.URL
+	return req, nil
+}
+
+// ToHttpRequest 将录制数据转换为 *HttpRequest 对象，v 可以是 *HttpRequest
+// 对象、反序列化得到的 map 或者旧版本录制的原始报文。
+func ToHttpRequest(v interface{}) (*HttpRequest, error) {
+	switch r := v.(type) {
+	case *HttpRequest:
+		return r, nil
+	case string:
+		req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(r)))
+		if err != nil {
+			return nil, err
+		}
+		defer req.Body.Close()
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		return NewHttpRequest(req, body), nil
+	case nil:
+		return nil, errors.New("http request is nil")
+	}
+	r := new(HttpRequest)
+	if err := convertJSON(v, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ToHttpResponse 将录制数据转换为 *HttpResponse 对象，同 ToHttpRequest 。
+func ToHttpResponse(v interface{}) (*HttpResponse, error) {
+	switch r := v.(type) {
+	case *HttpResponse:
+		return r, nil
+	case string:
+		resp, err := http.ReadResponse(bufio.NewReader(strings.NewReader(r)), nil)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return NewHttpResponse(resp.StatusCode, resp.Header, body), nil
+	case nil:
+		return nil, errors.New("http response is nil")
+	}
+	r := new(HttpResponse)
+	if err := convertJSON(v, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func convertJSON(from, to interface{}) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(b)).Decode(to)
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/fastdev/json"
+)
+
+func TestHttpRequest(t *testing.T) {
+
+	r := httptest.NewRequest("POST", "/users?id=1", strings.NewReader(""))
+	r.Header.Set("x-trace-id", "abc")
+	req := fastdev.NewHttpRequest(r, []byte(`{ "b": 2, "a": 1 }`))
+	b, err := json.Marshal(req)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), `{"method":"POST","url":"/users?id=1","host":"example.com","header":{"X-Trace-Id":["abc"]},"body":"{\"a\":1,\"b\":2}"}`)
+
+	req, err = fastdev.ToHttpRequest("POST /users?id=1 HTTP/1.1\r\nHost: example.com\r\nX-Trace-Id: abc\r\nContent-Length: 7\r\n\r\n{\"a\":1}")
+	assert.Nil(t, err)
+	assert.Equal(t, req, &fastdev.HttpRequest{
+		Method: "POST",
+		URL:    "/users?id=1",
+		Host:   "example.com",
+		Header: map[string][]string{"X-Trace-Id": {"abc"}, "Content-Length": {"7"}},
+		Body:   `{"a":1}`,
+	})
+
+	resp, err := fastdev.ToHttpResponse(map[string]interface{}{
+		"status": 200,
+		"header": map[string]interface{}{"Content-Type": []interface{}{"text/plain"}},
+		"body":   "ok",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, resp, &fastdev.HttpResponse{
+		Status: 200,
+		Header: map[string][]string{"Content-Type": {"text/plain"}},
+		Body:   "ok",
+	})
+}
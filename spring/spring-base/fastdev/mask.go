@@ -0,0 +1,355 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-spring/spring-base/fastdev/json"
+)
+
+// DefaultMaskReplace 默认的脱敏替换内容。
+const DefaultMaskReplace = "******"
+
+// Masker 录制数据的脱敏器，在录制会话保存之前作用于每个动作。
+type Masker interface {
+	Mask(action *Action)
+}
+
+var masker struct {
+	mutex   sync.RWMutex
+	maskers []Masker
+}
+
+// AddMasker 添加脱敏器，脱敏器按照添加的顺序执行。
+func AddMasker(m ...Masker) {
+	masker.mutex.Lock()
+	defer masker.mutex.Unlock()
+	masker.maskers = append(masker.maskers, m...)
+}
+
+// maskSession 对录制会话的上游数据和所有动作进行脱敏。
+func maskSession(session *Session) {
+
+	masker.mutex.RLock()
+	maskers := masker.maskers
+	masker.mutex.RUnlock()
+
+	if len(maskers) == 0 {
+		return
+	}
+
+	actions := session.Actions
+	if session.Inbound != nil {
+		actions = append([]*Action{session.Inbound}, actions...)
+	}
+	for _, a := range actions {
+		for _, m := range maskers {
+			m.Mask(a)
+		}
+	}
+}
+
+// MaskRule 基于规则的脱敏器。HTTP 协议的请求和响应是 HttpRequest、HttpResponse
+// 或者原始报文，Headers 和 Cookies 作用于报文头，Fields 作用于 JSON 格式的
+// 报文体；gRPC 协议的 Headers 作用于 metadata ，Fields 作用于消息，消息被
+// 修改时丢弃 protobuf 编码；其他协议的请求和响应
+// 转换为 JSON 后按照 Fields 进行脱敏。Regex 作用于所有的字符串内容，Replace
+// 可以使用 $1 等引用分组。
+type MaskRule struct {
+	Protocol string         // 生效的协议，为空时对所有协议生效
+	Headers  []string       // 脱敏的 HTTP 头，不区分大小写
+	Cookies  []string       // 脱敏的 Cookie 名称
+	Fields   []string       // 脱敏的 JSON 字段，例如 "user.password"，* 匹配任意字段或者数组元素
+	Regex    *regexp.Regexp // 脱敏的字符串模式
+	Replace  string         // 替换内容，为空时使用 DefaultMaskReplace
+}
+
+func (r *MaskRule) replace() string {
+	if r.Replace == "" {
+		return DefaultMaskReplace
+	}
+	return r.Replace
+}
+
+func (r *MaskRule) Mask(action *Action) {
+	if r.Protocol != "" && r.Protocol != action.Protocol {
+		return
+	}
+	action.Request = r.maskValue(action.Protocol, action.Request)
+	action.Response = r.maskValue(action.Protocol, action.Response)
+}
+
+func (r *MaskRule) maskValue(protocol string, v interface{}) interface{} {
+	switch s := v.(type) {
+	case nil:
+		return nil
+	case *HttpRequest:
+		body := r.maskRegex(r.maskBody(s.Body))
+		r.maskHeaders(s.Header, len(body), body != s.Body)
+		s.URL, s.Body = r.maskRegex(s.URL), body
+		return s
+	case *HttpResponse:
+		body := r.maskRegex(r.maskBody(s.Body))
+		r.maskHeaders(s.Header, len(body), body != s.Body)
+		s.Body = body
+		return s
+	case *GrpcRequest:
+		r.maskHeaders(s.Metadata, 0, false)
+		if msg := r.maskRegex(r.maskBody(s.Message)); msg != s.Message {
+			s.Message, s.Proto = msg, nil
+		}
+		return s
+	case *GrpcResponse:
+		s.Error = r.maskRegex(s.Error)
+		if msg := r.maskRegex(r.maskBody(s.Message)); msg != s.Message {
+			s.Message, s.Proto = msg, nil
+		}
+		return s
+	case string:
+		if protocol == HTTP {
+			if i := strings.Index(s, "\r\n\r\n"); i >= 0 {
+				return r.maskHTTP(s[:i], s[i+4:])
+			}
+		}
+		return r.maskText(s)
+	default:
+		if len(r.Fields) == 0 && r.Regex == nil {
+			return v
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var data interface{}
+		if err = decodeJSON(b, &data); err != nil {
+			return v
+		}
+		return r.maskJSON(data)
+	}
+}
+
+// maskText 对字符串进行脱敏，字符串是 JSON 格式时按照 Fields 进行脱敏。
+func (r *MaskRule) maskText(s string) string {
+	if len(r.Fields) > 0 {
+		var data interface{}
+		if err := decodeJSON([]byte(s), &data); err == nil {
+			if _, ok := data.(string); !ok && r.maskFields(data) {
+				if b, err := json.Marshal(data); err == nil {
+					s = string(b)
+				}
+			}
+		}
+	}
+	if r.Regex != nil {
+		s = r.Regex.ReplaceAllString(s, r.replace())
+	}
+	return s
+}
+
+// maskHTTP 对 HTTP 报文进行脱敏，报文体变化时同步修改 Content-Length 头。
+func (r *MaskRule) maskHTTP(head, body string) string {
+
+	maskedBody := r.maskBody(body)
+
+	lines := strings.Split(head, "\r\n")
+	for i := 1; i < len(lines); i++ {
+		ss := strings.SplitN(lines[i], ":", 2)
+		if len(ss) != 2 {
+			continue
+		}
+		name := strings.TrimSpace(ss[0])
+		value := strings.TrimSpace(ss[1])
+		if strings.EqualFold(name, "Content-Length") && maskedBody != body {
+			value = strconv.Itoa(len(maskedBody))
+		} else if masked := r.maskHeader(name, value); masked != value {
+			value = masked
+		} else {
+			continue
+		}
+		lines[i] = name + ": " + value
+	}
+
+	s := strings.Join(lines, "\r\n") + "\r\n\r\n" + maskedBody
+	if r.Regex != nil {
+		s = r.Regex.ReplaceAllString(s, r.replace())
+	}
+	return s
+}
+
+// maskBody 按照 Fields 对 JSON 格式的报文体进行脱敏。
+func (r *MaskRule) maskBody(body string) string {
+	if len(r.Fields) > 0 {
+		var data interface{}
+		if err := decodeJSON([]byte(body), &data); err == nil && r.maskFields(data) {
+			if b, err := json.Marshal(data); err == nil {
+				return string(b)
+			}
+		}
+	}
+	return body
+}
+
+// maskHeader 对 HTTP 头的值进行脱敏。
+func (r *MaskRule) maskHeader(name, value string) string {
+	switch {
+	case containsFold(r.Headers, name):
+		return r.replace()
+	case strings.EqualFold(name, "Cookie"):
+		return r.maskCookies(value, "; ")
+	case strings.EqualFold(name, "Set-Cookie"):
+		return r.maskCookies(value, ";")
+	}
+	return value
+}
+
+// maskHeaders 对结构化的 HTTP 头进行脱敏，报文体变化时同步修改 Content-Length 头。
+func (r *MaskRule) maskHeaders(header map[string][]string, bodyLen int, bodyChanged bool) {
+	for k, values := range header {
+		for i, v := range values {
+			if strings.EqualFold(k, "Content-Length") && bodyChanged {
+				v = strconv.Itoa(bodyLen)
+			} else {
+				v = r.maskRegex(r.maskHeader(k, v))
+			}
+			values[i] = v
+		}
+	}
+}
+
+// maskRegex 对字符串进行正则替换。
+func (r *MaskRule) maskRegex(s string) string {
+	if r.Regex == nil {
+		return s
+	}
+	return r.Regex.ReplaceAllString(s, r.replace())
+}
+
+// maskCookies 对 Cookie 头的值进行脱敏，Set-Cookie 头只有第一项是 Cookie 。
+func (r *MaskRule) maskCookies(value string, sep string) string {
+	if len(r.Cookies) == 0 {
+		return value
+	}
+	pairs := strings.Split(value, sep)
+	for i, pair := range pairs {
+		if sep == ";" && i > 0 {
+			break
+		}
+		ss := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(ss) == 2 && containsFold(r.Cookies, ss[0]) {
+			pairs[i] = ss[0] + "=" + r.replace()
+		}
+	}
+	return strings.Join(pairs, sep)
+}
+
+// maskJSON 对解析后的 JSON 数据进行脱敏。
+func (r *MaskRule) maskJSON(data interface{}) interface{} {
+	r.maskFields(data)
+	if r.Regex == nil {
+		return data
+	}
+	return r.maskStrings(data)
+}
+
+// maskStrings 对 JSON 数据中所有的字符串进行正则替换。
+func (r *MaskRule) maskStrings(data interface{}) interface{} {
+	switch v := data.(type) {
+	case string:
+		return r.Regex.ReplaceAllString(v, r.replace())
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = r.maskStrings(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = r.maskStrings(e)
+		}
+	}
+	return data
+}
+
+// maskFields 按照 Fields 对 JSON 数据进行脱敏，返回是否有字段被替换。
+func (r *MaskRule) maskFields(data interface{}) bool {
+	masked := false
+	for _, field := range r.Fields {
+		field = strings.TrimPrefix(strings.TrimPrefix(field, "$"), ".")
+		if r.maskPath(data, strings.Split(field, ".")) {
+			masked = true
+		}
+	}
+	return masked
+}
+
+func (r *MaskRule) maskPath(data interface{}, path []string) bool {
+	key, last := path[0], len(path) == 1
+	masked := false
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if key != "*" && key != k {
+				continue
+			}
+			if last {
+				v[k] = r.replace()
+				masked = true
+			} else if r.maskPath(e, path[1:]) {
+				masked = true
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			if key != "*" && key != strconv.Itoa(i) {
+				continue
+			}
+			if last {
+				v[i] = r.replace()
+				masked = true
+			} else if r.maskPath(e, path[1:]) {
+				masked = true
+			}
+		}
+	}
+	return masked
+}
+
+// decodeJSON 解析 JSON 数据，数字保持原样，存在多余的数据时返回错误。
+func decodeJSON(b []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if d.More() || len(bytes.TrimSpace(b[d.InputOffset():])) > 0 {
+		return errors.New("invalid json")
+	}
+	return nil
+}
+
+func containsFold(ss []string, s string) bool {
+	for _, e := range ss {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+)
+
+func TestMaskRule(t *testing.T) {
+
+	t.Run("http", func(t *testing.T) {
+		r := &fastdev.MaskRule{
+			Headers: []string{"authorization"},
+			Cookies: []string{"token"},
+			Fields:  []string{"$.user.password", "cards.*.no"},
+		}
+		a := &fastdev.Action{
+			Protocol: fastdev.HTTP,
+			Request: "POST /login HTTP/1.1\r\nHost: a.com\r\nAuthorization: Bearer abc\r\n" +
+				"Cookie: uid=1; token=xyz\r\nContent-Length: 64\r\n\r\n" +
+				`{"user":{"name":"tom","password":"123456"},"cards":[{"no":"62"}]}`,
+			Response: "HTTP/1.1 200 OK\r\nSet-Cookie: token=xyz; Path=/\r\n\r\nok",
+		}
+		r.Mask(a)
+		assert.Equal(t, a.Request, "POST /login HTTP/1.1\r\nHost: a.com\r\nAuthorization: ******\r\n"+
+			"Cookie: uid=1; token=******\r\nContent-Length: 69\r\n\r\n"+
+			`{"cards":[{"no":"******"}],"user":{"name":"tom","password":"******"}}`)
+		assert.Equal(t, a.Response, "HTTP/1.1 200 OK\r\nSet-Cookie: token=******; Path=/\r\n\r\nok")
+	})
+
+	t.Run("structured", func(t *testing.T) {
+		r := &fastdev.MaskRule{
+			Headers: []string{"Authorization"},
+			Cookies: []string{"token"},
+			Fields:  []string{"password"},
+		}
+		a := &fastdev.Action{
+			Protocol: fastdev.HTTP,
+			Request: &fastdev.HttpRequest{
+				Method: "POST",
+				URL:    "/login",
+				Header: map[string][]string{
+					"Authorization":  {"Bearer abc"},
+					"Cookie":         {"uid=1; token=xyz"},
+					"Content-Length": {"20"},
+				},
+				Body: `{"password":"1234"}`,
+			},
+		}
+		r.Mask(a)
+		assert.Equal(t, a.Request, &fastdev.HttpRequest{
+			Method: "POST",
+			URL:    "/login",
+			Header: map[string][]string{
+				"Authorization":  {"******"},
+				"Cookie":         {"uid=1; token=******"},
+				"Content-Length": {"21"},
+			},
+			Body: `{"password":"******"}`,
+		})
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		r := &fastdev.MaskRule{
+			Protocol: fastdev.REDIS,
+			Regex:    regexp.MustCompile(`(1\d{2})\d{4}(\d{4})`),
+			Replace:  "$1****$2",
+		}
+		a := &fastdev.Action{Protocol: fastdev.REDIS, Request: "GET phone:13812345678"}
+		r.Mask(a)
+		assert.Equal(t, a.Request, "GET phone:138****5678")
+		a = &fastdev.Action{Protocol: fastdev.HTTP, Request: "13812345678"}
+		r.Mask(a)
+		assert.Equal(t, a.Request, "13812345678")
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		r := &fastdev.MaskRule{Fields: []string{"args.1"}}
+		a := &fastdev.Action{
+			Protocol: fastdev.SQL,
+			Request: struct {
+				Query string        `json:"query"`
+				Args  []interface{} `json:"args"`
+			}{"SELECT * FROM user WHERE name=? AND password=?", []interface{}{"tom", "123456"}},
+		}
+		r.Mask(a)
+		assert.Equal(t, a.Request, map[string]interface{}{
+			"query": "SELECT * FROM user WHERE name=? AND password=?",
+			"args":  []interface{}{"tom", "******"},
+		})
+	})
+}
@@ -21,14 +21,15 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
-	"github.com/go-spring/spring-base/fastdev/json"
 	"github.com/go-spring/spring-base/knife"
 )
 
 var recorder struct {
-	mode bool     // 是否为录制模式。
-	data sync.Map // 正在录制的数据。
+	mode     bool     // 是否为录制模式。
+	disabled int32    // 运行时是否暂停录制新的请求。
+	data     sync.Map // 正在录制的数据。
 }
 
 // RecordMode 返回是否是录制模式。
@@ -36,6 +37,21 @@ func RecordMode() bool {
 	return recorder.mode
 }
 
+// SetRecordEnabled 在录制模式下运行时暂停或者恢复录制新的请求，已经开始录制
+// 的请求不受影响。
+func SetRecordEnabled(enabled bool) {
+	var v int32
+	if !enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&recorder.disabled, v)
+}
+
+// RecordEnabled 返回是否需要录制新的请求，即打开了录制模式并且没有被暂停。
+func RecordEnabled() bool {
+	return recorder.mode && atomic.LoadInt32(&recorder.disabled) == 0
+}
+
 func checkRecordMode() {
 	if !recorder.mode {
 		panic(errors.New("record mode not enabled"))
@@ -43,8 +59,10 @@ func checkRecordMode() {
 }
 
 type recordSession struct {
-	s *Session
-	m sync.Mutex
+	s       *Session
+	m       sync.Mutex
+	wg      sync.WaitGroup // 等待 Go 启动的 goroutine 结束
+	pending int32          // Go 启动的尚未结束的 goroutine 个数
 }
 
 func getRecordSession(ctx context.Context) *recordSession {
@@ -69,6 +87,12 @@ func getRecordSession(ctx context.Context) *recordSession {
 	return actual.(*recordSession)
 }
 
+// Recording 返回是否需要录制 ctx 对应的请求，即打开了录制模式并且 ctx 上绑定
+// 了会话 ID 。
+func Recording(ctx context.Context) bool {
+	return recorder.mode && GetRecordSessionID(ctx) != ""
+}
+
 // RecordAction 录制一个动作，会话 ID 从 Context 对象中获取。
 func RecordAction(ctx context.Context, action *Action) {
 
@@ -81,12 +105,30 @@ func RecordAction(ctx context.Context, action *Action) {
 	s.s.Actions = append(s.s.Actions, action)
 }
 
-// RecordInbound 录制上游流量，会话 ID 从 Context 对象中获取。
+// RecordInbound 录制上游流量，会话 ID 从 Context 对象中获取。如果还有 Go 启动
+// 的 goroutine 没有结束，录制会话会等待它们结束之后再保存。
 func RecordInbound(ctx context.Context, inbound *Action) *Session {
 
 	checkRecordMode()
 	s := getRecordSession(ctx)
 
+	s.m.Lock()
+	s.s.Inbound = inbound
+	s.m.Unlock()
+
+	if atomic.LoadInt32(&s.pending) == 0 {
+		s.save()
+	} else {
+		go func() {
+			s.wg.Wait()
+			s.save()
+		}()
+	}
+	return s.s
+}
+
+func (s *recordSession) save() {
+
 	defer func() {
 		recorder.data.Delete(s.s.Session)
 	}()
@@ -94,13 +136,7 @@ func RecordInbound(ctx context.Context, inbound *Action) *Session {
 	s.m.Lock()
 	defer s.m.Unlock()
 
-	s.s.Inbound = inbound
-
-	b, err := json.Marshal(s.s)
-	if err != nil {
-		b = []byte(err.Error())
+	if err := saveSession(s.s); err != nil {
+		fmt.Println("save record session error:", err)
 	}
-
-	fmt.Println(string(b))
-	return s.s
 }
@@ -19,6 +19,7 @@ package fastdev
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/go-spring/spring-base/assert"
 	"github.com/go-spring/spring-base/knife"
@@ -52,3 +53,76 @@ func TestRecordAction(t *testing.T) {
 	_, ok := recorder.data.Load(session.Session)
 	assert.False(t, ok)
 }
+
+func TestGo(t *testing.T) {
+
+	SetRecordMode(true)
+	defer func() {
+		SetRecordMode(false)
+	}()
+
+	var saved []*Session
+	SetStorage(storageFunc(func(s *Session) error {
+		saved = append(saved, s)
+		return nil
+	}))
+	defer SetStorage(nil)
+
+	ctx := knife.New(context.Background())
+	err := knife.Set(ctx, RecordSessionIDKey, NewSessionID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	start := make(chan struct{})
+	done := make(chan struct{})
+	Go(ctx, func(ctx context.Context) {
+		<-start
+		assert.Nil(t, ctx.Err())
+		RecordAction(ctx, &Action{Protocol: REDIS, Request: "GET a", Response: "1"})
+	})
+	Go(ctx, func(ctx context.Context) {
+		defer close(done)
+		panic("boom")
+	})
+	<-done
+
+	session := RecordInbound(ctx, &Action{Protocol: HTTP})
+	cancel()
+	assert.Equal(t, len(saved), 0)
+
+	close(start)
+	for i := 0; i < 100; i++ {
+		if _, ok := recorder.data.Load(session.Session); !ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, len(saved), 1)
+	assert.Equal(t, len(saved[0].Actions), 1)
+}
+
+type storageFunc func(s *Session) error
+
+func (f storageFunc) Save(s *Session) error {
+	return f(s)
+}
+
+func TestSetRecordEnabled(t *testing.T) {
+
+	assert.False(t, RecordEnabled())
+
+	SetRecordMode(true)
+	defer func() {
+		SetRecordMode(false)
+	}()
+	assert.True(t, RecordEnabled())
+
+	SetRecordEnabled(false)
+	assert.False(t, RecordEnabled())
+	assert.True(t, RecordMode())
+
+	SetRecordEnabled(true)
+	assert.True(t, RecordEnabled())
+}
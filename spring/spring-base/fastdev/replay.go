@@ -20,8 +20,10 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-base/util"
 )
 
 type replayData struct {
@@ -51,36 +53,89 @@ func Store(session *Session) {
 
 // ReplayAction 根据 action 传入的匹配信息返回对应的响应数据。
 func ReplayAction(ctx context.Context, action *Action) (bool, error) {
+	resp, ok, err := ReplayMatch(ctx, action.Protocol, func(request interface{}) bool {
+		return request == action.Request
+	})
+	if ok {
+		action.Response = resp
+	}
+	return ok, err
+}
+
+// ReplayMatch 查找第一个协议相同、请求满足 match 并且没有被回放过的动作，返回
+// 它的响应数据。适用于请求不能直接比较的协议，例如需要忽略部分字段的结构化请求。
+func ReplayMatch(ctx context.Context, protocol string, match func(request interface{}) bool) (interface{}, bool, error) {
 
 	if !replayer.mode {
-		return false, errors.New("replay mode not enabled")
+		return nil, false, errors.New("replay mode not enabled")
 	}
 
 	sessionID, ok := knife.Get(ctx, ReplaySessionIDKey)
 	if !ok {
-		return false, errors.New("session id not found")
+		return nil, false, errors.New("session id not found")
 	}
 
 	value, ok := replayer.data.Load(sessionID.(string))
 	if !ok {
-		return false, errors.New("session not found")
+		return nil, false, errors.New("session not found")
 	}
 
 	data := value.(*replayData)
 	actions := data.session.Actions
 
 	for i, r := range actions {
-		if r.Protocol != action.Protocol {
+		if r.Protocol != protocol {
 			continue
 		}
-		if r.Request != action.Request {
+		if !match(r.Request) {
 			continue
 		}
 		if _, loaded := data.matches.LoadOrStore(i, true); loaded {
 			continue
 		}
-		action.Response = r.Response
-		return true, nil
+		return r.Response, true, nil
+	}
+	return nil, false, nil
+}
+
+// MockReplayTime 将 ctx 上的当前时间设置为回放会话中上游请求的录制时间，处理
+// 函数使用 util.Now(ctx) 获取时间时，回放可以得到和录制时相同的结果。没有回放
+// 会话、没有录制时间或者时间已经被模拟时返回原来的 ctx 。
+func MockReplayTime(ctx context.Context) context.Context {
+
+	if util.NowMocked(ctx) {
+		return ctx
+	}
+
+	sessionID := GetReplaySessionID(ctx)
+	if sessionID == "" {
+		return ctx
+	}
+
+	value, ok := replayer.data.Load(sessionID)
+	if !ok {
+		return ctx
+	}
+
+	inbound := value.(*replayData).session.Inbound
+	if inbound == nil || inbound.Timestamp == 0 {
+		return ctx
+	}
+	return util.MockNow(ctx, time.Unix(0, inbound.Timestamp))
+}
+
+// UnmatchedActions 返回 sessionID 对应的回放数据中没有被回放的动作。
+func UnmatchedActions(sessionID string) []*Action {
+	value, ok := replayer.data.Load(sessionID)
+	if !ok {
+		return nil
+	}
+	var ret []*Action
+	data := value.(*replayData)
+	for i, action := range data.session.Actions {
+		if _, ok = data.matches.Load(i); !ok {
+			ret = append(ret, action)
+		}
 	}
-	return false, nil
+	return ret
 }
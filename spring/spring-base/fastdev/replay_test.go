@@ -19,9 +19,11 @@ package fastdev
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/go-spring/spring-base/assert"
 	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-base/util"
 )
 
 func TestReplayAction(t *testing.T) {
@@ -70,4 +72,32 @@ func TestReplayAction(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, ok)
 	assert.Equal(t, action.Response, "1")
+
+	unmatched := UnmatchedActions(sessionID)
+	assert.Equal(t, len(unmatched), 1)
+	assert.Equal(t, unmatched[0].Request, "SET a 1")
+}
+
+func TestMockReplayTime(t *testing.T) {
+
+	SetReplayMode(true, false)
+	defer SetReplayMode(false, false)
+
+	recorded := time.Date(2021, 10, 1, 8, 0, 0, 0, time.UTC)
+	session := &Session{
+		Session: NewSessionID(),
+		Inbound: &Action{Protocol: HTTP, Timestamp: recorded.UnixNano()},
+	}
+	Store(session)
+	defer Delete(session.Session)
+
+	ctx := knife.New(context.Background())
+	assert.True(t, MockReplayTime(ctx) == ctx)
+
+	err := knife.Set(ctx, ReplaySessionIDKey, session.Session)
+	assert.Nil(t, err)
+
+	ctx = MockReplayTime(ctx)
+	assert.True(t, util.Now(ctx).Sub(recorded) < time.Second)
+	assert.True(t, MockReplayTime(ctx) == ctx)
 }
@@ -48,6 +48,13 @@ func NewSessionID() string {
 	return string(buf)
 }
 
+// GetRecordSessionID 获取绑定在 context.Context 对象上的录制会话 ID 。
+func GetRecordSessionID(ctx context.Context) string {
+	var s string
+	_, _ = knife.Fetch(ctx, RecordSessionIDKey, &s)
+	return s
+}
+
 // GetReplaySessionID 获取绑定在 context.Context 对象上的 Session ID 。
 func GetReplaySessionID(ctx context.Context) string {
 	var s string
@@ -59,6 +66,9 @@ const (
 	HTTP  = "http"
 	REDIS = "redis"
 	APCU  = "apcu"
+	SQL   = "sql"
+	GRPC  = "grpc"
+	MONGO = "mongo"
 )
 
 // Action 将上下游调用、缓存获取、文件写入等抽象为一个动作。
@@ -67,6 +77,7 @@ type Action struct {
 	Request   interface{} `json:"request,omitempty"`  // 请求内容
 	Response  interface{} `json:"response,omitempty"` // 响应内容
 	Timestamp int64       `json:"timestamp"`          // 时间戳
+	Latency   int64       `json:"latency,omitempty"`  // 耗时，纳秒
 }
 
 // Session 一次上游调用称为一个会话。
@@ -91,6 +102,7 @@ type rawAction struct {
 	Request   json.RawMessage `json:"request,omitempty"`  // 请求内容
 	Response  json.RawMessage `json:"response,omitempty"` // 响应内容
 	Timestamp int64           `json:"timestamp"`          // 时间戳
+	Latency   int64           `json:"latency,omitempty"`  // 耗时，纳秒
 }
 
 type rawSession struct {
@@ -129,7 +141,11 @@ func ToSession(data []byte, sorted bool) (*Session, error) {
 }
 
 func toAction(action *rawAction, sorted bool) (ret *Action, err error) {
-	ret = &Action{Protocol: action.Protocol}
+	ret = &Action{
+		Protocol:  action.Protocol,
+		Timestamp: action.Timestamp,
+		Latency:   action.Latency,
+	}
 	ret.Request, err = toVal(action.Request, sorted)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/fastdev/json"
+)
+
+// Storage 录制数据的存储接口，因为 Store 已经用作回放数据的存储函数，所以命名
+// 为 Storage 。
+type Storage interface {
+
+	// Save 保存一次完整的录制会话。
+	Save(session *Session) error
+}
+
+var storage struct {
+	mutex sync.RWMutex
+	s     Storage
+}
+
+// SetStorage 设置录制数据的存储，s 为 nil 时恢复为打印到标准输出。
+func SetStorage(s Storage) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+	storage.s = s
+}
+
+// saveSession 对录制会话脱敏后进行保存，没有设置存储时打印到标准输出。
+func saveSession(session *Session) error {
+	maskSession(session)
+	storage.mutex.RLock()
+	s := storage.s
+	storage.mutex.RUnlock()
+	if s == nil {
+		return consoleStorage{}.Save(session)
+	}
+	return s.Save(session)
+}
+
+// consoleStorage 将录制数据打印到标准输出。
+type consoleStorage struct{}
+
+func (consoleStorage) Save(session *Session) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// FileStorageConfig 文件存储的配置。
+type FileStorageConfig struct {
+	Path       string        // 文件路径
+	MaxSize    int64         // 单个文件的最大字节数，超过后滚动，0 表示不滚动
+	MaxBackups int           // 保留的历史文件个数，0 表示不限制
+	MaxAge     time.Duration // 历史文件的保留时间，0 表示不限制
+}
+
+// FileStorage 将录制数据以 JSON Lines 格式写入文件，支持按大小滚动和清理历史文件。
+type FileStorage struct {
+	config FileStorageConfig
+	mutex  sync.Mutex
+	file   *os.File
+	size   int64
+}
+
+// NewFileStorage 返回新的文件存储。
+func NewFileStorage(config FileStorageConfig) (*FileStorage, error) {
+	s := &FileStorage{config: config}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStorage) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.config.Path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	s.file, s.size = f, info.Size()
+	return nil
+}
+
+func (s *FileStorage) Save(session *Session) error {
+
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.config.MaxSize > 0 && s.size > 0 && s.size+int64(len(b)) > s.config.MaxSize {
+		if err = s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(b)
+	s.size += int64(n)
+	return err
+}
+
+// rotate 将当前文件重命名为带时间戳的历史文件，然后清理过期的历史文件。
+func (s *FileStorage) rotate() error {
+
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	backup := s.config.Path + "." + time.Now().Format("20060102150405.000000")
+	if err := os.Rename(s.config.Path, backup); err != nil {
+		return err
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+	return s.clean()
+}
+
+// clean 清理超出个数或者超出保留时间的历史文件。
+func (s *FileStorage) clean() error {
+
+	backups, err := filepath.Glob(s.config.Path + ".*")
+	if err != nil {
+		return err
+	}
+
+	// 文件名中的时间戳保证了字典序就是时间顺序
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	now := time.Now()
+	for i, file := range backups {
+		expired := s.config.MaxBackups > 0 && i >= s.config.MaxBackups
+		if !expired && s.config.MaxAge > 0 {
+			ts := strings.TrimPrefix(file, s.config.Path+".")
+			t, err := time.ParseInLocation("20060102150405.000000", ts, time.Local)
+			expired = err == nil && now.Sub(t) > s.config.MaxAge
+		}
+		if expired {
+			if err = os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close 关闭文件。
+func (s *FileStorage) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fastdev_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+)
+
+func TestFileStorage(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "fastdev")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "record.jsonl")
+	s, err := fastdev.NewFileStorage(fastdev.FileStorageConfig{
+		Path:       path,
+		MaxSize:    100,
+		MaxBackups: 2,
+	})
+	assert.Nil(t, err)
+	defer s.Close()
+
+	for i := 0; i < 5; i++ {
+		err = s.Save(&fastdev.Session{Session: strings.Repeat("a", 60)})
+		assert.Nil(t, err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), `{"session":"`+strings.Repeat("a", 60)+`"}`+"\n")
+
+	backups, err := filepath.Glob(path + ".*")
+	assert.Nil(t, err)
+	assert.Equal(t, len(backups), 2)
+}
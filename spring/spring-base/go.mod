@@ -5,6 +5,5 @@ go 1.14
 require (
 	github.com/google/uuid v1.3.0
 	github.com/magiconair/properties v1.8.5
-	github.com/spf13/cast v1.4.1
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncPolicy 异步输出的队列满时的处理策略。
+type AsyncPolicy int
+
+const (
+	AsyncBlock = AsyncPolicy(iota) // 队列满时阻塞等待
+	AsyncDrop                      // 队列满时丢弃日志
+)
+
+// DefaultAsyncBufferSize 异步输出的默认队列长度。
+const DefaultAsyncBufferSize = 1024
+
+// AsyncConfig 异步输出的配置。
+type AsyncConfig struct {
+	BufferSize int         // 队列长度，不大于 0 时使用 DefaultAsyncBufferSize
+	Policy     AsyncPolicy // 队列满时的处理策略
+}
+
+type asyncEntry struct {
+	level Level
+	entry *Entry
+}
+
+// AsyncOutput 将日志放入有界队列，由后台 goroutine 调用 Output 进行输出，使
+// 日志的格式化和写入不再阻塞请求的处理。关闭之后的日志直接同步输出。
+type AsyncOutput struct {
+	output  Output
+	policy  AsyncPolicy
+	queue   chan asyncEntry
+	done    chan struct{}
+	mutex   sync.RWMutex
+	closed  bool
+	dropped uint64
+}
+
+// NewAsyncOutput 返回新的异步输出，使用 SetOutput(o.Output) 生效。
+func NewAsyncOutput(output Output, config AsyncConfig) *AsyncOutput {
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultAsyncBufferSize
+	}
+	o := &AsyncOutput{
+		output: output,
+		policy: config.Policy,
+		queue:  make(chan asyncEntry, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go o.run()
+	return o
+}
+
+func (o *AsyncOutput) run() {
+	defer close(o.done)
+	for e := range o.queue {
+		o.output(e.level, e.entry)
+	}
+}
+
+// Output 将日志放入队列，可以作为 Output 使用。
+func (o *AsyncOutput) Output(level Level, e *Entry) {
+
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	if o.closed {
+		o.output(level, e)
+		return
+	}
+
+	if o.policy == AsyncDrop {
+		select {
+		case o.queue <- asyncEntry{level: level, entry: e}:
+		default:
+			atomic.AddUint64(&o.dropped, 1)
+		}
+		return
+	}
+	o.queue <- asyncEntry{level: level, entry: e}
+}
+
+// Dropped 返回因为队列满而被丢弃的日志条数。
+func (o *AsyncOutput) Dropped() uint64 {
+	return atomic.LoadUint64(&o.dropped)
+}
+
+// Close 停止接收新的日志，等待队列中的日志全部输出后返回。
+func (o *AsyncOutput) Close() error {
+	o.mutex.Lock()
+	if o.closed {
+		o.mutex.Unlock()
+		return nil
+	}
+	o.closed = true
+	close(o.queue)
+	o.mutex.Unlock()
+	<-o.done
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+)
+
+func TestAsyncOutput(t *testing.T) {
+	defer log.Reset()
+
+	t.Run("block", func(t *testing.T) {
+		var msgs []string
+		o := log.NewAsyncOutput(func(level log.Level, e *log.Entry) {
+			msgs = append(msgs, e.GetMsg())
+		}, log.AsyncConfig{BufferSize: 1})
+		log.SetOutput(o.Output)
+		for _, s := range []string{"a", "b", "c"} {
+			log.Info(s)
+		}
+		assert.Nil(t, o.Close())
+		log.Info("d")
+		assert.Equal(t, msgs, []string{"a", "b", "c", "d"})
+		assert.Equal(t, o.Dropped(), uint64(0))
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		var (
+			msgs    []string
+			blocked sync.WaitGroup
+		)
+		blocked.Add(1)
+		started := make(chan struct{})
+		o := log.NewAsyncOutput(func(level log.Level, e *log.Entry) {
+			if e.GetMsg() == "a" {
+				close(started)
+				blocked.Wait()
+			}
+			msgs = append(msgs, e.GetMsg())
+		}, log.AsyncConfig{BufferSize: 1, Policy: log.AsyncDrop})
+		log.SetOutput(o.Output)
+		log.Info("a")
+		<-started
+		log.Info("b")
+		log.Info("c")
+		blocked.Done()
+		assert.Nil(t, o.Close())
+		assert.Equal(t, msgs, []string{"a", "b"})
+		assert.Equal(t, o.Dropped(), uint64(1))
+	})
+}
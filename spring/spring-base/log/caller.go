@@ -0,0 +1,77 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// SetCaller 设置是否记录日志的调用位置，默认记录。不需要调用位置时关闭可以提升
+// 日志的输出性能。
+func SetCaller(enabled bool) {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+	config.noCaller = !enabled
+}
+
+// SetStacktrace 设置为不低于 level 级别的日志记录调用栈，最多记录 depth 层，
+// depth 不大于 0 时不记录调用栈。
+func SetStacktrace(level Level, depth int) {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+	config.stackLevel = level
+	config.stackDepth = depth
+}
+
+// Skip 返回跳过 n 层调用栈的 Entry ，封装日志函数的工具函数可以使用它让调用位置
+// 和调用栈指向工具函数的调用者。
+func (e Entry) Skip(n int) Entry {
+	e.skip += n
+	return e
+}
+
+// GetStack 返回日志的调用栈，每层占两行，第一行是函数名，第二行是缩进的文件
+// 名和行号。
+func (e *Entry) GetStack() string {
+	return e.stack
+}
+
+// stacktrace 返回跳过 skip 层之后最多 depth 层的调用栈。
+func stacktrace(skip int, depth int) string {
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		sb.WriteString(frame.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(frame.File)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
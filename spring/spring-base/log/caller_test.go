@@ -0,0 +1,58 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+)
+
+// logError 模拟封装日志函数的工具函数。
+func logError(msg string) {
+	log.Tag("helper").Skip(1).Error(msg)
+}
+
+func TestCallerAndStack(t *testing.T) {
+	defer log.Reset()
+
+	var entries []*log.Entry
+	log.SetOutput(func(level log.Level, e *log.Entry) {
+		entries = append(entries, e)
+	})
+
+	log.SetStacktrace(log.ErrorLevel, 3)
+	logError("a") // 调用位置是这一行
+	log.Warn("b")
+
+	assert.True(t, strings.HasSuffix(entries[0].GetFile(), "caller_test.go"))
+	assert.Equal(t, entries[0].GetLine(), 42)
+	stack := strings.Split(entries[0].GetStack(), "\n")
+	assert.Equal(t, len(stack), 6)
+	assert.True(t, strings.HasSuffix(stack[0], "log_test.TestCallerAndStack"))
+	assert.Equal(t, entries[1].GetStack(), "")
+
+	log.SetCaller(false)
+	log.SetStacktrace(log.ErrorLevel, 0)
+	var buf bytes.Buffer
+	log.SetOutput(log.NewOutput(&buf, log.JSONEncoder{}))
+	log.Error("c")
+	assert.False(t, strings.Contains(buf.String(), `"file"`))
+}
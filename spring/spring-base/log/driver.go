@@ -0,0 +1,29 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+// Driver 日志后端的适配接口，应用已经统一使用了其他日志库时，可以通过 Driver
+// 将框架输出的日志转发到应用的日志库。级别过滤仍然由本包完成，Driver 只负责
+// 输出，PANIC 和 FATAL 级别的日志不应该导致 panic 或者退出程序。
+type Driver interface {
+	Log(level Level, e *Entry)
+}
+
+// SetDriver 使用 Driver 输出日志，等价于 SetOutput(d.Log) 。
+func SetDriver(d Driver) {
+	SetOutput(d.Log)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Encoder 日志编码器，将一条日志编码后写入 buf 。
+type Encoder interface {
+	Encode(buf *bytes.Buffer, level Level, e *Entry) error
+}
+
+// NewOutput 返回使用 encoder 编码并写入 w 的 Output 。
+func NewOutput(w io.Writer, encoder Encoder) Output {
+	var mutex sync.Mutex
+	return func(level Level, e *Entry) {
+		var buf bytes.Buffer
+		if err := encoder.Encode(&buf, level, e); err != nil {
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+// fieldValue 返回字段值的可编码形式。
+func fieldValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case error:
+		return x.Error()
+	case time.Duration:
+		return x.String()
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return x.String()
+	}
+	return v
+}
+
+// ConsoleEncoder 文本格式的编码器，格式和 Console 相同，字段以 key=value 的
+// 形式追加在消息后面。
+type ConsoleEncoder struct{}
+
+func (ConsoleEncoder) Encode(buf *bytes.Buffer, level Level, e *Entry) error {
+	strLevel := strings.ToUpper(level.String())
+	strTime := e.time.Format("2006-01-02 03-04-05.000")
+	_, _ = fmt.Fprintf(buf, "[%s] %s ", strLevel, strTime)
+	writeCaller(buf, e)
+	buf.WriteString(e.msg)
+	writeFields(buf, e.fields)
+	writeStack(buf, e.stack)
+	buf.WriteByte('\n')
+	return nil
+}
+
+// writeCaller 写入 "file:line " 格式的调用位置，没有记录调用位置时不写入。
+func writeCaller(buf *bytes.Buffer, e *Entry) {
+	if e.file != "" {
+		_, _ = fmt.Fprintf(buf, "%s:%d ", e.file, e.line)
+	}
+}
+
+// writeStack 在新的一行写入调用栈。
+func writeStack(buf *bytes.Buffer, stack string) {
+	if stack != "" {
+		buf.WriteByte('\n')
+		buf.WriteString(stack)
+	}
+}
+
+func writeFields(buf *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(f.Key)
+		buf.WriteByte('=')
+		s := fmt.Sprint(fieldValue(f.Value))
+		if strings.ContainsAny(s, " =\"\n") {
+			s = strconv.Quote(s)
+		}
+		buf.WriteString(s)
+	}
+}
+
+// JSONEncoder JSON 格式的编码器，每条日志编码为一行 JSON 对象，字段和 level、
+// time、file、line、tag、msg 位于同一层级，便于 ELK、Loki 等系统直接采集。
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(buf *bytes.Buffer, level Level, e *Entry) error {
+
+	buf.WriteString(`{"level":`)
+	writeJSON(buf, level.String())
+	buf.WriteString(`,"time":`)
+	writeJSON(buf, e.time.Format(time.RFC3339Nano))
+	if e.file != "" {
+		buf.WriteString(`,"file":`)
+		writeJSON(buf, e.file)
+		buf.WriteString(`,"line":`)
+		buf.WriteString(strconv.Itoa(e.line))
+	}
+	if e.tag != "" {
+		buf.WriteString(`,"tag":`)
+		writeJSON(buf, e.tag)
+	}
+	buf.WriteString(`,"msg":`)
+	writeJSON(buf, e.msg)
+
+	for _, f := range e.fields {
+		buf.WriteByte(',')
+		writeJSON(buf, f.Key)
+		buf.WriteByte(':')
+		writeJSON(buf, fieldValue(f.Value))
+	}
+
+	if e.stack != "" {
+		buf.WriteString(`,"stack":`)
+		writeJSON(buf, e.stack)
+	}
+
+	buf.WriteString("}\n")
+	return nil
+}
+
+// writeJSON 写入 v 的 JSON 编码，无法编码时写入 fmt.Sprint 的结果。
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(b)
+}
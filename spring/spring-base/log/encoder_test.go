@@ -0,0 +1,64 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+)
+
+func TestJSONEncoder(t *testing.T) {
+
+	var buf bytes.Buffer
+	log.SetOutput(log.NewOutput(&buf, log.JSONEncoder{}))
+	defer log.Reset()
+
+	e := log.With(log.String("user", "tom"))
+	e.Tag("_com_request_in").With(
+		log.Int("status", 200),
+		log.Duration("cost", 1500*time.Millisecond),
+		log.Err(errors.New("timeout")),
+		log.Any("ids", []int{1, 2}),
+	).Info("request done")
+	e.Warn("no fields added")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, len(lines), 2)
+
+	s := lines[0]
+	assert.True(t, strings.HasPrefix(s, `{"level":"info","time":"`))
+	assert.True(t, strings.Contains(s, `"tag":"_com_request_in","msg":"request done","user":"tom","status":200,"cost":"1.5s","error":"timeout","ids":[1,2]}`))
+	assert.True(t, strings.HasSuffix(lines[1], `"msg":"no fields added","user":"tom"}`))
+}
+
+func TestConsoleEncoder(t *testing.T) {
+
+	var buf bytes.Buffer
+	log.SetOutput(log.NewOutput(&buf, log.ConsoleEncoder{}))
+	defer log.Reset()
+
+	log.With(log.String("path", "/a b"), log.Bool("ok", true)).Error("failed")
+	s := buf.String()
+	assert.True(t, strings.HasPrefix(s, "[ERROR] "))
+	assert.True(t, strings.HasSuffix(s, ` failed path="/a b" ok=true`+"\n"))
+}
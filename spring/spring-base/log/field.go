@@ -0,0 +1,82 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"time"
+)
+
+// Field 结构化日志的字段。
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String 返回字符串类型的字段。
+func String(key string, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int 返回整数类型的字段。
+func Int(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float 返回浮点数类型的字段。
+func Float(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool 返回布尔类型的字段。
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration 返回时间间隔类型的字段，输出为 time.Duration 的字符串格式。
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time 返回时间类型的字段。
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err 返回键为 error 的字段。
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// Any 返回任意类型的字段。
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// With 创建包含结构化字段的 Entry 。
+func With(fields ...Field) Entry {
+	return empty.With(fields...)
+}
+
+// With 返回添加了结构化字段的 Entry ，原来的 Entry 不受影响。
+func (e Entry) With(fields ...Field) Entry {
+	e.fields = append(e.fields[:len(e.fields):len(e.fields)], fields...)
+	return e
+}
+
+func (e *Entry) GetFields() []Field {
+	return e.fields
+}
@@ -0,0 +1,236 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RotateNone   = ""       // 不按时间滚动
+	RotateDaily  = "daily"  // 每天滚动
+	RotateHourly = "hourly" // 每小时滚动
+)
+
+// backupTimeFormat 历史文件名中的时间戳格式，保证字典序就是时间顺序。
+const backupTimeFormat = "20060102150405.000000"
+
+// RollingFileConfig 滚动日志文件的配置。
+type RollingFileConfig struct {
+	Path       string        // 文件路径
+	MaxSize    int64         // 单个文件的最大字节数，超过后滚动，0 表示不按大小滚动
+	Rotate     string        // 按时间滚动的周期，daily 或者 hourly
+	MaxBackups int           // 保留的历史文件个数，0 表示不限制
+	MaxAge     time.Duration // 历史文件的保留时间，0 表示不限制
+	Compress   bool          // 是否使用 gzip 压缩历史文件
+}
+
+// RollingFile 支持按大小和时间滚动的日志文件，可以和 NewOutput 一起使用。历史
+// 文件的名称为文件路径加上滚动时的时间戳，压缩和清理在后台进行。
+type RollingFile struct {
+	config RollingFileConfig
+	mutex  sync.Mutex
+	file   *os.File
+	size   int64
+	period time.Time // 当前文件所属的时间周期
+	now    func() time.Time
+
+	cleanMutex sync.Mutex
+	wg         sync.WaitGroup
+}
+
+// NewRollingFile 返回新的滚动日志文件。
+func NewRollingFile(config RollingFileConfig) (*RollingFile, error) {
+	f := &RollingFile{config: config, now: time.Now}
+	if err := f.open(f.now()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// periodOf 返回 t 所属的时间周期的起始时间。
+func (f *RollingFile) periodOf(t time.Time) time.Time {
+	switch f.config.Rotate {
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case RotateHourly:
+		return t.Truncate(time.Hour)
+	}
+	return time.Time{}
+}
+
+func (f *RollingFile) open(now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(f.config.Path), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	f.period = f.periodOf(now)
+	if f.size > 0 && f.config.Rotate != RotateNone {
+		// 已有的文件按照最后修改时间确定所属的周期
+		f.period = f.periodOf(info.ModTime())
+	}
+	return nil
+}
+
+func (f *RollingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := f.now()
+	if f.size > 0 {
+		overSize := f.config.MaxSize > 0 && f.size+int64(len(p)) > f.config.MaxSize
+		overTime := f.config.Rotate != RotateNone && !f.periodOf(now).Equal(f.period)
+		if overSize || overTime {
+			if err := f.rotate(now); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate 将当前文件重命名为带时间戳的历史文件，然后在后台压缩和清理历史文件。
+func (f *RollingFile) rotate(now time.Time) error {
+
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	backup := f.config.Path + "." + now.Format(backupTimeFormat)
+	if err := os.Rename(f.config.Path, backup); err != nil {
+		return err
+	}
+
+	if err := f.open(now); err != nil {
+		return err
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.cleanMutex.Lock()
+		defer f.cleanMutex.Unlock()
+		if f.config.Compress {
+			f.compress()
+		}
+		if err := f.clean(now); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "clean log files error: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// compress 压缩所有没有压缩的历史文件，后台任务的执行顺序不影响结果。
+func (f *RollingFile) compress() {
+	backups, err := filepath.Glob(f.config.Path + ".*")
+	if err != nil {
+		return
+	}
+	for _, file := range backups {
+		if strings.HasSuffix(file, ".gz") {
+			continue
+		}
+		if err = compressFile(file); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "compress log file %s error: %v\n", file, err)
+		}
+	}
+}
+
+// compressFile 使用 gzip 压缩文件，成功后删除原文件。
+func compressFile(name string) error {
+
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := gzip.NewWriter(dst)
+	if _, err = io.Copy(w, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(name)
+}
+
+// clean 清理超出个数或者超出保留时间的历史文件。
+func (f *RollingFile) clean(now time.Time) error {
+
+	backups, err := filepath.Glob(f.config.Path + ".*")
+	if err != nil {
+		return err
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, file := range backups {
+		expired := f.config.MaxBackups > 0 && i >= f.config.MaxBackups
+		if !expired && f.config.MaxAge > 0 {
+			ts := strings.TrimPrefix(file, f.config.Path+".")
+			ts = strings.TrimSuffix(ts, ".gz")
+			t, err := time.ParseInLocation(backupTimeFormat, ts, time.Local)
+			expired = err == nil && now.Sub(t) > f.config.MaxAge
+		}
+		if expired {
+			if err = os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close 等待后台的压缩和清理结束，然后关闭文件。
+func (f *RollingFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.wg.Wait()
+	return f.file.Close()
+}
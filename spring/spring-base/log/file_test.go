@@ -0,0 +1,80 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+)
+
+func TestRollingFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "log")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	f, err := NewRollingFile(RollingFileConfig{
+		Path:       path,
+		MaxSize:    10,
+		Rotate:     RotateDaily,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	assert.Nil(t, err)
+
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.Local)
+	f.now = func() time.Time { return now }
+	f.period = f.periodOf(now)
+
+	for _, s := range []string{"0123456\n", "abcdefg\n", "hijklmn\n"} {
+		_, err = f.Write([]byte(s))
+		assert.Nil(t, err)
+		now = now.Add(time.Second)
+	}
+
+	// 跨天之后即使没有超过大小也会滚动
+	_, err = f.Write([]byte("a\n"))
+	assert.Nil(t, err)
+	now = now.Add(24 * time.Hour)
+	_, err = f.Write([]byte("b\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	b, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), "b\n")
+
+	backups, err := filepath.Glob(path + ".*.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, len(backups), 2)
+
+	r, err := os.Open(backups[1])
+	assert.Nil(t, err)
+	defer r.Close()
+	gr, err := gzip.NewReader(r)
+	assert.Nil(t, err)
+	b, err = ioutil.ReadAll(gr)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), "hijklmn\na\n")
+}
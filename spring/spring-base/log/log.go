@@ -18,6 +18,7 @@
 package log
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"runtime"
@@ -75,12 +76,20 @@ func Tag(tag string) Entry {
 
 // Entry 打包日志信息。
 type Entry struct {
-	ctx  context.Context
-	tag  string
-	msg  string
-	file string
-	line int
-	time time.Time
+	logger string
+	skip   int
+	stack  string
+	ctx    context.Context
+	tag    string
+	msg    string
+	file   string
+	line   int
+	time   time.Time
+	fields []Field
+}
+
+func (e *Entry) GetLogger() string {
+	return e.logger
 }
 
 func (e *Entry) GetCtx() context.Context {
@@ -141,13 +150,28 @@ func Console(level Level, e *Entry) {
 		strLevel = color.Green.Sprint(strLevel)
 	}
 	strTime := e.time.Format("2006-01-02 03-04-05.000")
-	_, _ = fmt.Printf("[%s] %s %s:%d %s\n", strLevel, strTime, e.file, e.line, e.msg)
+	if len(e.fields) == 0 && e.file != "" && e.stack == "" {
+		_, _ = fmt.Printf("[%s] %s %s:%d %s\n", strLevel, strTime, e.file, e.line, e.msg)
+		return
+	}
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "[%s] %s ", strLevel, strTime)
+	writeCaller(&buf, e)
+	buf.WriteString(e.msg)
+	writeFields(&buf, e.fields)
+	writeStack(&buf, e.stack)
+	_, _ = fmt.Println(buf.String())
 }
 
 var config = struct {
-	mutex  sync.RWMutex
-	level  Level
-	output Output
+	mutex      sync.RWMutex
+	level      Level
+	levels     map[string]Level    // 命名日志的输出级别
+	samplers   map[string]*sampler // 命名日志的采样配置
+	noCaller   bool                // 是否不记录调用位置
+	stackLevel Level               // 记录调用栈的最低级别
+	stackDepth int                 // 调用栈的最大层数，0 表示不记录
+	output     Output
 }{
 	level:  InfoLevel,
 	output: Console,
@@ -158,6 +182,11 @@ func Reset() {
 	config.mutex.Lock()
 	defer config.mutex.Unlock()
 	config.level = InfoLevel
+	config.levels = nil
+	config.samplers = nil
+	config.noCaller = false
+	config.stackLevel = 0
+	config.stackDepth = 0
 	config.output = Console
 }
 
@@ -175,6 +204,13 @@ func SetLevel(level Level) {
 	config.level = level
 }
 
+// GetOutput 获取日志的输出格式。
+func GetOutput() Output {
+	config.mutex.RLock()
+	defer config.mutex.RUnlock()
+	return config.output
+}
+
 // SetOutput 设置日志的输出格式。
 func SetOutput(output Output) {
 	config.mutex.Lock()
@@ -213,15 +249,23 @@ func Caller(skip int, fast bool) (file string, line int, loaded bool) {
 func outputf(level Level, e Entry, format string, args ...interface{}) {
 
 	var (
-		configLevel  Level
-		configOutput Output
+		configLevel   Level
+		configOutput  Output
+		configSampler *sampler
+		noCaller      bool
+		stackDepth    int
 	)
 
 	{
 		config.mutex.RLock()
 		defer config.mutex.RUnlock()
-		configLevel = config.level
+		configLevel = loggerLevel(e.logger)
 		configOutput = config.output
+		configSampler = loggerSampler(e.logger)
+		noCaller = config.noCaller
+		if level >= config.stackLevel {
+			stackDepth = config.stackDepth
+		}
 	}
 
 	if configLevel > level {
@@ -232,7 +276,24 @@ func outputf(level Level, e Entry, format string, args ...interface{}) {
 			args = fn()
 		}
 	}
-	e.file, e.line, _ = Caller(2, true)
+	if configSampler != nil {
+		key := format
+		if key == "" {
+			key = fmt.Sprint(args...)
+		}
+		if !configSampler.check(e.logger+":"+level.String()+":"+key, time.Now()) {
+			return
+		}
+	}
+	if !noCaller {
+		e.file, e.line, _ = Caller(2+e.skip, true)
+	}
+	if stackDepth > 0 {
+		e.stack = stacktrace(2+e.skip, stackDepth)
+	}
+	if mdc := GetMDC(e.ctx); len(mdc) > 0 {
+		e.fields = append(mdc[:len(mdc):len(mdc)], e.fields...)
+	}
 	configOutput(level, e.format(format, args...))
 }
 
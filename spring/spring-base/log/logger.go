@@ -0,0 +1,122 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RootLogger 根日志的名称，它的输出级别就是 SetLevel 设置的全局级别。
+const RootLogger = "root"
+
+// GetLogger 创建指定名称的 Entry 。名称使用 "." 分隔层级，例如 "web.access"
+// 的输出级别依次查找 "web.access"、"web" 的配置，都没有配置时使用全局级别。
+func GetLogger(name string) Entry {
+	loggers.Store(name, struct{}{})
+	e := empty
+	e.logger = name
+	return e
+}
+
+// loggers 使用过的命名日志。
+var loggers sync.Map
+
+// GetLoggerNames 返回使用过的和配置了输出级别的所有命名日志的名称，包括根日志，
+// 按照字典序排列。
+func GetLoggerNames() []string {
+	m := map[string]struct{}{RootLogger: {}}
+	loggers.Range(func(key, value interface{}) bool {
+		m[key.(string)] = struct{}{}
+		return true
+	})
+	for name := range GetLoggerLevels() {
+		m[name] = struct{}{}
+	}
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseLevel 将字符串解析为日志的输出级别，不区分大小写。
+func ParseLevel(s string) (Level, error) {
+	for level := TraceLevel; level <= FatalLevel; level++ {
+		if strings.EqualFold(s, level.String()) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// SetLoggerLevel 设置命名日志的输出级别，对它的所有子日志生效。
+func SetLoggerLevel(name string, level Level) {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+	if name == RootLogger || name == "" {
+		config.level = level
+		return
+	}
+	if config.levels == nil {
+		config.levels = make(map[string]Level)
+	}
+	config.levels[name] = level
+}
+
+// RemoveLoggerLevel 删除命名日志的输出级别，它将使用上级日志的输出级别。
+func RemoveLoggerLevel(name string) {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+	delete(config.levels, name)
+}
+
+// GetLoggerLevel 获取命名日志实际生效的输出级别。
+func GetLoggerLevel(name string) Level {
+	config.mutex.RLock()
+	defer config.mutex.RUnlock()
+	return loggerLevel(name)
+}
+
+// GetLoggerLevels 获取所有配置了输出级别的命名日志，包括根日志。
+func GetLoggerLevels() map[string]Level {
+	config.mutex.RLock()
+	defer config.mutex.RUnlock()
+	ret := map[string]Level{RootLogger: config.level}
+	for name, level := range config.levels {
+		ret[name] = level
+	}
+	return ret
+}
+
+// loggerLevel 按照层级查找命名日志的输出级别，调用者需要持有读锁。
+func loggerLevel(name string) Level {
+	for name != "" && len(config.levels) > 0 {
+		if level, ok := config.levels[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return config.level
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+)
+
+func TestGetLogger(t *testing.T) {
+	defer log.Reset()
+
+	var names []string
+	log.SetOutput(func(level log.Level, e *log.Entry) {
+		names = append(names, e.GetLogger()+":"+e.GetMsg())
+	})
+
+	log.SetLoggerLevel("web", log.DebugLevel)
+	log.SetLoggerLevel("web.access", log.WarnLevel)
+
+	assert.Equal(t, log.GetLoggerLevel("web.access.api"), log.WarnLevel)
+	assert.Equal(t, log.GetLoggerLevel("web.router"), log.DebugLevel)
+	assert.Equal(t, log.GetLoggerLevel("webx"), log.InfoLevel)
+
+	log.GetLogger("web.router").Debug("a")
+	log.GetLogger("web.access").Info("b")
+	log.GetLogger("web.access").Warn("c")
+	log.GetLogger("db").Debug("d")
+	log.Debug("e")
+	assert.Equal(t, names, []string{"web.router:a", "web.access:c"})
+
+	log.RemoveLoggerLevel("web.access")
+	assert.Equal(t, log.GetLoggerLevel("web.access"), log.DebugLevel)
+
+	log.SetLoggerLevel(log.RootLogger, log.ErrorLevel)
+	assert.Equal(t, log.GetLevel(), log.ErrorLevel)
+	assert.Equal(t, log.GetLoggerLevels(), map[string]log.Level{
+		log.RootLogger: log.ErrorLevel,
+		"web":          log.DebugLevel,
+	})
+	loggers := "," + strings.Join(log.GetLoggerNames(), ",") + ","
+	for _, name := range []string{"db", "root", "web", "web.access", "web.router"} {
+		assert.True(t, strings.Contains(loggers, ","+name+","))
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := log.ParseLevel("DEBUG")
+	assert.Nil(t, err)
+	assert.Equal(t, level, log.DebugLevel)
+	_, err = log.ParseLevel("verbose")
+	assert.Error(t, err, "unknown log level \"verbose\"")
+}
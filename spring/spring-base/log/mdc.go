@@ -0,0 +1,84 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"context"
+)
+
+const (
+	RequestIDKey = "request_id" // 请求 ID 在 MDC 中的键
+	TraceIDKey   = "trace_id"   // 链路追踪 ID 在 MDC 中的键
+)
+
+type mdcKeyType int
+
+var mdcKey mdcKeyType
+
+// PutMDC 返回在 MDC 中添加了 key 和 value 的 context.Context 对象，已经存在
+// 的 key 会被覆盖，原来的 ctx 不受影响。使用 Ctx(ctx) 输出日志时 MDC 中的值会
+// 作为结构化字段自动添加到日志中。
+func PutMDC(ctx context.Context, key string, value string) context.Context {
+	old := GetMDC(ctx)
+	fields := make([]Field, 0, len(old)+1)
+	for _, f := range old {
+		if f.Key != key {
+			fields = append(fields, f)
+		}
+	}
+	fields = append(fields, String(key, value))
+	return context.WithValue(ctx, mdcKey, fields)
+}
+
+// GetMDC 返回 ctx 上按照添加顺序排列的 MDC 字段，不能修改返回值。
+func GetMDC(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(mdcKey).([]Field)
+	return fields
+}
+
+// GetMDCValue 返回 ctx 上 MDC 中 key 对应的值。
+func GetMDCValue(ctx context.Context, key string) string {
+	for _, f := range GetMDC(ctx) {
+		if f.Key == key {
+			return f.Value.(string)
+		}
+	}
+	return ""
+}
+
+// WithRequestID 返回在 MDC 中设置了请求 ID 的 context.Context 对象。
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return PutMDC(ctx, RequestIDKey, requestID)
+}
+
+// GetRequestID 返回 ctx 上 MDC 中的请求 ID 。
+func GetRequestID(ctx context.Context) string {
+	return GetMDCValue(ctx, RequestIDKey)
+}
+
+// WithTraceID 返回在 MDC 中设置了链路追踪 ID 的 context.Context 对象。
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return PutMDC(ctx, TraceIDKey, traceID)
+}
+
+// GetTraceID 返回 ctx 上 MDC 中的链路追踪 ID 。
+func GetTraceID(ctx context.Context) string {
+	return GetMDCValue(ctx, TraceIDKey)
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+)
+
+func TestMDC(t *testing.T) {
+
+	var buf bytes.Buffer
+	log.SetOutput(log.NewOutput(&buf, log.JSONEncoder{}))
+	defer log.Reset()
+
+	ctx := log.WithRequestID(context.Background(), "r-1")
+	ctx = log.WithTraceID(ctx, "t-1")
+	ctx = log.PutMDC(ctx, "user", "tom")
+	child := log.PutMDC(ctx, "user", "jerry")
+
+	assert.Equal(t, log.GetRequestID(ctx), "r-1")
+	assert.Equal(t, log.GetTraceID(ctx), "t-1")
+	assert.Equal(t, log.GetMDCValue(ctx, "user"), "tom")
+	assert.Equal(t, log.GetMDCValue(child, "user"), "jerry")
+
+	log.Ctx(child).With(log.Int("status", 200)).Info("ok")
+	log.Ctx(context.Background()).Info("no mdc")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, len(lines), 2)
+	assert.True(t, strings.HasSuffix(lines[0], `"msg":"ok","request_id":"r-1","trace_id":"t-1","user":"jerry","status":200}`))
+	assert.True(t, strings.HasSuffix(lines[1], `"msg":"no mdc"}`))
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// SamplingConfig 日志采样配置，在每个统计周期内相同的日志先输出 First 条，之后
+// 每 Thereafter 条输出一条，用于防止错误循环等场景产生的日志风暴。相同的日志是
+// 指级别和格式化字符串相同，没有格式化字符串时为级别和消息相同。
+type SamplingConfig struct {
+	Interval   time.Duration // 统计周期，不大于 0 时为 1 秒
+	First      uint64        // 每个周期内先输出的条数
+	Thereafter uint64        // 之后每多少条输出一条，0 表示不再输出
+}
+
+type sampler struct {
+	config SamplingConfig
+	mutex  sync.Mutex
+	end    time.Time // 当前统计周期的结束时间
+	counts map[string]uint64
+}
+
+// check 返回是否输出 key 对应的日志。
+func (s *sampler) check(key string, now time.Time) bool {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// 每个周期清空计数，避免不同的日志过多时占用过多的内存
+	if !now.Before(s.end) {
+		s.counts = make(map[string]uint64)
+		s.end = now.Add(s.config.Interval)
+	}
+
+	n := s.counts[key] + 1
+	s.counts[key] = n
+	if n <= s.config.First {
+		return true
+	}
+	return s.config.Thereafter > 0 && (n-s.config.First)%s.config.Thereafter == 0
+}
+
+// SetSampling 设置命名日志的采样配置，对它的所有子日志生效，子日志有自己的配置
+// 时使用自己的配置。name 为 RootLogger 时对所有日志生效。
+func SetSampling(name string, c SamplingConfig) {
+	if c.Interval <= 0 {
+		c.Interval = time.Second
+	}
+	if name == "" {
+		name = RootLogger
+	}
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+	if config.samplers == nil {
+		config.samplers = make(map[string]*sampler)
+	}
+	config.samplers[name] = &sampler{config: c}
+}
+
+// RemoveSampling 删除命名日志的采样配置。
+func RemoveSampling(name string) {
+	if name == "" {
+		name = RootLogger
+	}
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+	delete(config.samplers, name)
+}
+
+// loggerSampler 按照层级查找命名日志的采样配置，调用者需要持有读锁。
+func loggerSampler(name string) *sampler {
+	if len(config.samplers) == 0 {
+		return nil
+	}
+	for name != "" {
+		if s, ok := config.samplers[name]; ok {
+			return s
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return config.samplers[RootLogger]
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+)
+
+func TestSampler(t *testing.T) {
+	s := &sampler{config: SamplingConfig{Interval: time.Second, First: 2, Thereafter: 3}}
+	now := time.Now()
+	var r []bool
+	for i := 0; i < 8; i++ {
+		r = append(r, s.check("a", now))
+	}
+	assert.Equal(t, r, []bool{true, true, false, false, true, false, false, true})
+	assert.True(t, s.check("b", now))
+	assert.True(t, s.check("a", now.Add(time.Second)))
+}
+
+func TestSetSampling(t *testing.T) {
+	defer Reset()
+
+	var msgs []string
+	SetOutput(func(level Level, e *Entry) {
+		msgs = append(msgs, e.GetLogger()+":"+e.GetMsg())
+	})
+
+	SetSampling("db", SamplingConfig{Interval: time.Hour, First: 1})
+	for i := 0; i < 3; i++ {
+		GetLogger("db.mysql").Errorf("query error: %d", i)
+		GetLogger("web").Errorf("request error: %d", i)
+	}
+	assert.Equal(t, msgs, []string{
+		"db.mysql:query error: 0",
+		"web:request error: 0",
+		"web:request error: 1",
+		"web:request error: 2",
+	})
+
+	RemoveSampling("db")
+	GetLogger("db.mysql").Errorf("query error: %d", 3)
+	assert.Equal(t, msgs[len(msgs)-1], "db.mysql:query error: 3")
+}
@@ -42,7 +42,7 @@ var WrapFormat = func(err error, fileline string, format string, a ...interface{
 
 // Error 创建携带文件信息的 error 对象。文件信息未来也许可以在编译期计算。
 func Error(fileline string, text string) error {
-	return WrapFormat(nil, fileline, "", text)
+	return WrapFormat(nil, fileline, "%s", text)
 }
 
 // Errorf 创建携带文件信息的 error 对象。文件信息未来也许可以在编译期计算。
@@ -52,7 +52,7 @@ func Errorf(fileline string, format string, a ...interface{}) error {
 
 // Wrap 创建携带文件信息的 error 对象。文件信息未来也许可以在编译期计算。
 func Wrap(err error, fileline string, text string) error {
-	return WrapFormat(err, fileline, "", text)
+	return WrapFormat(err, fileline, "%s", text)
 }
 
 // Wrapf 创建携带文件信息的 error 对象。文件信息未来也许可以在编译期计算。
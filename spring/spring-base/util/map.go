@@ -0,0 +1,148 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"container/list"
+)
+
+type mapEntry struct {
+	key   string
+	value interface{}
+}
+
+// OrderedMap 按照插入顺序遍历的 map ，覆盖已有 key 的值时不改变 key 的位置。
+type OrderedMap struct {
+	l *list.List
+	m map[string]*list.Element
+}
+
+// NewOrderedMap 返回一个空的 OrderedMap 。
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{
+		l: list.New(),
+		m: make(map[string]*list.Element),
+	}
+}
+
+// Len 返回 key 的个数。
+func (m *OrderedMap) Len() int {
+	return len(m.m)
+}
+
+// Has 返回 key 是否存在。
+func (m *OrderedMap) Has(key string) bool {
+	_, ok := m.m[key]
+	return ok
+}
+
+// Get 返回 key 对应的值。
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	if e, ok := m.m[key]; ok {
+		return e.Value.(*mapEntry).value, true
+	}
+	return nil, false
+}
+
+// Set 设置 key 对应的值，key 不存在时追加到末尾。
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if e, ok := m.m[key]; ok {
+		e.Value.(*mapEntry).value = value
+		return
+	}
+	m.m[key] = m.l.PushBack(&mapEntry{key: key, value: value})
+}
+
+// Delete 删除 key 及其对应的值。
+func (m *OrderedMap) Delete(key string) {
+	if e, ok := m.m[key]; ok {
+		m.l.Remove(e)
+		delete(m.m, key)
+	}
+}
+
+// Keys 按照插入顺序返回所有的 key 。
+func (m *OrderedMap) Keys() []string {
+	keys := make([]string, 0, len(m.m))
+	for e := m.l.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*mapEntry).key)
+	}
+	return keys
+}
+
+// Range 按照插入顺序遍历所有的 key 和值，fn 返回 false 时停止遍历。
+func (m *OrderedMap) Range(fn func(key string, value interface{}) bool) {
+	for e := m.l.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*mapEntry)
+		if !fn(entry.key, entry.value) {
+			return
+		}
+	}
+}
+
+// MultiMap 一个 key 对应多个值的 map ，key 按照第一次插入的顺序遍历，同一个
+// key 的值按照插入顺序排列。
+type MultiMap struct {
+	m *OrderedMap
+}
+
+// NewMultiMap 返回一个空的 MultiMap 。
+func NewMultiMap() *MultiMap {
+	return &MultiMap{m: NewOrderedMap()}
+}
+
+// Len 返回 key 的个数。
+func (m *MultiMap) Len() int {
+	return m.m.Len()
+}
+
+// Has 返回 key 是否存在。
+func (m *MultiMap) Has(key string) bool {
+	return m.m.Has(key)
+}
+
+// Get 返回 key 对应的所有值。
+func (m *MultiMap) Get(key string) []interface{} {
+	if v, ok := m.m.Get(key); ok {
+		return v.([]interface{})
+	}
+	return nil
+}
+
+// Add 为 key 追加一个值。
+func (m *MultiMap) Add(key string, value interface{}) {
+	v, _ := m.m.Get(key)
+	values, _ := v.([]interface{})
+	m.m.Set(key, append(values, value))
+}
+
+// Delete 删除 key 及其对应的所有值。
+func (m *MultiMap) Delete(key string) {
+	m.m.Delete(key)
+}
+
+// Keys 按照第一次插入的顺序返回所有的 key 。
+func (m *MultiMap) Keys() []string {
+	return m.m.Keys()
+}
+
+// Range 按照 key 的顺序遍历所有的 key 和值，fn 返回 false 时停止遍历。
+func (m *MultiMap) Range(fn func(key string, values []interface{}) bool) {
+	m.m.Range(func(key string, value interface{}) bool {
+		return fn(key, value.([]interface{}))
+	})
+}
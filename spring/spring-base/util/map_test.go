@@ -0,0 +1,67 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/util"
+)
+
+func TestOrderedMap(t *testing.T) {
+
+	m := util.NewOrderedMap()
+	m.Set("c", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	m.Set("a", 4)
+	assert.Equal(t, m.Len(), 3)
+	assert.Equal(t, m.Keys(), []string{"c", "a", "b"})
+
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, v, 4)
+
+	m.Delete("c")
+	assert.False(t, m.Has("c"))
+	m.Set("c", 5)
+	assert.Equal(t, m.Keys(), []string{"a", "b", "c"})
+
+	var keys []string
+	m.Range(func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		return key != "b"
+	})
+	assert.Equal(t, keys, []string{"a", "b"})
+}
+
+func TestMultiMap(t *testing.T) {
+
+	m := util.NewMultiMap()
+	m.Add("/b", 1)
+	m.Add("/a", 2)
+	m.Add("/b", 3)
+	assert.Equal(t, m.Len(), 2)
+	assert.Equal(t, m.Keys(), []string{"/b", "/a"})
+	assert.Equal(t, m.Get("/b"), []interface{}{1, 3})
+	assert.Equal(t, len(m.Get("/c")), 0)
+
+	m.Delete("/b")
+	assert.False(t, m.Has("/b"))
+	assert.Equal(t, m.Keys(), []string{"/a"})
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrPoolClosed 协程池关闭后提交任务时返回的错误。
+var ErrPoolClosed = errors.New("pool closed")
+
+// Pool 协程池，限制同时运行的 goroutine 数量，任务发生 panic 时交给 onPanic 处
+// 理，Close 时拒绝新的任务并等待已提交的任务全部结束。
+type Pool struct {
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.RWMutex
+	closed  bool
+	onPanic func(r interface{})
+}
+
+// NewPool 返回最多同时运行 size 个 goroutine 的协程池，onPanic 为 nil 时忽略
+// 任务的 panic 。
+func NewPool(size int, onPanic func(r interface{})) *Pool {
+	if size <= 0 {
+		panic(errors.New("pool size should be positive"))
+	}
+	return &Pool{
+		sem:     make(chan struct{}, size),
+		onPanic: onPanic,
+	}
+}
+
+// Go 提交一个任务，没有空闲的 goroutine 时阻塞等待，协程池关闭后返回 ErrPoolClosed 。
+func (p *Pool) Go(fn func()) error {
+
+	p.mutex.RLock()
+	if p.closed {
+		p.mutex.RUnlock()
+		return ErrPoolClosed
+	}
+	p.wg.Add(1)
+	p.mutex.RUnlock()
+
+	p.sem <- struct{}{}
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		defer func() {
+			if r := recover(); r != nil && p.onPanic != nil {
+				p.onPanic(r)
+			}
+		}()
+		fn()
+	}()
+	return nil
+}
+
+// Running 返回正在运行的 goroutine 数量。
+func (p *Pool) Running() int {
+	return len(p.sem)
+}
+
+// Close 关闭协程池，拒绝新的任务并等待已提交的任务全部结束。
+func (p *Pool) Close() {
+	p.mutex.Lock()
+	p.closed = true
+	p.mutex.Unlock()
+	p.wg.Wait()
+}
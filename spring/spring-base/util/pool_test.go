@@ -0,0 +1,60 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/util"
+)
+
+func TestPool(t *testing.T) {
+
+	var panics int32
+	p := util.NewPool(2, func(r interface{}) {
+		atomic.AddInt32(&panics, 1)
+	})
+
+	var running, maxRunning, count int32
+	for i := 0; i < 10; i++ {
+		err := p.Go(func() {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			if atomic.AddInt32(&count, 1)%5 == 0 {
+				panic("boom")
+			}
+		})
+		assert.Nil(t, err)
+	}
+
+	p.Close()
+	assert.Equal(t, atomic.LoadInt32(&count), int32(10))
+	assert.Equal(t, atomic.LoadInt32(&panics), int32(2))
+	assert.True(t, atomic.LoadInt32(&maxRunning) <= 2)
+	assert.Equal(t, p.Running(), 0)
+	assert.Equal(t, p.Go(func() {}), util.ErrPoolClosed)
+}
@@ -48,6 +48,12 @@ func Now(ctx context.Context) time.Time {
 	return t.base.Add(time.Now().Sub(t.from))
 }
 
+// NowMocked 返回 ctx 上的当前时间是否被模拟。
+func NowMocked(ctx context.Context) bool {
+	_, ok := ctx.Value(timeKey).(*TimeValue)
+	return ok
+}
+
 // MockNow 模拟当前时间。
 func MockNow(ctx context.Context, t time.Time) context.Context {
 	if _, ok := ctx.Value(timeKey).(*TimeValue); ok {
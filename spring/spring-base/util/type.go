@@ -18,7 +18,9 @@ package util
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"strings"
 )
 
 // IsBeanType 返回是否是 bean 类型。在 go-spring 里，变量的类型分为三种: bean 类
@@ -193,3 +195,56 @@ func HasReceiver(t reflect.Type, receiver reflect.Type) bool {
 func IsStructPtr(t reflect.Type) bool {
 	return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
 }
+
+// CheckImplements 检查类型 t 是否实现了接口 iface ，未实现时返回和编译器类似的错
+// 误信息，列出缺少的方法、签名不一致的方法以及只有指针类型才具有的方法。
+func CheckImplements(t reflect.Type, iface reflect.Type) error {
+
+	if iface.Kind() != reflect.Interface {
+		return fmt.Errorf("%s is not an interface", iface)
+	}
+	if t.Implements(iface) {
+		return nil
+	}
+
+	var missing, reasons []string
+	for i := 0; i < iface.NumMethod(); i++ {
+		want := iface.Method(i)
+		have, ok := t.MethodByName(want.Name)
+		if !ok {
+			if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+				if _, ok = reflect.PtrTo(t).MethodByName(want.Name); ok {
+					reasons = append(reasons, fmt.Sprintf("method %s has pointer receiver", want.Name))
+					continue
+				}
+			}
+			missing = append(missing, want.Name)
+			continue
+		}
+		haveType := have.Type
+		if t.Kind() != reflect.Interface { // 去掉接收者
+			in := make([]reflect.Type, 0, haveType.NumIn()-1)
+			for j := 1; j < haveType.NumIn(); j++ {
+				in = append(in, haveType.In(j))
+			}
+			out := make([]reflect.Type, 0, haveType.NumOut())
+			for j := 0; j < haveType.NumOut(); j++ {
+				out = append(out, haveType.Out(j))
+			}
+			haveType = reflect.FuncOf(in, out, haveType.IsVariadic())
+		}
+		if haveType != want.Type {
+			reasons = append(reasons, fmt.Sprintf("wrong type for method %s: have %s, want %s", want.Name, haveType, want.Type))
+		}
+	}
+
+	if len(missing) == 1 {
+		reasons = append([]string{"missing method " + missing[0]}, reasons...)
+	} else if len(missing) > 1 {
+		reasons = append([]string{"missing methods " + strings.Join(missing, ", ")}, reasons...)
+	}
+	if len(reasons) == 0 {
+		return fmt.Errorf("%s does not implement %s", t, iface)
+	}
+	return fmt.Errorf("%s does not implement %s (%s)", t, iface, strings.Join(reasons, "; "))
+}
@@ -849,3 +849,32 @@ func TestReflectType(t *testing.T) {
 		assert.Equal(t, d.typ.PkgPath(), d.pkgPath)
 	}
 }
+
+type checkReader interface {
+	Read(p []byte) (int, error)
+	Close() error
+	Name() string
+}
+
+type checkFile struct{}
+
+func (f checkFile) Read(p []byte) (int64, error) { return 0, nil }
+
+func (f *checkFile) Close() error { return nil }
+
+func TestCheckImplements(t *testing.T) {
+
+	iface := reflect.TypeOf((*checkReader)(nil)).Elem()
+	err := util.CheckImplements(reflect.TypeOf(checkFile{}), iface)
+	assert.Error(t, err, `^util_test.checkFile does not implement util_test.checkReader \(missing method Name; `+
+		`method Close has pointer receiver; wrong type for method Read: have func\(\[\]uint8\) \(int64, error\), want func\(\[\]uint8\) \(int, error\)\)$`)
+
+	err = util.CheckImplements(reflect.TypeOf(new(int)), reflect.TypeOf((*io.ReadCloser)(nil)).Elem())
+	assert.Error(t, err, `^\*int does not implement io.ReadCloser \(missing methods Close, Read\)$`)
+
+	err = util.CheckImplements(reflect.TypeOf(new(checkFile)), reflect.TypeOf((*io.Closer)(nil)).Elem())
+	assert.Nil(t, err)
+
+	err = util.CheckImplements(reflect.TypeOf(new(checkFile)), reflect.TypeOf(checkFile{}))
+	assert.Error(t, err, "util_test.checkFile is not an interface")
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cache 提供统一的缓存接口，支持内存和 Redis 两种实现，以及读穿透的
+// 辅助函数。
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Cache 缓存接口，值的编解码由调用方负责。
+type Cache interface {
+
+	// Get 返回 key 对应的值，不存在或者已过期时返回 false 。
+	Get(ctx context.Context, key string) (string, bool, error)
+
+	// Set 设置 key 对应的值，ttl 为 0 时永不过期。
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+
+	// Delete 删除 key 对应的值。
+	Delete(ctx context.Context, key string) error
+}
+
+// Loader 缓存未命中时加载 key 对应的值。
+type Loader func(ctx context.Context) (interface{}, error)
+
+// Metrics 缓存的访问统计。
+type Metrics struct {
+	Hits       int64 `json:"hits"`        // 命中次数
+	Misses     int64 `json:"misses"`      // 未命中次数
+	LoadErrors int64 `json:"load_errors"` // 加载失败的次数
+}
+
+var metrics struct {
+	hits       int64
+	misses     int64
+	loadErrors int64
+}
+
+// GetMetrics 返回 Load 和 Through 函数的访问统计。
+func GetMetrics() Metrics {
+	return Metrics{
+		Hits:       atomic.LoadInt64(&metrics.hits),
+		Misses:     atomic.LoadInt64(&metrics.misses),
+		LoadErrors: atomic.LoadInt64(&metrics.loadErrors),
+	}
+}
+
+type jitterCache struct {
+	Cache
+	ratio float64
+}
+
+// WithJitter 返回在 ttl 上增加 [0, ratio*ttl) 随机时长的缓存，避免大量的 key
+// 同时过期。
+func WithJitter(c Cache, ratio float64) Cache {
+	return &jitterCache{Cache: c, ratio: ratio}
+}
+
+func (c *jitterCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if ttl > 0 && c.ratio > 0 {
+		if n := int64(float64(ttl) * c.ratio); n > 0 {
+			ttl += time.Duration(rand.Int63n(n))
+		}
+	}
+	return c.Cache.Set(ctx, key, value, ttl)
+}
+
+// Load 从缓存 c 中读取 key 对应的值并使用 json 解码到 v ，未命中时调用 loader
+// 加载并写入缓存，写入缓存失败不影响返回结果。
+func Load(ctx context.Context, c Cache, key string, ttl time.Duration, v interface{}, loader Loader) error {
+
+	s, ok, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if ok {
+		atomic.AddInt64(&metrics.hits, 1)
+		return json.Unmarshal([]byte(s), v)
+	}
+	atomic.AddInt64(&metrics.misses, 1)
+
+	r, err := loader(ctx)
+	if err != nil {
+		atomic.AddInt64(&metrics.loadErrors, 1)
+		return err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_ = c.Set(ctx, key, string(b), ttl)
+	return json.Unmarshal(b, v)
+}
+
+var defaultCache Cache
+
+// SetDefault 设置 Through 函数使用的默认缓存。
+func SetDefault(c Cache) {
+	defaultCache = c
+}
+
+// Default 返回默认缓存，未设置时返回 nil 。
+func Default() Cache {
+	return defaultCache
+}
+
+// Through 使用默认缓存执行 Load 函数。
+func Through(ctx context.Context, key string, ttl time.Duration, v interface{}, loader Loader) error {
+	if defaultCache == nil {
+		return errors.New("no default cache")
+	}
+	return Load(ctx, defaultCache, key, ttl, v, loader)
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/cache"
+	"github.com/go-spring/spring-core/redis"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache()
+
+	assert.Nil(t, c.Set(ctx, "a", "1", 0))
+	assert.Nil(t, c.Set(ctx, "b", "2", time.Millisecond))
+
+	v, ok, err := c.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, v, "1")
+
+	time.Sleep(2 * time.Millisecond)
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+
+	assert.Nil(t, c.Delete(ctx, "a"))
+	_, ok, _ = c.Get(ctx, "a")
+	assert.False(t, ok)
+}
+
+func TestThrough(t *testing.T) {
+	ctx := context.Background()
+
+	var u user
+	loader := func(ctx context.Context) (interface{}, error) { return &user{Name: "go"}, nil }
+	assert.Error(t, cache.Through(ctx, "u", 0, &u, loader), "no default cache")
+
+	cache.SetDefault(cache.WithJitter(cache.NewMemoryCache(), 0.1))
+	defer cache.SetDefault(nil)
+
+	before := cache.GetMetrics()
+	n := 0
+	for i := 0; i < 2; i++ {
+		err := cache.Through(ctx, "u", time.Minute, &u, func(ctx context.Context) (interface{}, error) {
+			n++
+			return loader(ctx)
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, u, user{Name: "go"})
+	}
+	assert.Equal(t, n, 1)
+
+	err := cache.Through(ctx, "x", 0, &u, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("load error")
+	})
+	assert.Error(t, err, "load error")
+
+	after := cache.GetMetrics()
+	assert.Equal(t, after.Hits-before.Hits, int64(1))
+	assert.Equal(t, after.Misses-before.Misses, int64(2))
+	assert.Equal(t, after.LoadErrors-before.LoadErrors, int64(1))
+}
+
+type fakeRedis struct {
+	redis.Client
+	m    map[string]string
+	args []interface{}
+}
+
+func (r *fakeRedis) Get(ctx context.Context, key string) (string, error) {
+	if v, ok := r.m[key]; ok {
+		return v, nil
+	}
+	return "", redis.ErrNil
+}
+
+func (r *fakeRedis) Set(ctx context.Context, key string, value interface{}, args ...interface{}) (string, error) {
+	r.m[key], r.args = value.(string), args
+	return "OK", nil
+}
+
+func (r *fakeRedis) Del(ctx context.Context, keys ...string) (int64, error) {
+	for _, key := range keys {
+		delete(r.m, key)
+	}
+	return int64(len(keys)), nil
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	r := &fakeRedis{m: make(map[string]string)}
+	c := cache.NewRedisCache(r, "app:")
+
+	assert.Nil(t, c.Set(ctx, "a", "1", 2*time.Second))
+	assert.Equal(t, r.m, map[string]string{"app:a": "1"})
+	assert.Equal(t, r.args, []interface{}{"PX", int64(2000)})
+
+	v, ok, err := c.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, v, "1")
+
+	assert.Nil(t, c.Delete(ctx, "a"))
+	_, ok, err = c.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value  string
+	expire time.Time // 零值表示永不过期
+}
+
+// MemoryCache 基于 map 的内存缓存，过期的 key 在访问时删除。
+type MemoryCache struct {
+	mutex sync.RWMutex
+	items map[string]memoryItem
+}
+
+// NewMemoryCache 返回空的内存缓存。
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{items: make(map[string]memoryItem)}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mutex.RLock()
+	item, ok := c.items[key]
+	c.mutex.RUnlock()
+	if !ok {
+		return "", false, nil
+	}
+	if !item.expire.IsZero() && !time.Now().Before(item.expire) {
+		c.mutex.Lock()
+		if item, ok = c.items[key]; ok && !item.expire.IsZero() && !time.Now().Before(item.expire) {
+			delete(c.items, key)
+		}
+		c.mutex.Unlock()
+		return "", false, nil
+	}
+	return item.value, true, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expire = time.Now().Add(ttl)
+	}
+	c.mutex.Lock()
+	c.items[key] = item
+	c.mutex.Unlock()
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mutex.Lock()
+	delete(c.items, key)
+	c.mutex.Unlock()
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-core/redis"
+)
+
+// RedisCache 基于 Redis 的缓存，所有的 key 都会加上 prefix 前缀。
+type RedisCache struct {
+	client redis.Client
+	prefix string
+}
+
+// NewRedisCache 返回使用 client 的 Redis 缓存。
+func NewRedisCache(client redis.Client, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (string, bool, error) {
+	s, err := c.client.Get(ctx, c.prefix+key)
+	if err == redis.ErrNil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return s, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	var args []interface{}
+	if ttl > 0 {
+		args = append(args, "PX", int64(ttl/time.Millisecond))
+	}
+	_, err := c.client.Set(ctx, c.prefix+key, value, args...)
+	return err
+}
+
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	_, err := c.client.Del(ctx, c.prefix+key)
+	return err
+}
@@ -30,15 +30,199 @@ type WebServerConfig struct {
 	BasePath     string `value:"${web.server.base-path:=/}"`      // 根路径
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	Timeout      time.Duration `value:"${web.server.timeout:=0}"` // 处理请求的超时时间，0 表示不限制
+
+	MaxBodySize        int64 `value:"${web.server.max-body-size:=0}"`               // 请求体最大字节数，0 表示不限制
+	MaxMultipartMemory int64 `value:"${web.server.multipart.max-memory:=33554432}"` // multipart 表单最大内存
+	MaxUploadSize      int64 `value:"${web.server.multipart.max-size:=0}"`          // multipart 请求最大字节数
+
+	// AllowedUploadTypes 允许上传的文件类型，例如 image/png,image/* ，为空时不限制。
+	AllowedUploadTypes []string `value:"${web.server.multipart.allowed-types:=}"`
+
+	// Listen 监听地址，为空时监听 IP:Port ，unix:<path> 表示监听 unix socket ，
+	// systemd 或者 systemd:<name> 表示使用 systemd socket activation 传入的
+	// 监听器，fd:<n> 表示使用从父进程继承的文件描述符。
+	Listen     string `value:"${web.server.listen:=}"`
+	SocketMode string `value:"${web.server.listen-mode:=}"` // unix socket 文件的权限，例如 0660
 }
 
 func DefaultWebServerConfig() WebServerConfig {
 	return WebServerConfig{
-		Port:     8080,
-		BasePath: "/",
+		Port:               8080,
+		BasePath:           "/",
+		MaxMultipartMemory: 32 << 20,
 	}
 }
 
+// ManagementServerConfig 管理端点服务器配置。
+type ManagementServerConfig struct {
+	IP       string `value:"${management.server.ip:=}"`         // 监听 IP
+	Port     int    `value:"${management.server.port:=0}"`      // HTTP 端口
+	BasePath string `value:"${management.server.base-path:=/}"` // 根路径
+}
+
+// WebServerConfig 返回管理端点服务器对应的 Web 服务器配置。
+func (c ManagementServerConfig) WebServerConfig() WebServerConfig {
+	config := DefaultWebServerConfig()
+	config.IP = c.IP
+	config.Port = c.Port
+	config.BasePath = c.BasePath
+	return config
+}
+
+// NamedServerConfig web.servers.<name>.* 配置的附加 Web 服务器，每个服务器使用
+// 独立的端口、TLS 配置和过滤器，例如对外的 API 服务器和对内的管理服务器。
+type NamedServerConfig struct {
+	IP        string        `value:"${ip:=}"`              // 监听 IP
+	Port      int           `value:"${port}"`              // HTTP 端口
+	EnableSSL bool          `value:"${ssl.enable:=false}"` // 是否启用 HTTPS
+	KeyFile   string        `value:"${ssl.key:=}"`         // SSL 秘钥
+	CertFile  string        `value:"${ssl.cert:=}"`        // SSL 证书
+	BasePath  string        `value:"${base-path:=/}"`      // 根路径
+	Timeout   time.Duration `value:"${timeout:=0}"`        // 处理请求的超时时间，0 表示不限制
+	Filters   []string      `value:"${filters:=}"`         // 过滤器的 bean 选择器，为空时使用 web.server.filters 的过滤器
+	Listen    string        `value:"${listen:=}"`          // 监听地址，参见 WebServerConfig.Listen
+}
+
+// WebServerConfig 返回附加服务器对应的 Web 服务器配置。
+func (c NamedServerConfig) WebServerConfig() WebServerConfig {
+	config := DefaultWebServerConfig()
+	config.IP = c.IP
+	config.Port = c.Port
+	config.EnableSSL = c.EnableSSL
+	config.KeyFile = c.KeyFile
+	config.CertFile = c.CertFile
+	config.BasePath = c.BasePath
+	config.Timeout = c.Timeout
+	config.Listen = c.Listen
+	return config
+}
+
+// IPFilterConfig IP 访问控制过滤器配置，列表项可以是 IP 也可以是 CIDR 。
+type IPFilterConfig struct {
+	URLPatterns    []string `value:"${url-patterns:=}"`    // 生效的路由，为空时对所有路由生效
+	Allow          []string `value:"${allow:=}"`           // 允许访问的地址，为空时允许所有地址
+	Deny           []string `value:"${deny:=}"`            // 禁止访问的地址，优先于 Allow
+	TrustedProxies []string `value:"${trusted-proxies:=}"` // 可信代理，只信任这些代理设置的 X-Forwarded-For
+}
+
+// ExcludeConfig 访问日志和请求指标的排除规则，用于过滤健康检查和爬虫请求。
+type ExcludeConfig struct {
+	Paths      []string `value:"${paths:=}"`       // 排除的路径，支持 path.Match 通配符
+	UserAgents []string `value:"${user-agents:=}"` // 排除的 User-Agent ，包含即匹配，忽略大小写
+}
+
+// CircuitBreakerConfig 熔断器配置，失败率或者慢调用比例超过阈值时熔断。
+type CircuitBreakerConfig struct {
+	URLPatterns      []string      `value:"${url-patterns:=}"`        // 生效的路由，为空时对所有路由生效
+	FailureRate      float64       `value:"${failure-rate:=50}"`      // 熔断的失败率阈值，百分比
+	SlowCallRate     float64       `value:"${slow-call-rate:=100}"`   // 熔断的慢调用比例阈值，百分比
+	SlowCallDuration time.Duration `value:"${slow-call-duration:=0}"` // 慢调用的耗时阈值，0 表示不统计慢调用
+	MinRequests      int           `value:"${min-requests:=10}"`      // 统计窗口内计算比例需要的最少请求数
+	Window           time.Duration `value:"${window:=10s}"`           // 统计窗口的长度
+	OpenTimeout      time.Duration `value:"${open-timeout:=30s}"`     // 熔断后进入半开状态的等待时间
+	HalfOpenRequests int           `value:"${half-open-requests:=1}"` // 半开状态允许的探测请求数
+}
+
+// MessageSourceConfig 国际化消息源配置。
+type MessageSourceConfig struct {
+	Dir      string `value:"${web.i18n.dir:=}"`              // 属性文件所在的目录
+	Basename string `value:"${web.i18n.basename:=messages}"` // 属性文件的基础名称
+	Fallback string `value:"${web.i18n.fallback:=zh-CN}"`    // 默认语言
+}
+
+// RecordRuleConfig 流量录制的采样和过滤规则配置。
+type RecordRuleConfig struct {
+	Enabled     bool     `value:"${fastdev.record.enabled:=true}"`          // 是否录制新的请求，运行时可以通过管理端点修改
+	SampleRate  float64  `value:"${fastdev.record.sample-rate:=1}"`         // 采样率，取值范围 0~1
+	Include     []string `value:"${fastdev.record.include:=}"`              // 录制的请求，例如 "GET /api/*"
+	Exclude     []string `value:"${fastdev.record.exclude:=}"`              // 不录制的请求，优先于 Include
+	ForceHeader string   `value:"${fastdev.record.force-header:=}"`         // 请求头不为空时强制录制
+	MaxBodySize int      `value:"${fastdev.record.max-body-size:=1048576}"` // 录制的请求体和响应体的最大字节数，0 表示不限制
+}
+
+// MaskRuleConfig 录制数据的脱敏规则配置。
+type MaskRuleConfig struct {
+	Protocol string   `value:"${protocol:=}"` // 生效的协议，为空时对所有协议生效
+	Headers  []string `value:"${headers:=}"`  // 脱敏的 HTTP 头
+	Cookies  []string `value:"${cookies:=}"`  // 脱敏的 Cookie 名称
+	Fields   []string `value:"${fields:=}"`   // 脱敏的 JSON 字段，例如 "user.password"
+	Regex    string   `value:"${regex:=}"`    // 脱敏的字符串模式
+	Replace  string   `value:"${replace:=}"`  // 替换内容
+}
+
+// RecordStorageConfig 录制数据的存储配置。
+type RecordStorageConfig struct {
+	Type           string        `value:"${fastdev.storage.type:=console}"`             // 存储类型，console、file、redis 或者 mq
+	FilePath       string        `value:"${fastdev.storage.file.path:=record.log}"`     // 文件路径
+	FileMaxSize    int64         `value:"${fastdev.storage.file.max-size:=0}"`          // 单个文件的最大字节数，0 表示不滚动
+	FileMaxBackups int           `value:"${fastdev.storage.file.max-backups:=0}"`       // 保留的历史文件个数，0 表示不限制
+	FileMaxAge     time.Duration `value:"${fastdev.storage.file.max-age:=0}"`           // 历史文件的保留时间，0 表示不限制
+	RedisKey       string        `value:"${fastdev.storage.redis.key:=fastdev:record}"` // Redis 列表的键
+	RedisMaxLen    int64         `value:"${fastdev.storage.redis.max-len:=0}"`          // Redis 列表的最大长度，0 表示不限制
+	MQTopic        string        `value:"${fastdev.storage.mq.topic:=fastdev-record}"`  // 消息队列的主题
+}
+
+// MockServerConfig 模拟服务配置，配置了录制文件时 Web 服务器只使用录制的流量
+// 响应请求。
+type MockServerConfig struct {
+	File string `value:"${fastdev.mock.file:=}"` // JSON Lines 格式的录制文件
+}
+
+// LoggingFileConfig 日志文件配置，配置了文件路径时日志输出到滚动的日志文件。
+type LoggingFileConfig struct {
+	Path       string        `value:"${logging.file.path:=}"`          // 文件路径，为空时输出到控制台
+	Format     string        `value:"${logging.file.format:=console}"` // 输出格式，console 或者 json
+	MaxSize    int64         `value:"${logging.file.max-size:=0}"`     // 单个文件的最大字节数，0 表示不按大小滚动
+	Rotate     string        `value:"${logging.file.rotate:=}"`        // 按时间滚动的周期，daily 或者 hourly
+	MaxBackups int           `value:"${logging.file.max-backups:=0}"`  // 保留的历史文件个数，0 表示不限制
+	MaxAge     time.Duration `value:"${logging.file.max-age:=0}"`      // 历史文件的保留时间，0 表示不限制
+	Compress   bool          `value:"${logging.file.compress:=false}"` // 是否使用 gzip 压缩历史文件
+}
+
+// LoggingAsyncConfig 异步日志配置。
+type LoggingAsyncConfig struct {
+	Enabled    bool   `value:"${logging.async.enabled:=false}"`    // 是否异步输出日志
+	BufferSize int    `value:"${logging.async.buffer-size:=1024}"` // 队列长度
+	Policy     string `value:"${logging.async.policy:=block}"`     // 队列满时的处理策略，block 或者 drop
+}
+
+// LoggingMetricsConfig 日志指标配置。
+type LoggingMetricsConfig struct {
+	Enabled bool `value:"${logging.metrics.enabled:=true}"` // 是否统计各级别日志的数量
+}
+
+// LoggingCallerConfig 日志的调用位置和调用栈配置。
+type LoggingCallerConfig struct {
+	Caller     bool   `value:"${logging.caller:=true}"`            // 是否记录调用位置
+	StackLevel string `value:"${logging.stacktrace.level:=error}"` // 记录调用栈的最低级别
+	StackDepth int    `value:"${logging.stacktrace.depth:=0}"`     // 调用栈的最大层数，0 表示不记录
+}
+
+// LoggingSamplingConfig 日志采样配置，对所有日志生效，First 和 Thereafter 都
+// 为 0 时不采样。
+type LoggingSamplingConfig struct {
+	Interval   time.Duration `value:"${logging.sampling.interval:=1s}"`  // 统计周期
+	First      uint64        `value:"${logging.sampling.first:=0}"`      // 每个周期内相同的日志先输出的条数
+	Thereafter uint64        `value:"${logging.sampling.thereafter:=0}"` // 之后每多少条输出一条
+}
+
+// WebClientConfig HTTP 客户端配置。
+type WebClientConfig struct {
+	Timeout            int    `value:"${web.client.timeout:=0}"`                      // 请求超时，毫秒
+	ConnectTimeout     int    `value:"${web.client.connect-timeout:=30000}"`          // 连接超时，毫秒
+	IdleTimeout        int    `value:"${web.client.idle-timeout:=90000}"`             // 空闲连接超时，毫秒
+	MaxIdleConns       int    `value:"${web.client.max-idle-conns:=100}"`             // 最大空闲连接数
+	Proxy              string `value:"${web.client.proxy:=}"`                         // 代理地址，为空时使用环境变量
+	InsecureSkipVerify bool   `value:"${web.client.tls.insecure-skip-verify:=false}"` // 是否跳过证书校验
+	CAFile             string `value:"${web.client.tls.ca:=}"`                        // CA 证书
+	CertFile           string `value:"${web.client.tls.cert:=}"`                      // 客户端证书
+	KeyFile            string `value:"${web.client.tls.key:=}"`                       // 客户端秘钥
+	Retries            int    `value:"${web.client.retries:=0}"`                      // 重试次数
+	RetryBackoff       int    `value:"${web.client.retry-backoff:=100}"`              // 重试的初始间隔，毫秒
+	LoadBalanced       bool   `value:"${web.client.load-balanced:=false}"`            // 是否把请求的 host 作为服务名称进行负载均衡
+}
+
 // DatabaseClientConfig 关系型数据库客户端配置。
 type DatabaseClientConfig struct {
 	Url string `value:"${db.url}"`
@@ -66,17 +250,102 @@ func DefaultRedisClientConfig() RedisClientConfig {
 	}
 }
 
+// DataSourceConfig 数据源配置，默认数据源的属性前缀为 datasource ，其他数据源
+// 的属性前缀为 datasource.<name> 。
+type DataSourceConfig struct {
+	Driver          string        `value:"${driver:=mysql}"`        // 驱动名称
+	URL             string        `value:"${url}"`                  // 连接串
+	MaxOpenConns    int           `value:"${max-open-conns:=0}"`    // 最大连接数，0 表示不限制
+	MaxIdleConns    int           `value:"${max-idle-conns:=2}"`    // 最大空闲连接数
+	ConnMaxLifetime time.Duration `value:"${conn-max-lifetime:=0}"` // 连接的最大存活时间，0 表示不限制
+	SlowThreshold   time.Duration `value:"${slow-threshold:=0}"`    // 慢查询阈值，0 表示不记录慢查询
+}
+
 // MongoClientConfig MongoDB 客户端配置。
 type MongoClientConfig struct {
-	Url string `value:"${mongo.url:=mongodb://localhost}"`
+	Url            string        `value:"${mongo.url:=mongodb://localhost}"` // 连接串
+	ConnectTimeout time.Duration `value:"${mongo.connect-timeout:=10s}"`     // 连接超时
+	MaxPoolSize    uint64        `value:"${mongo.max-pool-size:=100}"`       // 连接池的最大连接数
+	Ping           bool          `value:"${mongo.ping:=true}"`               // 创建客户端时是否 PING 探测
 }
 
 // GrpcServerConfig gRPC 服务器配置。
 type GrpcServerConfig struct {
-	Port int `value:"${grpc.server.port:=9090}"`
+	Port            int           `value:"${grpc.server.port:=9090}"`            // 监听端口
+	CertFile        string        `value:"${grpc.server.tls.cert-file:=}"`       // TLS 证书文件，为空时不启用 TLS
+	KeyFile         string        `value:"${grpc.server.tls.key-file:=}"`        // TLS 私钥文件
+	AccessLog       bool          `value:"${grpc.server.access-log:=true}"`      // 是否记录访问日志
+	ShutdownTimeout time.Duration `value:"${grpc.server.shutdown-timeout:=10s}"` // 优雅停止的超时时间，超时后强制停止
 }
 
 // GrpcEndpointConfig gRPC 服务端点配置。
 type GrpcEndpointConfig struct {
 	Address string `value:"${address:=127.0.0.1:9090}"`
 }
+
+// GrpcClientConfig gRPC 客户端配置，属性前缀为 grpc.client.<name> 。
+type GrpcClientConfig struct {
+	Target           string        `value:"${target:=127.0.0.1:9090}"`       // 服务地址
+	TLS              bool          `value:"${tls.enabled:=false}"`           // 是否启用 TLS
+	CAFile           string        `value:"${tls.ca-file:=}"`                // CA 证书文件，为空时使用系统证书
+	ServerName       string        `value:"${tls.server-name:=}"`            // 校验证书使用的服务器名称
+	KeepaliveTime    time.Duration `value:"${keepalive.time:=0}"`            // 发送 keepalive ping 的间隔，0 表示不发送
+	KeepaliveTimeout time.Duration `value:"${keepalive.timeout:=20s}"`       // 等待 keepalive ping 响应的超时时间
+	MaxAttempts      int           `value:"${retry.max-attempts:=0}"`        // 最大请求次数，小于 2 时不重试
+	InitialBackoff   time.Duration `value:"${retry.initial-backoff:=100ms}"` // 首次重试的间隔
+	MaxBackoff       time.Duration `value:"${retry.max-backoff:=1s}"`        // 重试的最大间隔
+	RetryableCodes   []string      `value:"${retry.codes:=UNAVAILABLE}"`     // 可以重试的状态码
+}
+
+// DiscoveryConfig 服务注册配置，应用启动后把 web 服务器和 gRPC 服务器注册到注册
+// 中心，应用退出时注销。
+type DiscoveryConfig struct {
+	Enabled         bool   `value:"${discovery.register.enabled:=true}"`              // 是否注册
+	ServiceName     string `value:"${spring.application.name:=}"`                     // 服务名称
+	Host            string `value:"${discovery.host:=}"`                              // 注册的地址，为空时使用本机 IPv4 地址
+	RegisterWeb     bool   `value:"${discovery.register.web:=true}"`                  // 是否注册 web 服务器
+	WebPort         int    `value:"${web.server.port:=8080}"`                         // web 服务器端口
+	HealthCheckPath string `value:"${discovery.health-check-path:=/actuator/health}"` // web 服务器的健康检查路径
+	RegisterGrpc    bool   `value:"${discovery.register.grpc:=false}"`                // 是否注册 gRPC 服务器
+	GrpcPort        int    `value:"${grpc.server.port:=9090}"`                        // gRPC 服务器端口
+}
+
+// LoadBalancerConfig 客户端负载均衡配置。
+type LoadBalancerConfig struct {
+	Strategy      string        `value:"${loadbalancer.strategy:=round-robin}"`    // 负载均衡策略，round-robin、weighted 或 least-request
+	MaxFailures   int           `value:"${loadbalancer.ejection.max-failures:=5}"` // 连续失败多少次之后摘除实例，0 表示不摘除
+	EjectDuration time.Duration `value:"${loadbalancer.ejection.duration:=30s}"`   // 实例被摘除的时长
+}
+
+// GoroutinePoolConfig 容器协程池配置，Size 大于 0 时 Go 方法使用协程池限制同时
+// 运行的 goroutine 数量。
+type GoroutinePoolConfig struct {
+	Size int `value:"${spring.goroutine-pool.size:=0}"` // 协程池大小，0 表示不限制
+}
+
+// TaskConfig 容器后台任务配置。
+type TaskConfig struct {
+	ShutdownTimeout time.Duration `value:"${spring.task.shutdown-timeout:=30s}"` // 关闭时等待后台任务结束的超时时间，0 表示一直等待
+}
+
+// AutoExportConfig 自动导出接口的配置，位于 packages 中的接口会被自动导出，包名
+// 以 /... 结尾时包括它的子包，为空时不自动导出。
+type AutoExportConfig struct {
+	Packages []string `value:"${spring.bean.auto-export.packages:=}"` // 自动导出的接口所在的包
+}
+
+// RemoteConfig 远程配置源 (如 Nacos、Apollo、Consul) 的缓存配置。
+type RemoteConfig struct {
+	CacheTTL      time.Duration `value:"${spring.config.remote.cache-ttl:=30s}"`                 // 查找结果的缓存时间，0 表示不缓存
+	SnapshotDir   string        `value:"${spring.config.remote.snapshot-dir:=config/.snapshot}"` // 本地快照的保存目录，为空时不保存快照
+	RetryInterval time.Duration `value:"${spring.config.remote.retry-interval:=10s}"`            // 远程配置源不可用时后台重试的间隔
+}
+
+// BeanFailureConfig bean 初始化失败时的处理策略，fail-fast 表示刷新失败，skip
+// 表示将 bean 标记为不可用然后继续刷新，retry 表示其他 bean 完成初始化之后重试，
+// 重试仍然失败时将 bean 标记为不可用。
+type BeanFailureConfig struct {
+	Policy        string        `value:"${spring.bean.failure-policy:=fail-fast}"` // 处理策略
+	RetryAttempts int           `value:"${spring.bean.retry.max-attempts:=3}"`     // 最大重试次数
+	RetryInterval time.Duration `value:"${spring.bean.retry.interval:=1s}"`        // 重试间隔
+}
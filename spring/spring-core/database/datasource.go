@@ -0,0 +1,197 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/conf"
+)
+
+// DefaultDataSource 默认数据源的名称。
+const DefaultDataSource = "default"
+
+// Metrics 数据源的执行统计。
+type Metrics struct {
+	Queries int64         `json:"queries"` // 执行的语句数
+	Errors  int64         `json:"errors"`  // 执行失败的语句数
+	Slow    int64         `json:"slow"`    // 慢查询的数量
+	Latency time.Duration `json:"latency"` // 语句的累计耗时
+}
+
+type stats struct {
+	name    string
+	slow    time.Duration
+	queries int64
+	errors  int64
+	slows   int64
+	latency int64
+}
+
+var registry = struct {
+	sync.RWMutex
+	m map[string]*stats
+}{m: make(map[string]*stats)}
+
+// GetMetrics 返回数据源的执行统计，数据源不存在时返回零值。
+func GetMetrics(name string) Metrics {
+	registry.RLock()
+	s, ok := registry.m[name]
+	registry.RUnlock()
+	if !ok {
+		return Metrics{}
+	}
+	return Metrics{
+		Queries: atomic.LoadInt64(&s.queries),
+		Errors:  atomic.LoadInt64(&s.errors),
+		Slow:    atomic.LoadInt64(&s.slows),
+		Latency: time.Duration(atomic.LoadInt64(&s.latency)),
+	}
+}
+
+// record 记录一次语句的执行结果，driver.ErrSkip 不计入统计。
+func (s *stats) record(query string, start time.Time, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	cost := time.Since(start)
+	atomic.AddInt64(&s.queries, 1)
+	atomic.AddInt64(&s.latency, int64(cost))
+	if err != nil {
+		atomic.AddInt64(&s.errors, 1)
+	}
+	if s.slow > 0 && cost >= s.slow {
+		atomic.AddInt64(&s.slows, 1)
+		log.GetLogger("database").Warnf("slow query on %s cost %v: %s", s.name, cost, query)
+	}
+}
+
+func (s *stats) exec(query string, fn func() (driver.Result, error)) func() (driver.Result, error) {
+	return func() (driver.Result, error) {
+		start := time.Now()
+		r, err := fn()
+		s.record(query, start, err)
+		return r, err
+	}
+}
+
+func (s *stats) query(query string, fn func() (driver.Rows, error)) func() (driver.Rows, error) {
+	return func() (driver.Rows, error) {
+		start := time.Now()
+		r, err := fn()
+		s.record(query, start, err)
+		return r, err
+	}
+}
+
+type connector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Open 根据配置打开名为 name 的数据源，返回的 *sql.DB 会统计语句的执行情况、
+// 记录慢查询，并且支持流量录制。
+func Open(name string, config conf.DataSourceConfig) (*sql.DB, error) {
+
+	if config.URL == "" {
+		return nil, fmt.Errorf("datasource %s: url is empty", name)
+	}
+
+	db, err := sql.Open(config.Driver, config.URL)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	if err = db.Close(); err != nil {
+		return nil, err
+	}
+
+	s := &stats{name: name, slow: config.SlowThreshold}
+	registry.Lock()
+	registry.m[name] = s
+	registry.Unlock()
+
+	db = sql.OpenDB(&connector{
+		dsn:    config.URL,
+		driver: &recordDriver{Driver: d, stats: s},
+	})
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	return db, nil
+}
+
+// DataSources 按名称管理多个数据源。
+type DataSources struct {
+	m map[string]*sql.DB
+}
+
+// OpenDataSources 打开所有配置的数据源，任何一个打开失败都会关闭已经打开的数据源。
+func OpenDataSources(configs map[string]conf.DataSourceConfig) (*DataSources, error) {
+	ds := &DataSources{m: make(map[string]*sql.DB)}
+	for name, config := range configs {
+		db, err := Open(name, config)
+		if err != nil {
+			_ = ds.Close()
+			return nil, err
+		}
+		ds.m[name] = db
+	}
+	return ds, nil
+}
+
+// Get 返回名为 name 的数据源，不存在时返回 nil 。
+func (ds *DataSources) Get(name string) *sql.DB {
+	return ds.m[name]
+}
+
+// Names 返回所有数据源的名称，按字母顺序排列。
+func (ds *DataSources) Names() []string {
+	var names []string
+	for name := range ds.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close 关闭所有数据源，返回遇到的第一个错误。
+func (ds *DataSources) Close() error {
+	var ret error
+	for _, name := range ds.Names() {
+		if err := ds.m[name].Close(); err != nil && ret == nil {
+			ret = err
+		}
+	}
+	return ret
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/database"
+)
+
+type slowConn struct{ fakeConn }
+
+func (slowConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	time.Sleep(5 * time.Millisecond)
+	return nil, errors.New("exec failed")
+}
+
+type slowDriver struct{}
+
+func (slowDriver) Open(name string) (driver.Conn, error) { return slowConn{}, nil }
+
+func init() {
+	sql.Register("fake-slow", slowDriver{})
+}
+
+func TestOpen(t *testing.T) {
+
+	_, err := database.Open("empty", conf.DataSourceConfig{Driver: "fake-slow"})
+	assert.Error(t, err, "datasource empty: url is empty")
+
+	ds, err := database.OpenDataSources(map[string]conf.DataSourceConfig{
+		database.DefaultDataSource: {Driver: "fake-slow", URL: "a", SlowThreshold: time.Millisecond},
+		"order":                    {Driver: "fake-slow", URL: "b"},
+	})
+	assert.Nil(t, err)
+	defer ds.Close()
+
+	assert.Equal(t, ds.Names(), []string{"default", "order"})
+	assert.True(t, ds.Get("user") == nil)
+
+	db := ds.Get(database.DefaultDataSource)
+	_, err = db.ExecContext(context.Background(), "UPDATE user SET name=?", "a")
+	assert.Error(t, err, "exec failed")
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id FROM user")
+	assert.Nil(t, err)
+	assert.Nil(t, rows.Close())
+
+	m := database.GetMetrics(database.DefaultDataSource)
+	assert.Equal(t, m.Queries, int64(2))
+	assert.Equal(t, m.Errors, int64(1))
+	assert.Equal(t, m.Slow, int64(1))
+	assert.True(t, m.Latency > 0)
+
+	assert.Equal(t, database.GetMetrics("order"), database.Metrics{})
+	assert.Equal(t, database.GetMetrics("user"), database.Metrics{})
+}
@@ -0,0 +1,285 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package database 为 database/sql 提供流量录制的驱动包装器。
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/util"
+)
+
+// SQLRequest 录制的 SQL 请求。
+type SQLRequest struct {
+	Query string        `json:"query"`
+	Args  []interface{} `json:"args,omitempty"`
+}
+
+// SQLResult 录制的 SQL 执行结果。
+type SQLResult struct {
+	LastInsertId int64 `json:"last_insert_id"`
+	RowsAffected int64 `json:"rows_affected"`
+}
+
+// SQLRows 录制的 SQL 查询结果。
+type SQLRows struct {
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// RecordDriver 包装 driver.Driver 对象，录制上下文中绑定了录制会话 ID 的 SQL
+// 请求，使用方式为 sql.Register("mysql-record", database.RecordDriver(d))。
+// 只有带 Context 的调用 (ExecContext、QueryContext 等) 才能被录制。
+func RecordDriver(d driver.Driver) driver.Driver {
+	return &recordDriver{Driver: d}
+}
+
+type recordDriver struct {
+	driver.Driver
+	stats *stats // 执行统计，为 nil 时不统计
+}
+
+func (d *recordDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &recordConn{Conn: c, stats: d.stats}, nil
+}
+
+func toArgs(args []driver.NamedValue) []interface{} {
+	var ret []interface{}
+	for _, arg := range args {
+		ret = append(ret, toValue(arg.Value))
+	}
+	return ret
+}
+
+func toValue(v driver.Value) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+func toValues(args []driver.NamedValue) []driver.Value {
+	ret := make([]driver.Value, len(args))
+	for i, v := range args {
+		ret[i] = v.Value
+	}
+	return ret
+}
+
+// recordExec 录制 Exec 请求。
+func recordExec(ctx context.Context, s *stats, query string, args []driver.NamedValue,
+	fn func() (driver.Result, error)) (driver.Result, error) {
+
+	if s != nil {
+		fn = s.exec(query, fn)
+	}
+
+	if !fastdev.Recording(ctx) {
+		return fn()
+	}
+
+	start := util.Now(ctx)
+	r, err := fn()
+	latency := util.Now(ctx).Sub(start)
+	if err == driver.ErrSkip {
+		return r, err
+	}
+
+	var resp interface{}
+	if err != nil {
+		resp = "(err) " + err.Error()
+	} else {
+		result := &SQLResult{}
+		result.LastInsertId, _ = r.LastInsertId()
+		result.RowsAffected, _ = r.RowsAffected()
+		resp = result
+	}
+
+	fastdev.RecordAction(ctx, &fastdev.Action{
+		Protocol:  fastdev.SQL,
+		Request:   &SQLRequest{Query: query, Args: toArgs(args)},
+		Response:  resp,
+		Timestamp: start.UnixNano(),
+		Latency:   int64(latency),
+	})
+	return r, err
+}
+
+// recordQuery 录制 Query 请求，查询结果在 Rows 关闭时录制。
+func recordQuery(ctx context.Context, s *stats, query string, args []driver.NamedValue,
+	fn func() (driver.Rows, error)) (driver.Rows, error) {
+
+	if s != nil {
+		fn = s.query(query, fn)
+	}
+
+	if !fastdev.Recording(ctx) {
+		return fn()
+	}
+
+	start := util.Now(ctx)
+	rows, err := fn()
+	if err == driver.ErrSkip {
+		return rows, err
+	}
+
+	action := &fastdev.Action{
+		Protocol:  fastdev.SQL,
+		Request:   &SQLRequest{Query: query, Args: toArgs(args)},
+		Timestamp: start.UnixNano(),
+	}
+
+	if err != nil {
+		action.Response = "(err) " + err.Error()
+		action.Latency = int64(util.Now(ctx).Sub(start))
+		fastdev.RecordAction(ctx, action)
+		return nil, err
+	}
+
+	return &recordRows{
+		Rows:   rows,
+		ctx:    ctx,
+		start:  start,
+		action: action,
+		result: &SQLRows{Columns: rows.Columns()},
+	}, nil
+}
+
+type recordConn struct {
+	driver.Conn
+	stats *stats
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	s, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &recordStmt{Stmt: s, query: query, stats: c.stats}, nil
+}
+
+func (c *recordConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		s   driver.Stmt
+		err error
+	)
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = p.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &recordStmt{Stmt: s, query: query, stats: c.stats}, nil
+}
+
+func (c *recordConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *recordConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return recordExec(ctx, c.stats, query, args, func() (driver.Result, error) {
+		return e.ExecContext(ctx, query, args)
+	})
+}
+
+func (c *recordConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return recordQuery(ctx, c.stats, query, args, func() (driver.Rows, error) {
+		return q.QueryContext(ctx, query, args)
+	})
+}
+
+type recordStmt struct {
+	driver.Stmt
+	query string
+	stats *stats
+}
+
+func (s *recordStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return recordExec(ctx, s.stats, s.query, args, func() (driver.Result, error) {
+		if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+			return e.ExecContext(ctx, args)
+		}
+		return s.Stmt.Exec(toValues(args))
+	})
+}
+
+func (s *recordStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return recordQuery(ctx, s.stats, s.query, args, func() (driver.Rows, error) {
+		if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			return q.QueryContext(ctx, args)
+		}
+		return s.Stmt.Query(toValues(args))
+	})
+}
+
+type recordRows struct {
+	driver.Rows
+	ctx    context.Context
+	start  time.Time
+	action *fastdev.Action
+	result *SQLRows
+	once   sync.Once
+}
+
+func (r *recordRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		row := make([]interface{}, len(dest))
+		for i, v := range dest {
+			row[i] = toValue(v)
+		}
+		r.result.Rows = append(r.result.Rows, row)
+	} else if err != io.EOF {
+		r.record("(err) " + err.Error())
+	}
+	return err
+}
+
+func (r *recordRows) Close() error {
+	r.record(r.result)
+	return r.Rows.Close()
+}
+
+func (r *recordRows) record(resp interface{}) {
+	r.once.Do(func() {
+		r.action.Response = resp
+		r.action.Latency = int64(util.Now(r.ctx).Sub(r.start))
+		fastdev.RecordAction(r.ctx, r.action)
+	})
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-core/database"
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ n int }
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n >= 2 {
+		return io.EOF
+	}
+	r.n++
+	dest[0], dest[1] = int64(r.n), []byte("name")
+	return nil
+}
+
+func init() {
+	sql.Register("fake-record", database.RecordDriver(fakeDriver{}))
+}
+
+func TestRecordDriver(t *testing.T) {
+
+	fastdev.SetRecordMode(true)
+	defer fastdev.SetRecordMode(false)
+
+	ctx := knife.New(context.Background())
+	err := knife.Set(ctx, fastdev.RecordSessionIDKey, fastdev.NewSessionID())
+	assert.Nil(t, err)
+
+	db, err := sql.Open("fake-record", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, "UPDATE user SET name=? WHERE id=?", "a", 1)
+	assert.Nil(t, err)
+
+	rows, err := db.QueryContext(ctx, "SELECT id, name FROM user")
+	assert.Nil(t, err)
+	for rows.Next() {
+	}
+	assert.Nil(t, rows.Close())
+
+	session := fastdev.RecordInbound(ctx, &fastdev.Action{})
+	assert.Equal(t, len(session.Actions), 2)
+
+	assert.Equal(t, session.Actions[0].Protocol, fastdev.SQL)
+	assert.Equal(t, session.Actions[0].Request, &database.SQLRequest{
+		Query: "UPDATE user SET name=? WHERE id=?",
+		Args:  []interface{}{"a", int64(1)},
+	})
+	assert.Equal(t, session.Actions[0].Response, &database.SQLResult{RowsAffected: 1})
+
+	assert.Equal(t, session.Actions[1].Response, &database.SQLRows{
+		Columns: []string{"id", "name"},
+		Rows:    [][]interface{}{{int64(1), "name"}, {int64(2), "name"}},
+	})
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-core/conf"
+)
+
+// Strategy 负载均衡策略。
+type Strategy int
+
+const (
+	RoundRobin   = Strategy(iota) // 轮询
+	Weighted                      // 平滑加权轮询，权重记录在 Metadata 的 weight 字段中，默认为 1
+	LeastRequest                  // 选择进行中请求最少的实例
+)
+
+// ParseStrategy 解析 round-robin、weighted 和 least-request 形式的策略名称。
+func ParseStrategy(s string) (Strategy, error) {
+	switch s {
+	case "", "round-robin":
+		return RoundRobin, nil
+	case "weighted":
+		return Weighted, nil
+	case "least-request":
+		return LeastRequest, nil
+	}
+	return 0, fmt.Errorf("unknown load balancer strategy %q", s)
+}
+
+// EjectionPolicy 实例的摘除策略，实例连续失败 MaxFailures 次之后在 Duration
+// 时间内不会被选中，MaxFailures 为 0 时不摘除实例。所有实例都被摘除时忽略摘除
+// 状态。
+type EjectionPolicy struct {
+	MaxFailures int
+	Duration    time.Duration
+}
+
+type endpoint struct {
+	inflight     int64
+	failures     int
+	ejectedUntil time.Time
+	current      int // 平滑加权轮询的当前权重
+}
+
+// Balancer 从一组实例中选择一个实例，实例通过 ID 区分。
+type Balancer struct {
+	strategy  Strategy
+	ejection  EjectionPolicy
+	mutex     sync.Mutex
+	next      uint64
+	endpoints map[string]*endpoint
+}
+
+// NewBalancer 返回使用策略 s 和摘除策略 e 的 Balancer 对象。
+func NewBalancer(s Strategy, e EjectionPolicy) *Balancer {
+	return &Balancer{strategy: s, ejection: e, endpoints: make(map[string]*endpoint)}
+}
+
+func weight(i *Instance) int {
+	if w, err := strconv.Atoi(i.Metadata["weight"]); err == nil && w > 0 {
+		return w
+	}
+	return 1
+}
+
+func (b *Balancer) endpoint(id string) *endpoint {
+	e, ok := b.endpoints[id]
+	if !ok {
+		e = &endpoint{}
+		b.endpoints[id] = e
+	}
+	return e
+}
+
+// Pick 选择一个实例，调用方在请求结束后需要调用返回的 done 函数报告请求结果，
+// instances 为空时返回 nil 。
+func (b *Balancer) Pick(instances []*Instance) (*Instance, func(err error)) {
+	if len(instances) == 0 {
+		return nil, nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	var available []*Instance
+	for _, i := range instances {
+		if now.Before(b.endpoint(i.ID).ejectedUntil) {
+			continue
+		}
+		available = append(available, i)
+	}
+	if len(available) == 0 {
+		available = instances
+	}
+
+	var r *Instance
+	switch b.strategy {
+	case Weighted:
+		total, best := 0, (*endpoint)(nil)
+		for _, i := range available {
+			e, w := b.endpoint(i.ID), weight(i)
+			e.current += w
+			total += w
+			if best == nil || e.current > best.current {
+				best, r = e, i
+			}
+		}
+		best.current -= total
+	case LeastRequest:
+		for _, i := range available {
+			if r == nil || b.endpoint(i.ID).inflight < b.endpoint(r.ID).inflight {
+				r = i
+			}
+		}
+	default:
+		r = available[b.next%uint64(len(available))]
+		b.next++
+	}
+
+	e := b.endpoint(r.ID)
+	e.inflight++
+	var once sync.Once
+	return r, func(err error) {
+		once.Do(func() { b.done(e, err) })
+	}
+}
+
+func (b *Balancer) done(e *endpoint, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	e.inflight--
+	if err == nil {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if b.ejection.MaxFailures > 0 && e.failures >= b.ejection.MaxFailures {
+		e.failures = 0
+		e.ejectedUntil = time.Now().Add(b.ejection.Duration)
+	}
+}
+
+// LoadBalancer 使用 Resolver 查询服务的实例，然后使用 Balancer 选择实例。
+type LoadBalancer struct {
+	resolver Resolver
+	balancer *Balancer
+}
+
+// NewLoadBalancer 返回 LoadBalancer 对象。
+func NewLoadBalancer(r Resolver, b *Balancer) *LoadBalancer {
+	return &LoadBalancer{resolver: r, balancer: b}
+}
+
+// NewLoadBalancerFromConfig 根据配置返回 LoadBalancer 对象。
+func NewLoadBalancerFromConfig(r Resolver, config conf.LoadBalancerConfig) (*LoadBalancer, error) {
+	s, err := ParseStrategy(config.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	e := EjectionPolicy{MaxFailures: config.MaxFailures, Duration: config.EjectDuration}
+	return NewLoadBalancer(r, NewBalancer(s, e)), nil
+}
+
+// Resolver 返回使用的 Resolver 对象。
+func (lb *LoadBalancer) Resolver() Resolver {
+	return lb.resolver
+}
+
+// Balancer 返回使用的 Balancer 对象。
+func (lb *LoadBalancer) Balancer() *Balancer {
+	return lb.balancer
+}
+
+// Choose 选择服务 name 的一个实例，没有可用实例时返回 ErrNoInstance 。
+func (lb *LoadBalancer) Choose(ctx context.Context, name string) (*Instance, func(err error), error) {
+	instances, err := lb.resolver.Resolve(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	i, done := lb.balancer.Pick(instances)
+	if i == nil {
+		return nil, nil, ErrNoInstance
+	}
+	return i, done, nil
+}
+
+// Next 选择服务 name 的一个实例并返回 host:port 形式的地址，实现了 web.Balancer
+// 接口。
+func (lb *LoadBalancer) Next(ctx context.Context, name string) (string, func(err error), error) {
+	i, done, err := lb.Choose(ctx, name)
+	if err != nil {
+		return "", nil, err
+	}
+	return i.Address(), done, nil
+}
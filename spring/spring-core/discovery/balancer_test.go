@@ -0,0 +1,105 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/discovery"
+)
+
+func pick(b *discovery.Balancer, instances []*discovery.Instance, n int) []string {
+	var ids []string
+	for i := 0; i < n; i++ {
+		r, done := b.Pick(instances)
+		done(nil)
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestBalancer(t *testing.T) {
+
+	instances := []*discovery.Instance{
+		{ID: "a", Metadata: map[string]string{"weight": "3"}},
+		{ID: "b"},
+	}
+
+	t.Run("round robin", func(t *testing.T) {
+		b := discovery.NewBalancer(discovery.RoundRobin, discovery.EjectionPolicy{})
+		assert.Equal(t, pick(b, instances, 4), []string{"a", "b", "a", "b"})
+		r, done := b.Pick(nil)
+		assert.True(t, r == nil && done == nil)
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		b := discovery.NewBalancer(discovery.Weighted, discovery.EjectionPolicy{})
+		assert.Equal(t, pick(b, instances, 4), []string{"a", "a", "b", "a"})
+	})
+
+	t.Run("least request", func(t *testing.T) {
+		b := discovery.NewBalancer(discovery.LeastRequest, discovery.EjectionPolicy{})
+		r1, done1 := b.Pick(instances)
+		r2, done2 := b.Pick(instances)
+		assert.Equal(t, r1.ID, "a")
+		assert.Equal(t, r2.ID, "b")
+		done1(nil)
+		r3, _ := b.Pick(instances)
+		assert.Equal(t, r3.ID, "a")
+		done2(nil)
+	})
+
+	t.Run("ejection", func(t *testing.T) {
+		e := discovery.EjectionPolicy{MaxFailures: 2, Duration: 50 * time.Millisecond}
+		b := discovery.NewBalancer(discovery.RoundRobin, e)
+		for i := 0; i < 2; i++ {
+			_, done := b.Pick(instances[:1])
+			done(errors.New("error"))
+		}
+		assert.Equal(t, pick(b, instances, 3), []string{"b", "b", "b"})
+		assert.Equal(t, pick(b, instances[:1], 1), []string{"a"})
+		time.Sleep(60 * time.Millisecond)
+		assert.Equal(t, len(pick(b, instances, 2)), 2)
+	})
+}
+
+func TestLoadBalancer(t *testing.T) {
+
+	_, err := discovery.NewLoadBalancerFromConfig(nil, conf.LoadBalancerConfig{Strategy: "random"})
+	assert.Error(t, err, "unknown load balancer strategy \"random\"")
+
+	ctx := context.Background()
+	r := discovery.NewMemoryRegistry()
+	err = r.Register(ctx, &discovery.Instance{ID: "1", Name: "order", Host: "10.0.0.1", Port: 8080})
+	assert.Nil(t, err)
+
+	lb, err := discovery.NewLoadBalancerFromConfig(r, conf.LoadBalancerConfig{Strategy: "least-request"})
+	assert.Nil(t, err)
+
+	addr, done, err := lb.Next(ctx, "order")
+	assert.Nil(t, err)
+	assert.Equal(t, addr, "10.0.0.1:8080")
+	done(nil)
+
+	_, _, err = lb.Next(ctx, "user")
+	assert.True(t, errors.Is(err, discovery.ErrNoInstance))
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package discovery 提供服务注册和发现的接口，具体实现由 Consul、Nacos 等启动器
+// 提供。
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-base/util"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/gs"
+)
+
+// ErrNoInstance 服务没有可用的实例。
+var ErrNoInstance = errors.New("no available instance")
+
+// 服务实例的协议，记录在 Metadata 的 protocol 字段中。
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// Instance 服务实例。
+type Instance struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Host           string            `json:"host"`
+	Port           int               `json:"port"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	HealthCheckURL string            `json:"health_check_url,omitempty"` // 为空时使用 TCP 检查
+}
+
+// Address 返回 host:port 形式的地址。
+func (i *Instance) Address() string {
+	return fmt.Sprintf("%s:%d", i.Host, i.Port)
+}
+
+// Registry 服务注册中心。
+type Registry interface {
+	Register(ctx context.Context, i *Instance) error
+	Deregister(ctx context.Context, i *Instance) error
+}
+
+// Resolver 查询服务的可用实例。
+type Resolver interface {
+	Resolve(ctx context.Context, name string) ([]*Instance, error)
+}
+
+// Picker 使用轮询的方式从 Resolver 返回的实例中选择一个。
+type Picker struct {
+	resolver Resolver
+	next     uint64
+}
+
+// NewPicker 返回使用 r 查询实例的 Picker 对象。
+func NewPicker(r Resolver) *Picker {
+	return &Picker{resolver: r}
+}
+
+// Pick 返回服务 name 的一个可用实例，没有可用实例时返回 ErrNoInstance 。
+func (p *Picker) Pick(ctx context.Context, name string) (*Instance, error) {
+	instances, err := p.resolver.Resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, ErrNoInstance
+	}
+	n := atomic.AddUint64(&p.next, 1) - 1
+	return instances[n%uint64(len(instances))], nil
+}
+
+// Instances 根据配置返回需要注册的服务实例，gRPC 实例的服务名称为 <name>-grpc 。
+func Instances(config conf.DiscoveryConfig) ([]*Instance, error) {
+
+	if !config.Enabled {
+		return nil, nil
+	}
+	if config.ServiceName == "" {
+		return nil, errors.New("spring.application.name is required for service registration")
+	}
+
+	host := config.Host
+	if host == "" {
+		host = util.LocalIPv4()
+	}
+
+	var ret []*Instance
+	if config.RegisterWeb {
+		i := &Instance{
+			ID:       fmt.Sprintf("%s-%s-%d", config.ServiceName, host, config.WebPort),
+			Name:     config.ServiceName,
+			Host:     host,
+			Port:     config.WebPort,
+			Metadata: map[string]string{"protocol": ProtocolHTTP},
+		}
+		if config.HealthCheckPath != "" {
+			i.HealthCheckURL = fmt.Sprintf("http://%s%s", i.Address(), config.HealthCheckPath)
+		}
+		ret = append(ret, i)
+	}
+	if config.RegisterGrpc {
+		name := config.ServiceName + "-grpc"
+		ret = append(ret, &Instance{
+			ID:       fmt.Sprintf("%s-%s-%d", name, host, config.GrpcPort),
+			Name:     name,
+			Host:     host,
+			Port:     config.GrpcPort,
+			Metadata: map[string]string{"protocol": ProtocolGRPC},
+		})
+	}
+	return ret, nil
+}
+
+// Registrar 在应用启动后注册服务实例，在应用退出时注销，导出为 gs.AppEvent 后
+// 生效。
+type Registrar struct {
+	registry  Registry
+	instances []*Instance
+	mutex     sync.Mutex
+	done      []*Instance
+}
+
+// NewRegistrar 返回把配置的服务实例注册到 r 的 Registrar 对象。
+func NewRegistrar(r Registry, config conf.DiscoveryConfig) (*Registrar, error) {
+	instances, err := Instances(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Registrar{registry: r, instances: instances}, nil
+}
+
+// Register 注册所有服务实例，遇到错误时停止注册。
+func (r *Registrar) Register(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, i := range r.instances {
+		if err := r.registry.Register(ctx, i); err != nil {
+			return err
+		}
+		log.Infof("register %s %s to registry", i.Name, i.Address())
+		r.done = append(r.done, i)
+	}
+	return nil
+}
+
+// Deregister 注销所有已经注册的服务实例。
+func (r *Registrar) Deregister(ctx context.Context) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, i := range r.done {
+		if err := r.registry.Deregister(ctx, i); err != nil {
+			log.Errorf("deregister %s %s error: %v", i.Name, i.Address(), err)
+			continue
+		}
+		log.Infof("deregister %s %s from registry", i.Name, i.Address())
+	}
+	r.done = nil
+}
+
+func (r *Registrar) OnAppStart(ctx gs.Context) {
+	if err := r.Register(ctx.Context()); err != nil {
+		log.Errorf("register service error: %v", err)
+	}
+}
+
+func (r *Registrar) OnAppStop(ctx context.Context) {
+	r.Deregister(ctx)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/discovery"
+)
+
+func TestInstances(t *testing.T) {
+
+	_, err := discovery.Instances(conf.DiscoveryConfig{Enabled: true})
+	assert.Error(t, err, "spring.application.name is required")
+
+	instances, err := discovery.Instances(conf.DiscoveryConfig{Enabled: false, ServiceName: "order"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(instances), 0)
+
+	instances, err = discovery.Instances(conf.DiscoveryConfig{
+		Enabled:         true,
+		ServiceName:     "order",
+		Host:            "10.0.0.1",
+		RegisterWeb:     true,
+		WebPort:         8080,
+		HealthCheckPath: "/actuator/health",
+		RegisterGrpc:    true,
+		GrpcPort:        9090,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, instances, []*discovery.Instance{
+		{
+			ID:             "order-10.0.0.1-8080",
+			Name:           "order",
+			Host:           "10.0.0.1",
+			Port:           8080,
+			Metadata:       map[string]string{"protocol": "http"},
+			HealthCheckURL: "http://10.0.0.1:8080/actuator/health",
+		},
+		{
+			ID:       "order-grpc-10.0.0.1-9090",
+			Name:     "order-grpc",
+			Host:     "10.0.0.1",
+			Port:     9090,
+			Metadata: map[string]string{"protocol": "grpc"},
+		},
+	})
+}
+
+func TestRegistrar(t *testing.T) {
+	ctx := context.Background()
+	r := discovery.NewMemoryRegistry()
+
+	registrar, err := discovery.NewRegistrar(r, conf.DiscoveryConfig{
+		Enabled:     true,
+		ServiceName: "order",
+		Host:        "10.0.0.1",
+		RegisterWeb: true,
+		WebPort:     8080,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, r.Register(ctx, &discovery.Instance{ID: "order-10.0.0.2-8080", Name: "order", Host: "10.0.0.2", Port: 8080}))
+	assert.Nil(t, registrar.Register(ctx))
+
+	p := discovery.NewPicker(r)
+	var addresses []string
+	for i := 0; i < 3; i++ {
+		instance, err := p.Pick(ctx, "order")
+		assert.Nil(t, err)
+		addresses = append(addresses, instance.Address())
+	}
+	assert.Equal(t, addresses, []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.1:8080"})
+
+	registrar.Deregister(ctx)
+	instances, err := r.Resolve(ctx, "order")
+	assert.Nil(t, err)
+	assert.Equal(t, len(instances), 1)
+
+	_, err = p.Pick(ctx, "user")
+	assert.Equal(t, err, discovery.ErrNoInstance)
+}
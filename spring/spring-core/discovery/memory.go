@@ -0,0 +1,66 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryRegistry 进程内的注册中心，同时实现了 Registry 和 Resolver 接口，适合
+// 测试和本地开发。
+type MemoryRegistry struct {
+	mutex     sync.RWMutex
+	instances map[string]map[string]*Instance
+}
+
+// NewMemoryRegistry 返回空的进程内注册中心。
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{instances: make(map[string]map[string]*Instance)}
+}
+
+func (r *MemoryRegistry) Register(ctx context.Context, i *Instance) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	m, ok := r.instances[i.Name]
+	if !ok {
+		m = make(map[string]*Instance)
+		r.instances[i.Name] = m
+	}
+	m[i.ID] = i
+	return nil
+}
+
+func (r *MemoryRegistry) Deregister(ctx context.Context, i *Instance) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.instances[i.Name], i.ID)
+	return nil
+}
+
+// Resolve 返回服务 name 的所有实例，按照 ID 排序。
+func (r *MemoryRegistry) Resolve(ctx context.Context, name string) ([]*Instance, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var ret []*Instance
+	for _, i := range r.instances[name] {
+		ret = append(ret, i)
+	}
+	sort.Slice(ret, func(a, b int) bool { return ret[a].ID < ret[b].ID })
+	return ret, nil
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package feature 提供基于动态属性的功能开关。开关的值来自 feature.flags.<name>
+// 属性，支持 true/false (on/off) 以及 N% 形式的按比例灰度，属性变化时通知监听函数。
+package feature
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Prefix 功能开关的属性前缀。
+const Prefix = "feature.flags."
+
+// Listener 功能开关变化的监听函数，value 为新的值，开关被删除时为空字符串。
+type Listener func(name string, value string)
+
+// Manager 管理功能开关的值，值通过 Update 整体更新。
+type Manager struct {
+	mutex     sync.RWMutex
+	values    map[string]string
+	listeners map[string][]Listener
+}
+
+// NewManager 返回没有任何开关的 Manager 对象。
+func NewManager() *Manager {
+	return &Manager{
+		values:    make(map[string]string),
+		listeners: make(map[string][]Listener),
+	}
+}
+
+var defaultManager = NewManager()
+
+// Default 返回包级别的 Manager 对象，IsEnabled 等函数使用该对象。
+func Default() *Manager {
+	return defaultManager
+}
+
+// Update 使用 props 中 feature.flags. 前缀的属性替换所有开关的值，并通知值发生
+// 变化的开关的监听函数。
+func (m *Manager) Update(props map[string]string) {
+
+	values := make(map[string]string)
+	for k, v := range props {
+		if strings.HasPrefix(k, Prefix) {
+			values[strings.TrimPrefix(k, Prefix)] = v
+		}
+	}
+
+	type change struct{ name, value string }
+	var changes []change
+
+	m.mutex.Lock()
+	for name, v := range values {
+		if old, ok := m.values[name]; !ok || old != v {
+			changes = append(changes, change{name, v})
+		}
+	}
+	for name := range m.values {
+		if _, ok := values[name]; !ok {
+			changes = append(changes, change{name, ""})
+		}
+	}
+	m.values = values
+	listeners := make(map[string][]Listener)
+	for _, c := range changes {
+		var fns []Listener
+		fns = append(fns, m.listeners[c.name]...)
+		listeners[c.name] = append(fns, m.listeners[""]...)
+	}
+	m.mutex.Unlock()
+
+	for _, c := range changes {
+		for _, fn := range listeners[c.name] {
+			fn(c.name, c.value)
+		}
+	}
+}
+
+// OnChange 注册开关 name 的监听函数，name 为空时监听所有开关。
+func (m *Manager) OnChange(name string, fn Listener) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.listeners[name] = append(m.listeners[name], fn)
+}
+
+// Value 返回开关 name 的原始值，不存在时返回空字符串。
+func (m *Manager) Value(name string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.values[name]
+}
+
+// Names 返回所有开关的名称。
+func (m *Manager) Names() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	var names []string
+	for name := range m.values {
+		names = append(names, name)
+	}
+	return names
+}
+
+// IsEnabled 返回开关 name 是否打开，N% 形式的值每次调用按比例随机返回。
+func (m *Manager) IsEnabled(name string) bool {
+	return evaluate(m.Value(name), func() int { return rand.Intn(100) })
+}
+
+// IsEnabledFor 返回开关 name 对于 key (通常为用户 ID) 是否打开，N% 形式的值
+// 对同一个 key 总是返回相同的结果。
+func (m *Manager) IsEnabledFor(name string, key string) bool {
+	return evaluate(m.Value(name), func() int { return bucket(name, key) })
+}
+
+// Flag 返回开关 name 的 Flag 对象。
+func (m *Manager) Flag(name string) *Flag {
+	return &Flag{m: m, name: name}
+}
+
+// Watch 从 s 加载属性并更新开关的值，直到 ctx 结束。
+func (m *Manager) Watch(ctx context.Context, s Source) error {
+	return s.Watch(ctx, m.Update)
+}
+
+// bucket 返回 name 和 key 对应的 [0, 100) 之间的固定值。
+func bucket(name string, key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + key))
+	return int(h.Sum32() % 100)
+}
+
+// evaluate 计算开关的值，无法解析的值视为关闭。
+func evaluate(value string, bucket func() int) bool {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "%") {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || n <= 0 {
+			return false
+		}
+		return n >= 100 || float64(bucket()) < n
+	}
+	switch strings.ToLower(value) {
+	case "true", "on", "yes", "1":
+		return true
+	}
+	return false
+}
+
+// Flag 一个功能开关，可以注册为 bean 注入到业务代码中。
+type Flag struct {
+	m    *Manager
+	name string
+}
+
+// New 返回包级别 Manager 中开关 name 的 Flag 对象。
+func New(name string) *Flag {
+	return defaultManager.Flag(name)
+}
+
+// Name 返回开关的名称。
+func (f *Flag) Name() string {
+	return f.name
+}
+
+// Value 返回开关的原始值。
+func (f *Flag) Value() string {
+	return f.m.Value(f.name)
+}
+
+// IsEnabled 返回开关是否打开。
+func (f *Flag) IsEnabled() bool {
+	return f.m.IsEnabled(f.name)
+}
+
+// IsEnabledFor 返回开关对于 key 是否打开。
+func (f *Flag) IsEnabledFor(key string) bool {
+	return f.m.IsEnabledFor(f.name, key)
+}
+
+// OnChange 注册开关变化的监听函数。
+func (f *Flag) OnChange(fn func(value string)) {
+	f.m.OnChange(f.name, func(name string, value string) { fn(value) })
+}
+
+// IsEnabled 返回包级别 Manager 中开关 name 是否打开。
+func IsEnabled(name string) bool {
+	return defaultManager.IsEnabled(name)
+}
+
+// IsEnabledFor 返回包级别 Manager 中开关 name 对于 key 是否打开。
+func IsEnabledFor(name string, key string) bool {
+	return defaultManager.IsEnabledFor(name, key)
+}
+
+// OnChange 在包级别 Manager 上注册开关 name 的监听函数。
+func OnChange(name string, fn Listener) {
+	defaultManager.OnChange(name, fn)
+}
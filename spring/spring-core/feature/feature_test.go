@@ -0,0 +1,128 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package feature_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/feature"
+)
+
+func TestManager(t *testing.T) {
+
+	m := feature.NewManager()
+	assert.False(t, m.IsEnabled("checkout"))
+
+	var changes []string
+	m.OnChange("checkout", func(name string, value string) {
+		changes = append(changes, name+"="+value)
+	})
+	var all int
+	m.OnChange("", func(name string, value string) { all++ })
+
+	m.Update(map[string]string{
+		"feature.flags.checkout": "on",
+		"feature.flags.search":   "false",
+		"feature.flags.beta":     "0%",
+		"feature.flags.gamma":    "100%",
+		"web.server.port":        "8080",
+	})
+	names := m.Names()
+	sort.Strings(names)
+	assert.Equal(t, names, []string{"beta", "checkout", "gamma", "search"})
+	assert.True(t, m.IsEnabled("checkout"))
+	assert.False(t, m.IsEnabled("search"))
+	assert.False(t, m.IsEnabledFor("beta", "u1"))
+	assert.True(t, m.IsEnabledFor("gamma", "u1"))
+	assert.Equal(t, all, 4)
+
+	m.Update(map[string]string{
+		"feature.flags.checkout": "on",
+		"feature.flags.search":   "false",
+	})
+	assert.Equal(t, all, 6)
+
+	m.Update(map[string]string{"feature.flags.search": "false"})
+	assert.Equal(t, changes, []string{"checkout=on", "checkout="})
+	assert.False(t, m.IsEnabled("checkout"))
+}
+
+func TestPercentage(t *testing.T) {
+
+	m := feature.NewManager()
+	m.Update(map[string]string{"feature.flags.rollout": "30%"})
+	f := m.Flag("rollout")
+	assert.Equal(t, f.Value(), "30%")
+
+	n := 0
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		enabled := f.IsEnabledFor(key)
+		assert.Equal(t, f.IsEnabledFor(key), enabled)
+		if enabled {
+			n++
+		}
+	}
+	assert.True(t, n > 200 && n < 400)
+
+	var value string
+	f.OnChange(func(v string) { value = v })
+	m.Update(map[string]string{"feature.flags.rollout": "abc%"})
+	assert.Equal(t, value, "abc%")
+	assert.False(t, f.IsEnabled())
+}
+
+func TestFileSource(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "feature")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "feature.properties")
+	err = ioutil.WriteFile(file, []byte("feature.flags.checkout=off\n"), os.ModePerm)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := feature.NewManager()
+	changed := make(chan string, 2)
+	m.OnChange("checkout", func(name string, value string) { changed <- value })
+
+	exit := make(chan error)
+	go func() { exit <- m.Watch(ctx, feature.NewFileSource(file, 10*time.Millisecond)) }()
+	assert.Equal(t, <-changed, "off")
+
+	err = ioutil.WriteFile(file, []byte("feature.flags.checkout=on\n"), os.ModePerm)
+	assert.Nil(t, err)
+	assert.Equal(t, <-changed, "on")
+	assert.True(t, m.IsEnabled("checkout"))
+
+	cancel()
+	assert.Nil(t, <-exit)
+
+	err = feature.NewFileSource(filepath.Join(dir, "none.properties"), time.Second).Watch(ctx, m.Update)
+	assert.NotNil(t, err)
+}
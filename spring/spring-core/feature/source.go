@@ -0,0 +1,85 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package feature
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-base/log"
+)
+
+// Source 动态属性源。
+type Source interface {
+
+	// Watch 加载属性并调用 fn ，之后每当属性发生变化时再次调用 fn ，直到 ctx
+	// 结束。首次加载失败时返回错误。
+	Watch(ctx context.Context, fn func(props map[string]string)) error
+}
+
+// FileSource 定时读取属性文件的动态属性源，支持 conf 包支持的所有文件格式。
+type FileSource struct {
+	file     string
+	interval time.Duration
+}
+
+// NewFileSource 返回每隔 interval 读取一次 file 的 FileSource 对象。
+func NewFileSource(file string, interval time.Duration) *FileSource {
+	return &FileSource{file: file, interval: interval}
+}
+
+func (s *FileSource) load() (map[string]string, error) {
+	p, err := conf.Load(s.file)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string)
+	for _, k := range p.Keys() {
+		m[k] = p.Get(k)
+	}
+	return m, nil
+}
+
+func (s *FileSource) Watch(ctx context.Context, fn func(props map[string]string)) error {
+
+	last, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(last)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		m, err := s.load()
+		if err != nil {
+			log.Errorf("load %s error: %v", s.file, err)
+			continue
+		}
+		if !reflect.DeepEqual(m, last) {
+			last = m
+			fn(m)
+		}
+	}
+}
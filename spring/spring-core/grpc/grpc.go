@@ -16,7 +16,16 @@
 
 package grpc
 
+import "context"
+
+// Server gRPC 服务提供者，导出为 bean 的 *Server 对象会被自动注册到 gRPC 服务器。
 type Server struct {
 	Register interface{} // 服务注册函数
 	Service  interface{} // 服务提供者
 }
+
+// Authenticator gRPC 请求的认证器，返回的 ctx 可以携带认证后的身份信息，返回
+// 错误时请求以 Unauthenticated 状态失败。
+type Authenticator interface {
+	Authenticate(ctx context.Context, method string) (context.Context, error)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metrics gRPC 方法的执行统计。
+type Metrics struct {
+	Requests int64         `json:"requests"` // 请求数
+	Errors   int64         `json:"errors"`   // 失败的请求数
+	Latency  time.Duration `json:"latency"`  // 请求的累计耗时
+}
+
+var metrics = struct {
+	sync.Mutex
+	m map[string]*Metrics
+}{m: make(map[string]*Metrics)}
+
+// RecordMetrics 记录方法 method 的一次请求结果。
+func RecordMetrics(method string, start time.Time, err error) {
+	cost := time.Since(start)
+	metrics.Lock()
+	defer metrics.Unlock()
+	m, ok := metrics.m[method]
+	if !ok {
+		m = &Metrics{}
+		metrics.m[method] = m
+	}
+	m.Requests++
+	m.Latency += cost
+	if err != nil {
+		m.Errors++
+	}
+}
+
+// GetMetrics 返回所有方法的执行统计。
+func GetMetrics() map[string]Metrics {
+	metrics.Lock()
+	defer metrics.Unlock()
+	ret := make(map[string]Metrics, len(metrics.m))
+	for k, v := range metrics.m {
+		ret[k] = *v
+	}
+	return ret
+}
+
+// GetMethods 返回有执行统计的方法，按字母顺序排列。
+func GetMethods() []string {
+	metrics.Lock()
+	defer metrics.Unlock()
+	var ret []string
+	for k := range metrics.m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
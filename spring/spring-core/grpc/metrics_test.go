@@ -0,0 +1,44 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/grpc"
+)
+
+func TestMetrics(t *testing.T) {
+
+	start := time.Now()
+	grpc.RecordMetrics("/helloworld.Greeter/SayHello", start, nil)
+	grpc.RecordMetrics("/helloworld.Greeter/SayHello", start, errors.New("error"))
+	grpc.RecordMetrics("/helloworld.Greeter/SayBye", start, nil)
+
+	assert.Equal(t, grpc.GetMethods(), []string{
+		"/helloworld.Greeter/SayBye",
+		"/helloworld.Greeter/SayHello",
+	})
+
+	m := grpc.GetMetrics()["/helloworld.Greeter/SayHello"]
+	assert.Equal(t, m.Requests, int64(2))
+	assert.Equal(t, m.Errors, int64(1))
+	assert.True(t, m.Latency > 0)
+}
@@ -19,13 +19,13 @@ package gs
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"reflect"
 	"strings"
-	"syscall"
+	"time"
 
 	"github.com/go-spring/spring-base/cast"
 	"github.com/go-spring/spring-base/conf"
@@ -34,6 +34,7 @@ import (
 	"github.com/go-spring/spring-core/grpc"
 	"github.com/go-spring/spring-core/gs/arg"
 	"github.com/go-spring/spring-core/gs/internal"
+	"github.com/go-spring/spring-core/metrics"
 	"github.com/go-spring/spring-core/mq"
 	"github.com/go-spring/spring-core/web"
 )
@@ -41,6 +42,13 @@ import (
 // SpringBannerVisible 是否显示 banner。
 const SpringBannerVisible = "spring.banner.visible"
 
+// SpringConfigWarnUnused 是否在应用启动后打印配置文件中从未被读取过的属性。
+const SpringConfigWarnUnused = "spring.config.warn-unused-keys"
+
+// LoggingLevelPrefix 命名日志输出级别的属性前缀，例如 logging.level.web=debug
+// 设置 web 及其子日志的输出级别，logging.level.root 设置全局的输出级别。
+const LoggingLevelPrefix = "logging.level."
+
 // AppRunner 命令行启动器接口
 type AppRunner interface {
 	Run(ctx Context)
@@ -58,6 +66,7 @@ type tempApp struct {
 	grpcServers     *GrpcServers
 	mapOfOnProperty map[string]interface{}
 	banner          string
+	configKeys      []string // 从配置文件加载的属性
 }
 
 // App 应用
@@ -67,7 +76,10 @@ type App struct {
 	c *container
 	b *bootstrap
 
-	exitChan chan struct{}
+	modules []*module // 按照注册的顺序刷新
+
+	exitChan   chan struct{}
+	logClosers []io.Closer // 程序退出时按照逆序关闭，保证日志全部输出
 
 	Events  []AppEvent  `autowire:"${application-event.collection:=*?}"`
 	Runners []AppRunner `autowire:"${command-line-runner.collection:=*?}"`
@@ -122,17 +134,22 @@ func (app *App) Banner(banner string) {
 	app.banner = banner
 }
 
-func (app *App) Run() error {
+// Run 启动应用，依次加载配置、打印 banner、刷新容器，然后阻塞直到程序退出。
+func (app *App) Run(opts ...RunOption) error {
+
+	arg := newRunArg(opts)
 
 	// 响应控制台的 Ctrl+C 及 kill 命令。
-	go func() {
-		ch := make(chan os.Signal, 1)
-		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
-		sig := <-ch
-		app.ShutDown(fmt.Sprintf("signal %v", sig))
-	}()
-
-	if err := app.start(); err != nil {
+	if len(arg.signals) > 0 {
+		go func() {
+			ch := make(chan os.Signal, 1)
+			signal.Notify(ch, arg.signals...)
+			sig := <-ch
+			app.ShutDown(fmt.Sprintf("signal %v", sig))
+		}()
+	}
+
+	if err := app.start(arg); err != nil {
 		return err
 	}
 
@@ -143,7 +160,20 @@ func (app *App) Run() error {
 	}
 
 	app.c.Close()
+	for i := len(app.modules) - 1; i >= 0; i-- {
+		app.modules[i].c.Close()
+	}
 	log.Info("application exited")
+
+	if len(app.logClosers) == 0 {
+		return nil
+	}
+	log.SetOutput(log.Console)
+	for i := len(app.logClosers) - 1; i >= 0; i-- {
+		if err := app.logClosers[i].Close(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -155,7 +185,9 @@ func (app *App) clear() {
 	app.tempApp = nil
 }
 
-func (app *App) start() error {
+func (app *App) start(arg *runArg) error {
+
+	startTime := time.Now()
 
 	app.Object(app)
 	app.Object(app.consumers)
@@ -167,13 +199,20 @@ func (app *App) start() error {
 		resourceLocator: new(defaultResourceLocator),
 	}
 
+	for k, v := range arg.properties {
+		if err := e.p.Set(k, v); err != nil {
+			return err
+		}
+		e.p.SetOrigin(k, "run option")
+	}
+
 	if err := e.prepare(); err != nil {
 		return err
 	}
 
 	showBanner := cast.ToBool(e.p.Get(SpringBannerVisible))
 	if showBanner {
-		app.printBanner(app.getBanner(e))
+		app.printBanner(app.getBanner(e), e.ActiveProfiles)
 	}
 
 	if app.b != nil {
@@ -187,11 +226,42 @@ func (app *App) start() error {
 	}
 
 	// 保存从环境变量和命令行解析的属性
-	for _, k := range e.p.Keys() {
-		app.c.p.Set(k, e.p.Get(k))
+	if err := e.merge(app.c.p, e.p, ""); err != nil {
+		return err
+	}
+
+	if err := app.setLoggerLevels(); err != nil {
+		return err
+	}
+
+	if err := app.setLogCaller(); err != nil {
+		return err
+	}
+
+	if err := app.setLogSampling(); err != nil {
+		return err
+	}
+
+	if err := app.setLogFile(); err != nil {
+		return err
+	}
+
+	if err := app.setLogAsync(); err != nil {
+		return err
+	}
+
+	if err := app.setLogMetrics(); err != nil {
+		return err
+	}
+
+	if err := app.startModules(); err != nil {
+		return err
 	}
 
 	for key, f := range app.mapOfOnProperty {
+		if !app.c.p.Has(key) {
+			continue
+		}
 		t := reflect.TypeOf(f)
 		in := reflect.New(t.In(0)).Elem()
 		err := app.c.p.Bind(in, conf.Key(key))
@@ -205,16 +275,29 @@ func (app *App) start() error {
 		return err
 	}
 
+	if err := app.injectHandlers(); err != nil {
+		return err
+	}
+
 	// 执行命令行启动器
 	for _, r := range app.Runners {
 		r.Run(app.c)
 	}
 
+	if err := app.callRunners(arg.args); err != nil {
+		return err
+	}
+
 	// 通知应用启动事件
 	for _, event := range app.Events {
 		event.OnAppStart(app.c)
 	}
 
+	app.warnUnusedKeys()
+
+	metrics.NewGauge("gs.beans").Set(float64(len(app.c.Beans())))
+	metrics.NewGauge("gs.startup.seconds").Set(time.Since(startTime).Seconds())
+
 	app.clear()
 
 	// 通知应用停止事件
@@ -229,6 +312,25 @@ func (app *App) start() error {
 	return nil
 }
 
+// injectHandlers 为需要注入参数的处理函数注入 bean 对象。
+func (app *App) injectHandlers() error {
+	injector := func(t reflect.Type) (reflect.Value, error) {
+		v := reflect.New(t)
+		if err := app.c.Get(v.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Elem(), nil
+	}
+	for _, mapper := range app.router.Mappers() {
+		if h, ok := mapper.Handler().(web.Injectable); ok {
+			if err := h.Inject(injector); err != nil {
+				return fmt.Errorf("%s %s: %w", web.GetMethod(mapper.Method()), mapper.Path(), err)
+			}
+		}
+	}
+	return nil
+}
+
 const DefaultBanner = `
                                               (_)              
   __ _    ___             ___   _ __    _ __   _   _ __     __ _ 
@@ -256,8 +358,8 @@ func (app *App) getBanner(e *configuration) string {
 	return banner
 }
 
-// printBanner 打印 banner 到控制台
-func (app *App) printBanner(banner string) {
+// printBanner 打印 banner 到控制台，banner 下方居中显示版本和激活的 profile 。
+func (app *App) printBanner(banner string, profiles []string) {
 
 	if banner[0] != '\n' {
 		fmt.Println()
@@ -275,14 +377,19 @@ func (app *App) printBanner(banner string) {
 		fmt.Println()
 	}
 
+	footer := Version
+	if len(profiles) > 0 {
+		footer += " (profiles: " + strings.Join(profiles, ",") + ")"
+	}
+
 	var padding []byte
-	if n := (maxLength - len(Version)) / 2; n > 0 {
+	if n := (maxLength - len(footer)) / 2; n > 0 {
 		padding = make([]byte, n)
 		for i := range padding {
 			padding[i] = ' '
 		}
 	}
-	fmt.Println(string(padding) + Version + "\n")
+	fmt.Println(string(padding) + footer + "\n")
 }
 
 func (app *App) loadProperties(e *configuration) error {
@@ -306,20 +413,50 @@ func (app *App) loadProperties(e *configuration) error {
 		}
 	}
 
+	loader := newPropertyLoader(e, app.c.p)
 	for _, resource := range resources {
-		b, err := ioutil.ReadAll(resource)
-		if err != nil {
+		if err := loader.load(resource.Name(), resource); err != nil {
 			return err
 		}
-		p, err := conf.Bytes(b, filepath.Ext(resource.Name()))
-		if err != nil {
-			return err
-		}
-		for _, key := range p.Keys() {
-			app.c.p.Set(key, p.Get(key))
+	}
+	app.configKeys = loader.keys
+
+	return nil
+}
+
+// warnUnusedKeys 打印配置文件中从未被读取过的属性，用于发现拼写错误的配置项。
+func (app *App) warnUnusedKeys() {
+	if !cast.ToBool(app.c.p.Get(SpringConfigWarnUnused)) {
+		return
+	}
+	fromFile := make(map[string]bool)
+	for _, key := range app.configKeys {
+		fromFile[key] = true
+	}
+	var unused []string
+	for _, key := range app.c.p.Unused() {
+		if fromFile[key] {
+			unused = append(unused, key)
 		}
 	}
+	if len(unused) > 0 {
+		log.Warnf("configured but never read properties: %s", strings.Join(unused, ", "))
+	}
+}
 
+// setLoggerLevels 根据 logging.level 属性设置命名日志的输出级别。属性只在启动
+// 时读取，不支持热更新，运行时调整级别需要使用 /actuator/loggers 端点。
+func (app *App) setLoggerLevels() error {
+	for _, key := range app.c.p.Keys() {
+		if !strings.HasPrefix(key, LoggingLevelPrefix) {
+			continue
+		}
+		level, err := log.ParseLevel(app.c.p.Get(key))
+		if err != nil {
+			return fmt.Errorf("property %s: %w", key, err)
+		}
+		log.SetLoggerLevel(strings.TrimPrefix(key, LoggingLevelPrefix), level)
+	}
 	return nil
 }
 
@@ -358,6 +495,44 @@ func (app *App) Go(fn func(ctx context.Context)) {
 	app.c.Go(fn)
 }
 
+// GoTask 参考 Container.GoTask 的解释。
+func (app *App) GoTask(name string, fn func(ctx context.Context)) context.CancelFunc {
+	return app.c.GoTask(name, fn)
+}
+
+// Tasks 参考 Container.Tasks 的解释。
+func (app *App) Tasks() []TaskInfo {
+	return app.c.Tasks()
+}
+
+// Module 返回名为 name 的模块，不存在时创建一个新的模块。
+func (app *App) Module(name string) *module {
+	for _, m := range app.modules {
+		if m.name == name {
+			return m
+		}
+	}
+	m := newModule(name)
+	app.modules = append(app.modules, m)
+	return m
+}
+
+// startModules 按照注册的顺序刷新模块，然后将模块导出的 bean 注册到主容器。
+func (app *App) startModules() error {
+	var exports []*BeanDefinition
+	for _, m := range app.modules {
+		beans, err := m.start(app.c.p, exports)
+		if err != nil {
+			return fmt.Errorf("module %q: %w", m.name, err)
+		}
+		exports = append(exports, beans...)
+	}
+	for _, b := range exports {
+		app.c.register(importBean(b))
+	}
+	return nil
+}
+
 // Bootstrap 返回 *bootstrap 对象。
 func (app *App) Bootstrap() *bootstrap {
 	if app.b == nil {
@@ -366,13 +541,18 @@ func (app *App) Bootstrap() *bootstrap {
 	return app.b
 }
 
-// OnProperty 当 key 对应的属性值准备好后发送一个通知。
+// OnProperty 当 key 对应的属性值准备好后发送一个通知，属性不存在时不发送通知。
 func (app *App) OnProperty(key string, fn interface{}) {
 	err := validOnProperty(fn)
 	util.Panic(err).When(err != nil)
 	app.mapOfOnProperty[key] = fn
 }
 
+// OnProgress 参考 Container.OnProgress 的解释。
+func (app *App) OnProgress(fn ProgressListener) {
+	app.c.OnProgress(fn)
+}
+
 // Property 参考 Container.Property 的解释。
 func (app *App) Property(key string, value interface{}) {
 	app.c.Property(key, value)
@@ -463,6 +643,11 @@ func (app *App) RequestBinding(method uint32, path string, fn interface{}) *web.
 	return app.router.RequestBinding(method, path, fn)
 }
 
+// Version 返回注册指定 API 版本号处理函数的 Router 。
+func (app *App) Version(version string) web.Router {
+	return app.router.Version(version)
+}
+
 // Consume 注册 MQ 消费者。
 func (app *App) Consume(fn interface{}, topics ...string) {
 	app.consumers.Add(mq.Bind(fn, topics...))
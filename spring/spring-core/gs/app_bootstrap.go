@@ -95,8 +95,8 @@ func (b *bootstrap) start(e *configuration) error {
 	}
 
 	// 保存从环境变量和命令行解析的属性
-	for _, k := range e.p.Keys() {
-		b.c.p.Set(k, e.p.Get(k))
+	if err := e.merge(b.c.p, e.p, ""); err != nil {
+		return err
 	}
 
 	for key, f := range b.mapOfOnProperty {
@@ -130,15 +130,12 @@ func (b *bootstrap) loadConfigFile(e *configuration, filename string) error {
 		if err != nil {
 			return err
 		}
-		p := conf.New()
+		loader := newPropertyLoader(e, b.c.p)
 		for _, file := range resources {
-			if err = p.Load(file.Name()); err != nil {
+			if err = loader.load(file.Name(), file); err != nil {
 				return err
 			}
 		}
-		for _, key := range p.Keys() {
-			b.c.p.Set(key, p.Get(key))
-		}
 	}
 	return nil
 }
@@ -39,6 +39,7 @@ type configuration struct {
 	resourceLocator  ResourceLocator
 	ActiveProfiles   []string `value:"${spring.profiles.active:=}"`
 	ConfigExtensions []string `value:"${spring.config.extensions:=.properties,.prop,.yaml,.yml,.toml,.tml}"`
+	ListMerge        string   `value:"${spring.config.list-merge:=replace}"`
 }
 
 // loadCmdArgs 加载 -name value 形式的命令行参数。
@@ -52,12 +53,14 @@ func loadCmdArgs(p *conf.Properties) error {
 				v = ss[1]
 			}
 			p.Set(k, v)
+			p.SetOrigin(k, "command line")
 			continue
 		}
 		if strings.HasPrefix(s, "-") {
 			k, v := s[1:], ""
 			if i >= len(os.Args)-1 {
 				p.Set(k, v)
+				p.SetOrigin(k, "command line")
 				return nil
 			}
 			next := os.Args[i+1]
@@ -66,6 +69,7 @@ func loadCmdArgs(p *conf.Properties) error {
 				i++
 			}
 			p.Set(k, v)
+			p.SetOrigin(k, "command line")
 		}
 	}
 	return nil
@@ -125,10 +129,12 @@ func loadSystemEnv(p *conf.Properties) error {
 			propKey = strings.ReplaceAll(propKey, "_", ".")
 			propKey = strings.ToLower(propKey)
 			p.Set(propKey, v)
+			p.SetOrigin(propKey, "env:"+k)
 			continue
 		}
 		if matches(includeRex, k) && !matches(excludeRex, k) {
 			p.Set(k, v)
+			p.SetOrigin(k, "env:"+k)
 		}
 	}
 	return nil
@@ -147,5 +153,18 @@ func (e *configuration) prepare() error {
 	if err := e.p.Bind(e.resourceLocator); err != nil {
 		return err
 	}
+	if _, err := conf.ParseListMerge(e.ListMerge); err != nil {
+		return err
+	}
 	return nil
 }
+
+// merge 按照 spring.config.list-merge 属性指定的列表合并策略将 src 合并到 p 中，
+// origin 是 src 中没有记录来源的属性的来源。
+func (e *configuration) merge(p *conf.Properties, src *conf.Properties, origin string) error {
+	listMerge, err := conf.ParseListMerge(e.ListMerge)
+	if err != nil {
+		return err
+	}
+	return p.Merge(src, conf.MergeList(listMerge), conf.MergeOrigin(origin))
+}
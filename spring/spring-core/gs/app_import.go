@@ -0,0 +1,224 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-spring/spring-base/conf"
+)
+
+const (
+	// ConfigImport 导入其他配置文件的属性，多个文件使用逗号分隔或者使用数组。
+	ConfigImport = "spring.config.import"
+
+	// ConfigInclude 和 ConfigImport 的含义相同，是更简短的写法。
+	ConfigInclude = "include"
+
+	// ConfigActivateOnProfile 文档的激活条件，只有激活的 profile 满足条件时才加载该
+	// 文档的属性，多个 profile 使用逗号分隔，满足其一即可，以 ! 开头表示该 profile
+	// 未被激活。
+	ConfigActivateOnProfile = "spring.config.activate.on-profile"
+
+	// optionalPrefix 可选导入的前缀，文件不存在时不会报错。
+	optionalPrefix = "optional:"
+)
+
+// propertyLoader 加载配置文件的属性，并递归地处理配置文件中的导入指令。导入的
+// 文件路径可以是绝对路径，也可以是相对于导入它的文件所在目录的相对路径，支持
+// filepath.Match 格式的通配符，以 optional: 开头时文件不存在不会报错。导入文件
+// 的属性优先级高于导入它的文件，同一个文件只会被导入一次。每个文件的属性按照
+// spring.config.list-merge 属性指定的列表合并策略合并到已有的属性中。支持多文档
+// 的文件 (如 yaml) 按照文档的顺序加载，只有满足 spring.config.activate.on-profile
+// 条件的文档才会被加载。
+type propertyLoader struct {
+	e       *configuration
+	p       *conf.Properties
+	keys    []string // 加载的所有属性
+	visited map[string]bool
+}
+
+func newPropertyLoader(e *configuration, p *conf.Properties) *propertyLoader {
+	return &propertyLoader{e: e, p: p, visited: make(map[string]bool)}
+}
+
+// load 加载资源 r 的属性，name 为资源的名称 (文件路径) 。
+func (l *propertyLoader) load(name string, r io.Reader) error {
+
+	if abs, err := filepath.Abs(name); err == nil {
+		l.visited[abs] = true
+	}
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	ext := filepath.Ext(name)
+	docs, err := conf.Documents(b, ext)
+	if err != nil {
+		return fmt.Errorf("load %s error: %w", name, err)
+	}
+	lines := conf.Lines(b, ext)
+	if len(lines) != len(docs) {
+		lines = nil
+	}
+	for i, p := range docs {
+		if !l.activated(p.Get(ConfigActivateOnProfile)) {
+			continue
+		}
+		var m map[string]int
+		if lines != nil {
+			m = lines[i]
+		}
+		if err = l.loadDocument(name, p, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activated 返回激活的 profile 是否满足文档的激活条件，没有激活条件时总是满足。
+func (l *propertyLoader) activated(onProfile string) bool {
+	if strings.TrimSpace(onProfile) == "" {
+		return true
+	}
+	for _, s := range strings.Split(onProfile, ",") {
+		s = strings.TrimSpace(s)
+		not := strings.HasPrefix(s, "!")
+		if not {
+			s = strings.TrimSpace(s[1:])
+		}
+		active := false
+		for _, profile := range l.e.ActiveProfiles {
+			if profile == s {
+				active = true
+				break
+			}
+		}
+		if active != not {
+			return true
+		}
+	}
+	return false
+}
+
+// loadDocument 加载资源 name 中的一个文档的属性，lines 是属性所在的行号，属性的
+// 来源记录为 name:line 的形式。
+func (l *propertyLoader) loadDocument(name string, p *conf.Properties, lines map[string]int) error {
+
+	props := conf.New()
+	for _, key := range p.Keys() {
+		if isImportKey(key) || key == ConfigActivateOnProfile {
+			continue
+		}
+		if err := props.Set(key, p.Get(key)); err != nil {
+			return err
+		}
+		if n := conf.LineOf(lines, key); n > 0 {
+			props.SetOrigin(key, fmt.Sprintf("%s:%d", name, n))
+		}
+	}
+	err := l.e.merge(l.p, props, name)
+	if err != nil {
+		return fmt.Errorf("load %s error: %w", name, err)
+	}
+	l.keys = append(l.keys, props.Keys()...)
+
+	var imports []string
+	for _, key := range []string{ConfigImport, ConfigInclude} {
+		var s []string
+		if err = p.Bind(&s, conf.Tag("${"+key+":=}")); err != nil {
+			return fmt.Errorf("%s: %s %w", name, key, err)
+		}
+		imports = append(imports, s...)
+	}
+
+	dir := filepath.Dir(name)
+	for _, s := range imports {
+		if err = l.importFile(dir, strings.TrimSpace(s)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// importFile 导入 dir 目录下符合 location 的配置文件。
+func (l *propertyLoader) importFile(dir string, location string) error {
+
+	if location == "" {
+		return nil
+	}
+
+	optional := strings.HasPrefix(location, optionalPrefix)
+	if optional {
+		location = strings.TrimPrefix(location, optionalPrefix)
+	}
+
+	pattern := location
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("import %s error: %w", location, err)
+	}
+	if len(files) == 0 {
+		if optional {
+			return nil
+		}
+		return fmt.Errorf("import %s error: file not found", location)
+	}
+
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		if l.visited[abs] {
+			continue
+		}
+		if err = l.loadFile(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *propertyLoader) loadFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return l.load(file, f)
+}
+
+// isImportKey 返回 key 是否是导入指令。
+func isImportKey(key string) bool {
+	for _, s := range []string{ConfigImport, ConfigInclude} {
+		if key == s || strings.HasPrefix(key, s+"[") {
+			return true
+		}
+	}
+	return false
+}
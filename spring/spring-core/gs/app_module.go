@@ -0,0 +1,119 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"reflect"
+
+	"github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-core/gs/arg"
+	"github.com/go-spring/spring-core/gs/internal"
+)
+
+// module 应用的模块，每个模块拥有独立的 IoC 容器，便于按照业务边界拆分单体应用。
+// 模块和应用共享属性，模块自己设置的属性优先。模块按照注册的顺序在主容器之前刷新，
+// 通过 Export 导出的 bean 可以被之后刷新的模块和主容器注入，应用退出时先关闭主
+// 容器，然后按照注册的逆序关闭模块。
+type module struct {
+	name    string
+	c       *container
+	exports []BeanSelector
+}
+
+func newModule(name string) *module {
+	return &module{name: name, c: New().(*container)}
+}
+
+// Name 返回模块的名称。
+func (m *module) Name() string {
+	return m.name
+}
+
+// Property 设置模块自己的属性，优先于应用共享的属性。
+func (m *module) Property(key string, value interface{}) {
+	m.c.Property(key, value)
+}
+
+// Object 参考 Container.Object 的解释。
+func (m *module) Object(i interface{}) *BeanDefinition {
+	return m.c.register(NewBean(reflect.ValueOf(i)))
+}
+
+// Provide 参考 Container.Provide 的解释。
+func (m *module) Provide(ctor interface{}, args ...arg.Arg) *BeanDefinition {
+	return m.c.register(NewBean(ctor, args...))
+}
+
+// Export 导出符合选择器的 bean ，选择器的形式和 Context.Get 相同。
+func (m *module) Export(selectors ...BeanSelector) {
+	m.exports = append(m.exports, selectors...)
+}
+
+// start 使用共享的属性刷新模块的容器，imports 是之前的模块导出的 bean ，返回
+// 本模块导出的 bean 。
+func (m *module) start(p *conf.Properties, imports []*BeanDefinition) ([]*BeanDefinition, error) {
+
+	for _, k := range p.Keys() {
+		if !m.c.p.Has(k) {
+			m.c.p.Set(k, p.Get(k))
+		}
+	}
+
+	for _, b := range imports {
+		m.c.register(importBean(b))
+	}
+
+	if err := m.c.Refresh(internal.AutoClear(false)); err != nil {
+		return nil, err
+	}
+
+	var exports []*BeanDefinition
+	found := make(map[*BeanDefinition]bool)
+	for _, selector := range m.exports {
+		beans, err := m.c.findBean(selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range beans {
+			if !b.imported && !found[b] {
+				found[b] = true
+				exports = append(exports, b)
+			}
+		}
+	}
+
+	m.c.clear()
+	return exports, nil
+}
+
+// importBean 返回导入到其他容器的 bean ，导入的 bean 已经在所在模块完成注入，
+// 决议后直接标记为注入完成，并且由所在模块负责销毁。
+func importBean(b *BeanDefinition) *BeanDefinition {
+	return &BeanDefinition{
+		typeName: b.typeName,
+		v:        b.v,
+		t:        b.t,
+		file:     b.file,
+		line:     b.line,
+		name:     b.name,
+		status:   Default,
+		primary:  b.primary,
+		order:    b.order,
+		exports:  b.exports,
+		imported: true,
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/go-spring/spring-base/conf"
+)
+
+// RunOption 应用的启动选项。
+type RunOption func(arg *runArg)
+
+type runArg struct {
+	properties map[string]string // 默认属性，可以被环境变量和命令行参数覆盖
+	signals    []os.Signal       // 触发程序退出的信号
+	args       []string          // 传给启动器的命令行参数
+}
+
+func newRunArg(opts []RunOption) *runArg {
+	arg := &runArg{
+		properties: make(map[string]string),
+		signals:    []os.Signal{os.Interrupt, syscall.SIGTERM},
+		args:       os.Args[1:],
+	}
+	for _, opt := range opts {
+		opt(arg)
+	}
+	return arg
+}
+
+// ConfigLocations 设置配置文件的默认查找目录，对应 spring.config.locations 属性。
+func ConfigLocations(locations ...string) RunOption {
+	return func(arg *runArg) {
+		arg.properties["spring.config.locations"] = strings.Join(locations, ",")
+	}
+}
+
+// ActiveProfiles 设置默认激活的 profile ，对应 spring.profiles.active 属性。
+func ActiveProfiles(profiles ...string) RunOption {
+	return func(arg *runArg) {
+		arg.properties["spring.profiles.active"] = strings.Join(profiles, ",")
+	}
+}
+
+// ShowBanner 设置启动时是否打印 banner ，对应 spring.banner.visible 属性。
+func ShowBanner(show bool) RunOption {
+	return func(arg *runArg) {
+		if show {
+			arg.properties[SpringBannerVisible] = "true"
+		} else {
+			arg.properties[SpringBannerVisible] = "false"
+		}
+	}
+}
+
+// ListMerge 设置合并多个配置文件时列表的合并策略，对应 spring.config.list-merge
+// 属性，默认为 conf.ListReplace ，即后加载的列表整体替换先加载的列表。
+func ListMerge(m conf.ListMerge) RunOption {
+	return func(arg *runArg) {
+		arg.properties["spring.config.list-merge"] = m.String()
+	}
+}
+
+// Signals 设置触发程序退出的信号，默认为 Ctrl+C 和 kill 命令，为空时不响应信号。
+func Signals(signals ...os.Signal) RunOption {
+	return func(arg *runArg) {
+		arg.signals = signals
+	}
+}
+
+// Args 设置传给 CommandLineRunner 和 ApplicationRunner 的命令行参数，默认为
+// os.Args[1:] 。
+func Args(args ...string) RunOption {
+	return func(arg *runArg) {
+		arg.args = args
+	}
+}
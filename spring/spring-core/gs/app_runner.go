@@ -0,0 +1,154 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"sort"
+	"strings"
+)
+
+// CommandLineRunner 命令行启动器，容器刷新完成后按照 bean 的排序序号执行，args
+// 是原始的命令行参数，返回 error 时应用启动失败。
+type CommandLineRunner interface {
+	Run(ctx Context, args []string) error
+}
+
+// ApplicationRunner 应用启动器，和 CommandLineRunner 一起按照 bean 的排序序号
+// 执行，区别是 args 为解析后的命令行参数。
+type ApplicationRunner interface {
+	Run(ctx Context, args *ApplicationArguments) error
+}
+
+// ApplicationArguments 解析后的命令行参数，--name=value、--name、-name value
+// 以及 -name 形式的参数为选项参数，其他参数为非选项参数。
+type ApplicationArguments struct {
+	source  []string
+	names   []string
+	options map[string][]string
+	nonOpts []string
+}
+
+// NewApplicationArguments 解析命令行参数，args 不包含程序名称。
+func NewApplicationArguments(args []string) *ApplicationArguments {
+	a := &ApplicationArguments{
+		source:  args,
+		options: make(map[string][]string),
+	}
+	for i := 0; i < len(args); i++ {
+		s := args[i]
+		switch {
+		case strings.HasPrefix(s, "--"):
+			ss := strings.SplitN(strings.TrimPrefix(s, "--"), "=", 2)
+			if len(ss) > 1 {
+				a.addOption(ss[0], ss[1], true)
+			} else {
+				a.addOption(ss[0], "", false)
+			}
+		case strings.HasPrefix(s, "-") && len(s) > 1:
+			if i < len(args)-1 && !strings.HasPrefix(args[i+1], "-") {
+				a.addOption(s[1:], args[i+1], true)
+				i++
+			} else {
+				a.addOption(s[1:], "", false)
+			}
+		default:
+			a.nonOpts = append(a.nonOpts, s)
+		}
+	}
+	return a
+}
+
+func (a *ApplicationArguments) addOption(name, value string, hasValue bool) {
+	values, ok := a.options[name]
+	if !ok {
+		a.names = append(a.names, name)
+	}
+	if hasValue {
+		values = append(values, value)
+	}
+	a.options[name] = values
+}
+
+// SourceArgs 返回原始的命令行参数。
+func (a *ApplicationArguments) SourceArgs() []string {
+	return a.source
+}
+
+// OptionNames 按照出现的顺序返回所有选项的名称。
+func (a *ApplicationArguments) OptionNames() []string {
+	return a.names
+}
+
+// ContainsOption 返回是否存在名为 name 的选项。
+func (a *ApplicationArguments) ContainsOption(name string) bool {
+	_, ok := a.options[name]
+	return ok
+}
+
+// OptionValues 返回名为 name 的选项的所有值，选项不带值时返回空列表。
+func (a *ApplicationArguments) OptionValues(name string) []string {
+	return a.options[name]
+}
+
+// NonOptionArgs 返回非选项参数。
+func (a *ApplicationArguments) NonOptionArgs() []string {
+	return a.nonOpts
+}
+
+// callRunners 按照 bean 的排序序号执行 CommandLineRunner 和 ApplicationRunner 。
+func (app *App) callRunners(args []string) error {
+
+	var beans []*BeanDefinition
+	found := make(map[*BeanDefinition]bool)
+	for _, selector := range []BeanSelector{
+		(*CommandLineRunner)(nil),
+		(*ApplicationRunner)(nil),
+	} {
+		result, err := app.c.findBean(selector)
+		if err != nil {
+			return err
+		}
+		for _, b := range result {
+			if !found[b] {
+				found[b] = true
+				beans = append(beans, b)
+			}
+		}
+	}
+
+	// beansById 是 map ，先按照 ID 排序保证相同序号时的执行顺序稳定
+	sort.Slice(beans, func(i, j int) bool { return beans[i].ID() < beans[j].ID() })
+	sort.Stable(byOrder(beans))
+
+	var appArgs *ApplicationArguments
+	for _, b := range beans {
+		switch r := b.Interface().(type) {
+		case CommandLineRunner:
+			if err := r.Run(app.c, args); err != nil {
+				return err
+			}
+		case ApplicationRunner:
+			if appArgs == nil {
+				appArgs = NewApplicationArguments(args)
+			}
+			if err := r.Run(app.c, appArgs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -17,11 +17,17 @@
 package gs_test
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-base/log"
 	"github.com/go-spring/spring-core/gs"
 )
 
@@ -81,3 +87,341 @@ func TestConfig(t *testing.T) {
 		defer app.ShutDown("run test end")
 	})
 }
+
+func TestRunOptions(t *testing.T) {
+
+	os.Clearenv()
+	app := gs.NewApp()
+
+	var endpoints map[string]string
+	app.Property("grpc.endpoint.greeter", "127.0.0.1:9090")
+	app.OnProperty("grpc.endpoint", func(m map[string]string) { endpoints = m })
+	app.OnProperty("grpc.client", func(m map[string]string) { panic("grpc.client not exist") })
+
+	var name, profiles string
+	app.Provide(func(ctx gs.Context) bool {
+		name = ctx.Prop("spring.application.name")
+		profiles = ctx.Prop("spring.profiles.active")
+		return true
+	})
+
+	exit := make(chan error)
+	go func() {
+		exit <- app.Run(
+			gs.ConfigLocations("testdata/config/"),
+			gs.ActiveProfiles("test"),
+			gs.ShowBanner(true),
+			gs.Signals(),
+		)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	app.ShutDown("run test end")
+	assert.Nil(t, <-exit)
+	assert.Equal(t, name, "test.yaml")
+	assert.Equal(t, profiles, "test")
+	assert.Equal(t, endpoints, map[string]string{"greeter": "127.0.0.1:9090"})
+}
+
+func TestConfigImport(t *testing.T) {
+
+	runApp := func(location string, opts ...gs.RunOption) (map[string]string, error) {
+		os.Clearenv()
+		app := gs.NewApp()
+		props := make(map[string]string)
+		app.Provide(func(ctx gs.Context) bool {
+			for _, key := range ctx.Keys() {
+				props[key] = ctx.Prop(key)
+			}
+			return true
+		})
+		exit := make(chan error)
+		opts = append(opts, gs.ConfigLocations(location), gs.Signals())
+		go func() { exit <- app.Run(opts...) }()
+		select {
+		case err := <-exit:
+			return nil, err
+		case <-time.After(100 * time.Millisecond):
+		}
+		app.ShutDown("run test end")
+		return props, <-exit
+	}
+
+	t.Run("success", func(t *testing.T) {
+		props, err := runApp("testdata/import/")
+		assert.Nil(t, err)
+		assert.Equal(t, props["spring.application.name"], "import")
+		assert.Equal(t, props["db.host"], "db.local")
+		assert.Equal(t, props["db.port"], "3306")
+		assert.Equal(t, props["db.user"], "root")
+		assert.Equal(t, props["mq.topic"], "order")
+		assert.Equal(t, props["redis.addr"], "127.0.0.1:6379")
+		assert.Equal(t, props["servers[0].host"], "c")
+		_, ok := props["servers[1].host"]
+		assert.False(t, ok)
+		_, ok = props["spring.config.import"]
+		assert.False(t, ok)
+	})
+
+	t.Run("list append", func(t *testing.T) {
+		props, err := runApp("testdata/import/", gs.ListMerge(conf.ListAppend))
+		assert.Nil(t, err)
+		assert.Equal(t, props["servers[0].host"], "a")
+		assert.Equal(t, props["servers[1].host"], "b")
+		assert.Equal(t, props["servers[2].host"], "c")
+	})
+
+	t.Run("list index", func(t *testing.T) {
+		props, err := runApp("testdata/import/", gs.ListMerge(conf.ListByIndex))
+		assert.Nil(t, err)
+		assert.Equal(t, props["servers[0].host"], "c")
+		assert.Equal(t, props["servers[1].host"], "b")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "import")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "application.properties")
+		err = ioutil.WriteFile(file, []byte("include=missing.properties"), os.ModePerm)
+		assert.Nil(t, err)
+		_, err = runApp(dir)
+		assert.Error(t, err, "import missing.properties error: file not found")
+	})
+}
+
+func TestConfigProfileDocuments(t *testing.T) {
+
+	runApp := func(profiles ...string) map[string]string {
+		os.Clearenv()
+		app := gs.NewApp()
+		props := make(map[string]string)
+		app.Provide(func(ctx gs.Context) bool {
+			for _, key := range ctx.Keys() {
+				props[key] = ctx.Prop(key)
+			}
+			return true
+		})
+		exit := make(chan error)
+		go func() {
+			exit <- app.Run(
+				gs.ConfigLocations("testdata/profiles/"),
+				gs.ActiveProfiles(profiles...),
+				gs.Signals(),
+			)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		app.ShutDown("run test end")
+		assert.Nil(t, <-exit)
+		return props
+	}
+
+	props := runApp()
+	assert.Equal(t, props["spring.application.name"], "profiles")
+	assert.Equal(t, props["db.host"], "db.local")
+	assert.Equal(t, props["db.port"], "3306")
+	assert.Equal(t, props["db.user"], "test")
+	_, ok := props[gs.ConfigActivateOnProfile]
+	assert.False(t, ok)
+
+	props = runApp("dev")
+	assert.Equal(t, props["db.host"], "db.dev")
+	assert.Equal(t, props["db.port"], "3306")
+	assert.Equal(t, props["db.user"], "test")
+
+	props = runApp("prod")
+	assert.Equal(t, props["db.host"], "db.prod")
+	assert.Equal(t, props["db.port"], "3307")
+	_, ok = props["db.user"]
+	assert.False(t, ok)
+}
+
+func TestConfigProps(t *testing.T) {
+
+	os.Clearenv()
+	gs.Setenv("GS_DB_USER", "admin")
+	app := gs.NewApp()
+
+	props := make(map[string]conf.Property)
+	app.Provide(func(ctx gs.Context) bool {
+		for _, p := range gs.ConfigProps(ctx) {
+			props[p.Key] = p
+		}
+		return true
+	})
+
+	exit := make(chan error)
+	go func() { exit <- app.Run(gs.ConfigLocations("testdata/import/"), gs.Signals()) }()
+	time.Sleep(100 * time.Millisecond)
+	app.ShutDown("run test end")
+	assert.Nil(t, <-exit)
+
+	assert.Equal(t, props["spring.config.locations"], conf.Property{
+		Key: "spring.config.locations", Value: "testdata/import/", Origin: "run option",
+	})
+	assert.Equal(t, props["db.port"], conf.Property{
+		Key: "db.port", Value: "3306", Origin: "testdata/import/application.yaml:8",
+	})
+	assert.Equal(t, props["db.host"], conf.Property{
+		Key: "db.host", Value: "db.local", Origin: "testdata/import/common.properties:1",
+	})
+	assert.Equal(t, props["db.password"], conf.Property{
+		Key: "db.password", Value: gs.MaskedValue, Origin: "testdata/import/common.properties:3",
+	})
+	assert.Equal(t, props["db.user"], conf.Property{
+		Key: "db.user", Value: "admin", Origin: "env:GS_DB_USER",
+	})
+}
+
+func TestWarnUnusedKeys(t *testing.T) {
+	defer log.Reset()
+
+	var warnings []string
+	log.SetOutput(func(level log.Level, e *log.Entry) {
+		if level == log.WarnLevel {
+			warnings = append(warnings, e.GetMsg())
+		}
+	})
+
+	dir, err := ioutil.TempDir("", "unused")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "application.properties")
+	data := "spring.config.warn-unused-keys=true\nserver.port=8080\nserver.prot=9090\n"
+	err = ioutil.WriteFile(file, []byte(data), os.ModePerm)
+	assert.Nil(t, err)
+
+	os.Clearenv()
+	app := gs.NewApp()
+	app.Object(&struct {
+		Port int `value:"${server.port}"`
+	}{})
+
+	exit := make(chan error)
+	go func() { exit <- app.Run(gs.ConfigLocations(dir), gs.Signals()) }()
+	time.Sleep(100 * time.Millisecond)
+	app.ShutDown("run test end")
+	assert.Nil(t, <-exit)
+
+	assert.Equal(t, warnings, []string{"configured but never read properties: server.prot"})
+}
+
+type orderedRunner struct {
+	name  string
+	calls *[]string
+}
+
+func (r *orderedRunner) Run(ctx gs.Context, args []string) error {
+	*r.calls = append(*r.calls, r.name+":"+strings.Join(args, " "))
+	return nil
+}
+
+type argsRunner struct {
+	calls *[]string
+	err   error
+}
+
+func (r *argsRunner) Run(ctx gs.Context, args *gs.ApplicationArguments) error {
+	*r.calls = append(*r.calls, "args:"+strings.Join(args.OptionValues("name"), ","))
+	return r.err
+}
+
+func TestRunners(t *testing.T) {
+
+	os.Clearenv()
+
+	t.Run("order", func(t *testing.T) {
+		var calls []string
+		app := gs.NewApp()
+		app.Object(&orderedRunner{"b", &calls}).Name("b").Order(2).Export((*gs.CommandLineRunner)(nil))
+		app.Object(&orderedRunner{"a", &calls}).Name("a").Order(0).Export((*gs.CommandLineRunner)(nil))
+		app.Object(&argsRunner{calls: &calls}).Order(1).Export((*gs.ApplicationRunner)(nil))
+
+		exit := make(chan error)
+		go func() {
+			exit <- app.Run(gs.Signals(), gs.Args("--name=go", "-name", "spring", "x"))
+		}()
+		time.Sleep(100 * time.Millisecond)
+		app.ShutDown("run test end")
+		assert.Nil(t, <-exit)
+		assert.Equal(t, calls, []string{
+			"a:--name=go -name spring x",
+			"args:go,spring",
+			"b:--name=go -name spring x",
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var calls []string
+		app := gs.NewApp()
+		app.Object(&argsRunner{calls: &calls, err: errors.New("runner error")}).Export((*gs.ApplicationRunner)(nil))
+		err := app.Run(gs.Signals(), gs.Args())
+		assert.Error(t, err, "runner error")
+	})
+}
+
+func TestApplicationArguments(t *testing.T) {
+	args := gs.NewApplicationArguments([]string{"--a=1", "--b", "-c", "3", "x", "-d", "--a=2", "y"})
+	assert.Equal(t, args.OptionNames(), []string{"a", "b", "c", "d"})
+	assert.Equal(t, args.OptionValues("a"), []string{"1", "2"})
+	assert.True(t, args.ContainsOption("b"))
+	assert.Equal(t, len(args.OptionValues("b")), 0)
+	assert.Equal(t, args.OptionValues("c"), []string{"3"})
+	assert.False(t, args.ContainsOption("x"))
+	assert.Equal(t, args.NonOptionArgs(), []string{"x", "y"})
+}
+
+type moduleRepo struct {
+	URL       string `value:"${db.url}"`
+	Pool      int    `value:"${db.pool}"`
+	destroyed int
+}
+
+func (r *moduleRepo) OnDestroy() { r.destroyed++ }
+
+type moduleService struct {
+	Repo *moduleRepo `autowire:""`
+}
+
+type moduleHandler struct {
+	Service *moduleService `autowire:""`
+	Repo    *moduleRepo    `autowire:""`
+}
+
+func TestModules(t *testing.T) {
+
+	os.Clearenv()
+	app := gs.NewApp()
+	app.Property("db.url", "mysql://shared")
+	app.Property("db.pool", 8)
+
+	repo := new(moduleRepo)
+	core := app.Module("core")
+	core.Property("db.pool", 2)
+	core.Object(repo)
+	core.Export((*moduleRepo)(nil))
+	assert.Equal(t, app.Module("core"), core)
+
+	web := app.Module("web")
+	web.Provide(func(r *moduleRepo) *moduleService {
+		return &moduleService{Repo: r}
+	})
+	web.Export((*moduleService)(nil))
+
+	handler := new(moduleHandler)
+	app.Object(handler)
+
+	exit := make(chan error)
+	go func() {
+		exit <- app.Run(gs.Signals(), gs.Args())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	app.ShutDown("run test end")
+	assert.Nil(t, <-exit)
+
+	assert.Equal(t, repo.URL, "mysql://shared")
+	assert.Equal(t, repo.Pool, 2)
+	assert.Equal(t, handler.Repo, repo)
+	assert.Equal(t, handler.Service.Repo, repo)
+	assert.Equal(t, repo.destroyed, 1)
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"strings"
 
 	"github.com/go-spring/spring-base/log"
 	"github.com/go-spring/spring-base/util"
@@ -45,7 +46,8 @@ type Context interface {
 // Arg 用于为函数参数提供绑定值。可以是 bean.Selector 类型，表示注入 bean ；
 // 可以是 ${X:=Y} 形式的字符串，表示属性绑定或者注入 bean ；可以是 ValueArg
 // 类型，表示不从 IoC 容器获取而是用户传入的普通值；可以是 IndexArg 类型，表示
-// 带有下标的参数绑定；可以是 *optionArg 类型，用于为 Option 方法提供参数绑定。
+// 带有下标的参数绑定；可以是 *optionArg 类型，用于为 Option 方法提供参数绑定；
+// 可以是 OptionalArg 类型，表示可选的 bean 。
 type Arg interface{}
 
 // IndexArg 包含下标的参数绑定。
@@ -85,11 +87,58 @@ type ValueArg struct {
 	v interface{}
 }
 
-// Value 返回包含具体值的参数绑定。
+// Value 返回包含具体值的参数绑定。v 是 ${X:=Y} 形式的字符串时表示属性绑定，
+// 属性值按照参数类型进行转换，属性不存在时使用默认值；v 的类型和参数类型不同
+// 但是可以转换时 (例如 int 和 int64) 自动进行类型转换。
 func Value(v interface{}) ValueArg {
 	return ValueArg{v: v}
 }
 
+// get 返回参数的真实值，t 是参数的类型。
+func (arg ValueArg) get(ctx Context, t reflect.Type) (reflect.Value, error) {
+	if s, ok := arg.v.(string); ok && isPropertyTag(s) {
+		v := reflect.New(t).Elem()
+		if err := ctx.Bind(v, s); err != nil {
+			return reflect.Value{}, err
+		}
+		return v, nil
+	}
+	if arg.v == nil {
+		return reflect.Zero(t), nil
+	}
+	v := reflect.ValueOf(arg.v)
+	if vt := v.Type(); !vt.AssignableTo(t) && vt.ConvertibleTo(t) && isConvertible(vt, t) {
+		return v.Convert(t), nil
+	}
+	return v, nil
+}
+
+// isPropertyTag 返回 s 是否是 ${X:=Y} 形式的属性绑定。
+func isPropertyTag(s string) bool {
+	return strings.HasPrefix(s, "${") && strings.HasSuffix(s, "}")
+}
+
+// isConvertible 返回是否需要将 from 类型的值转换为 to 类型，只转换基础类型，
+// 避免 int 转 string 这种语义不同的转换。
+func isConvertible(from, to reflect.Type) bool {
+	if from.Kind() == reflect.String || to.Kind() == reflect.String {
+		return from.Kind() == to.Kind()
+	}
+	return util.IsPrimitiveValueType(from) && util.IsPrimitiveValueType(to)
+}
+
+// OptionalArg 可选的 bean 参数绑定。
+type OptionalArg struct {
+	selector Arg
+}
+
+// Optional 返回可选的 bean 参数绑定，selector 的含义和 bean 参数绑定相同，为
+// nil 时按照参数类型查找。找不到匹配的 bean 时参数为零值 (指针和接口为 nil) ，
+// 而不是返回错误，适用于链路追踪、指标统计等可选的集成。
+func Optional(selector Arg) OptionalArg {
+	return OptionalArg{selector: selector}
+}
+
 // argList 函数参数绑定列表。
 type argList struct {
 
@@ -235,15 +284,24 @@ func (r *argList) getArg(ctx Context, arg Arg, t reflect.Type, fileLine string)
 			return results[0], nil
 		}
 	case ValueArg:
-		return reflect.ValueOf(g.v), nil
+		var v reflect.Value
+		v, err = g.get(ctx, t)
+		return v, err
 	case *optionArg:
 		return g.call(ctx)
-	case internal.BeanDefinition:
-		tag = g.ID()
-	case string:
-		tag = g
+	case OptionalArg:
+		if !util.IsBeanReceiver(t) {
+			err = fmt.Errorf("optional arg should be bean receiver, but %s", t.String())
+			return reflect.Value{}, err
+		}
+		if g.selector != nil {
+			tag = toTag(g.selector)
+		}
+		if !strings.HasSuffix(tag, "?") {
+			tag += "?"
+		}
 	default:
-		tag = util.TypeName(g) + ":"
+		tag = toTag(g)
 	}
 
 	v := reflect.New(t).Elem()
@@ -266,6 +324,18 @@ func (r *argList) getArg(ctx Context, arg Arg, t reflect.Type, fileLine string)
 	return v, nil
 }
 
+// toTag 返回 bean 参数绑定对应的 tag 。
+func toTag(arg Arg) string {
+	switch g := arg.(type) {
+	case internal.BeanDefinition:
+		return g.ID()
+	case string:
+		return g
+	default:
+		return util.TypeName(g) + ":"
+	}
+}
+
 func (r *argList) Len() int {
 	return len(r.args)
 }
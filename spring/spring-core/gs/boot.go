@@ -61,9 +61,14 @@ func Go(fn func(ctx context.Context)) {
 	gApp.Go(fn)
 }
 
-// Run 启动程序。
-func Run() error {
-	return gApp.Run()
+// GoTask 参考 App.GoTask 的解释。
+func GoTask(name string, fn func(ctx context.Context)) context.CancelFunc {
+	return gApp.GoTask(name, fn)
+}
+
+// Run 启动程序，参考 App.Run 的解释。
+func Run(opts ...RunOption) error {
+	return gApp.Run(opts...)
 }
 
 // ShutDown 停止程序。
@@ -71,11 +76,21 @@ func ShutDown(msg ...string) {
 	gApp.ShutDown(msg...)
 }
 
+// OnProgress 参考 App.OnProgress 的解释。
+func OnProgress(fn ProgressListener) {
+	gApp.OnProgress(fn)
+}
+
 // Banner 参考 App.Banner 的解释。
 func Banner(banner string) {
 	gApp.Banner(banner)
 }
 
+// Module 参考 App.Module 的解释。
+func Module(name string) *module {
+	return gApp.Module(name)
+}
+
 // Bootstrap 参考 App.Bootstrap 的解释。
 func Bootstrap() *bootstrap {
 	return app().Bootstrap()
@@ -176,6 +191,11 @@ func RequestBinding(method uint32, path string, fn interface{}) *web.Mapper {
 	return app().RequestBinding(method, path, fn)
 }
 
+// APIVersion 参考 App.Version 的解释。
+func APIVersion(version string) web.Router {
+	return app().Version(version)
+}
+
 // Consume 参考 App.Consume 的解释。
 func Consume(fn interface{}, topics ...string) {
 	app().Consume(fn, topics...)
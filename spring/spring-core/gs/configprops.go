@@ -0,0 +1,79 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"strings"
+
+	"github.com/go-spring/spring-base/conf"
+)
+
+// MaskedValue 敏感属性被隐藏后的值。
+const MaskedValue = "******"
+
+// secretKeys 属性名包含这些片段时属性值会被隐藏。
+var secretKeys = []string{"password", "secret", "token", "credential", "private-key", "access-key"}
+
+// AddSecretKeys 添加敏感属性名的片段，属性名包含这些片段 (不区分大小写) 时
+// ConfigProps 返回的属性值会被隐藏。
+func AddSecretKeys(keys ...string) {
+	for _, k := range keys {
+		secretKeys = append(secretKeys, strings.ToLower(k))
+	}
+}
+
+// IsSecretKey 返回 key 是否是敏感属性。
+func IsSecretKey(key string) bool {
+	k := strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskValue 隐藏敏感属性的值。
+func MaskValue(key, value string) string {
+	if IsSecretKey(key) {
+		return MaskedValue
+	}
+	return value
+}
+
+// ConfigProps 按照生效顺序返回应用的所有属性以及每个属性的来源 (配置文件、环境
+// 变量、命令行参数等) ，敏感属性的值被隐藏。ConfigProps 不会把属性标记为被读取
+// 过，应用启动完成之后属性会被清除，此时返回空列表。
+func ConfigProps(ctx Context) []conf.Property {
+
+	var props []conf.Property
+	if c, ok := ctx.(*container); ok {
+		if c.tempContainer == nil {
+			return nil
+		}
+		props = c.p.Dump()
+	} else {
+		for _, key := range ctx.Keys() {
+			props = append(props, conf.Property{Key: key, Value: ctx.Prop(key)})
+		}
+	}
+
+	for i := range props {
+		props[i].Value = MaskValue(props[i].Key, props[i].Value)
+	}
+	return props
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/conf"
+)
+
+// autoExport 记录自动导出接口的配置以及已经处理过的接口。开启自动导出之后，按照
+// 接口类型注入或者查找 bean 时，所有实现了该接口的 bean 都会被导出为该接口，不再
+// 需要逐个调用 Export 方法。只有位于配置的包中的非空接口才会被自动导出。
+type autoExport struct {
+	packages []string
+	visited  map[reflect.Type]bool
+}
+
+func (c *container) initAutoExport() error {
+	var config conf.AutoExportConfig
+	if err := c.p.Bind(&config); err != nil {
+		return err
+	}
+	for _, s := range config.Packages {
+		if s = strings.TrimSpace(s); s != "" {
+			c.autoExport.packages = append(c.autoExport.packages, s)
+		}
+	}
+	c.autoExport.visited = make(map[reflect.Type]bool)
+	return nil
+}
+
+// exportable 返回接口 t 是否可以被自动导出。
+func (m *autoExport) exportable(t reflect.Type) bool {
+	if t.Kind() != reflect.Interface || t.NumMethod() == 0 {
+		return false
+	}
+	pkgPath := t.PkgPath()
+	for _, s := range m.packages {
+		if pkg := strings.TrimSuffix(s, "/..."); pkg != s {
+			if pkgPath == pkg || strings.HasPrefix(pkgPath, pkg+"/") {
+				return true
+			}
+		} else if pkgPath == s {
+			return true
+		}
+	}
+	return false
+}
+
+// exportTo 返回 bean 是否可以被自动导出为接口 t 。
+func (c *container) exportTo(b *BeanDefinition, t reflect.Type) bool {
+	return c.autoExport.exportable(t) && b.Type() != t && b.Type().Implements(t)
+}
+
+// autoExportBeans 将所有实现了接口 t 的已决议的 bean 导出为 t ，每个接口只处理一次。
+func (c *container) autoExportBeans(t reflect.Type) {
+	if c.autoExport.visited[t] || !c.autoExport.exportable(t) {
+		return
+	}
+	c.autoExport.visited[t] = true
+	for _, b := range c.beans {
+		if b.status < Resolved && b.status != Failed {
+			continue
+		}
+		if !c.exportTo(b, t) {
+			continue
+		}
+		exported := false
+		for _, typ := range b.exports {
+			if typ == t {
+				exported = true
+				break
+			}
+		}
+		if exported {
+			continue
+		}
+		log.Debugf("auto export %s name:%q type:%q %s", b.getClass(), b.BeanName(), t, b.FileLine())
+		b.exports = append(b.exports, t)
+		c.beansByType[t] = append(c.beansByType[t], b)
+	}
+}
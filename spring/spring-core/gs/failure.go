@@ -0,0 +1,122 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/conf"
+)
+
+// FailurePolicy bean 初始化失败时的处理策略，通过 spring.bean.failure-policy
+// 属性配置。
+type FailurePolicy string
+
+const (
+	FailFast       = FailurePolicy("fail-fast") // 刷新失败，默认策略
+	SkipAndDegrade = FailurePolicy("skip")      // 将 bean 标记为不可用，继续刷新
+	RetryLater     = FailurePolicy("retry")     // 其他 bean 完成初始化之后重试
+)
+
+// failure 记录 bean 初始化失败的处理策略和不可用的 bean 。
+type failure struct {
+	config conf.BeanFailureConfig
+	beans  []*BeanDefinition
+}
+
+func (c *container) initFailure() error {
+	if err := c.p.Bind(&c.failure.config); err != nil {
+		return err
+	}
+	switch FailurePolicy(c.failure.config.Policy) {
+	case FailFast, SkipAndDegrade, RetryLater:
+		return nil
+	default:
+		return fmt.Errorf("unknown bean failure policy %q", c.failure.config.Policy)
+	}
+}
+
+// FailurePolicy 返回 bean 初始化失败时的处理策略。
+func (c *container) FailurePolicy() FailurePolicy {
+	return FailurePolicy(c.failure.config.Policy)
+}
+
+// FailedBeans 返回初始化失败而被标记为不可用的 bean ，使用 BeanDefinition.Err
+// 获取失败的原因。应用启动完成之后仍然可以调用，用于健康检查和管理端点。
+func (c *container) FailedBeans() []*BeanDefinition {
+	return c.failure.beans
+}
+
+// wireFailed 处理 bean 注入失败，注入路径上 depth 之后所有未完成注入的 bean
+// 都会被标记为不可用，查找 bean 时会跳过这些 bean ，依赖它们的 bean 也会因此注入
+// 失败，可选依赖则注入空值。
+func (c *container) wireFailed(stack *wiringStack, depth int, err error) error {
+
+	if c.FailurePolicy() == FailFast {
+		return err
+	}
+
+	log.Errorf("%s ↩\n%s", err, stack.path())
+
+	for i := len(stack.beans) - 1; i >= depth; i-- {
+		b := stack.beans[i]
+		if b.status == Wired || b.status == Failed {
+			continue
+		}
+		b.status = Failed
+		b.err = err
+		delete(stack.destroyerMap, b.ID())
+		c.failure.beans = append(c.failure.beans, b)
+		c.progress.failed(b)
+	}
+	stack.beans = stack.beans[:depth]
+	return nil
+}
+
+// retryFailed 按照 retry 策略重新注入不可用的 bean ，重试成功的 bean 从不可用
+// 列表中移除。
+func (c *container) retryFailed(stack *wiringStack) {
+
+	if c.FailurePolicy() != RetryLater {
+		return
+	}
+
+	config := c.failure.config
+	for i := 0; i < config.RetryAttempts && len(c.failure.beans) > 0; i++ {
+
+		time.Sleep(config.RetryInterval)
+
+		beans := c.failure.beans
+		c.failure.beans = nil
+		for _, b := range beans {
+			b.status = Resolved
+			b.err = nil
+			c.progress.retry()
+		}
+
+		for _, b := range beans {
+			if b.status == Failed {
+				continue
+			}
+			if err := c.wireBean(b, stack); err != nil {
+				_ = c.wireFailed(stack, 0, err)
+			}
+		}
+	}
+}
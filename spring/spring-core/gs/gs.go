@@ -27,7 +27,6 @@ import (
 	"reflect"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/go-spring/spring-base/conf"
@@ -54,7 +53,10 @@ type Container interface {
 	Object(i interface{}) *BeanDefinition
 	Provide(ctor interface{}, args ...arg.Arg) *BeanDefinition
 	Refresh(opts ...internal.RefreshOption) error
+	OnProgress(fn ProgressListener)
 	Go(fn func(ctx context.Context))
+	GoTask(name string, fn func(ctx context.Context)) context.CancelFunc
+	Tasks() []TaskInfo
 	Close()
 }
 
@@ -79,7 +81,11 @@ type container struct {
 	cancel     context.CancelFunc
 	destroyers []func()
 	state      refreshState
-	wg         sync.WaitGroup
+	tasks      taskManager
+	pool       *util.Pool
+	progress   progress
+	failure    failure
+	autoExport autoExport
 }
 
 // New 创建 IoC 容器。
@@ -111,6 +117,16 @@ func (c *container) Property(key string, value interface{}) {
 	c.p.Set(key, value)
 }
 
+// OnProgress 添加容器刷新进度的监听函数，每个 bean 完成初始化 (属性绑定、依赖
+// 注入以及执行初始化函数) 之后通知一次，开始初始化之前会先通知一次初始进度，
+// 可以用于展示大型应用的启动进度或者作为就绪检查的依据。
+func (c *container) OnProgress(fn ProgressListener) {
+	if c.state != Unrefreshed {
+		panic(errors.New("should call before Refresh"))
+	}
+	c.progress.listeners = append(c.progress.listeners, fn)
+}
+
 func (c *container) register(b *BeanDefinition) *BeanDefinition {
 	if c.state != Unrefreshed {
 		panic(errors.New("should call before Refresh"))
@@ -260,6 +276,22 @@ func (c *container) Refresh(opts ...internal.RefreshOption) (err error) {
 		opt(optArg)
 	}
 
+	if err = c.initPool(); err != nil {
+		return err
+	}
+
+	if err = c.initFailure(); err != nil {
+		return err
+	}
+
+	if err = c.initTasks(); err != nil {
+		return err
+	}
+
+	if err = c.initAutoExport(); err != nil {
+		return err
+	}
+
 	c.Object(c).Export((*Context)(nil))
 	c.state = Refreshing
 
@@ -275,6 +307,14 @@ func (c *container) Refresh(opts ...internal.RefreshOption) (err error) {
 		}
 	}
 
+	total := 0
+	for _, b := range c.beansById {
+		if b.status != Wired { // 导入的 bean 不计入进度
+			total++
+		}
+	}
+	c.progress.begin(total)
+
 	stack := newWiringStack()
 
 	defer func() {
@@ -285,11 +325,18 @@ func (c *container) Refresh(opts ...internal.RefreshOption) (err error) {
 	}()
 
 	for _, b := range c.beansById {
+		if b.status == Failed {
+			continue
+		}
 		if err = c.wireBean(b, stack); err != nil {
-			return err
+			if err = c.wireFailed(stack, 0, err); err != nil {
+				return err
+			}
 		}
 	}
 
+	c.retryFailed(stack)
+
 	c.destroyers = stack.sortDestroyers()
 	c.state = Refreshed
 
@@ -315,7 +362,7 @@ func (c *container) registerBean(b *BeanDefinition) error {
 // resolveBean 判断 bean 的有效性，如果 bean 是无效的则被标记为已删除。
 func (c *container) resolveBean(b *BeanDefinition) error {
 
-	if b.status >= Resolving {
+	if b.status >= Resolving || b.status == Failed {
 		return nil
 	}
 
@@ -367,6 +414,26 @@ func (c *container) resolveBean(b *BeanDefinition) error {
 	}
 
 	b.status = Resolved
+	if b.imported { // 导入的 bean 已经在所在模块完成注入
+		b.status = Wired
+	}
+	return nil
+}
+
+// checkInterfaceBean 按照名称注入接口类型时，如果存在同名但是没有实现该接口的
+// bean ，则返回列出缺少的方法的错误，方便用户定位问题。
+func (c *container) checkInterfaceBean(t reflect.Type, tag wireTag) error {
+	if t.Kind() != reflect.Interface || tag.beanName == "" {
+		return nil
+	}
+	for _, b := range c.beansByName[tag.beanName] {
+		if b.status == Deleted || !b.Match(tag.typeName, tag.beanName) {
+			continue
+		}
+		if err := util.CheckImplements(b.Type(), t); err != nil {
+			return fmt.Errorf("can't inject %s into bean:%q type:%q: %w", b, tag, t, err)
+		}
+	}
 	return nil
 }
 
@@ -451,7 +518,7 @@ func (c *container) findBean(selector BeanSelector) ([]*BeanDefinition, error) {
 			if err := c.resolveBean(b); err != nil {
 				return nil, err
 			}
-			if b.status == Deleted {
+			if b.status == Deleted || b.status == Failed {
 				continue
 			}
 			result = append(result, b)
@@ -483,7 +550,7 @@ func (c *container) findBean(selector BeanSelector) ([]*BeanDefinition, error) {
 				return true
 			}
 		}
-		return false
+		return c.exportTo(b, t)
 	})
 }
 
@@ -496,6 +563,10 @@ func (c *container) wireBean(b *BeanDefinition, stack *wiringStack) error {
 		return fmt.Errorf("bean:%q have been deleted", b.ID())
 	}
 
+	if b.status == Failed {
+		return fmt.Errorf("bean:%q is unavailable: %v", b.ID(), b.err)
+	}
+
 	// 运行时 Get 或者 Wire 会出现下面这种情况。
 	if c.state == Refreshed && b.status == Wired {
 		return nil
@@ -510,7 +581,7 @@ func (c *container) wireBean(b *BeanDefinition, stack *wiringStack) error {
 	}()
 
 	// 记录注入路径上的销毁函数及其执行的先后顺序。
-	if _, ok := b.Interface().(BeanDestroy); ok || b.destroy != nil {
+	if _, ok := b.Interface().(BeanDestroy); (ok || b.destroy != nil) && !b.imported {
 		haveDestroy = true
 		d := stack.saveDestroyer(b)
 		if i := stack.destroyers.Back(); i != nil {
@@ -558,8 +629,8 @@ func (c *container) wireBean(b *BeanDefinition, stack *wiringStack) error {
 
 	t := v.Type()
 	for _, typ := range b.exports {
-		if !t.Implements(typ) {
-			return fmt.Errorf("%s doesn't implement interface %s", b, typ)
+		if err = util.CheckImplements(t, typ); err != nil {
+			return fmt.Errorf("%s export error: %w", b, err)
 		}
 	}
 
@@ -584,6 +655,7 @@ func (c *container) wireBean(b *BeanDefinition, stack *wiringStack) error {
 
 	b.status = Wired
 	stack.popBack()
+	c.progress.wired(b)
 	return nil
 }
 
@@ -692,6 +764,13 @@ func (c *container) wireStruct(v reflect.Value, opt conf.BindParam, stack *wirin
 			continue
 		}
 
+		if tag, ok = ft.Tag.Lookup("resource"); ok {
+			if err := c.wireResource(fv, tag); err != nil {
+				return fmt.Errorf("%q wired error: %w", fieldPath, err)
+			}
+			continue
+		}
+
 		subParam := conf.BindParam{
 			Type: ft.Type,
 			Key:  opt.Key,
@@ -770,14 +849,21 @@ func (c *container) getBean(v reflect.Value, tag wireTag, stack *wiringStack) er
 		return fmt.Errorf("%s is not valid receiver type", t.String())
 	}
 
+	var failedBean *BeanDefinition
 	foundBeans := make([]*BeanDefinition, 0)
 
+	c.autoExportBeans(t)
 	cache := c.beansByType[t]
 	for i := 0; i < len(cache); i++ {
 		b := cache[i]
-		if b.Match(tag.typeName, tag.beanName) {
-			foundBeans = append(foundBeans, b)
+		if !b.Match(tag.typeName, tag.beanName) {
+			continue
 		}
+		if b.status == Failed {
+			failedBean = b
+			continue
+		}
+		foundBeans = append(foundBeans, b)
 	}
 
 	// 指定 bean 名称时通过名称获取，防止未通过 Export 方法导出接口。
@@ -785,7 +871,7 @@ func (c *container) getBean(v reflect.Value, tag wireTag, stack *wiringStack) er
 		cache = c.beansByName[tag.beanName]
 		for i := 0; i < len(cache); i++ {
 			b := cache[i]
-			if b.Type().AssignableTo(t) && b.Match(tag.typeName, tag.beanName) {
+			if b.status != Failed && b.Type().AssignableTo(t) && b.Match(tag.typeName, tag.beanName) {
 				found := false // 对结果排重
 				for _, r := range foundBeans {
 					if r == b {
@@ -805,6 +891,12 @@ func (c *container) getBean(v reflect.Value, tag wireTag, stack *wiringStack) er
 		if tag.nullable {
 			return nil
 		}
+		if failedBean != nil {
+			return fmt.Errorf("bean:%q is unavailable: %v", failedBean.ID(), failedBean.err)
+		}
+		if err := c.checkInterfaceBean(t, tag); err != nil {
+			return err
+		}
 		return fmt.Errorf("can't find bean, bean:%q type:%q", tag, t)
 	}
 
@@ -843,8 +935,13 @@ func (c *container) getBean(v reflect.Value, tag wireTag, stack *wiringStack) er
 	}
 
 	// 确保找到的 bean 已经完成依赖注入。
+	depth := len(stack.beans)
 	err := c.wireBean(result, stack)
 	if err != nil {
+		// 可选的依赖初始化失败时按照失败策略降级为空值。
+		if tag.nullable && c.FailurePolicy() != FailFast {
+			return c.wireFailed(stack, depth, err)
+		}
 		return err
 	}
 
@@ -889,6 +986,8 @@ func (b byOrder) Len() int           { return len(b) }
 func (b byOrder) Less(i, j int) bool { return b[i].order < b[j].order }
 func (b byOrder) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 
+// collectBeans 收集 tags 对应的 bean 然后赋值给 v ，v 为切片时按照 Order 排序，
+// v 为 map 时 key 为 bean 名称，可以用来构造策略模式的分发表。
 func (c *container) collectBeans(v reflect.Value, tags []wireTag, stack *wiringStack) error {
 
 	t := v.Type()
@@ -901,7 +1000,20 @@ func (c *container) collectBeans(v reflect.Value, tags []wireTag, stack *wiringS
 		return fmt.Errorf("%s is not valid receiver type", t.String())
 	}
 
-	beans := c.beansByType[et]
+	// map 的 key 为 bean 名称，所以 key 必须是字符串类型。
+	if t.Kind() == reflect.Map && t.Key().Kind() != reflect.String {
+		return fmt.Errorf("%s should use string key", t.String())
+	}
+
+	c.autoExportBeans(et)
+
+	// 复制一份，后面的过滤操作会修改切片。
+	var beans []*BeanDefinition
+	for _, b := range c.beansByType[et] {
+		if b.status != Failed {
+			beans = append(beans, b)
+		}
+	}
 	if len(tags) > 0 {
 
 		var (
@@ -980,7 +1092,11 @@ func (c *container) collectBeans(v reflect.Value, tags []wireTag, stack *wiringS
 	case reflect.Map:
 		ret = reflect.MakeMap(t)
 		for _, b := range beans {
-			ret.SetMapIndex(reflect.ValueOf(b.name), b.Value())
+			k := reflect.ValueOf(b.name).Convert(t.Key())
+			if ret.MapIndex(k).IsValid() {
+				return fmt.Errorf("found duplicate bean name %q for %s", b.name, t.String())
+			}
+			ret.SetMapIndex(k, b.Value())
 		}
 	}
 	v.Set(ret)
@@ -992,9 +1108,18 @@ func (c *container) collectBeans(v reflect.Value, tags []wireTag, stack *wiringS
 func (c *container) Close() {
 
 	c.cancel()
-	c.wg.Wait()
+	if c.pool != nil {
+		go c.pool.Close() // 拒绝新的任务，等待由 taskManager 负责。
+	}
 
-	log.Info("goroutines exited")
+	if c.tasks.wait() {
+		log.Info("goroutines exited")
+	} else {
+		for _, t := range c.tasks.list() {
+			log.Warnf("task %q still running after %v", t.Name, t.Running)
+		}
+		log.Warnf("goroutines not exited in %v", c.tasks.timeout)
+	}
 
 	for _, f := range c.destroyers {
 		f()
@@ -1002,18 +1127,3 @@ func (c *container) Close() {
 
 	log.Info("container closed")
 }
-
-// Go 创建安全可等待的 goroutine，fn 要求的 ctx 对象由 IoC 容器提供，当 IoC 容
-// 器关闭时 ctx会 发出 Done 信号， fn 在接收到此信号后应当立即退出。
-func (c *container) Go(fn func(ctx context.Context)) {
-	c.wg.Add(1)
-	go func() {
-		defer c.wg.Done()
-		defer func() {
-			if r := recover(); r != nil {
-				log.Panic(r)
-			}
-		}()
-		fn(c.ctx)
-	}()
-}
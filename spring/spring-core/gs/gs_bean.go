@@ -40,6 +40,7 @@ const (
 type beanStatus int8
 
 const (
+	Failed    = beanStatus(-2)   // 初始化失败，不可用
 	Deleted   = beanStatus(-1)   // 已删除
 	Default   = beanStatus(iota) // 未处理
 	Resolving                    // 正在决议
@@ -72,6 +73,7 @@ type BeanDefinition struct {
 
 	name    string         // 名称
 	status  beanStatus     // 状态
+	err     error          // 初始化失败的原因
 	primary bool           // 是否为主版本
 	method  bool           // 是否为成员方法
 	cond    cond.Condition // 判断条件
@@ -80,6 +82,8 @@ type BeanDefinition struct {
 	destroy interface{}    // 销毁函数
 	depends []BeanSelector // 间接依赖项
 	exports []reflect.Type // 导出的接口
+
+	imported bool // 是否从其他模块导入
 }
 
 // Type 返回 bean 的类型。
@@ -122,6 +126,16 @@ func (d *BeanDefinition) Wired() bool {
 	return d.status == Wired
 }
 
+// Failed 返回 bean 是否因为初始化失败而不可用。
+func (d *BeanDefinition) Failed() bool {
+	return d.status == Failed
+}
+
+// Err 返回 bean 初始化失败的原因。
+func (d *BeanDefinition) Err() error {
+	return d.err
+}
+
 // FileLine 返回 bean 的注册点。
 func (d *BeanDefinition) FileLine() string {
 	return fmt.Sprintf("%s:%d", d.file, d.line)
@@ -233,6 +247,12 @@ func (d *BeanDefinition) export(exports ...interface{}) error {
 		if typ.Kind() != reflect.Interface {
 			return errors.New("only interface type can be exported")
 		}
+		// 构造函数返回接口类型时只能在创建之后检查。
+		if d.t.Kind() != reflect.Interface {
+			if err := util.CheckImplements(d.t, typ); err != nil {
+				return fmt.Errorf("%s export error: %w", d, err)
+			}
+		}
 		exported := false
 		for _, export := range d.exports {
 			if typ == export {
@@ -307,6 +327,16 @@ func NewBean(objOrCtor interface{}, ctorArgs ...arg.Arg) *BeanDefinition {
 		panic(errors.New("bean should be *val but not *ref"))
 	}
 
+	if isStrictMode() {
+		var fnType reflect.Type
+		if f != nil {
+			fnType = reflect.TypeOf(objOrCtor)
+		}
+		if err := checkStrict(t, f, fnType); err != nil {
+			panic(fmt.Errorf("strict mode: %s:%d %w", file, line, err))
+		}
+	}
+
 	// Type.String() 一般返回 *pkg.Type 形式的字符串，
 	// 我们只取最后的类型名，如有需要请自定义 bean 名称。
 	s := strings.Split(t.String(), ".")
@@ -45,6 +45,12 @@ type Context interface {
 	Wire(objOrCtor interface{}, ctorArgs ...arg.Arg) (interface{}, error)
 	Invoke(fn interface{}, args ...arg.Arg) ([]interface{}, error)
 	Go(fn func(ctx context.Context))
+	GoTask(name string, fn func(ctx context.Context)) context.CancelFunc
+	Tasks() []TaskInfo
+	Keys() []string
+	Beans() []*BeanDefinition
+	FailedBeans() []*BeanDefinition
+	FailurePolicy() FailurePolicy
 }
 
 func (c *container) Has(key string) bool {
@@ -59,6 +65,29 @@ func (c *container) Bind(i interface{}, opts ...conf.BindOption) error {
 	return c.p.Bind(i, opts...)
 }
 
+// Keys 返回所有属性的 key ，应用启动完成之后属性会被清除，此时返回空列表。
+func (c *container) Keys() []string {
+	if c.tempContainer == nil {
+		return nil
+	}
+	return c.p.Keys()
+}
+
+// Beans 返回所有有效的 bean 对象，即未被标记为删除的 bean 对象，应用启动完成之
+// 后 bean 的元数据会被清除，此时返回空列表。
+func (c *container) Beans() []*BeanDefinition {
+	if c.tempContainer == nil {
+		return nil
+	}
+	var ret []*BeanDefinition
+	for _, b := range c.beans {
+		if b.status != Deleted {
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
 // Find 查找符合条件的 bean 对象，注意该函数只能保证返回的 bean 是有效的，即未被
 // 标记为删除的，而不能保证已经完成属性绑定和依赖注入。
 func (c *container) Find(selector BeanSelector) ([]cond.BeanDefinition, error) {
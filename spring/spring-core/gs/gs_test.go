@@ -17,6 +17,7 @@
 package gs_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"image"
@@ -24,6 +25,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -2228,13 +2230,44 @@ func (_ *filterImpl) Filter(input string) string {
 	return input
 }
 
+type stringerOnly struct{}
+
+func (_ *stringerOnly) String() string {
+	return ""
+}
+
+type wrongFilter struct{}
+
+func (_ *wrongFilter) Filter(input []byte) string {
+	return string(input)
+}
+
 func TestApplicationContext_BeanCache(t *testing.T) {
 
 	t.Run("not implement interface", func(t *testing.T) {
 		c := gs.New()
-		c.Object(new(int)).Export((*filter)(nil))
+		assert.Panic(t, func() {
+			c.Object(new(int)).Export((*filter)(nil))
+		}, `export error: \*int does not implement gs_test.filter \(missing method Filter\)`)
+	})
+
+	t.Run("constructor returns interface", func(t *testing.T) {
+		c := gs.New()
+		c.Provide(func() fmt.Stringer { return new(stringerOnly) }).Export((*filter)(nil))
 		err := c.Refresh()
-		assert.Error(t, err, "doesn't implement interface gs_test.filter")
+		assert.Error(t, err, `export error: \*gs_test.stringerOnly does not implement gs_test.filter \(missing method Filter\)`)
+	})
+
+	t.Run("inject by name", func(t *testing.T) {
+		var server struct {
+			F filter `autowire:"f"`
+		}
+		c := gs.New()
+		c.Object(new(wrongFilter)).Name("f")
+		c.Object(&server)
+		err := c.Refresh()
+		assert.Error(t, err, `can't inject object bean name:"f" .* \*gs_test.wrongFilter does not implement gs_test.filter `+
+			`\(wrong type for method Filter: have func\(\[\]uint8\) string, want func\(string\) string\)`)
 	})
 
 	t.Run("implement interface", func(t *testing.T) {
@@ -2254,6 +2287,41 @@ func TestApplicationContext_BeanCache(t *testing.T) {
 	})
 }
 
+func TestContainer_AutoExport(t *testing.T) {
+
+	type server struct {
+		F       filter       `autowire:""`
+		Filters []filter     `autowire:""`
+		S       fmt.Stringer `autowire:"?"`
+		OK      *bool        `autowire:"?"`
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		c := gs.New()
+		c.Object(new(filterImpl))
+		c.Object(new(server))
+		err := c.Refresh()
+		assert.Error(t, err, "can't find bean, bean:\"\" type:\"gs_test.filter\"")
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		s := new(server)
+		c := gs.New()
+		c.Property("spring.bean.auto-export.packages", "github.com/go-spring/spring-core/...")
+		c.Object(new(filterImpl))
+		c.Object(new(stringerOnly))
+		c.Object(new(int)).Name("int")
+		c.Provide(func() bool { return true }).On(cond.OnBean((*filter)(nil)))
+		c.Object(s)
+		err := c.Refresh()
+		assert.Nil(t, err)
+		assert.NotNil(t, s.F)
+		assert.Equal(t, len(s.Filters), 1)
+		assert.True(t, s.S == nil)
+		assert.NotNil(t, s.OK)
+	})
+}
+
 type IntInterface interface {
 	Value() int
 }
@@ -2870,6 +2938,84 @@ func TestMapCollection(t *testing.T) {
 		})
 		assert.Nil(t, err)
 	})
+
+	t.Run("strategy", func(t *testing.T) {
+		c := gs.New()
+		c.Object(&alipay{}).Name("alipay").Export((*payment)(nil))
+		c.Object(&wechatPay{}).Name("wechat").Export((*payment)(nil))
+		c.Provide(func(m map[paymentType]payment) *paymentRouter {
+			return &paymentRouter{payments: m}
+		})
+		c.Object(new(paymentHolder))
+		err := runTest(c, func(p gs.Context) {
+
+			var r *paymentRouter
+			err := p.Get(&r)
+			assert.Nil(t, err)
+			assert.Equal(t, len(r.payments), 2)
+			assert.Equal(t, r.payments["alipay"].Pay(), "alipay")
+			assert.Equal(t, r.payments["wechat"].Pay(), "wechat")
+
+			var h *paymentHolder
+			err = p.Get(&h)
+			assert.Nil(t, err)
+			assert.Equal(t, len(h.All), 2)
+			assert.Equal(t, len(h.Some), 1)
+			assert.Equal(t, h.Some["wechat"].Pay(), "wechat")
+
+			var m map[string]payment
+			err = p.Get(&m, "alipay", "*")
+			assert.Nil(t, err)
+			assert.Equal(t, len(m), 2)
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		c := gs.New()
+		c.Object(&alipay{}).Name("pay").Export((*payment)(nil))
+		c.Object(&wechatPay{}).Name("pay").Export((*payment)(nil))
+		err := runTest(c, func(p gs.Context) {
+			var m map[string]payment
+			err := p.Get(&m)
+			assert.Error(t, err, "found duplicate bean name \"pay\" for map\\[string\\]gs_test.payment")
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("int key", func(t *testing.T) {
+		c := gs.New()
+		c.Object(&alipay{}).Export((*payment)(nil))
+		err := runTest(c, func(p gs.Context) {
+			var m map[int]payment
+			err := p.Get(&m)
+			assert.Error(t, err, "map\\[int\\]gs_test.payment should use string key")
+		})
+		assert.Nil(t, err)
+	})
+}
+
+type payment interface {
+	Pay() string
+}
+
+type paymentType string
+
+type alipay struct{}
+
+func (*alipay) Pay() string { return "alipay" }
+
+type wechatPay struct{}
+
+func (*wechatPay) Pay() string { return "wechat" }
+
+type paymentRouter struct {
+	payments map[paymentType]payment
+}
+
+type paymentHolder struct {
+	All  map[string]payment `autowire:""`
+	Some map[string]payment `autowire:"wechat"`
 }
 
 type circularA struct {
@@ -2902,6 +3048,62 @@ func TestLazy(t *testing.T) {
 	}
 }
 
+type optionalTracer interface {
+	Trace(name string) string
+}
+
+type optionalTracerImpl struct{}
+
+func (t *optionalTracerImpl) Trace(name string) string { return "trace:" + name }
+
+type optionalLogger struct{}
+
+type optionalService struct {
+	tracer optionalTracer
+	logger *optionalLogger
+}
+
+func newOptionalService(tracer optionalTracer, logger *optionalLogger) *optionalService {
+	return &optionalService{tracer: tracer, logger: logger}
+}
+
+func TestOptionalArg(t *testing.T) {
+
+	t.Run("missing", func(t *testing.T) {
+		c := gs.New()
+		c.Provide(newOptionalService, arg.Optional(nil), arg.Optional("logger"))
+		err := runTest(c, func(p gs.Context) {
+			var s *optionalService
+			err := p.Get(&s)
+			assert.Nil(t, err)
+			assert.True(t, s.tracer == nil)
+			assert.True(t, s.logger == nil)
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("found", func(t *testing.T) {
+		c := gs.New()
+		c.Object(&optionalTracerImpl{}).Export((*optionalTracer)(nil))
+		c.Provide(newOptionalService, arg.Optional((*optionalTracerImpl)(nil)), arg.Optional(nil))
+		err := runTest(c, func(p gs.Context) {
+			var s *optionalService
+			err := p.Get(&s)
+			assert.Nil(t, err)
+			assert.Equal(t, s.tracer.Trace("a"), "trace:a")
+			assert.True(t, s.logger == nil)
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("not bean receiver", func(t *testing.T) {
+		c := gs.New()
+		c.Provide(func(i int) *optionalService { return nil }, arg.Optional(nil))
+		err := c.Refresh()
+		assert.Error(t, err, "optional arg should be bean receiver, but int")
+	})
+}
+
 type memory struct {
 }
 
@@ -2935,3 +3137,315 @@ func TestDestroyDependence(t *testing.T) {
 	err := c.Refresh()
 	assert.Nil(t, err)
 }
+
+func TestApplicationContext_Beans(t *testing.T) {
+
+	c := gs.New()
+	c.Property("a.b", "c")
+	c.Object(new(int)).Name("i")
+	c.Object(new(string)).Name("s").On(cond.OnProperty("no-such-key"))
+
+	err := runTest(c, func(ctx gs.Context) {
+		keys := make(map[string]bool)
+		for _, k := range ctx.Keys() {
+			keys[k] = true
+		}
+		assert.True(t, keys["a.b"])
+		names := make(map[string]bool)
+		for _, b := range ctx.Beans() {
+			names[b.BeanName()] = true
+		}
+		assert.True(t, names["i"])
+		assert.False(t, names["s"])
+	})
+	assert.Nil(t, err)
+}
+
+func TestContainer_GoroutinePool(t *testing.T) {
+
+	c := gs.New()
+	c.Property("spring.goroutine-pool.size", 2)
+	err := c.Refresh()
+	assert.Nil(t, err)
+
+	var mutex sync.Mutex
+	running, maxRunning, count := 0, 0, 0
+	for i := 0; i < 6; i++ {
+		c.Go(func(ctx context.Context) {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mutex.Lock()
+			running--
+			count++
+			mutex.Unlock()
+			panic("goroutine panic")
+		})
+	}
+
+	c.Close()
+	assert.Equal(t, count, 6)
+	assert.True(t, maxRunning <= 2)
+}
+
+func TestContainer_GoTask(t *testing.T) {
+
+	t.Run("cancel", func(t *testing.T) {
+		c := gs.New()
+		err := c.Refresh()
+		assert.Nil(t, err)
+
+		exited := make(chan struct{})
+		cancel := c.GoTask("poller", func(ctx context.Context) {
+			<-ctx.Done()
+			close(exited)
+		})
+		c.GoTask("watcher", func(ctx context.Context) { <-ctx.Done() })
+
+		tasks := c.Tasks()
+		assert.Equal(t, len(tasks), 2)
+		assert.Equal(t, tasks[0].Name, "poller")
+		assert.Equal(t, tasks[1].Name, "watcher")
+
+		cancel()
+		<-exited
+		for len(c.Tasks()) != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equal(t, c.Tasks()[0].Name, "watcher")
+
+		c.Close()
+		assert.Equal(t, len(c.Tasks()), 0)
+	})
+
+	t.Run("shutdown timeout", func(t *testing.T) {
+		c := gs.New()
+		c.Property("spring.task.shutdown-timeout", "10ms")
+		err := c.Refresh()
+		assert.Nil(t, err)
+
+		release := make(chan struct{})
+		defer close(release)
+		c.GoTask("straggler", func(ctx context.Context) { <-release })
+
+		start := time.Now()
+		c.Close()
+		assert.True(t, time.Since(start) < time.Second)
+		tasks := c.Tasks()
+		assert.Equal(t, len(tasks), 1)
+		assert.Equal(t, tasks[0].Name, "straggler")
+	})
+}
+
+type valueArgServer struct {
+	addr    string
+	port    int
+	timeout time.Duration
+	tags    []string
+	retry   int64
+}
+
+func newValueArgServer(addr string, port int, timeout time.Duration, tags []string, retry int64) *valueArgServer {
+	return &valueArgServer{addr: addr, port: port, timeout: timeout, tags: tags, retry: retry}
+}
+
+func TestValueArg(t *testing.T) {
+
+	t.Run("property", func(t *testing.T) {
+		c := gs.New()
+		c.Property("server.port", "9090")
+		c.Property("server.tags", "a,b")
+		c.Provide(newValueArgServer,
+			arg.Value("localhost"),
+			arg.Value("${server.port}"),
+			arg.Value("${server.timeout:=3s}"),
+			arg.Value("${server.tags}"),
+			arg.Value(3),
+		)
+		err := runTest(c, func(p gs.Context) {
+			var s *valueArgServer
+			err := p.Get(&s)
+			assert.Nil(t, err)
+			assert.Equal(t, s.addr, "localhost")
+			assert.Equal(t, s.port, 9090)
+			assert.Equal(t, s.timeout, 3*time.Second)
+			assert.Equal(t, s.tags, []string{"a", "b"})
+			assert.Equal(t, s.retry, int64(3))
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := gs.New()
+		c.Property("server.port", "abc")
+		c.Provide(newValueArgServer, arg.Value(""), arg.Value("${server.port}"))
+		err := c.Refresh()
+		assert.Error(t, err, "Key:server.port .* unable to cast \"abc\" of type string to int64")
+	})
+}
+
+func TestContainer_OnProgress(t *testing.T) {
+	c := gs.New()
+	c.Object(&memory{})
+	c.Object(&table{})
+	c.Object(&struct{}{}).On(cond.OnProperty("disabled"))
+	var events []gs.RefreshProgress
+	c.OnProgress(func(p gs.RefreshProgress) {
+		events = append(events, p)
+	})
+	err := c.Refresh()
+	assert.Nil(t, err)
+	assert.Equal(t, len(events), 4)
+	assert.Equal(t, events[0].Initialized, 0)
+	assert.Equal(t, events[0].Bean, "")
+	var beans []string
+	for i, e := range events {
+		assert.Equal(t, e.Total, 3)
+		assert.Equal(t, e.Initialized, i)
+		if e.Bean != "" {
+			beans = append(beans, e.Bean)
+		}
+	}
+	sort.Strings(beans)
+	assert.Equal(t, beans, []string{"container", "memory", "table"})
+	assert.True(t, events[3].Done())
+	assert.Panic(t, func() { c.OnProgress(func(gs.RefreshProgress) {}) }, "should call before Refresh")
+}
+
+type failingDataSource struct {
+	failures int
+}
+
+func (d *failingDataSource) OnInit(ctx gs.Context) error {
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+type failingRepository struct {
+	DataSource *failingDataSource `autowire:""`
+}
+
+type failingService struct {
+	DataSource *failingDataSource `autowire:"?"`
+	Context    gs.Context         `autowire:""`
+}
+
+func TestContainer_FailurePolicy(t *testing.T) {
+
+	t.Run("fail-fast", func(t *testing.T) {
+		c := gs.New()
+		c.Object(&failingDataSource{failures: 1})
+		err := c.Refresh()
+		assert.Error(t, err, "connection refused")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		c := gs.New()
+		c.Property("spring.bean.failure-policy", "ignore")
+		err := c.Refresh()
+		assert.Error(t, err, "unknown bean failure policy \"ignore\"")
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		c := gs.New()
+		c.Property("spring.bean.failure-policy", "skip")
+		c.Object(&failingDataSource{failures: 1})
+		c.Object(&failingRepository{})
+		s := &failingService{}
+		c.Object(s)
+		err := c.Refresh()
+		assert.Nil(t, err)
+		assert.True(t, s.DataSource == nil)
+		assert.Equal(t, s.Context.FailurePolicy(), gs.SkipAndDegrade)
+		var names []string
+		for _, b := range s.Context.FailedBeans() {
+			assert.True(t, b.Failed())
+			assert.Error(t, b.Err(), "connection refused")
+			names = append(names, b.BeanName())
+		}
+		sort.Strings(names)
+		assert.Equal(t, names, []string{"failingDataSource", "failingRepository"})
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		c := gs.New()
+		c.Property("spring.bean.failure-policy", "retry")
+		c.Property("spring.bean.retry.interval", "1ms")
+		c.Object(&failingDataSource{failures: 2})
+		r := &failingRepository{}
+		c.Object(r)
+		s := &failingService{}
+		c.Object(s)
+		err := c.Refresh()
+		assert.Nil(t, err)
+		assert.Equal(t, len(s.Context.FailedBeans()), 0)
+		assert.True(t, r.DataSource != nil)
+	})
+
+	t.Run("retry exhausted", func(t *testing.T) {
+		c := gs.New()
+		c.Property("spring.bean.failure-policy", "retry")
+		c.Property("spring.bean.retry.interval", "1ms")
+		c.Property("spring.bean.retry.max-attempts", "2")
+		c.Object(&failingDataSource{failures: 5})
+		s := &failingService{}
+		c.Object(s)
+		err := c.Refresh()
+		assert.Nil(t, err)
+		assert.Equal(t, len(s.Context.FailedBeans()), 1)
+	})
+}
+
+type strictConfig struct {
+	Port int `value:"${port:=8080}"`
+}
+
+type strictServer struct {
+	Config  strictConfig `value:"${server}"`
+	Service *strictService
+}
+
+type strictService struct {
+	Server *strictServer `autowire:"?"`
+}
+
+type strictInvalidField struct {
+	Server strictServer `autowire:""`
+}
+
+func TestStrictMode(t *testing.T) {
+
+	gs.StrictMode(true)
+	defer gs.StrictMode(false)
+
+	c := gs.New()
+	c.Object(&strictServer{})
+	c.Provide(func(s *strictServer) *strictService { return &strictService{} })
+	c.Provide(func(port int, s *strictServer) *strictService { return nil }, "${port:=8080}")
+
+	assert.Panic(t, func() {
+		c.Object(new(int))
+	}, "strict mode: .*gs_test.go:[0-9]+ object bean should be pointer to struct, but \\*int")
+
+	assert.Panic(t, func() {
+		c.Provide(func() strictServer { return strictServer{} })
+	}, "strict mode: .*gs_test.go:[0-9]+ constructor should return pointer to struct or interface, but gs_test.strictServer")
+
+	assert.Panic(t, func() {
+		c.Provide(func(m map[string][]int) *strictService { return nil })
+	}, "strict mode: .*gs_test.go:[0-9]+ constructor arg 0 type map\\[string\\]\\[\\]int can't be injected")
+
+	assert.Panic(t, func() {
+		c.Object(&strictInvalidField{})
+	}, "strict mode: .*gs_test.go:[0-9]+ \"strictInvalidField.Server\" is not valid receiver type gs_test.strictServer")
+
+	gs.StrictMode(false)
+	c.Object(new(int))
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"fmt"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/metrics"
+)
+
+// setLogCaller 根据 logging.caller 和 logging.stacktrace 属性设置是否记录调用
+// 位置和调用栈。
+func (app *App) setLogCaller() error {
+	var config conf.LoggingCallerConfig
+	if err := app.c.p.Bind(&config); err != nil {
+		return err
+	}
+	level, err := log.ParseLevel(config.StackLevel)
+	if err != nil {
+		return err
+	}
+	log.SetCaller(config.Caller)
+	log.SetStacktrace(level, config.StackDepth)
+	return nil
+}
+
+// setLogSampling 根据 logging.sampling 属性设置全局的日志采样。
+func (app *App) setLogSampling() error {
+	var config conf.LoggingSamplingConfig
+	if err := app.c.p.Bind(&config); err != nil {
+		return err
+	}
+	if config.First == 0 && config.Thereafter == 0 {
+		return nil
+	}
+	log.SetSampling(log.RootLogger, log.SamplingConfig{
+		Interval:   config.Interval,
+		First:      config.First,
+		Thereafter: config.Thereafter,
+	})
+	return nil
+}
+
+// setLogFile 根据 logging.file 属性将日志输出到滚动的日志文件。
+func (app *App) setLogFile() error {
+
+	var config conf.LoggingFileConfig
+	if err := app.c.p.Bind(&config); err != nil {
+		return err
+	}
+	if config.Path == "" {
+		return nil
+	}
+
+	var encoder log.Encoder
+	switch config.Format {
+	case "console":
+		encoder = log.ConsoleEncoder{}
+	case "json":
+		encoder = log.JSONEncoder{}
+	default:
+		return fmt.Errorf("unknown log format %q", config.Format)
+	}
+
+	switch config.Rotate {
+	case log.RotateNone, log.RotateDaily, log.RotateHourly:
+	default:
+		return fmt.Errorf("unknown log rotate %q", config.Rotate)
+	}
+
+	f, err := log.NewRollingFile(log.RollingFileConfig{
+		Path:       config.Path,
+		MaxSize:    config.MaxSize,
+		Rotate:     config.Rotate,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     config.MaxAge,
+		Compress:   config.Compress,
+	})
+	if err != nil {
+		return err
+	}
+	app.logClosers = append(app.logClosers, f)
+	log.SetOutput(log.NewOutput(f, encoder))
+	return nil
+}
+
+// setLogAsync 根据 logging.async 属性将日志的输出改为异步输出，程序退出时等待
+// 队列中的日志全部输出。
+func (app *App) setLogAsync() error {
+
+	var config conf.LoggingAsyncConfig
+	if err := app.c.p.Bind(&config); err != nil {
+		return err
+	}
+	if !config.Enabled {
+		return nil
+	}
+
+	var policy log.AsyncPolicy
+	switch config.Policy {
+	case "block":
+		policy = log.AsyncBlock
+	case "drop":
+		policy = log.AsyncDrop
+	default:
+		return fmt.Errorf("unknown log async policy %q", config.Policy)
+	}
+
+	o := log.NewAsyncOutput(log.GetOutput(), log.AsyncConfig{
+		BufferSize: config.BufferSize,
+		Policy:     policy,
+	})
+	app.logClosers = append(app.logClosers, o)
+	log.SetOutput(o.Output)
+	return nil
+}
+
+// setLogMetrics 根据 logging.metrics 属性统计各级别日志的数量，参见
+// metrics.LogOutput 。
+func (app *App) setLogMetrics() error {
+	var config conf.LoggingMetricsConfig
+	if err := app.c.p.Bind(&config); err != nil {
+		return err
+	}
+	if config.Enabled {
+		log.SetOutput(metrics.LogOutput(log.GetOutput()))
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-base/util"
+	"github.com/go-spring/spring-core/conf"
+)
+
+// GoPanicHandler 处理 Go 和 GoTask 启动的 goroutine 中的 panic ，在 recover 所
+// 在的 goroutine 中调用，默认输出 PANIC 级别的日志。
+var GoPanicHandler = func(r interface{}) {
+	log.Panic(r)
+}
+
+// initPool 根据 spring.goroutine-pool 属性创建容器的协程池。
+func (c *container) initPool() error {
+	var config conf.GoroutinePoolConfig
+	if err := c.p.Bind(&config); err != nil {
+		return err
+	}
+	if config.Size > 0 {
+		c.pool = util.NewPool(config.Size, func(r interface{}) { GoPanicHandler(r) })
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"time"
+)
+
+// RefreshProgress 容器刷新过程中 bean 初始化的进度。
+type RefreshProgress struct {
+	Total       int           // 需要初始化的 bean 总数
+	Initialized int           // 已经初始化的 bean 数量
+	Failed      int           // 初始化失败而不可用的 bean 数量
+	Bean        string        // 刚刚完成初始化的 bean 的名称
+	Cost        time.Duration // 从开始刷新到现在的耗时
+}
+
+// Done 返回是否所有的 bean 都已经完成初始化或者被标记为不可用。
+func (p RefreshProgress) Done() bool {
+	return p.Initialized+p.Failed >= p.Total
+}
+
+// ProgressListener 容器刷新进度的监听函数，在刷新容器的 goroutine 中同步执行，
+// 不能执行耗时的操作，也不能调用容器的方法。
+type ProgressListener func(p RefreshProgress)
+
+// progress 记录容器刷新的进度并通知监听函数。
+type progress struct {
+	listeners   []ProgressListener
+	start       time.Time
+	total       int
+	initialized int
+	failedCount int
+}
+
+// begin 开始记录进度，total 是需要初始化的 bean 总数。
+func (p *progress) begin(total int) {
+	p.start = time.Now()
+	p.total = total
+	p.initialized = 0
+	p.failedCount = 0
+	p.notify("")
+}
+
+// wired 记录一个 bean 完成初始化。
+func (p *progress) wired(b *BeanDefinition) {
+	if p.initialized+p.failedCount >= p.total {
+		return // 刷新完成之后运行时注入的 bean 不计入进度。
+	}
+	p.initialized++
+	p.notify(b.BeanName())
+}
+
+// failed 记录一个 bean 初始化失败。
+func (p *progress) failed(b *BeanDefinition) {
+	p.failedCount++
+	p.notify(b.BeanName())
+}
+
+// retry 记录一个初始化失败的 bean 开始重试。
+func (p *progress) retry() {
+	p.failedCount--
+}
+
+func (p *progress) notify(bean string) {
+	if len(p.listeners) == 0 {
+		return
+	}
+	e := RefreshProgress{
+		Total:       p.total,
+		Initialized: p.initialized,
+		Failed:      p.failedCount,
+		Bean:        bean,
+		Cost:        time.Since(p.start),
+	}
+	for _, fn := range p.listeners {
+		fn(e)
+	}
+}
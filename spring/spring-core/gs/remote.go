@@ -0,0 +1,313 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bconf "github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/conf"
+)
+
+// RemoteSourceEvent 远程配置源的可用状态发生变化时的事件。
+type RemoteSourceEvent struct {
+	Source    string    // 配置源的名称
+	Available bool      // 配置源是否可用
+	Err       error     // 配置源不可用的原因
+	Time      time.Time // 状态变化的时间
+}
+
+// RemoteSourceListener 远程配置源状态变化的监听函数。
+type RemoteSourceListener func(e RemoteSourceEvent)
+
+// remoteEntry 缓存的查找结果。
+type remoteEntry struct {
+	names   []string
+	data    [][]byte
+	fetched time.Time
+}
+
+// resources 返回缓存内容的副本。
+func (e *remoteEntry) resources() []Resource {
+	ret := make([]Resource, len(e.data))
+	for i := range e.data {
+		ret[i] = NewResource(e.names[i], e.data[i])
+	}
+	return ret
+}
+
+// RemoteLocator 为远程配置源 (如 Nacos、Apollo、Consul) 提供缓存和本地快照的
+// ResourceLocator 。缓存时间内的查找直接返回缓存的结果；远程查找成功时将结果保存
+// 为本地快照，远程配置源不可用时使用最近一次保存的快照启动，同时在后台定时重试，
+// 直到远程配置源恢复。配置源的可用状态发生变化时通知 OnEvent 注册的监听函数。
+// 通过 Bootstrap().ResourceLocator 注册时缓存配置从 bootstrap 属性中读取。
+type RemoteLocator struct {
+	Config conf.RemoteConfig `value:"${}"`
+
+	name   string
+	remote ResourceLocator
+
+	mutex     sync.Mutex
+	cache     map[string]*remoteEntry
+	failed    map[string]bool // 使用快照的资源
+	available bool
+	retrying  bool
+	listeners []RemoteSourceListener
+	stop      chan struct{}
+}
+
+// NewRemoteLocator 返回名为 name 的远程配置源 remote 的 RemoteLocator 对象，name
+// 同时也是本地快照的子目录名。
+func NewRemoteLocator(name string, remote ResourceLocator) *RemoteLocator {
+	l := &RemoteLocator{
+		name:      name,
+		remote:    remote,
+		cache:     make(map[string]*remoteEntry),
+		failed:    make(map[string]bool),
+		available: true,
+		stop:      make(chan struct{}),
+	}
+	_ = bconf.New().Bind(&l.Config) // 使用默认值
+	return l
+}
+
+// OnEvent 注册远程配置源状态变化的监听函数，监听函数可能在后台 goroutine 中执行。
+func (l *RemoteLocator) OnEvent(fn RemoteSourceListener) *RemoteLocator {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.listeners = append(l.listeners, fn)
+	return l
+}
+
+// Available 返回远程配置源当前是否可用。
+func (l *RemoteLocator) Available() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.available
+}
+
+// Locate 查找名字为 filename 的资源。
+func (l *RemoteLocator) Locate(filename string) ([]Resource, error) {
+
+	l.mutex.Lock()
+	e, ok := l.cache[filename]
+	if ok && l.Config.CacheTTL > 0 && time.Since(e.fetched) < l.Config.CacheTTL {
+		l.mutex.Unlock()
+		return e.resources(), nil
+	}
+	l.mutex.Unlock()
+
+	e, err := l.fetch(filename)
+	if err == nil {
+		return e.resources(), nil
+	}
+
+	e, ok = l.loadSnapshot(filename)
+	if !ok {
+		return nil, err
+	}
+	log.Warnf("remote source %s is unavailable, use snapshot of %s: %v", l.name, filename, err)
+
+	l.mutex.Lock()
+	l.failed[filename] = true
+	l.setAvailable(false, err)
+	if !l.retrying {
+		l.retrying = true
+		go l.retry()
+	}
+	l.mutex.Unlock()
+	return e.resources(), nil
+}
+
+// fetch 从远程配置源查找资源，成功时更新缓存和本地快照。
+func (l *RemoteLocator) fetch(filename string) (*remoteEntry, error) {
+
+	resources, err := l.remote.Locate(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &remoteEntry{fetched: time.Now()}
+	for _, r := range resources {
+		b, err := ioutil.ReadAll(r)
+		if c, ok := r.(io.Closer); ok {
+			c.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.names = append(e.names, r.Name())
+		e.data = append(e.data, b)
+	}
+
+	if err = l.saveSnapshot(filename, e); err != nil {
+		log.Warnf("save snapshot of %s error: %v", filename, err)
+	}
+
+	l.mutex.Lock()
+	l.cache[filename] = e
+	l.mutex.Unlock()
+	return e, nil
+}
+
+// snapshotDir 返回本地快照的保存目录。
+func (l *RemoteLocator) snapshotDir() string {
+	if l.Config.SnapshotDir == "" {
+		return ""
+	}
+	return filepath.Join(l.Config.SnapshotDir, l.name)
+}
+
+// saveSnapshot 将查找结果保存为 序号-filename 形式的本地快照文件。
+func (l *RemoteLocator) saveSnapshot(filename string, e *remoteEntry) error {
+	dir := l.snapshotDir()
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	old, err := filepath.Glob(filepath.Join(dir, "*-"+filename))
+	if err != nil {
+		return err
+	}
+	for _, file := range old {
+		if err = os.Remove(file); err != nil {
+			return err
+		}
+	}
+	for i, b := range e.data {
+		file := filepath.Join(dir, fmt.Sprintf("%d-%s", i, filename))
+		if err = ioutil.WriteFile(file, b, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSnapshot 加载 filename 的本地快照，资源的名称为快照文件的路径。
+func (l *RemoteLocator) loadSnapshot(filename string) (*remoteEntry, bool) {
+	dir := l.snapshotDir()
+	if dir == "" {
+		return nil, false
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*-"+filename))
+	if err != nil || len(files) == 0 {
+		return nil, false
+	}
+	index := func(file string) int {
+		s := strings.SplitN(filepath.Base(file), "-", 2)[0]
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return index(files[i]) < index(files[j])
+	})
+	e := &remoteEntry{}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, false
+		}
+		e.names = append(e.names, file)
+		e.data = append(e.data, b)
+	}
+	return e, true
+}
+
+// retry 定时重新查找使用快照的资源，全部成功后远程配置源恢复为可用状态。
+func (l *RemoteLocator) retry() {
+	interval := l.Config.RetryInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		l.mutex.Lock()
+		var files []string
+		for filename := range l.failed {
+			files = append(files, filename)
+		}
+		l.mutex.Unlock()
+
+		var lastErr error
+		for _, filename := range files {
+			if _, err := l.fetch(filename); err != nil {
+				lastErr = err
+				continue
+			}
+			l.mutex.Lock()
+			delete(l.failed, filename)
+			l.mutex.Unlock()
+		}
+
+		if lastErr != nil {
+			log.Debugf("remote source %s is still unavailable: %v", l.name, lastErr)
+			continue
+		}
+
+		log.Infof("remote source %s recovered", l.name)
+		l.mutex.Lock()
+		l.retrying = false
+		l.setAvailable(true, nil)
+		l.mutex.Unlock()
+		return
+	}
+}
+
+// setAvailable 更新远程配置源的可用状态，状态变化时通知监听函数，调用时需要持有锁。
+func (l *RemoteLocator) setAvailable(available bool, err error) {
+	if l.available == available {
+		return
+	}
+	l.available = available
+	e := RemoteSourceEvent{
+		Source:    l.name,
+		Available: available,
+		Err:       err,
+		Time:      time.Now(),
+	}
+	for _, fn := range l.listeners {
+		fn(e)
+	}
+}
+
+// OnDestroy 停止后台重试。
+func (l *RemoteLocator) OnDestroy() {
+	select {
+	case <-l.stop:
+	default:
+		close(l.stop)
+	}
+}
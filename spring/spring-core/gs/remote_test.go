@@ -0,0 +1,132 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/gs"
+)
+
+type remoteSource struct {
+	mutex sync.Mutex
+	data  string
+	err   error
+	calls int
+}
+
+func (s *remoteSource) set(data string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data, s.err = data, err
+}
+
+func (s *remoteSource) Locate(filename string) ([]gs.Resource, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []gs.Resource{gs.NewResource("remote/"+filename, []byte(s.data))}, nil
+}
+
+func readResources(t *testing.T, resources []gs.Resource) []string {
+	var ret []string
+	for _, r := range resources {
+		b, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		ret = append(ret, r.Name()+"="+string(b))
+	}
+	return ret
+}
+
+func TestRemoteLocator(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	source := &remoteSource{data: "a=1"}
+	events := make(chan gs.RemoteSourceEvent, 2)
+	l := gs.NewRemoteLocator("nacos", source).OnEvent(func(e gs.RemoteSourceEvent) {
+		events <- e
+	})
+	defer l.OnDestroy()
+	l.Config.CacheTTL = 0
+	l.Config.SnapshotDir = dir
+	l.Config.RetryInterval = 10 * time.Millisecond
+
+	const filename = "application.properties"
+	snapshot := filepath.Join(dir, "nacos", "0-"+filename)
+
+	resources, err := l.Locate(filename)
+	assert.Nil(t, err)
+	assert.Equal(t, readResources(t, resources), []string{"remote/" + filename + "=a=1"})
+	b, err := ioutil.ReadFile(snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), "a=1")
+
+	source.set("", errors.New("connection refused"))
+	resources, err = l.Locate(filename)
+	assert.Nil(t, err)
+	assert.Equal(t, readResources(t, resources), []string{snapshot + "=a=1"})
+	assert.False(t, l.Available())
+	e := <-events
+	assert.False(t, e.Available)
+	assert.Equal(t, e.Source, "nacos")
+	assert.Error(t, e.Err, "connection refused")
+
+	source.set("a=2", nil)
+	select {
+	case e = <-events:
+		assert.True(t, e.Available)
+	case <-time.After(time.Second):
+		t.Fatal("remote source should recover")
+	}
+	assert.True(t, l.Available())
+	b, err = ioutil.ReadFile(snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), "a=2")
+
+	t.Run("no snapshot", func(t *testing.T) {
+		_, err = l.Locate("bootstrap.properties")
+		assert.Nil(t, err)
+		source.set("", errors.New("connection refused"))
+		_, err = l.Locate("missing.properties")
+		assert.Error(t, err, "connection refused")
+		source.set("a=2", nil)
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		l.Config.CacheTTL = time.Hour
+		_, err = l.Locate(filename)
+		assert.Nil(t, err)
+		calls := source.calls
+		resources, err = l.Locate(filename)
+		assert.Nil(t, err)
+		assert.Equal(t, source.calls, calls)
+		assert.Equal(t, readResources(t, resources), []string{"remote/" + filename + "=a=2"})
+	})
+}
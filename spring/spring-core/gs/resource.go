@@ -0,0 +1,200 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResourceLoader 加载 path 对应的资源，path 是去掉前缀之后的位置。
+type ResourceLoader func(path string) (Resource, error)
+
+var resourceLoaders = struct {
+	sync.RWMutex
+	loaders map[string]ResourceLoader
+}{
+	loaders: map[string]ResourceLoader{
+		"file":  loadFileResource,
+		"embed": loadEmbedResource,
+		"http":  loadHTTPResource,
+		"https": loadHTTPResource,
+	},
+}
+
+// embedFS EmbedFS 注册的文件系统的 Open 方法。
+var embedFS = struct {
+	sync.RWMutex
+	open []reflect.Value
+}{}
+
+// RegisterResourceLoader 注册 prefix 前缀的资源加载器，例如 "oss"，已经存在的
+// 加载器会被覆盖。
+func RegisterResourceLoader(prefix string, loader ResourceLoader) {
+	resourceLoaders.Lock()
+	defer resourceLoaders.Unlock()
+	resourceLoaders.loaders[prefix] = loader
+}
+
+// EmbedFS 注册 embed: 前缀的资源使用的文件系统，fsys 必须具有 Open(string)
+// (T, error) 方法并且 T 实现了 io.Reader 接口，例如 embed.FS 或者 http.Dir 。
+// 注册了多个文件系统时按照注册的顺序查找。
+func EmbedFS(fsys interface{}) {
+	v := reflect.ValueOf(fsys)
+	m := v.MethodByName("Open")
+	if !m.IsValid() {
+		panic(errors.New("fsys should have Open method"))
+	}
+	t := m.Type()
+	if t.NumIn() != 1 || t.In(0).Kind() != reflect.String || t.NumOut() != 2 ||
+		!t.Out(0).Implements(reflect.TypeOf((*io.Reader)(nil)).Elem()) ||
+		!t.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		panic(errors.New("fsys.Open should be func(string)(io.Reader,error)"))
+	}
+	embedFS.Lock()
+	defer embedFS.Unlock()
+	embedFS.open = append(embedFS.open, m)
+}
+
+// LoadResource 加载 location 对应的资源，location 的格式为 prefix:path ，例如
+// file:config/tls.pem 、embed:templates/index.html 、https://host/schema.json ，
+// 没有前缀时按照 file: 处理。资源的内容会一次性读入内存，因此适合模板、证书以及
+// schema 这类较小的资源。
+func LoadResource(location string) (Resource, error) {
+	prefix, path := "file", location
+	if i := strings.Index(location, ":"); i > 0 {
+		prefix, path = location[:i], location[i+1:]
+	}
+	resourceLoaders.RLock()
+	loader, ok := resourceLoaders.loaders[prefix]
+	resourceLoaders.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource prefix %q", prefix)
+	}
+	if prefix == "http" || prefix == "https" {
+		path = location
+	}
+	return loader(path)
+}
+
+// bytesResource 内容保存在内存中的资源。
+type bytesResource struct {
+	*bytes.Reader
+	name string
+}
+
+// NewResource 返回内容为 data 的资源，用于实现 ResourceLoader 。
+func NewResource(name string, data []byte) Resource {
+	return &bytesResource{Reader: bytes.NewReader(data), name: name}
+}
+
+func (r *bytesResource) Name() string {
+	return r.name
+}
+
+func loadFileResource(path string) (Resource, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewResource(path, b), nil
+}
+
+func loadEmbedResource(path string) (Resource, error) {
+	embedFS.RLock()
+	fs := embedFS.open
+	embedFS.RUnlock()
+	for _, open := range fs {
+		out := open.Call([]reflect.Value{reflect.ValueOf(path)})
+		if !out[1].IsNil() {
+			continue
+		}
+		r := out[0].Interface().(io.Reader)
+		b, err := ioutil.ReadAll(r)
+		if c, ok := r.(io.Closer); ok {
+			c.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return NewResource(path, b), nil
+	}
+	return nil, fmt.Errorf("embed resource %q not found", path)
+}
+
+// httpResourceClient 加载 http: 和 https: 前缀资源的客户端。
+var httpResourceClient = &http.Client{Timeout: 30 * time.Second}
+
+func loadHTTPResource(url string) (Resource, error) {
+	resp, err := httpResourceClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("load resource %q error: %s", url, resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return NewResource(url, b), nil
+}
+
+var (
+	resourceType = reflect.TypeOf((*Resource)(nil)).Elem()
+	bytesType    = reflect.TypeOf([]byte(nil))
+)
+
+// wireResource 为 resource 标签的字段注入资源，tag 支持 ${} 属性引用，字段的
+// 类型可以是 Resource 、[]byte 或者 string 。
+func (c *container) wireResource(v reflect.Value, tag string) error {
+	location, err := c.p.Resolve(tag)
+	if err != nil {
+		return err
+	}
+	t := v.Type()
+	if t != resourceType && t != bytesType && t.Kind() != reflect.String {
+		return fmt.Errorf("%s is not valid resource receiver type", t.String())
+	}
+	r, err := LoadResource(location)
+	if err != nil {
+		return err
+	}
+	if t == resourceType {
+		v.Set(reflect.ValueOf(r))
+		return nil
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if t == bytesType {
+		v.SetBytes(b)
+	} else {
+		v.SetString(string(b))
+	}
+	return nil
+}
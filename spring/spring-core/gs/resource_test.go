@@ -0,0 +1,91 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/gs"
+)
+
+type resourceBean struct {
+	Config   gs.Resource `resource:"file:testdata/config/application.properties"`
+	Embed    []byte      `resource:"embed:/config/extension.properties"`
+	Schema   string      `resource:"${schema.url}"`
+	Optional string      `resource:"${template:=mem:index}"`
+}
+
+func TestResource(t *testing.T) {
+
+	gs.EmbedFS(http.Dir("testdata"))
+	gs.RegisterResourceLoader("mem", func(path string) (gs.Resource, error) {
+		return gs.NewResource(path, []byte("<html>"+path+"</html>")), nil
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schema.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"type":"object"}`)
+	}))
+	defer server.Close()
+
+	t.Run("inject", func(t *testing.T) {
+		c := gs.New()
+		c.Property("schema.url", server.URL+"/schema.json")
+		b := &resourceBean{}
+		c.Object(b)
+		err := c.Refresh()
+		assert.Nil(t, err)
+		assert.Equal(t, b.Config.Name(), "testdata/config/application.properties")
+		config, _ := ioutil.ReadAll(b.Config)
+		expect, _ := ioutil.ReadFile("testdata/config/application.properties")
+		assert.Equal(t, config, expect)
+		expect, _ = ioutil.ReadFile("testdata/config/extension.properties")
+		assert.Equal(t, b.Embed, expect)
+		assert.Equal(t, b.Schema, `{"type":"object"}`)
+		assert.Equal(t, b.Optional, "<html>index</html>")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := gs.LoadResource("embed:/not-exist")
+		assert.Error(t, err, "embed resource \"/not-exist\" not found")
+		_, err = gs.LoadResource(server.URL + "/not-exist")
+		assert.Error(t, err, "404 Not Found")
+		_, err = gs.LoadResource("oss:bucket/file")
+		assert.Error(t, err, "unsupported resource prefix \"oss\"")
+		r, err := gs.LoadResource("testdata/config/application.yaml")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasSuffix(r.Name(), "application.yaml"))
+	})
+
+	t.Run("invalid receiver", func(t *testing.T) {
+		c := gs.New()
+		c.Object(&struct {
+			R int `resource:"file:testdata/config/application.yaml"`
+		}{})
+		err := c.Refresh()
+		assert.Error(t, err, "int is not valid resource receiver type")
+	})
+}
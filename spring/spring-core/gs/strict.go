@@ -0,0 +1,132 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/go-spring/spring-base/util"
+	"github.com/go-spring/spring-core/gs/arg"
+)
+
+// StrictModeEnv 开启严格模式的环境变量，值为 true 时开启。
+const StrictModeEnv = "GS_STRICT_MODE"
+
+var strictMode int32
+
+func init() {
+	if ok, _ := strconv.ParseBool(os.Getenv(StrictModeEnv)); ok {
+		strictMode = 1
+	}
+}
+
+// StrictMode 开启或者关闭严格模式。严格模式下注册 bean 时就会检查那些不利于反
+// 射处理的 bean ，例如非结构体指针的对象、返回非结构体指针的构造函数、参数类型
+// 无法注入的构造函数以及 tag 和类型不匹配的字段，并在注册点 panic ，而不是等到
+// Refresh 时才报错。严格模式需要在注册 bean 之前开启，因为 bean 通常在 init 函
+// 数中注册，所以也可以通过 GS_STRICT_MODE 环境变量开启。
+func StrictMode(enable bool) {
+	var v int32
+	if enable {
+		v = 1
+	}
+	atomic.StoreInt32(&strictMode, v)
+}
+
+func isStrictMode() bool {
+	return atomic.LoadInt32(&strictMode) == 1
+}
+
+// checkStrict 按照严格模式的规则检查 bean ，t 是 bean 的类型，f 不为空时表示
+// 构造函数 bean 。
+func checkStrict(t reflect.Type, f *arg.Callable, fnType reflect.Type) error {
+
+	if f == nil {
+		if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("object bean should be pointer to struct, but %s", t)
+		}
+		return checkStrictFields(t.Elem())
+	}
+
+	if out := fnType.Out(0); out.Kind() != reflect.Interface &&
+		(out.Kind() != reflect.Ptr || out.Elem().Kind() != reflect.Struct) {
+		return fmt.Errorf("constructor should return pointer to struct or interface, but %s", out)
+	}
+
+	numIn := fnType.NumIn()
+	if fnType.IsVariadic() {
+		numIn--
+	}
+	for i := 0; i < numIn; i++ {
+		if a, ok := f.Arg(i); ok && a != "" {
+			continue // 明确指定了参数绑定
+		}
+		in := fnType.In(i)
+		if !util.IsBeanReceiver(in) && !util.IsValueType(in) {
+			return fmt.Errorf("constructor arg %d type %s can't be injected", i, in)
+		}
+	}
+
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+		return checkStrictFields(t.Elem())
+	}
+	return nil
+}
+
+// checkStrictFields 检查结构体字段的 tag 和类型是否匹配。
+func checkStrictFields(t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		fieldPath := t.Name() + "." + ft.Name
+
+		_, ok := ft.Tag.Lookup("autowire")
+		if !ok {
+			_, ok = ft.Tag.Lookup("inject")
+		}
+		if ok {
+			if !util.IsBeanReceiver(ft.Type) {
+				return fmt.Errorf("%q is not valid receiver type %s", fieldPath, ft.Type)
+			}
+			continue
+		}
+
+		if _, ok = ft.Tag.Lookup("resource"); ok {
+			if ft.Type != resourceType && ft.Type != bytesType && ft.Type.Kind() != reflect.String {
+				return fmt.Errorf("%q is not valid resource receiver type %s", fieldPath, ft.Type)
+			}
+			continue
+		}
+
+		if _, ok = ft.Tag.Lookup("value"); ok {
+			if !util.IsValueType(ft.Type) {
+				return fmt.Errorf("%q is not valid value type %s", fieldPath, ft.Type)
+			}
+			continue
+		}
+
+		if ft.Anonymous && ft.Type.Kind() == reflect.Struct {
+			if err := checkStrictFields(ft.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-base/util"
+	"github.com/go-spring/spring-core/conf"
+)
+
+// TaskInfo 正在运行的后台任务。
+type TaskInfo struct {
+	ID      uint64        `json:"id"`
+	Name    string        `json:"name"`
+	Started time.Time     `json:"started"`
+	Running time.Duration `json:"running"`
+}
+
+type task struct {
+	id      uint64
+	name    string
+	started time.Time
+	cancel  context.CancelFunc
+}
+
+// taskManager 跟踪容器中所有通过 Go 和 GoTask 启动的后台任务，容器关闭时通知
+// 任务退出并在超时时间内等待任务结束，超时后报告仍在运行的任务。
+type taskManager struct {
+	mutex   sync.Mutex
+	wg      sync.WaitGroup
+	nextID  uint64
+	tasks   map[uint64]*task
+	timeout time.Duration
+}
+
+func (c *container) initTasks() error {
+	var config conf.TaskConfig
+	if err := c.p.Bind(&config); err != nil {
+		return err
+	}
+	c.tasks.timeout = config.ShutdownTimeout
+	return nil
+}
+
+// add 记录一个新的任务。
+func (m *taskManager) add(name string, cancel context.CancelFunc) *task {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.tasks == nil {
+		m.tasks = make(map[uint64]*task)
+	}
+	m.nextID++
+	t := &task{id: m.nextID, name: name, started: time.Now(), cancel: cancel}
+	m.tasks[t.id] = t
+	m.wg.Add(1)
+	return t
+}
+
+// done 删除一个已经结束的任务。
+func (m *taskManager) done(t *task) {
+	m.mutex.Lock()
+	delete(m.tasks, t.id)
+	m.mutex.Unlock()
+	t.cancel()
+	m.wg.Done()
+}
+
+// list 返回按照启动顺序排列的正在运行的任务。
+func (m *taskManager) list() []TaskInfo {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := time.Now()
+	ret := make([]TaskInfo, 0, len(m.tasks))
+	for _, t := range m.tasks {
+		ret = append(ret, TaskInfo{
+			ID:      t.id,
+			Name:    t.name,
+			Started: t.started,
+			Running: now.Sub(t.started),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return ret
+}
+
+// wait 等待所有的任务结束，超时返回 false 。
+func (m *taskManager) wait() bool {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	if m.timeout <= 0 {
+		<-done
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(m.timeout):
+		return false
+	}
+}
+
+// Go 创建安全可等待的 goroutine，fn 要求的 ctx 对象由 IoC 容器提供，当 IoC 容
+// 器关闭时 ctx会 发出 Done 信号， fn 在接收到此信号后应当立即退出。配置了协程池
+// 时 fn 交给协程池执行，协程池已满时阻塞等待。任务的名称为 fn 的函数名。
+func (c *container) Go(fn func(ctx context.Context)) {
+	_, _, fnName := util.FileLine(fn)
+	c.GoTask(fnName, fn)
+}
+
+// GoTask 创建名称为 name 的后台任务，任务可以通过返回的 cancel 函数单独取消，
+// 也会在 IoC 容器关闭时被取消，使用 Tasks 查看正在运行的任务。
+func (c *container) GoTask(name string, fn func(ctx context.Context)) context.CancelFunc {
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	t := c.tasks.add(name, cancel)
+
+	run := func() {
+		defer c.tasks.done(t)
+		fn(ctx)
+	}
+
+	if c.pool != nil {
+		if err := c.pool.Go(run); err != nil {
+			c.tasks.done(t)
+			log.Error(err)
+		}
+		return cancel
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				GoPanicHandler(r)
+			}
+		}()
+		run()
+	}()
+	return cancel
+}
+
+// Tasks 返回正在运行的后台任务。
+func (c *container) Tasks() []TaskInfo {
+	return c.tasks.list()
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package lock 提供分布式锁接口，用于在多个实例之间协调定时任务等操作。
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+// ErrNotObtained 锁已经被其他实例持有。
+var ErrNotObtained = errors.New("lock not obtained")
+
+// ErrNotHeld 锁已经过期或者被其他实例持有，无法续期或者释放。
+var ErrNotHeld = errors.New("lock not held")
+
+// Lock 已经获得的锁。
+type Lock interface {
+
+	// Key 返回锁的名称。
+	Key() string
+
+	// Token 返回单调递增的 fencing token ，后获得锁的实例拿到更大的值，下游
+	// 资源可以拒绝携带较小 token 的请求。
+	Token() int64
+
+	// Refresh 把锁的过期时间延长为 ttl ，锁不再持有时返回 ErrNotHeld 。
+	Refresh(ctx context.Context, ttl time.Duration) error
+
+	// Unlock 释放锁，锁不再持有时返回 ErrNotHeld 。
+	Unlock(ctx context.Context) error
+}
+
+// Locker 分布式锁的实现。
+type Locker interface {
+
+	// TryLock 尝试获得名为 key 的锁，锁被其他实例持有时返回 ErrNotObtained 。
+	TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+}
+
+// Obtain 每隔 retry 尝试获得一次锁，直到成功或者 ctx 结束。
+func Obtain(ctx context.Context, l Locker, key string, ttl, retry time.Duration) (Lock, error) {
+	for {
+		r, err := l.TryLock(ctx, key, ttl)
+		if err != ErrNotObtained {
+			return r, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+// AutoRenew 每隔 ttl/3 把锁续期为 ttl ，直到调用返回的 stop 函数或者续期失败。
+func AutoRenew(ctx context.Context, r Lock, ttl time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.Refresh(ctx, ttl); err != nil {
+					if ctx.Err() == nil {
+						log.Errorf("renew lock %s error: %v", r.Key(), err)
+					}
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Do 获得锁之后执行 fn ，执行期间自动续期，执行完成后释放锁。锁被其他实例持有
+// 时返回 ErrNotObtained ，适合只需要一个实例执行的定时任务。
+func Do(ctx context.Context, l Locker, key string, ttl time.Duration, fn func(ctx context.Context, token int64) error) error {
+	r, err := l.TryLock(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	stop := AutoRenew(ctx, r, ttl)
+	defer func() {
+		stop()
+		if err := r.Unlock(context.Background()); err != nil {
+			log.Errorf("unlock %s error: %v", key, err)
+		}
+	}()
+	return fn(ctx, r.Token())
+}
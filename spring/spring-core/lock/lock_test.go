@@ -0,0 +1,119 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lock_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/lock"
+	"github.com/go-spring/spring-core/redis"
+)
+
+// fakeRedis 只支持锁使用的命令，忽略过期时间。
+type fakeRedis struct {
+	redis.Client
+	mutex    sync.Mutex
+	m        map[string]string
+	counter  map[string]int64
+	refreshs int
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{m: make(map[string]string), counter: make(map[string]int64)}
+}
+
+func (r *fakeRedis) Incr(ctx context.Context, key string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.counter[key]++
+	return r.counter[key], nil
+}
+
+func (r *fakeRedis) Set(ctx context.Context, key string, value interface{}, args ...interface{}) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.m[key]; ok {
+		return "", redis.ErrNil
+	}
+	r.m[key] = value.(string)
+	return "OK", nil
+}
+
+func (r *fakeRedis) Int64(ctx context.Context, args ...interface{}) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	key, value := args[3].(string), args[4].(string)
+	if r.m[key] != value {
+		return 0, nil
+	}
+	if len(args) == 6 {
+		r.refreshs++
+	} else {
+		delete(r.m, key)
+	}
+	return 1, nil
+}
+
+func TestRedisLocker(t *testing.T) {
+	ctx := context.Background()
+	r := newFakeRedis()
+	l := lock.NewRedisLocker(r, "lock:")
+
+	a, err := l.TryLock(ctx, "job", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, a.Key(), "job")
+	assert.Equal(t, a.Token(), int64(1))
+	assert.Equal(t, r.m, map[string]string{"lock:job": "1"})
+
+	_, err = l.TryLock(ctx, "job", time.Second)
+	assert.Equal(t, err, lock.ErrNotObtained)
+
+	assert.Nil(t, a.Refresh(ctx, time.Second))
+	assert.Nil(t, a.Unlock(ctx))
+	assert.Equal(t, a.Unlock(ctx), lock.ErrNotHeld)
+	assert.Equal(t, a.Refresh(ctx, time.Second), lock.ErrNotHeld)
+
+	b, err := l.TryLock(ctx, "job", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, b.Token(), int64(3))
+
+	c, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = lock.Obtain(c, l, "job", time.Second, 5*time.Millisecond)
+	assert.Equal(t, err, context.DeadlineExceeded)
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	r := newFakeRedis()
+	l := lock.NewRedisLocker(r, "")
+
+	var token int64
+	err := lock.Do(ctx, l, "job", 30*time.Millisecond, func(ctx context.Context, t int64) error {
+		token = t
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, token, int64(1))
+	assert.True(t, r.refreshs > 0)
+	assert.Equal(t, len(r.m), 0)
+}
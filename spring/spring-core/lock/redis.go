@@ -0,0 +1,99 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lock
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-spring/spring-core/redis"
+)
+
+// refreshScript 锁的值等于 token 时更新过期时间。
+const refreshScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) else return 0 end`
+
+// unlockScript 锁的值等于 token 时删除锁。
+const unlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// RedisLocker 基于 Redis 的分布式锁，锁的值为 fencing token ，token 使用
+// <key>:token 计数器生成。
+type RedisLocker struct {
+	client redis.Client
+	prefix string
+}
+
+// NewRedisLocker 返回使用 client 的 RedisLocker 对象，所有的 key 都会加上
+// prefix 前缀。
+func NewRedisLocker(client redis.Client, prefix string) *RedisLocker {
+	return &RedisLocker{client: client, prefix: prefix}
+}
+
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	k := l.prefix + key
+	token, err := l.client.Incr(ctx, k+":token")
+	if err != nil {
+		return nil, err
+	}
+	v := strconv.FormatInt(token, 10)
+	_, err = l.client.Set(ctx, k, v, "PX", int64(ttl/time.Millisecond), "NX")
+	if err == redis.ErrNil {
+		return nil, ErrNotObtained
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &redisLock{client: l.client, key: key, k: k, token: token, value: v}, nil
+}
+
+type redisLock struct {
+	client redis.Client
+	key    string
+	k      string
+	token  int64
+	value  string
+}
+
+func (r *redisLock) Key() string {
+	return r.key
+}
+
+func (r *redisLock) Token() int64 {
+	return r.token
+}
+
+func (r *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	n, err := r.client.Int64(ctx, "EVAL", refreshScript, 1, r.k, r.value, int64(ttl/time.Millisecond))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+func (r *redisLock) Unlock(ctx context.Context) error {
+	n, err := r.client.Int64(ctx, "EVAL", unlockScript, 1, r.k, r.value)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
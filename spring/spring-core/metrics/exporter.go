@@ -0,0 +1,48 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+// Exporter 把指标导出到监控系统。
+type Exporter interface {
+	Export(samples []Sample) error
+}
+
+// Push 每隔 interval 把 r 中的指标导出到 e ，直到 ctx 结束，结束时再导出一次。
+func Push(ctx context.Context, r *Registry, e Exporter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := e.Export(r.Snapshot()); err != nil {
+				log.Errorf("export metrics error: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := e.Export(r.Snapshot()); err != nil {
+				log.Errorf("export metrics error: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"github.com/go-spring/spring-base/log"
+)
+
+// LogOutput 返回统计各级别日志数量的 log.Output ，统计指标为 log.events ，标签
+// level 为日志级别，日志交给 next 输出。
+func LogOutput(next log.Output) log.Output {
+	var counters [log.FatalLevel + 1]Counter
+	for l := log.TraceLevel; l <= log.FatalLevel; l++ {
+		counters[l] = NewCounter("log.events", "level", l.String())
+	}
+	return func(level log.Level, e *log.Entry) {
+		if level <= log.FatalLevel {
+			counters[level].Inc()
+		}
+		next(level, e)
+	}
+}
@@ -0,0 +1,301 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package metrics 提供与具体监控系统无关的指标接口，包括计数器、仪表、直方图和
+// 计时器，指标可以带有标签，通过 Exporter 导出到 Prometheus、statsd、OTLP 等系统。
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Type 指标类型。
+type Type int
+
+const (
+	CounterType   = Type(iota) // 计数器
+	GaugeType                  // 仪表
+	HistogramType              // 直方图，计时器以秒为单位记录到直方图
+)
+
+func (t Type) String() string {
+	switch t {
+	case CounterType:
+		return "counter"
+	case GaugeType:
+		return "gauge"
+	default:
+		return "histogram"
+	}
+}
+
+// Counter 只增不减的计数器。
+type Counter interface {
+	Inc()
+	Add(delta float64)
+}
+
+// Gauge 可以任意设置的仪表。
+type Gauge interface {
+	Set(v float64)
+	Add(delta float64)
+}
+
+// Histogram 记录观测值分布的直方图。
+type Histogram interface {
+	Observe(v float64)
+}
+
+// Timer 记录耗时的计时器。
+type Timer interface {
+	Record(d time.Duration)
+	Since(start time.Time)
+}
+
+// DefaultBuckets 直方图默认的桶的上界，适用于以秒为单位的耗时。
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Sample 指标在某一时刻的值。
+type Sample struct {
+	Name    string            `json:"name"`
+	Type    Type              `json:"-"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Value   float64           `json:"value"`             // 计数器和仪表的值，直方图的观测值之和
+	Count   uint64            `json:"count,omitempty"`   // 直方图的观测次数
+	Buckets []float64         `json:"buckets,omitempty"` // 直方图的桶的上界
+	Counts  []uint64          `json:"counts,omitempty"`  // 直方图每个桶的累计观测次数
+}
+
+type meter struct {
+	name    string
+	typ     Type
+	tags    map[string]string
+	bits    uint64 // 计数器和仪表的值
+	mutex   sync.Mutex
+	sum     float64
+	count   uint64
+	buckets []float64
+	counts  []uint64
+}
+
+func (m *meter) Inc() {
+	m.Add(1)
+}
+
+func (m *meter) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&m.bits)
+		v := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&m.bits, old, v) {
+			return
+		}
+	}
+}
+
+func (m *meter) Set(v float64) {
+	atomic.StoreUint64(&m.bits, math.Float64bits(v))
+}
+
+func (m *meter) Observe(v float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sum += v
+	m.count++
+	for i, b := range m.buckets {
+		if v <= b {
+			m.counts[i]++
+		}
+	}
+}
+
+func (m *meter) Record(d time.Duration) {
+	m.Observe(d.Seconds())
+}
+
+func (m *meter) Since(start time.Time) {
+	m.Record(time.Since(start))
+}
+
+func (m *meter) sample() Sample {
+	s := Sample{Name: m.name, Type: m.typ, Tags: m.tags}
+	if m.typ != HistogramType {
+		s.Value = math.Float64frombits(atomic.LoadUint64(&m.bits))
+		return s
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s.Value = m.sum
+	s.Count = m.count
+	s.Buckets = m.buckets
+	s.Counts = append([]uint64(nil), m.counts...)
+	return s
+}
+
+// Registry 保存所有指标，相同名称和标签的指标只创建一次。
+type Registry struct {
+	mutex   sync.RWMutex
+	meters  map[string]*meter
+	types   map[string]Type
+	buckets []float64
+}
+
+// NewRegistry 返回使用 buckets 作为直方图的桶的 Registry 对象，buckets 为空时
+// 使用 DefaultBuckets 。
+func NewRegistry(buckets ...float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sort.Float64s(buckets)
+	return &Registry{
+		meters:  make(map[string]*meter),
+		types:   make(map[string]Type),
+		buckets: buckets,
+	}
+}
+
+// toTags 把 k1, v1, k2, v2 形式的标签转换为 map 。
+func toTags(tags []string) map[string]string {
+	if len(tags)%2 != 0 {
+		panic(fmt.Errorf("metrics: odd number of tags %v", tags))
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(tags)/2)
+	for i := 0; i < len(tags); i += 2 {
+		m[tags[i]] = tags[i+1]
+	}
+	return m
+}
+
+func meterKey(name string, tags map[string]string) string {
+	var keys []string
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteString("|" + k + "=" + tags[k])
+	}
+	return b.String()
+}
+
+// meter 返回名称为 name 标签为 tags 的指标，不存在时创建，同名指标的类型不同
+// 时 panic 。
+func (r *Registry) meter(typ Type, name string, tags []string) *meter {
+	m := toTags(tags)
+	key := meterKey(name, m)
+
+	r.mutex.RLock()
+	v, ok := r.meters[key]
+	r.mutex.RUnlock()
+	if ok && v.typ == typ {
+		return v
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if t, ok := r.types[name]; ok && t != typ {
+		panic(fmt.Errorf("metrics: %s is a %s, not a %s", name, t, typ))
+	}
+	if v, ok = r.meters[key]; ok {
+		return v
+	}
+	v = &meter{name: name, typ: typ, tags: m}
+	if typ == HistogramType {
+		v.buckets = r.buckets
+		v.counts = make([]uint64, len(r.buckets))
+	}
+	r.meters[key] = v
+	r.types[name] = typ
+	return v
+}
+
+// Counter 返回名称为 name 的计数器，tags 为 k1, v1, k2, v2 形式的标签。
+func (r *Registry) Counter(name string, tags ...string) Counter {
+	return r.meter(CounterType, name, tags)
+}
+
+// Gauge 返回名称为 name 的仪表，tags 为 k1, v1, k2, v2 形式的标签。
+func (r *Registry) Gauge(name string, tags ...string) Gauge {
+	return r.meter(GaugeType, name, tags)
+}
+
+// Histogram 返回名称为 name 的直方图，tags 为 k1, v1, k2, v2 形式的标签。
+func (r *Registry) Histogram(name string, tags ...string) Histogram {
+	return r.meter(HistogramType, name, tags)
+}
+
+// Timer 返回名称为 name 的计时器，tags 为 k1, v1, k2, v2 形式的标签。
+func (r *Registry) Timer(name string, tags ...string) Timer {
+	return r.meter(HistogramType, name, tags)
+}
+
+// Snapshot 返回所有指标当前的值，按照名称和标签排序。
+func (r *Registry) Snapshot() []Sample {
+	r.mutex.RLock()
+	var keys []string
+	for k := range r.meters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	meters := make([]*meter, len(keys))
+	for i, k := range keys {
+		meters[i] = r.meters[k]
+	}
+	r.mutex.RUnlock()
+
+	samples := make([]Sample, len(meters))
+	for i, m := range meters {
+		samples[i] = m.sample()
+	}
+	return samples
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default 返回框架内部使用的 Registry 对象。
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// NewCounter 返回默认 Registry 中名称为 name 的计数器。
+func NewCounter(name string, tags ...string) Counter {
+	return defaultRegistry.Counter(name, tags...)
+}
+
+// NewGauge 返回默认 Registry 中名称为 name 的仪表。
+func NewGauge(name string, tags ...string) Gauge {
+	return defaultRegistry.Gauge(name, tags...)
+}
+
+// NewHistogram 返回默认 Registry 中名称为 name 的直方图。
+func NewHistogram(name string, tags ...string) Histogram {
+	return defaultRegistry.Histogram(name, tags...)
+}
+
+// NewTimer 返回默认 Registry 中名称为 name 的计时器。
+func NewTimer(name string, tags ...string) Timer {
+	return defaultRegistry.Timer(name, tags...)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics_test
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/metrics"
+)
+
+func TestRegistry(t *testing.T) {
+
+	r := metrics.NewRegistry(1, 0.1)
+	r.Counter("requests", "method", "GET").Inc()
+	r.Counter("requests", "method", "GET").Add(2)
+	r.Counter("requests", "method", "POST").Inc()
+	r.Gauge("pool.size").Set(5)
+	r.Gauge("pool.size").Add(-1)
+	r.Histogram("size").Observe(0.05)
+	r.Timer("size").Record(500 * time.Millisecond)
+	r.Timer("size").Since(time.Now().Add(-2 * time.Second))
+
+	assert.Panic(t, func() { r.Gauge("requests") }, "metrics: requests is a counter, not a gauge")
+	assert.Panic(t, func() { r.Counter("requests", "method") }, "metrics: odd number of tags \\[method\\]")
+
+	samples := r.Snapshot()
+	assert.Equal(t, len(samples), 4)
+	assert.Equal(t, samples[0].Name, "pool.size")
+	assert.Equal(t, samples[0].Value, float64(4))
+	assert.Equal(t, samples[1].Tags, map[string]string{"method": "GET"})
+	assert.Equal(t, samples[1].Value, float64(3))
+	assert.Equal(t, samples[2].Value, float64(1))
+	assert.Equal(t, samples[3].Count, uint64(3))
+	assert.Equal(t, samples[3].Buckets, []float64{0.1, 1})
+	assert.Equal(t, samples[3].Counts, []uint64{1, 2})
+}
+
+func TestWritePrometheus(t *testing.T) {
+
+	r := metrics.NewRegistry(0.5)
+	r.Counter("http.requests", "uri", `/a"b`).Inc()
+	r.Timer("http.latency").Record(time.Second)
+
+	var buf bytes.Buffer
+	err := metrics.WritePrometheus(&buf, r.Snapshot())
+	assert.Nil(t, err)
+	assert.Equal(t, buf.String(), strings.Join([]string{
+		`# TYPE http_latency histogram`,
+		`http_latency_bucket{le="0.5"} 0`,
+		`http_latency_bucket{le="+Inf"} 1`,
+		`http_latency_sum 1`,
+		`http_latency_count 1`,
+		`# TYPE http_requests counter`,
+		`http_requests{uri="/a\"b"} 1`,
+		``,
+	}, "\n"))
+}
+
+func TestStatsdExporter(t *testing.T) {
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	e, err := metrics.NewStatsdExporter(conn.LocalAddr().String(), "app.")
+	assert.Nil(t, err)
+	defer e.Close()
+
+	read := func() string {
+		b := make([]byte, 2048)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(b)
+		assert.Nil(t, err)
+		return string(b[:n])
+	}
+
+	r := metrics.NewRegistry()
+	c := r.Counter("jobs", "queue", "a")
+	c.Add(3)
+	r.Gauge("workers").Set(2)
+	r.Timer("latency").Record(time.Second)
+
+	assert.Nil(t, e.Export(r.Snapshot()))
+	assert.Equal(t, read(), "app.jobs:3|c|#queue:a\napp.latency.count:1|c\napp.latency.sum:1|c\napp.workers:2|g")
+
+	c.Inc()
+	assert.Nil(t, e.Export(r.Snapshot()))
+	assert.Equal(t, read(), "app.jobs:1|c|#queue:a\napp.latency.count:0|c\napp.latency.sum:0|c\napp.workers:2|g")
+}
+
+func TestLogOutput(t *testing.T) {
+
+	var n int
+	output := metrics.LogOutput(func(level log.Level, e *log.Entry) { n++ })
+	output(log.ErrorLevel, &log.Entry{})
+	output(log.ErrorLevel, &log.Entry{})
+	output(log.InfoLevel, &log.Entry{})
+	assert.Equal(t, n, 3)
+
+	var errors, infos float64
+	for _, s := range metrics.Default().Snapshot() {
+		if s.Name != "log.events" {
+			continue
+		}
+		switch s.Tags["level"] {
+		case "error":
+			errors = s.Value
+		case "info":
+			infos = s.Value
+		}
+	}
+	assert.True(t, errors >= 2 && infos >= 1)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType Prometheus 文本格式的 Content-Type 。
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusName 把指标名称转换为 Prometheus 支持的名称，非法字符替换为下划线。
+func prometheusName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// labels 返回 {k1="v1",k2="v2"} 形式的标签，extra 为额外的标签。
+func labels(tags map[string]string, extra ...string) string {
+	var keys []string
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var ss []string
+	for _, k := range keys {
+		ss = append(ss, prometheusName(k)+`="`+labelReplacer.Replace(tags[k])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		ss = append(ss, extra[i]+`="`+extra[i+1]+`"`)
+	}
+	if len(ss) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(ss, ",") + "}"
+}
+
+// WritePrometheus 以 Prometheus 文本格式输出指标。
+func WritePrometheus(w io.Writer, samples []Sample) error {
+	b := bufio.NewWriter(w)
+	var last string
+	for _, s := range samples {
+		name := prometheusName(s.Name)
+		if name != last {
+			b.WriteString("# TYPE " + name + " " + s.Type.String() + "\n")
+			last = name
+		}
+		if s.Type != HistogramType {
+			b.WriteString(name + labels(s.Tags) + " " + formatFloat(s.Value) + "\n")
+			continue
+		}
+		for i, bound := range s.Buckets {
+			b.WriteString(name + "_bucket" + labels(s.Tags, "le", formatFloat(bound)) + " " + strconv.FormatUint(s.Counts[i], 10) + "\n")
+		}
+		b.WriteString(name + "_bucket" + labels(s.Tags, "le", "+Inf") + " " + strconv.FormatUint(s.Count, 10) + "\n")
+		b.WriteString(name + "_sum" + labels(s.Tags) + " " + formatFloat(s.Value) + "\n")
+		b.WriteString(name + "_count" + labels(s.Tags) + " " + strconv.FormatUint(s.Count, 10) + "\n")
+	}
+	return b.Flush()
+}
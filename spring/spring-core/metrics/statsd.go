@@ -0,0 +1,116 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"strconv"
+)
+
+// maxPacketSize 每个 UDP 包的最大长度。
+const maxPacketSize = 1432
+
+// StatsdExporter 以 DogStatsD 格式通过 UDP 导出指标，计数器和直方图导出两次
+// 导出之间的增量，直方图导出为 <name>.count 和 <name>.sum 两个计数器。
+type StatsdExporter struct {
+	conn   net.Conn
+	prefix string
+	last   map[string]float64
+}
+
+// NewStatsdExporter 返回向 address 发送指标的 StatsdExporter 对象，prefix 为
+// 指标名称的前缀。
+func NewStatsdExporter(address string, prefix string) (*StatsdExporter, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsdExporter{conn: conn, prefix: prefix, last: make(map[string]float64)}, nil
+}
+
+// delta 返回指标相对于上一次导出的增量。
+func (e *StatsdExporter) delta(key string, v float64) float64 {
+	d := v - e.last[key]
+	e.last[key] = v
+	return d
+}
+
+func statsdTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	var keys []string
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	b.WriteString("|#")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k + ":" + tags[k])
+	}
+	return b.String()
+}
+
+func (e *StatsdExporter) Export(samples []Sample) error {
+	var lines []string
+	for _, s := range samples {
+		name := e.prefix + s.Name
+		tags := statsdTags(s.Tags)
+		key := meterKey(s.Name, s.Tags)
+		switch s.Type {
+		case CounterType:
+			lines = append(lines, name+":"+formatFloat(e.delta(key, s.Value))+"|c"+tags)
+		case GaugeType:
+			lines = append(lines, name+":"+formatFloat(s.Value)+"|g"+tags)
+		default:
+			count := e.delta(key+"|count", float64(s.Count))
+			sum := e.delta(key+"|sum", s.Value)
+			lines = append(lines, name+".count:"+strconv.FormatFloat(count, 'f', -1, 64)+"|c"+tags)
+			lines = append(lines, name+".sum:"+formatFloat(sum)+"|c"+tags)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		if buf.Len() > 0 && buf.Len()+len(line)+1 > maxPacketSize {
+			if _, err := e.conn.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(line)
+	}
+	if buf.Len() > 0 {
+		_, err := e.conn.Write(buf.Bytes())
+		return err
+	}
+	return nil
+}
+
+// Close 关闭 UDP 连接。
+func (e *StatsdExporter) Close() error {
+	return e.conn.Close()
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mq
+
+import (
+	"context"
+	"sync"
+)
+
+// Publisher 消息发布者，业务代码应该依赖该接口而不是具体的 MQ 实现。
+type Publisher = Producer
+
+// Subscriber 消息订阅者，订阅需要在应用启动之前完成。
+type Subscriber interface {
+	Subscribe(c Consumer) error
+}
+
+// MemoryBus 进程内的消息总线，同时实现了 Publisher 和 Subscriber 接口，消息
+// 同步分发给订阅该主题的所有消费者，适合测试和单实例部署。
+type MemoryBus struct {
+	mutex     sync.RWMutex
+	consumers map[string][]Consumer
+}
+
+// NewMemoryBus 返回空的进程内消息总线。
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{consumers: make(map[string][]Consumer)}
+}
+
+func (b *MemoryBus) Subscribe(c Consumer) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, topic := range c.Topics() {
+		b.consumers[topic] = append(b.consumers[topic], c)
+	}
+	return nil
+}
+
+// SendMessage 把消息分发给订阅该主题的所有消费者，返回第一个消费错误。
+func (b *MemoryBus) SendMessage(ctx context.Context, msg Message) error {
+	return Send(ctx, msg, func(ctx context.Context) error {
+		b.mutex.RLock()
+		consumers := b.consumers[msg.Topic()]
+		b.mutex.RUnlock()
+		var ret error
+		for _, c := range consumers {
+			if err := Consume(ctx, c, msg); err != nil && ret == nil {
+				ret = err
+			}
+		}
+		return ret
+	})
+}
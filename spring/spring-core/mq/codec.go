@@ -0,0 +1,85 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mq
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// CodecKey 消息额外信息中记录编码方式的 key ，没有记录时使用 json 编码。
+const CodecKey = "codec"
+
+// Codec 消息内容的编解码器。
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+var codecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{m: map[string]Codec{"json": jsonCodec{}}}
+
+// RegisterCodec 注册名为 name 的编解码器，例如 Avro 编解码器，同名的编解码器
+// 会被覆盖。
+func RegisterCodec(name string, c Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.m[name] = c
+}
+
+// GetCodec 返回名为 name 的编解码器，name 为空时返回 json 编解码器。
+func GetCodec(name string) (Codec, error) {
+	if name == "" {
+		name = "json"
+	}
+	codecs.RLock()
+	defer codecs.RUnlock()
+	if c, ok := codecs.m[name]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("codec %q not found", name)
+}
+
+// Encode 使用名为 codec 的编解码器创建主题为 topic 的消息。
+func Encode(topic string, codec string, v interface{}) (*message, error) {
+	c, err := GetCodec(codec)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	msg := NewMessage().WithTopic(topic).WithBody(body)
+	if codec != "" {
+		msg.WithExtra(CodecKey, codec)
+	}
+	return msg, nil
+}
@@ -18,7 +18,6 @@ package mq
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"reflect"
 
@@ -48,16 +47,17 @@ func (c *consumer) Topics() []string {
 }
 
 func (c *consumer) Consume(ctx context.Context, msg Message) error {
-	e := reflect.New(c.e.Elem())
-	err := json.Unmarshal(msg.Body(), e.Interface())
+	codec, err := GetCodec(msg.Extra()[CodecKey])
 	if err != nil {
 		return err
 	}
-	out := c.v.Call([]reflect.Value{reflect.ValueOf(ctx), e})
-	if err = out[0].Interface().(error); err != nil {
+	e := reflect.New(c.e.Elem())
+	if err = codec.Unmarshal(msg.Body(), e.Interface()); err != nil {
 		return err
 	}
-	return nil
+	out := c.v.Call([]reflect.Value{reflect.ValueOf(ctx), e})
+	err, _ = out[0].Interface().(error)
+	return err
 }
 
 func validBindFn(t reflect.Type) bool {
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mq
+
+import "context"
+
+// Hook 消息收发的钩子，可用于链路追踪和指标统计。
+type Hook struct {
+
+	// SendFunc 包装消息的发送过程，可以通过 msg.Extra() 向消息添加需要传播的
+	// 字段，然后使用新的 ctx 调用 send 函数发送消息。
+	SendFunc func(ctx context.Context, msg Message, send func(ctx context.Context) error) error
+
+	// ConsumeFunc 包装消息的消费过程，使用新的 ctx 调用 consume 函数消费消息。
+	ConsumeFunc func(ctx context.Context, msg Message, consume func(ctx context.Context) error) error
+}
+
+var hook Hook
+
+// SetHook 设置钩子方法。
+func SetHook(h Hook) {
+	hook = h
+}
+
+// Send 使用 send 函数发送消息，设置了钩子时由钩子包装发送过程，Producer 的实现
+// 应该在 send 函数中读取 msg.Extra() 。
+func Send(ctx context.Context, msg Message, send func(ctx context.Context) error) error {
+	if hook.SendFunc == nil {
+		return send(ctx)
+	}
+	return hook.SendFunc(ctx, msg, send)
+}
+
+// Consume 使用消费者 c 消费消息，设置了钩子时由钩子包装消费过程，消息驱动应该
+// 通过该函数把消息分发给消费者。
+func Consume(ctx context.Context, c Consumer, msg Message) error {
+	if hook.ConsumeFunc == nil {
+		return c.Consume(ctx, msg)
+	}
+	return hook.ConsumeFunc(ctx, msg, func(ctx context.Context) error {
+		return c.Consume(ctx, msg)
+	})
+}
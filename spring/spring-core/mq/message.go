@@ -33,7 +33,7 @@ type message struct {
 
 // NewMessage 创建新的消息对象。
 func NewMessage() *message {
-	return &message{}
+	return &message{extra: make(map[string]string)}
 }
 
 // Topic 返回消息的主题。
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mq_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/mq"
+)
+
+type order struct {
+	ID int `json:"id"`
+}
+
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte("ORDER"), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v interface{}) error {
+	v.(*order).ID = len(data)
+	return nil
+}
+
+type producer struct {
+	msgs []mq.Message
+}
+
+func (p *producer) SendMessage(ctx context.Context, msg mq.Message) error {
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func TestCodec(t *testing.T) {
+
+	_, err := mq.Encode("order", "avro", &order{})
+	assert.Error(t, err, "codec \"avro\" not found")
+
+	var ids []int
+	c := mq.Bind(func(ctx context.Context, o *order) error {
+		ids = append(ids, o.ID)
+		return nil
+	}, "order")
+
+	msg, err := mq.Encode("order", "", &order{ID: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, string(msg.Body()), `{"id":3}`)
+	assert.Nil(t, c.Consume(context.Background(), msg))
+
+	mq.RegisterCodec("upper", upperCodec{})
+	msg, err = mq.Encode("order", "upper", &order{})
+	assert.Nil(t, err)
+	assert.Equal(t, msg.Extra(), map[string]string{mq.CodecKey: "upper"})
+	assert.Nil(t, c.Consume(context.Background(), msg))
+
+	assert.Equal(t, ids, []int{3, 5})
+}
+
+func TestWithRetry(t *testing.T) {
+
+	n := 0
+	c := mq.Bind(func(ctx context.Context, o *order) error {
+		if n++; n < 3 {
+			return errors.New("failed")
+		}
+		return nil
+	}, "order")
+
+	msg := mq.NewMessage().WithTopic("order").WithID("1").WithBody([]byte(`{"id":1}`))
+
+	p := &producer{}
+	err := mq.WithRetry(c, mq.RetryPolicy{MaxAttempts: 3}, p).Consume(context.Background(), msg)
+	assert.Nil(t, err)
+	assert.Equal(t, n, 3)
+
+	n = 0
+	err = mq.WithRetry(c, mq.RetryPolicy{MaxAttempts: 2}, nil).Consume(context.Background(), msg)
+	assert.Error(t, err, "failed")
+
+	n = 0
+	policy := mq.RetryPolicy{MaxAttempts: 2, DeadLetter: "order.DLT"}
+	err = mq.WithRetry(c, policy, p).Consume(context.Background(), msg)
+	assert.Nil(t, err)
+	assert.Equal(t, len(p.msgs), 1)
+	assert.Equal(t, p.msgs[0].Topic(), "order.DLT")
+	assert.Equal(t, p.msgs[0].ID(), "1")
+	assert.Equal(t, p.msgs[0].Extra(), map[string]string{mq.ErrorKey: "failed"})
+}
+
+func TestMemoryBus(t *testing.T) {
+
+	var got []int
+	c := mq.Bind(func(ctx context.Context, o *order) error {
+		got = append(got, o.ID)
+		if o.ID < 0 {
+			return errors.New("invalid id")
+		}
+		return nil
+	}, "order", "order.retry")
+
+	bus := mq.NewMemoryBus()
+	assert.Nil(t, bus.Subscribe(c))
+
+	var p mq.Publisher = bus
+	for _, topic := range []string{"order", "order.retry", "user"} {
+		msg, err := mq.Encode(topic, "", &order{ID: len(topic)})
+		assert.Nil(t, err)
+		assert.Nil(t, p.SendMessage(context.Background(), msg))
+	}
+	assert.Equal(t, got, []int{5, 11})
+
+	msg, _ := mq.Encode("order", "", &order{ID: -1})
+	assert.Error(t, p.SendMessage(context.Background(), msg), "invalid id")
+}
+
+func TestHook(t *testing.T) {
+
+	var calls []string
+	mq.SetHook(mq.Hook{
+		SendFunc: func(ctx context.Context, msg mq.Message, send func(ctx context.Context) error) error {
+			calls = append(calls, "send:"+msg.Topic())
+			msg.Extra()["trace"] = "abc"
+			return send(ctx)
+		},
+		ConsumeFunc: func(ctx context.Context, msg mq.Message, consume func(ctx context.Context) error) error {
+			calls = append(calls, "consume:"+msg.Extra()["trace"])
+			return consume(ctx)
+		},
+	})
+	defer mq.SetHook(mq.Hook{})
+
+	bus := mq.NewMemoryBus()
+	c := mq.Bind(func(ctx context.Context, o *order) error {
+		calls = append(calls, "order:"+strconv.Itoa(o.ID))
+		return nil
+	}, "order")
+	assert.Nil(t, bus.Subscribe(c))
+
+	msg, err := mq.Encode("order", "", &order{ID: 1})
+	assert.Nil(t, err)
+	assert.Nil(t, bus.SendMessage(context.Background(), msg))
+	assert.Equal(t, calls, []string{"send:order", "consume:abc", "order:1"})
+}
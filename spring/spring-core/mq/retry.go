@@ -0,0 +1,73 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mq
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+// ErrorKey 死信消息的额外信息中记录消费失败原因的 key 。
+const ErrorKey = "error"
+
+// RetryPolicy 消费失败时的重试策略。
+type RetryPolicy struct {
+	MaxAttempts int           // 最大消费次数，小于 1 时按 1 处理
+	Backoff     time.Duration // 两次消费之间的间隔
+	DeadLetter  string        // 死信主题，为空时丢弃消费失败的消息
+}
+
+type retryConsumer struct {
+	Consumer
+	policy   RetryPolicy
+	producer Producer
+}
+
+// WithRetry 返回按照 policy 重试的消费者，重试全部失败后使用 producer 把消息
+// 发送到死信主题，发送成功时不再返回消费错误。
+func WithRetry(c Consumer, policy RetryPolicy, producer Producer) Consumer {
+	return &retryConsumer{Consumer: c, policy: policy, producer: producer}
+}
+
+func (c *retryConsumer) Consume(ctx context.Context, msg Message) error {
+	var err error
+	for i := 0; ; i++ {
+		if err = c.Consumer.Consume(ctx, msg); err == nil {
+			return nil
+		}
+		if i+1 >= c.policy.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.policy.Backoff):
+		}
+	}
+	if c.policy.DeadLetter == "" || c.producer == nil {
+		return err
+	}
+	log.Warnf("send message of %s to %s: %v", msg.Topic(), c.policy.DeadLetter, err)
+	dead := NewMessage().WithTopic(c.policy.DeadLetter).WithID(msg.ID()).WithBody(msg.Body())
+	for k, v := range msg.Extra() {
+		dead.WithExtra(k, v)
+	}
+	dead.WithExtra(ErrorKey, err.Error())
+	return c.producer.SendMessage(ctx, dead)
+}
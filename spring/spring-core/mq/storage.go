@@ -0,0 +1,44 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mq
+
+import (
+	"context"
+
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/fastdev/json"
+)
+
+// RecordStorage 将录制数据以 JSON 格式发送到消息队列，消息的 ID 是会话 ID 。
+type RecordStorage struct {
+	producer Producer
+	topic    string
+}
+
+// NewRecordStorage 返回新的 RecordStorage 对象。
+func NewRecordStorage(producer Producer, topic string) *RecordStorage {
+	return &RecordStorage{producer: producer, topic: topic}
+}
+
+func (s *RecordStorage) Save(session *fastdev.Session) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	msg := NewMessage().WithTopic(s.topic).WithID(session.Session).WithBody(b)
+	return s.producer.SendMessage(context.Background(), msg)
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metrics Redis 命令的执行统计，不包括回放模式下的命令。
+type Metrics struct {
+	Commands int64         `json:"commands"` // 执行的命令数
+	Errors   int64         `json:"errors"`   // 执行失败的命令数，不包括 ErrNil
+	Latency  time.Duration `json:"latency"`  // 命令的累计耗时
+}
+
+var metrics struct {
+	commands int64
+	errors   int64
+	latency  int64
+}
+
+// GetMetrics 返回 Redis 命令的执行统计。
+func GetMetrics() Metrics {
+	return Metrics{
+		Commands: atomic.LoadInt64(&metrics.commands),
+		Errors:   atomic.LoadInt64(&metrics.errors),
+		Latency:  time.Duration(atomic.LoadInt64(&metrics.latency)),
+	}
+}
+
+// recordMetrics 记录一次命令的执行结果。
+func recordMetrics(start time.Time, err error) {
+	atomic.AddInt64(&metrics.commands, 1)
+	atomic.AddInt64(&metrics.latency, int64(time.Since(start)))
+	if err != nil && err != ErrNil {
+		atomic.AddInt64(&metrics.errors, 1)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-spring/spring-base/cast"
 	"github.com/go-spring/spring-base/fastdev"
@@ -114,12 +115,12 @@ type transform func(interface{}, error) (interface{}, error)
 func (c *BaseClient) do(ctx context.Context, args []interface{}, trans transform) (ret interface{}, err error) {
 
 	var timeNow int64
-	if fastdev.RecordMode() {
+	if fastdev.Recording(ctx) {
 		timeNow = util.Now(ctx).UnixNano()
 	}
 
 	defer func() {
-		if fastdev.RecordMode() {
+		if fastdev.Recording(ctx) {
 			var resp interface{}
 			if err == nil {
 				resp = ret
@@ -133,6 +134,7 @@ func (c *BaseClient) do(ctx context.Context, args []interface{}, trans transform
 				Request:   cmdString(args),
 				Response:  resp,
 				Timestamp: timeNow,
+				Latency:   util.Now(ctx).UnixNano() - timeNow,
 			})
 		}
 	}()
@@ -176,6 +178,9 @@ func (c *BaseClient) do(ctx context.Context, args []interface{}, trans transform
 		return action.Response, nil
 	}
 
+	start := time.Now()
+	defer func() { recordMetrics(start, err) }()
+
 	if trans == nil {
 		return c.DoFunc(ctx, args...)
 	}
@@ -17,6 +17,8 @@
 package redis
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/go-spring/spring-base/assert"
@@ -58,3 +60,21 @@ func TestCmdString(t *testing.T) {
 		assert.Equal(t, cmdString(testcase.cmd), testcase.val)
 	}
 }
+
+func TestMetrics(t *testing.T) {
+
+	results := []error{nil, ErrNil, errors.New("conn refused")}
+	c := &BaseClient{DoFunc: func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		err := results[0]
+		results = results[1:]
+		return "OK", err
+	}}
+
+	before := GetMetrics()
+	for i := 0; i < 3; i++ {
+		_, _ = c.String(context.Background(), "PING")
+	}
+	after := GetMetrics()
+	assert.Equal(t, after.Commands-before.Commands, int64(3))
+	assert.Equal(t, after.Errors-before.Errors, int64(1))
+}
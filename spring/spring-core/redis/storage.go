@@ -0,0 +1,55 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"context"
+
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/fastdev/json"
+)
+
+// RecordStorage 将录制数据以 JSON 格式保存到 Redis 列表中，新数据在列表头部。
+type RecordStorage struct {
+	client Client
+	key    string
+	maxLen int64
+}
+
+// NewRecordStorage 返回新的 RecordStorage 对象，maxLen 是列表保留的最大长度，
+// 0 表示不限制。
+func NewRecordStorage(client Client, key string, maxLen int64) *RecordStorage {
+	if key == "" {
+		key = "fastdev:record"
+	}
+	return &RecordStorage{client: client, key: key, maxLen: maxLen}
+}
+
+func (s *RecordStorage) Save(session *fastdev.Session) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if _, err = s.client.LPush(ctx, s.key, string(b)); err != nil {
+		return err
+	}
+	if s.maxLen > 0 {
+		_, err = s.client.LTrim(ctx, s.key, 0, s.maxLen-1)
+	}
+	return err
+}
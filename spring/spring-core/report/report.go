@@ -0,0 +1,185 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package report 提供错误上报的抽象，Web 处理函数的 panic 、ERROR 级别的日志以及
+// 后台 goroutine 的 panic 都可以通过 Register 注册的 Reporter 上报到 Sentry 等错
+// 误追踪服务。
+package report
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/web"
+)
+
+// Tag 错误上报相关的日志使用的标签，带有该标签的日志不会被再次上报。
+const Tag = web.ReportedTag
+
+// 事件的级别。
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// maxStackSize 捕获的调用栈的最大字节数。
+const maxStackSize = 8 << 10
+
+// Event 一次需要上报的错误。
+type Event struct {
+	Level   string            // 级别，error 或者 fatal
+	Message string            // 错误信息
+	Value   interface{}       // panic 抛出的值或者 error 对象，可能为空
+	Stack   []byte            // 调用栈，可能为空
+	Logger  string            // 日志输出的 logger 或者事件的来源
+	Tags    map[string]string // 附加的标签，例如请求 ID 、请求路径
+	Time    time.Time         // 发生的时间
+}
+
+// Reporter 错误上报接口。Report 在发生错误的 goroutine 中同步执行，耗时的操作
+// 应该异步完成，并且不能输出 ERROR 级别的日志，除非日志带有 Tag 标签。
+type Reporter interface {
+	Report(ctx context.Context, e *Event)
+}
+
+var (
+	mutex     sync.RWMutex
+	reporters []Reporter
+)
+
+// Register 注册错误上报器。
+func Register(r Reporter) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	reporters = append(reporters, r)
+}
+
+// Unregister 取消注册错误上报器。
+func Unregister(r Reporter) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, v := range reporters {
+		if v == r {
+			reporters = append(reporters[:i:i], reporters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Enabled 返回是否注册了错误上报器。
+func Enabled() bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return len(reporters) > 0
+}
+
+// Report 将 e 发送给所有注册的错误上报器，上报器的 panic 会被忽略。
+func Report(ctx context.Context, e *Event) {
+	mutex.RLock()
+	rs := reporters
+	mutex.RUnlock()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	for _, r := range rs {
+		func() {
+			defer func() {
+				if x := recover(); x != nil {
+					log.Ctx(ctx).Tag(Tag).Errorf("report error: %v", x)
+				}
+			}()
+			r.Report(ctx, e)
+		}()
+	}
+}
+
+// stack 返回当前 goroutine 的调用栈。
+func stack() []byte {
+	b := make([]byte, maxStackSize)
+	return b[:runtime.Stack(b, false)]
+}
+
+// LogOutput 返回上报 ERROR 级别日志的日志输出，日志仍然由 next 输出。PANIC 和
+// FATAL 级别的日志一般由 panic 恢复的地方输出，已经通过其他方式上报，因此不会被
+// 重复上报。
+func LogOutput(next log.Output) log.Output {
+	return func(level log.Level, e *log.Entry) {
+		next(level, e)
+		if level != log.ErrorLevel || e.GetTag() == Tag || !Enabled() {
+			return
+		}
+		ctx := e.GetCtx()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		Report(ctx, &Event{
+			Level:   LevelError,
+			Message: e.GetMsg(),
+			Logger:  e.GetLogger(),
+			Tags:    map[string]string{"file": fmt.Sprintf("%s:%d", e.GetFile(), e.GetLine())},
+			Time:    e.GetTime(),
+		})
+	}
+}
+
+// PanicHandler 返回上报 panic 的处理函数，用于 gs.GoPanicHandler ，上报之后继续
+// 调用 next 。需要在 recover 所在的 goroutine 中调用，以便捕获 panic 的调用栈。
+func PanicHandler(next func(r interface{})) func(r interface{}) {
+	return func(r interface{}) {
+		if Enabled() {
+			Report(context.Background(), &Event{
+				Level:   LevelFatal,
+				Message: fmt.Sprint(r),
+				Value:   r,
+				Stack:   stack(),
+				Logger:  "goroutine",
+			})
+		}
+		next(r)
+	}
+}
+
+// webReporter 上报 Web 处理函数的 panic 。
+type webReporter struct{}
+
+// WebReporter 返回上报 Web 处理函数 panic 的 web.PanicReporter 对象，可以注册
+// 为 bean 供 web.RecoveryFilter 使用。
+func WebReporter() web.PanicReporter {
+	return webReporter{}
+}
+
+func (webReporter) ReportPanic(ctx context.Context, info *web.PanicInfo) {
+	tags := map[string]string{
+		"http.method": info.Method,
+		"http.path":   info.Path,
+	}
+	if info.RequestID != "" {
+		tags["request_id"] = info.RequestID
+	}
+	Report(ctx, &Event{
+		Level:   LevelFatal,
+		Message: fmt.Sprint(info.Value),
+		Value:   info.Value,
+		Stack:   info.Stack,
+		Logger:  "web",
+		Tags:    tags,
+		Time:    info.Time,
+	})
+}
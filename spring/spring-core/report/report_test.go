@@ -0,0 +1,154 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/report"
+	"github.com/go-spring/spring-core/web"
+)
+
+type reporter struct {
+	mutex  sync.Mutex
+	events []*report.Event
+}
+
+func (r *reporter) Report(ctx context.Context, e *report.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *reporter) take() []*report.Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+type brokenReporter struct{}
+
+func (brokenReporter) Report(ctx context.Context, e *report.Event) {
+	panic("broken")
+}
+
+func TestReport(t *testing.T) {
+
+	r := new(reporter)
+	assert.False(t, report.Enabled())
+	report.Register(brokenReporter{})
+	report.Register(r)
+	defer report.Unregister(brokenReporter{})
+	defer report.Unregister(r)
+	assert.True(t, report.Enabled())
+
+	t.Run("log", func(t *testing.T) {
+		defer log.Reset()
+		var outputs []string
+		log.SetOutput(report.LogOutput(func(level log.Level, e *log.Entry) {
+			outputs = append(outputs, e.GetMsg())
+		}))
+		log.Warn("warn")
+		log.Error("db error")
+		log.Panic("panic")
+		log.Tag(report.Tag).Error("report error")
+		log.Ctx(log.WithRequestID(context.Background(), "req-1")).Errorf("%d", 42)
+		assert.Equal(t, outputs, []string{
+			"warn",
+			"db error", "report error: broken",
+			"panic",
+			"report error",
+			"42", "report error: broken",
+		})
+		events := r.take()
+		assert.Equal(t, len(events), 2)
+		assert.Equal(t, events[0].Level, report.LevelError)
+		assert.Equal(t, events[0].Message, "db error")
+		assert.True(t, strings.Contains(events[0].Tags["file"], "report_test.go:"))
+		assert.Equal(t, events[1].Message, "42")
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		var handled interface{}
+		handler := report.PanicHandler(func(r interface{}) { handled = r })
+		func() {
+			defer func() { handler(recover()) }()
+			panic("boom")
+		}()
+		assert.Equal(t, handled, "boom")
+		events := r.take()
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].Level, report.LevelFatal)
+		assert.Equal(t, events[0].Value, "boom")
+		assert.True(t, strings.Contains(string(events[0].Stack), "report_test.go"))
+		assert.False(t, events[0].Time.IsZero())
+	})
+
+	t.Run("web", func(t *testing.T) {
+		router := web.NewRouter()
+		router.GetMapping("/panic", func(ctx web.Context) { panic(errors.New("boom")) })
+		recovery := web.RecoveryFilter(0, report.WebReporter())
+		h := web.ToHTTPHandler(router, web.RequestIDFilter(""), recovery)
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		req.Header.Set(web.HeaderXRequestID, "req-2")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, http.StatusInternalServerError)
+		events := r.take()
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].Message, "boom")
+		assert.Equal(t, events[0].Logger, "web")
+		assert.Equal(t, events[0].Tags, map[string]string{
+			"http.method": "GET",
+			"http.path":   "/panic",
+			"request_id":  "req-2",
+		})
+	})
+
+	t.Run("web log", func(t *testing.T) {
+		defer log.Reset()
+		var outputs []string
+		log.SetOutput(report.LogOutput(func(level log.Level, e *log.Entry) {
+			msg := strings.SplitN(e.GetMsg(), "\n", 2)[0]
+			outputs = append(outputs, level.String()+" "+msg)
+		}))
+		router := web.NewRouter()
+		router.GetMapping("/panic", func(ctx web.Context) { panic("boom") })
+		h := web.ToHTTPHandler(router, web.RecoveryFilter(0, report.WebReporter()))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+		assert.Equal(t, w.Code, http.StatusInternalServerError)
+		assert.Equal(t, outputs, []string{
+			"error panic recovered: GET /panic boom",
+			"error report error: broken",
+		})
+		events := r.take()
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].Level, report.LevelFatal)
+		assert.Equal(t, events[0].Logger, "web")
+	})
+}
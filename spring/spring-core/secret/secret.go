@@ -0,0 +1,206 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package secret 提供从 Vault 、AWS Secrets Manager 等密钥管理服务获取属性值
+// 的能力。密钥以 ${prefix:path#field} 的形式在属性中引用，在属性绑定时读取，
+// 读取的结果会被缓存，并且在租约到期前自动续期或者重新读取。
+package secret
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-base/log"
+)
+
+// Secret 从密钥管理服务读取的密钥。
+type Secret struct {
+	Data          map[string]string // 密钥的字段
+	LeaseID       string            // 租约 ID ，没有租约时为空
+	LeaseDuration time.Duration     // 租约的有效期，为 0 时使用缓存的默认有效期
+	Renewable     bool              // 租约是否可以续期
+}
+
+// Provider 密钥管理服务，path 不存在时返回 conf.ErrNotExist 。
+type Provider interface {
+	Read(ctx context.Context, path string) (*Secret, error)
+}
+
+// Renewer 支持租约续期的密钥管理服务，返回的 Secret 的 Data 为空时沿用旧值。
+type Renewer interface {
+	Renew(ctx context.Context, s *Secret) (*Secret, error)
+}
+
+type entry struct {
+	secret   *Secret
+	expireAt time.Time // 为零值时永不过期
+}
+
+// Cache 缓存从 Provider 读取的密钥。
+type Cache struct {
+	provider Provider
+	ttl      time.Duration // 没有租约的密钥的缓存时间，为 0 时永不过期
+	mutex    sync.Mutex
+	entries  map[string]*entry
+}
+
+// NewCache 返回新的 *Cache 对象，ttl 是没有租约的密钥的缓存时间，为 0 时永不过期。
+func NewCache(p Provider, ttl time.Duration) *Cache {
+	return &Cache{
+		provider: p,
+		ttl:      ttl,
+		entries:  make(map[string]*entry),
+	}
+}
+
+// Register 把 c 注册为前缀为 prefix 的属性值解析器。
+func Register(prefix string, c *Cache) {
+	conf.NewResolver(prefix, c.Resolve)
+}
+
+// Resolve 实现 conf.Resolver 接口。
+func (c *Cache) Resolve(ref string) (string, error) {
+	return c.Get(context.Background(), ref)
+}
+
+// Get 返回 path#field 形式的引用对应的密钥字段。省略 #field 时密钥必须只有
+// 一个字段，密钥或者字段不存在时返回 conf.ErrNotExist 。
+func (c *Cache) Get(ctx context.Context, ref string) (string, error) {
+
+	path, field := ref, ""
+	if i := strings.LastIndexByte(ref, '#'); i >= 0 {
+		path, field = ref[:i], ref[i+1:]
+	}
+
+	s, err := c.secret(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	if field == "" {
+		if len(s.Data) != 1 {
+			return "", fmt.Errorf("secret %s has %d fields, field required", path, len(s.Data))
+		}
+		for _, v := range s.Data {
+			return v, nil
+		}
+	}
+
+	v, ok := s.Data[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s field %s %w", path, field, conf.ErrNotExist)
+	}
+	return v, nil
+}
+
+func (c *Cache) secret(ctx context.Context, path string) (*Secret, error) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if e, ok := c.entries[path]; ok && !e.expired(time.Now()) {
+		return e.secret, nil
+	}
+
+	s, err := c.provider.Read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[path] = c.newEntry(s)
+	return s, nil
+}
+
+func (c *Cache) newEntry(s *Secret) *entry {
+	e := &entry{secret: s}
+	if d := c.duration(s); d > 0 {
+		e.expireAt = time.Now().Add(d)
+	}
+	return e
+}
+
+func (c *Cache) duration(s *Secret) time.Duration {
+	if s.LeaseDuration > 0 {
+		return s.LeaseDuration
+	}
+	return c.ttl
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// Renew 对剩余有效期不足三分之一的密钥进行续期，不能续期或者续期失败时重新读取。
+func (c *Cache) Renew(ctx context.Context) error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var lastErr error
+	now := time.Now()
+	for path, e := range c.entries {
+		if e.expireAt.IsZero() {
+			continue
+		}
+		if e.expireAt.Sub(now) > c.duration(e.secret)/3 {
+			continue
+		}
+		if err := c.renew(ctx, path, e); err != nil {
+			log.Errorf("renew secret %s error: %v", path, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (c *Cache) renew(ctx context.Context, path string, e *entry) error {
+
+	if r, ok := c.provider.(Renewer); ok && e.secret.Renewable && e.secret.LeaseID != "" {
+		s, err := r.Renew(ctx, e.secret)
+		if err == nil {
+			if s.Data == nil {
+				s.Data = e.secret.Data
+			}
+			c.entries[path] = c.newEntry(s)
+			return nil
+		}
+		log.Warnf("renew lease of secret %s error: %v", path, err)
+	}
+
+	s, err := c.provider.Read(ctx, path)
+	if err != nil {
+		return err
+	}
+	c.entries[path] = c.newEntry(s)
+	return nil
+}
+
+// Watch 每隔 interval 调用一次 Renew ，直到 ctx 结束。
+func (c *Cache) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.Renew(ctx)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-core/secret"
+)
+
+type provider struct {
+	reads  int
+	renews int
+	data   map[string]*secret.Secret
+	err    error
+}
+
+func (p *provider) Read(ctx context.Context, path string) (*secret.Secret, error) {
+	p.reads++
+	if p.err != nil {
+		return nil, p.err
+	}
+	s, ok := p.data[path]
+	if !ok {
+		return nil, conf.ErrNotExist
+	}
+	c := *s
+	return &c, nil
+}
+
+func (p *provider) Renew(ctx context.Context, s *secret.Secret) (*secret.Secret, error) {
+	p.renews++
+	return &secret.Secret{
+		LeaseID:       s.LeaseID,
+		LeaseDuration: s.LeaseDuration,
+		Renewable:     true,
+	}, nil
+}
+
+func TestCache_Get(t *testing.T) {
+
+	p := &provider{data: map[string]*secret.Secret{
+		"secret/db":    {Data: map[string]string{"user": "root", "password": "123456"}},
+		"secret/token": {Data: map[string]string{"value": "abc"}},
+	}}
+	c := secret.NewCache(p, 0)
+
+	v, err := c.Get(context.Background(), "secret/db#password")
+	assert.Nil(t, err)
+	assert.Equal(t, v, "123456")
+
+	v, err = c.Get(context.Background(), "secret/db#user")
+	assert.Nil(t, err)
+	assert.Equal(t, v, "root")
+	assert.Equal(t, p.reads, 1)
+
+	v, err = c.Get(context.Background(), "secret/token")
+	assert.Nil(t, err)
+	assert.Equal(t, v, "abc")
+
+	_, err = c.Get(context.Background(), "secret/db")
+	assert.Error(t, err, "secret secret/db has 2 fields, field required")
+
+	_, err = c.Get(context.Background(), "secret/db#host")
+	assert.True(t, errors.Is(err, conf.ErrNotExist))
+
+	_, err = c.Get(context.Background(), "secret/none#host")
+	assert.True(t, errors.Is(err, conf.ErrNotExist))
+}
+
+func TestCache_Resolver(t *testing.T) {
+
+	p := &provider{data: map[string]*secret.Secret{
+		"secret/data/db": {Data: map[string]string{"password": "123456"}},
+	}}
+	secret.Register("test-vault", secret.NewCache(p, 0))
+
+	props := conf.New()
+	_ = props.Set("db.password", "${test-vault:secret/data/db#password}")
+
+	var s struct {
+		Password string `value:"${db.password}"`
+		User     string `value:"${test-vault:secret/data/db#user:=root}"`
+	}
+	err := props.Bind(&s)
+	assert.Nil(t, err)
+	assert.Equal(t, s.Password, "123456")
+	assert.Equal(t, s.User, "root")
+}
+
+func TestCache_Renew(t *testing.T) {
+
+	p := &provider{data: map[string]*secret.Secret{
+		"lease": {
+			Data:          map[string]string{"password": "123456"},
+			LeaseID:       "lease-1",
+			LeaseDuration: 30 * time.Millisecond,
+			Renewable:     true,
+		},
+		"static": {Data: map[string]string{"password": "abc"}},
+	}}
+	c := secret.NewCache(p, 30*time.Millisecond)
+
+	_, err := c.Get(context.Background(), "lease#password")
+	assert.Nil(t, err)
+	_, err = c.Get(context.Background(), "static#password")
+	assert.Nil(t, err)
+	assert.Equal(t, p.reads, 2)
+
+	err = c.Renew(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, p.renews, 0)
+
+	time.Sleep(25 * time.Millisecond)
+	err = c.Renew(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, p.renews, 1)
+	assert.Equal(t, p.reads, 3)
+
+	v, err := c.Get(context.Background(), "lease#password")
+	assert.Nil(t, err)
+	assert.Equal(t, v, "123456")
+	assert.Equal(t, p.reads, 3)
+
+	p.err = errors.New("connection refused")
+	time.Sleep(35 * time.Millisecond)
+	_, err = c.Get(context.Background(), "static#password")
+	assert.Error(t, err, "connection refused")
+}
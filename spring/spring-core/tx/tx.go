@@ -0,0 +1,186 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tx 提供声明式的事务管理，支持 Required、RequiresNew 和 Nested 三种
+// 传播行为，事务通过 context.Context 在调用链中传递。
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrRollbackOnly 加入的事务执行失败后外层事务只能回滚，此时提交返回该错误。
+var ErrRollbackOnly = errors.New("transaction is marked as rollback-only")
+
+// Propagation 事务的传播行为。
+type Propagation int
+
+const (
+	PropagationRequired    = Propagation(iota) // 存在事务时加入，否则开启新事务
+	PropagationRequiresNew                     // 总是开启新事务，与外层事务互不影响
+	PropagationNested                          // 存在事务时使用保存点嵌套执行，否则开启新事务
+)
+
+// Executor 执行 SQL 语句的对象，*sql.DB 和 *sql.Tx 都实现了该接口。
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// TxManager 管理一个数据源上的事务。
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager 返回管理 db 上事务的 TxManager 对象。
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+type ctxKey struct {
+	m *TxManager
+}
+
+type txState struct {
+	tx           *sql.Tx
+	savepoints   int
+	rollbackOnly bool
+}
+
+func (m *TxManager) state(ctx context.Context) *txState {
+	s, _ := ctx.Value(ctxKey{m}).(*txState)
+	return s
+}
+
+// Executor 返回执行 SQL 语句的对象，上下文中存在事务时返回该事务，否则返回
+// 数据源本身，repository 应该通过该方法获取执行语句的对象。
+func (m *TxManager) Executor(ctx context.Context) Executor {
+	if s := m.state(ctx); s != nil {
+		return s.tx
+	}
+	return m.db
+}
+
+// InTx 返回上下文中是否存在事务。
+func (m *TxManager) InTx(ctx context.Context) bool {
+	return m.state(ctx) != nil
+}
+
+// Execute 按照传播行为 p 在事务中执行 fn ，fn 返回错误或者 panic 时回滚事务。
+func (m *TxManager) Execute(ctx context.Context, p Propagation, fn func(ctx context.Context) error) error {
+	s := m.state(ctx)
+	if s == nil || p == PropagationRequiresNew {
+		return m.begin(ctx, fn)
+	}
+	if p == PropagationNested {
+		return m.nested(ctx, s, fn)
+	}
+	if err := fn(ctx); err != nil {
+		s.rollbackOnly = true
+		return err
+	}
+	return nil
+}
+
+// begin 开启新事务执行 fn 。
+func (m *TxManager) begin(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	s := &txState{tx: tx}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, ctxKey{m}, s)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if s.rollbackOnly {
+		_ = tx.Rollback()
+		return ErrRollbackOnly
+	}
+	return tx.Commit()
+}
+
+// nested 使用保存点在当前事务中执行 fn ，失败时只回滚到保存点。
+func (m *TxManager) nested(ctx context.Context, s *txState, fn func(ctx context.Context) error) (err error) {
+
+	s.savepoints++
+	name := fmt.Sprintf("sp_%d", s.savepoints)
+	if _, err = s.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = s.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(r)
+		}
+	}()
+
+	if err = fn(ctx); err != nil {
+		_, _ = s.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		return err
+	}
+	_, err = s.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+var defaultManager *TxManager
+
+// SetDefault 设置 Required 等函数使用的默认 TxManager 对象。
+func SetDefault(m *TxManager) {
+	defaultManager = m
+}
+
+// Default 返回默认的 TxManager 对象，未设置时返回 nil 。
+func Default() *TxManager {
+	return defaultManager
+}
+
+func execute(ctx context.Context, p Propagation, fn func(ctx context.Context) error) error {
+	if defaultManager == nil {
+		return errors.New("no default TxManager")
+	}
+	return defaultManager.Execute(ctx, p, fn)
+}
+
+// Required 使用默认 TxManager 执行 fn ，存在事务时加入，否则开启新事务。
+func Required(ctx context.Context, fn func(ctx context.Context) error) error {
+	return execute(ctx, PropagationRequired, fn)
+}
+
+// RequiresNew 使用默认 TxManager 在新事务中执行 fn 。
+func RequiresNew(ctx context.Context, fn func(ctx context.Context) error) error {
+	return execute(ctx, PropagationRequiresNew, fn)
+}
+
+// Nested 使用默认 TxManager 执行 fn ，存在事务时使用保存点嵌套执行。
+func Nested(ctx context.Context, fn func(ctx context.Context) error) error {
+	return execute(ctx, PropagationNested, fn)
+}
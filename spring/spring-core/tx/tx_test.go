@@ -0,0 +1,144 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tx_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/tx"
+)
+
+type fakeDriver struct{ log *[]string }
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ log *[]string }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { *c.log = append(*c.log, "BEGIN"); return fakeTx(c), nil }
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.log = append(*c.log, query)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{ log *[]string }
+
+func (t fakeTx) Commit() error   { *t.log = append(*t.log, "COMMIT"); return nil }
+func (t fakeTx) Rollback() error { *t.log = append(*t.log, "ROLLBACK"); return nil }
+
+var stmts []string
+
+func init() {
+	sql.Register("fake-tx", fakeDriver{log: &stmts})
+}
+
+func newManager(t *testing.T) *tx.TxManager {
+	db, err := sql.Open("fake-tx", "")
+	assert.Nil(t, err)
+	db.SetMaxOpenConns(2)
+	stmts = nil
+	return tx.NewTxManager(db)
+}
+
+func TestTxManager(t *testing.T) {
+
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	t.Run("required", func(t *testing.T) {
+		m := newManager(t)
+		tx.SetDefault(m)
+		defer tx.SetDefault(nil)
+		err := tx.Required(ctx, func(ctx context.Context) error {
+			assert.True(t, m.InTx(ctx))
+			_, err := m.Executor(ctx).ExecContext(ctx, "INSERT 1")
+			assert.Nil(t, err)
+			return tx.Required(ctx, func(ctx context.Context) error {
+				_, err := m.Executor(ctx).ExecContext(ctx, "INSERT 2")
+				return err
+			})
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, stmts, []string{"BEGIN", "INSERT 1", "INSERT 2", "COMMIT"})
+	})
+
+	t.Run("rollback-only", func(t *testing.T) {
+		m := newManager(t)
+		err := m.Execute(ctx, tx.PropagationRequired, func(ctx context.Context) error {
+			_ = m.Execute(ctx, tx.PropagationRequired, func(ctx context.Context) error {
+				return errFailed
+			})
+			return nil
+		})
+		assert.Equal(t, err, tx.ErrRollbackOnly)
+		assert.Equal(t, stmts, []string{"BEGIN", "ROLLBACK"})
+	})
+
+	t.Run("requires-new", func(t *testing.T) {
+		m := newManager(t)
+		err := m.Execute(ctx, tx.PropagationRequired, func(ctx context.Context) error {
+			err := m.Execute(ctx, tx.PropagationRequiresNew, func(ctx context.Context) error {
+				return errFailed
+			})
+			assert.Equal(t, err, errFailed)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, stmts, []string{"BEGIN", "BEGIN", "ROLLBACK", "COMMIT"})
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		m := newManager(t)
+		err := m.Execute(ctx, tx.PropagationNested, func(ctx context.Context) error {
+			_ = m.Execute(ctx, tx.PropagationNested, func(ctx context.Context) error {
+				return errFailed
+			})
+			return m.Execute(ctx, tx.PropagationNested, func(ctx context.Context) error {
+				return nil
+			})
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, stmts, []string{
+			"BEGIN",
+			"SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1",
+			"SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_2",
+			"COMMIT",
+		})
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		m := newManager(t)
+		assert.Panic(t, func() {
+			_ = m.Execute(ctx, tx.PropagationRequired, func(ctx context.Context) error {
+				panic("boom")
+			})
+		}, "boom")
+		assert.Equal(t, stmts, []string{"BEGIN", "ROLLBACK"})
+	})
+
+	t.Run("no default", func(t *testing.T) {
+		err := tx.Required(ctx, func(ctx context.Context) error { return nil })
+		assert.Error(t, err, "no default TxManager")
+	})
+}
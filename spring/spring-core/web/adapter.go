@@ -0,0 +1,585 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-spring/spring-base/knife"
+)
+
+const defaultMemory = 32 << 20 // 32 MB
+
+// WrapHTTPHandler 将标准的 http.Handler 适配为 Handler ，和 WrapH 相同。
+func WrapHTTPHandler(h http.Handler) Handler {
+	return &httpHandler{h}
+}
+
+// WrapMiddleware 将标准的 net/http 中间件适配为 Filter 。中间件替换的请求对象
+// 会通过 SetRequest 传递给后续的过滤器和处理函数，但是中间件包装的
+// http.ResponseWriter 不会传递，依赖包装响应对象的中间件需要改写为 Filter 。
+func WrapMiddleware(m func(http.Handler) http.Handler) Filter {
+	return FuncFilter(func(ctx Context, chain FilterChain) {
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r != ctx.Request() {
+				ctx.SetRequest(r)
+			}
+			chain.Next(ctx)
+		})
+		m(next).ServeHTTP(ctx.ResponseWriter(), ctx.Request())
+	})
+}
+
+// ToHTTPHandler 将 Router 上注册的处理函数适配为标准的 http.Handler ，这样不需要
+// 启动 Container 就能把路由挂载到 http.ServeMux 或者第三方路由上。路由优先匹配
+// 静态路径，然后是命名参数，最后是通配符，路径不存在时返回 404 ，方法不匹配时
+// 返回 405 并设置 Allow 响应头。filters 会在每个处理函数之前执行。
+func ToHTTPHandler(r Router, filters ...Filter) http.Handler {
+	h := &routerHandler{filters: filters}
+	for _, m := range MergeVersionMappers(r.Mappers()) {
+		path, wildCardName := ToPathStyle(m.Path(), EchoPathStyle)
+		h.routes = append(h.routes, &route{
+			method:       m.Method(),
+			path:         path,
+			segments:     strings.Split(strings.TrimPrefix(path, "/"), "/"),
+			wildCardName: wildCardName,
+			handler:      m.Handler(),
+		})
+	}
+	sort.SliceStable(h.routes, func(i, j int) bool {
+		return h.routes[i].less(h.routes[j])
+	})
+	return h
+}
+
+// route 转换为 echo 风格的路由。
+type route struct {
+	method       uint32
+	path         string
+	segments     []string
+	wildCardName string
+	handler      Handler
+}
+
+// segmentRank 返回路径片段的匹配优先级，数字越小越优先。
+func segmentRank(s string) int {
+	if strings.HasPrefix(s, "*") {
+		return 2
+	}
+	if strings.HasPrefix(s, ":") {
+		return 1
+	}
+	return 0
+}
+
+func (r *route) less(o *route) bool {
+	for i := 0; i < len(r.segments) && i < len(o.segments); i++ {
+		a, b := segmentRank(r.segments[i]), segmentRank(o.segments[i])
+		if a != b {
+			return a < b
+		}
+	}
+	return len(r.segments) > len(o.segments)
+}
+
+// match 返回请求路径是否匹配路由，匹配时同时返回路径参数的名称和值。
+func (r *route) match(segments []string) (names, values []string, ok bool) {
+	for i, s := range r.segments {
+		if i >= len(segments) {
+			return nil, nil, false
+		}
+		if strings.HasPrefix(s, "*") {
+			names = append(names, "*")
+			values = append(values, strings.Join(segments[i:], "/"))
+			return names, values, true
+		}
+		if strings.HasPrefix(s, ":") {
+			if segments[i] == "" {
+				return nil, nil, false
+			}
+			names = append(names, s[1:])
+			values = append(values, segments[i])
+			continue
+		}
+		if s != segments[i] {
+			return nil, nil, false
+		}
+	}
+	if len(segments) != len(r.segments) {
+		return nil, nil, false
+	}
+	return names, values, true
+}
+
+type routerHandler struct {
+	routes  []*route
+	filters []Filter
+}
+
+func (h *routerHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+
+	segments := strings.Split(strings.TrimPrefix(req.URL.Path, "/"), "/")
+
+	var allow []string
+	for _, r := range h.routes {
+		names, values, ok := r.match(segments)
+		if !ok {
+			continue
+		}
+		method := methodOf(req.Method)
+		if r.method&method != method || method == 0 {
+			for _, m := range GetMethod(r.method) {
+				allow = appendUnique(allow, m)
+			}
+			continue
+		}
+		ctx := NewHTTPContext(w, req, r.handler, r.path, names, values)
+		InvokeHandler(ctx, r.handler, h.filters)
+		return
+	}
+
+	if len(allow) > 0 {
+		sort.Strings(allow)
+		w.Header().Set(HeaderAllow, strings.Join(allow, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// methodOf 返回 HTTP 方法对应的 MethodXXX 常量，不支持的方法返回 0 。
+func methodOf(method string) uint32 {
+	for k, v := range httpMethods {
+		if v == method {
+			return k
+		}
+	}
+	return 0
+}
+
+func appendUnique(a []string, s string) []string {
+	for _, v := range a {
+		if v == s {
+			return a
+		}
+	}
+	return append(a, s)
+}
+
+// httpContext 基于标准 net/http 的 Context 实现。
+type httpContext struct {
+	writer     *BufferedResponseWriter
+	request    *http.Request
+	handler    Handler
+	path       string
+	pathNames  []string
+	pathValues []string
+	status     int
+}
+
+// NewHTTPContext 返回基于标准 net/http 的 Context 对象，path 为注册的路由地址，
+// names 和 values 为路径参数，通配符参数的名称为 * 。
+func NewHTTPContext(w http.ResponseWriter, r *http.Request, h Handler, path string, names, values []string) Context {
+	if names == nil {
+		names = []string{}
+		values = []string{}
+	}
+	return &httpContext{
+		writer:     &BufferedResponseWriter{ResponseWriter: w},
+		request:    r.WithContext(knife.New(r.Context())),
+		handler:    h,
+		path:       path,
+		pathNames:  names,
+		pathValues: values,
+		status:     http.StatusOK,
+	}
+}
+
+// NativeContext 返回封装的底层上下文对象
+func (ctx *httpContext) NativeContext() interface{} {
+	return ctx.request
+}
+
+// Request returns `*http.Request`.
+func (ctx *httpContext) Request() *http.Request {
+	return ctx.request
+}
+
+// SetRequest sets `*http.Request`.
+func (ctx *httpContext) SetRequest(r *http.Request) {
+	ctx.request = r
+}
+
+// Context 返回 Request 绑定的 context.Context 对象
+func (ctx *httpContext) Context() context.Context {
+	return ctx.request.Context()
+}
+
+// IsTLS returns true if HTTP connection is TLS otherwise false.
+func (ctx *httpContext) IsTLS() bool {
+	return ctx.request.TLS != nil
+}
+
+// IsWebSocket returns true if HTTP connection is WebSocket otherwise false.
+func (ctx *httpContext) IsWebSocket() bool {
+	upgrade := ctx.request.Header.Get("Upgrade")
+	return strings.EqualFold(upgrade, "websocket")
+}
+
+// Scheme returns the HTTP protocol scheme, `http` or `https`.
+func (ctx *httpContext) Scheme() string {
+	r := ctx.request
+	if r.TLS != nil {
+		return "https"
+	}
+	if scheme := r.Header.Get(HeaderXForwardedProto); scheme != "" {
+		return scheme
+	}
+	if scheme := r.Header.Get(HeaderXForwardedProtocol); scheme != "" {
+		return scheme
+	}
+	if ssl := r.Header.Get(HeaderXForwardedSsl); ssl == "on" {
+		return "https"
+	}
+	if scheme := r.Header.Get(HeaderXUrlScheme); scheme != "" {
+		return scheme
+	}
+	return "http"
+}
+
+// ClientIP implements a best effort algorithm to return the real client IP
+func (ctx *httpContext) ClientIP() string {
+	if s := ctx.request.Header.Get(HeaderXForwardedFor); s != "" {
+		return strings.TrimSpace(strings.Split(s, ",")[0])
+	}
+	if s := ctx.request.Header.Get("X-Real-Ip"); s != "" {
+		return s
+	}
+	host, _, err := net.SplitHostPort(ctx.request.RemoteAddr)
+	if err != nil {
+		return ctx.request.RemoteAddr
+	}
+	return host
+}
+
+// Path returns the registered path for the handler.
+func (ctx *httpContext) Path() string {
+	return ctx.path
+}
+
+// Handler returns the matched handler by router.
+func (ctx *httpContext) Handler() Handler {
+	return ctx.handler
+}
+
+// ContentType returns the Content-Type header of the request.
+func (ctx *httpContext) ContentType() string {
+	return filterFlags(ctx.request.Header.Get(HeaderContentType))
+}
+
+// GetHeader returns value from request headers.
+func (ctx *httpContext) GetHeader(key string) string {
+	return ctx.request.Header.Get(key)
+}
+
+// GetRawData return stream data.
+func (ctx *httpContext) GetRawData() ([]byte, error) {
+	return ioutil.ReadAll(ctx.request.Body)
+}
+
+// PathParam returns path parameter by name.
+func (ctx *httpContext) PathParam(name string) string {
+	for i, s := range ctx.pathNames {
+		if s == name {
+			return ctx.pathValues[i]
+		}
+	}
+	return ""
+}
+
+// PathParamNames returns path parameter names.
+func (ctx *httpContext) PathParamNames() []string {
+	return ctx.pathNames
+}
+
+// PathParamValues returns path parameter values.
+func (ctx *httpContext) PathParamValues() []string {
+	return ctx.pathValues
+}
+
+// QueryParam returns the query param for the provided name.
+func (ctx *httpContext) QueryParam(name string) string {
+	return ctx.request.URL.Query().Get(name)
+}
+
+// QueryParams returns the query parameters as `url.Values`.
+func (ctx *httpContext) QueryParams() url.Values {
+	return ctx.request.URL.Query()
+}
+
+// QueryString returns the URL query string.
+func (ctx *httpContext) QueryString() string {
+	return ctx.request.URL.RawQuery
+}
+
+// FormValue returns the form field value for the provided name.
+func (ctx *httpContext) FormValue(name string) string {
+	return ctx.request.FormValue(name)
+}
+
+// FormParams returns the form parameters as `url.Values`.
+func (ctx *httpContext) FormParams() (url.Values, error) {
+	r := ctx.request
+	if strings.HasPrefix(ctx.ContentType(), MIMEMultipartForm) {
+		if err := r.ParseMultipartForm(defaultMemory); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+	}
+	return r.Form, nil
+}
+
+// FormFile returns the multipart form file for the provided name.
+func (ctx *httpContext) FormFile(name string) (*multipart.FileHeader, error) {
+	_, fh, err := ctx.request.FormFile(name)
+	return fh, err
+}
+
+// SaveUploadedFile uploads the form file to specific dst.
+func (ctx *httpContext) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	return SaveUploadedFile(file, dst)
+}
+
+// MultipartForm returns the multipart form.
+func (ctx *httpContext) MultipartForm() (*multipart.Form, error) {
+	err := ctx.request.ParseMultipartForm(defaultMemory)
+	return ctx.request.MultipartForm, err
+}
+
+// Cookie returns the named cookie provided in the request.
+func (ctx *httpContext) Cookie(name string) (*http.Cookie, error) {
+	return ctx.request.Cookie(name)
+}
+
+// Cookies returns the HTTP cookies sent with the request.
+func (ctx *httpContext) Cookies() []*http.Cookie {
+	return ctx.request.Cookies()
+}
+
+// Bind binds the request body into provided type `i`. 请求体只支持 JSON 和 XML
+// 格式，然后根据字段上的标签绑定请求头、查询参数、路径参数和 cookie 。
+func (ctx *httpContext) Bind(i interface{}) error {
+	if r := ctx.request; r.Body != nil && r.ContentLength != 0 {
+		var err error
+		switch ctx.ContentType() {
+		case MIMEApplicationJSON:
+			err = json.NewDecoder(r.Body).Decode(i)
+		case MIMEApplicationXML, MIMETextXML:
+			err = xml.NewDecoder(r.Body).Decode(i)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return BindParams(ctx, i)
+}
+
+// Session returns the session of the request.
+func (ctx *httpContext) Session() (*Session, error) {
+	return GetSession(ctx)
+}
+
+// ResponseWriter returns `http.ResponseWriter`.
+func (ctx *httpContext) ResponseWriter() ResponseWriter {
+	return ctx.writer
+}
+
+// Status sets the HTTP response code. 响应码在写入响应体时才会发送。
+func (ctx *httpContext) Status(code int) {
+	ctx.status = code
+}
+
+// Header is a intelligent shortcut for c.Writer.Header().Set(key, value).
+func (ctx *httpContext) Header(key, value string) {
+	ctx.writer.Header().Set(key, value)
+}
+
+// SetCookie adds a `Set-Cookie` header in HTTP response.
+func (ctx *httpContext) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(ctx.writer, cookie)
+}
+
+// NoContent sends a response with no body and a status code.
+func (ctx *httpContext) NoContent(code int) {
+	ctx.writer.WriteHeader(code)
+}
+
+// writeBlob 设置 Content-Type 然后发送响应码和响应体。
+func (ctx *httpContext) writeBlob(contentType string, b []byte) {
+	ctx.Header(HeaderContentType, contentType)
+	ctx.writer.WriteHeader(ctx.status)
+	if _, err := ctx.writer.Write(b); err != nil {
+		panic(err)
+	}
+}
+
+// String writes the given string into the response body.
+func (ctx *httpContext) String(format string, values ...interface{}) {
+	ctx.writeBlob(MIMETextPlainCharsetUTF8, []byte(fmt.Sprintf(format, values...)))
+}
+
+// HTML sends an HTTP response.
+func (ctx *httpContext) HTML(html string) {
+	ctx.writeBlob(MIMETextHTMLCharsetUTF8, []byte(html))
+}
+
+// HTMLBlob sends an HTTP blob response.
+func (ctx *httpContext) HTMLBlob(b []byte) {
+	ctx.writeBlob(MIMETextHTMLCharsetUTF8, b)
+}
+
+// JSON sends a JSON response.
+func (ctx *httpContext) JSON(i interface{}) {
+	b, err := json.Marshal(i)
+	if err != nil {
+		panic(err)
+	}
+	ctx.writeBlob(MIMEApplicationJSONCharsetUTF8, b)
+}
+
+// JSONPretty sends a pretty-print JSON.
+func (ctx *httpContext) JSONPretty(i interface{}, indent string) {
+	b, err := json.MarshalIndent(i, "", indent)
+	if err != nil {
+		panic(err)
+	}
+	ctx.writeBlob(MIMEApplicationJSONCharsetUTF8, b)
+}
+
+// JSONBlob sends a JSON blob response.
+func (ctx *httpContext) JSONBlob(b []byte) {
+	ctx.writeBlob(MIMEApplicationJSONCharsetUTF8, b)
+}
+
+// JSONP sends a JSONP response.
+func (ctx *httpContext) JSONP(callback string, i interface{}) {
+	b, err := json.Marshal(i)
+	if err != nil {
+		panic(err)
+	}
+	ctx.JSONPBlob(callback, b)
+}
+
+// JSONPBlob sends a JSONP blob response.
+func (ctx *httpContext) JSONPBlob(callback string, b []byte) {
+	data := append([]byte(callback+"("), b...)
+	ctx.writeBlob(MIMEApplicationJavaScriptCharsetUTF8, append(data, ");"...))
+}
+
+// XML sends an XML response.
+func (ctx *httpContext) XML(i interface{}) {
+	ctx.XMLPretty(i, "")
+}
+
+// XMLPretty sends a pretty-print XML.
+func (ctx *httpContext) XMLPretty(i interface{}, indent string) {
+	b, err := xml.MarshalIndent(i, "", indent)
+	if err != nil {
+		panic(err)
+	}
+	ctx.XMLBlob(b)
+}
+
+// XMLBlob sends an XML blob response.
+func (ctx *httpContext) XMLBlob(b []byte) {
+	ctx.writeBlob(MIMEApplicationXMLCharsetUTF8, append([]byte(xml.Header), b...))
+}
+
+// Blob sends a blob response with content type.
+func (ctx *httpContext) Blob(contentType string, b []byte) {
+	ctx.writeBlob(contentType, b)
+}
+
+// Respond sends a response in the format negotiated by the Accept header.
+func (ctx *httpContext) Respond(i interface{}) {
+	Respond(ctx, i)
+}
+
+// File sends a response with the content of the file.
+func (ctx *httpContext) File(file string) {
+	http.ServeFile(ctx.writer, ctx.request, file)
+}
+
+func (ctx *httpContext) contentDisposition(file, name, dispositionType string) {
+	if name == "" {
+		name = filepath.Base(file)
+	}
+	s := fmt.Sprintf("%s; filename=%q", dispositionType, name)
+	ctx.Header(HeaderContentDisposition, s)
+	ctx.File(file)
+}
+
+// Attachment sends a response as attachment.
+func (ctx *httpContext) Attachment(file string, name string) {
+	ctx.contentDisposition(file, name, "attachment")
+}
+
+// Inline sends a response as inline.
+func (ctx *httpContext) Inline(file string, name string) {
+	ctx.contentDisposition(file, name, "inline")
+}
+
+// Redirect redirects the request to a provided URL with status code.
+func (ctx *httpContext) Redirect(code int, url string) {
+	http.Redirect(ctx.writer, ctx.request, url, code)
+}
+
+// SSEvent writes a Server-Sent Event into the body stream.
+func (ctx *httpContext) SSEvent(name string, message interface{}) {
+	h := ctx.writer.Header()
+	if h.Get(HeaderContentType) == "" {
+		h.Set(HeaderContentType, "text/event-stream")
+		h.Set(HeaderCacheControl, "no-cache")
+	}
+	data, ok := message.(string)
+	if !ok {
+		b, err := json.Marshal(message)
+		if err != nil {
+			panic(err)
+		}
+		data = string(b)
+	}
+	s := fmt.Sprintf("event:%s\ndata:%s\n\n", name, data)
+	if _, err := ctx.writer.Write([]byte(s)); err != nil {
+		panic(err)
+	}
+	ctx.writer.Flush()
+}
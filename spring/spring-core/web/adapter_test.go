@@ -0,0 +1,258 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+type ctxKey string
+
+func TestToHTTPHandler(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/users/{id}", func(ctx web.Context) {
+		ctx.String("user %s", ctx.PathParam("id"))
+	})
+	r.GetMapping("/users/me", func(ctx web.Context) {
+		ctx.String("me")
+	})
+	r.PostMapping("/users/:id", func(ctx web.Context) {
+		var req struct {
+			Name string `json:"name"`
+			ID   string `path:"id"`
+		}
+		if err := ctx.Bind(&req); err != nil {
+			panic(err)
+		}
+		ctx.Status(http.StatusCreated)
+		ctx.JSON(req)
+	})
+	r.GetMapping("/static/*", func(ctx web.Context) {
+		ctx.String(ctx.PathParam("*"))
+	})
+	r.HandleGet("/ping", web.WrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong " + r.Context().Value(ctxKey("tag")).(string)))
+	})))
+
+	middleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "true")
+			ctx := context.WithValue(r.Context(), ctxKey("tag"), "wrapped")
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	h := web.ToHTTPHandler(r, web.WrapMiddleware(middleware))
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if body != "" {
+			req = httptest.NewRequest(method, target, strings.NewReader(body))
+			req.Header.Set(web.HeaderContentType, web.MIMEApplicationJSON)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("param", func(t *testing.T) {
+		w := serve(http.MethodGet, "/users/42", "")
+		assert.Equal(t, w.Code, http.StatusOK)
+		assert.Equal(t, w.Body.String(), "user 42")
+		assert.Equal(t, w.Header().Get("X-Middleware"), "true")
+	})
+
+	t.Run("static first", func(t *testing.T) {
+		w := serve(http.MethodGet, "/users/me", "")
+		assert.Equal(t, w.Body.String(), "me")
+	})
+
+	t.Run("bind", func(t *testing.T) {
+		w := serve(http.MethodPost, "/users/7", `{"name":"jim"}`)
+		assert.Equal(t, w.Code, http.StatusCreated)
+		assert.Equal(t, w.Body.String(), `{"name":"jim","ID":"7"}`)
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		w := serve(http.MethodGet, "/static/css/app.css", "")
+		assert.Equal(t, w.Body.String(), "css/app.css")
+	})
+
+	t.Run("http handler", func(t *testing.T) {
+		w := serve(http.MethodGet, "/ping", "")
+		assert.Equal(t, w.Body.String(), "pong wrapped")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		w := serve(http.MethodGet, "/unknown", "")
+		assert.Equal(t, w.Code, http.StatusNotFound)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := serve(http.MethodDelete, "/users/42", "")
+		assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+		assert.Equal(t, w.Header().Get(web.HeaderAllow), "GET, POST")
+	})
+}
+
+func TestToHTTPHandler_Priority(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/a/*", func(ctx web.Context) {
+		ctx.String("any %s", ctx.PathParam("*"))
+	})
+	r.GetMapping("/a/:x/c", func(ctx web.Context) {
+		ctx.String("param %s", ctx.PathParam("x"))
+	})
+	r.GetMapping("/a/b/*", func(ctx web.Context) {
+		ctx.String("static any %s", ctx.PathParam("*"))
+	})
+	r.GetMapping("/a/b/c", func(ctx web.Context) {
+		ctx.String("static")
+	})
+	r.GetMapping("/files/:name", func(ctx web.Context) {
+		ctx.String("file %s", ctx.PathParam("name"))
+	})
+	h := web.ToHTTPHandler(r)
+
+	testcases := []struct {
+		target string
+		code   int
+		body   string
+	}{
+		{"/a/b/c", http.StatusOK, "static"},
+		{"/a/x/c", http.StatusOK, "param x"},
+		{"/a/b/d", http.StatusOK, "static any d"},
+		{"/a/b/c/d", http.StatusOK, "static any c/d"},
+		{"/a/x/d", http.StatusOK, "any x/d"},
+		{"/a/", http.StatusOK, "any "},
+		{"/a/b/", http.StatusOK, "static any "},
+		{"/files/a.txt", http.StatusOK, "file a.txt"},
+		{"/files/", http.StatusNotFound, ""},
+		{"/files/a/b", http.StatusNotFound, ""},
+	}
+	for _, c := range testcases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.target, nil))
+		assert.Equal(t, w.Code, c.code)
+		if c.code == http.StatusOK {
+			assert.Equal(t, w.Body.String(), c.body)
+		}
+	}
+}
+
+func TestToHTTPHandler_MethodNotAllowed(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/items/:id", func(ctx web.Context) {})
+	r.PutMapping("/items/{id}", func(ctx web.Context) {})
+	r.DeleteMapping("/items/*", func(ctx web.Context) {})
+	r.PostMapping("/items", func(ctx web.Context) {})
+	h := web.ToHTTPHandler(r)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/items/1", nil))
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, w.Header().Get(web.HeaderAllow), "DELETE, GET, PUT")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, w.Header().Get(web.HeaderAllow), "POST")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/items/1", nil))
+	assert.Equal(t, w.Code, http.StatusOK)
+}
+
+func TestToHTTPHandler_Bind(t *testing.T) {
+
+	type Item struct {
+		ID    string `path:"id" xml:"-"`
+		Name  string `json:"name" xml:"name"`
+		Trace string `header:"X-Trace" xml:"-"`
+		Page  int    `query:"page" xml:"-"`
+	}
+
+	r := web.NewRouter()
+	r.PostMapping("/items/:id", func(ctx web.Context) {
+		var item Item
+		if err := ctx.Bind(&item); err != nil {
+			panic(web.NewHttpError(http.StatusBadRequest, err.Error()))
+		}
+		ctx.String("%s %s %s %d", item.ID, item.Name, item.Trace, item.Page)
+	})
+	h := web.ToHTTPHandler(r)
+
+	testcases := []struct {
+		contentType string
+		body        string
+	}{
+		{web.MIMEApplicationJSON, `{"name":"jim"}`},
+		{web.MIMEApplicationJSONCharsetUTF8, `{"name":"jim"}`},
+		{"application/json; charset=utf-8", `{"name":"jim"}`},
+		{web.MIMEApplicationXMLCharsetUTF8, `<Item><name>jim</name></Item>`},
+		{web.MIMETextXML, `<Item><name>jim</name></Item>`},
+	}
+	for _, c := range testcases {
+		req := httptest.NewRequest(http.MethodPost, "/items/7?page=3", strings.NewReader(c.body))
+		req.Header.Set(web.HeaderContentType, c.contentType)
+		req.Header.Set("X-Trace", "abc")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, http.StatusOK)
+		assert.Equal(t, w.Body.String(), "7 jim abc 3")
+	}
+}
+
+func TestToHTTPHandler_ClientIP(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/ip", func(ctx web.Context) {
+		ctx.String(ctx.ClientIP())
+	})
+	h := web.ToHTTPHandler(r)
+
+	testcases := []struct {
+		header     map[string]string
+		remoteAddr string
+		expect     string
+	}{
+		{nil, "10.0.0.1:1234", "10.0.0.1"},
+		{nil, "10.0.0.1", "10.0.0.1"},
+		{map[string]string{"X-Real-Ip": "1.1.1.1"}, "10.0.0.1:1234", "1.1.1.1"},
+		{map[string]string{web.HeaderXForwardedFor: "2.2.2.2"}, "10.0.0.1:1234", "2.2.2.2"},
+		{map[string]string{web.HeaderXForwardedFor: " 3.3.3.3 , 2.2.2.2", "X-Real-Ip": "1.1.1.1"}, "10.0.0.1:1234", "3.3.3.3"},
+	}
+	for _, c := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = c.remoteAddr
+		for k, v := range c.header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, w.Body.String(), c.expect)
+	}
+}
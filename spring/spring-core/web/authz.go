@@ -0,0 +1,150 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+// Principal 访问控制使用的当前用户，由认证过滤器通过
+// RequestContextHolder.SetPrincipal 设置。
+type Principal interface {
+	Name() string
+	HasRole(role string) bool
+	HasPermission(permission string) bool
+}
+
+// SimplePrincipal Principal 的简单实现，权限支持 * 通配符，例如 orders:* 包含
+// orders:write 权限。
+type SimplePrincipal struct {
+	Username    string
+	Roles       []string
+	Permissions []string
+}
+
+// Name 返回用户名。
+func (p *SimplePrincipal) Name() string {
+	return p.Username
+}
+
+// HasRole 返回用户是否具有 role 角色。
+func (p *SimplePrincipal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission 返回用户是否具有 permission 权限。
+func (p *SimplePrincipal) HasPermission(permission string) bool {
+	for _, s := range p.Permissions {
+		if s == permission || s == "*" {
+			return true
+		}
+		if strings.HasSuffix(s, ":*") && strings.HasPrefix(permission, s[:len(s)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy 路由的访问控制策略，返回是否允许 p 访问，p 不会为 nil 。
+type Policy func(ctx Context, p Principal) bool
+
+// RequireRole 返回具有任意一个角色时允许访问的策略。
+func RequireRole(roles ...string) Policy {
+	return func(ctx Context, p Principal) bool {
+		for _, role := range roles {
+			if p.HasRole(role) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RequirePermission 返回具有所有权限时允许访问的策略。
+func RequirePermission(permissions ...string) Policy {
+	return func(ctx Context, p Principal) bool {
+		for _, permission := range permissions {
+			if !p.HasPermission(permission) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Authenticated 只要求请求已经认证的策略。
+func Authenticated(ctx Context, p Principal) bool {
+	return true
+}
+
+// anonymous 没有实现 Principal 接口的用户，不具有任何角色和权限。
+type anonymous struct{}
+
+func (a anonymous) Name() string              { return "" }
+func (a anonymous) HasRole(string) bool       { return false }
+func (a anonymous) HasPermission(string) bool { return false }
+
+// authorizedHandler 执行访问控制策略之后才调用的处理函数。
+type authorizedHandler struct {
+	Handler
+	path     string
+	policies []Policy
+}
+
+func (h *authorizedHandler) Invoke(ctx Context) {
+
+	v := new(RequestContextHolder).Principal(ctx.Context())
+	if v == nil {
+		log.Ctx(ctx.Context()).Warnf("audit: %s %s denied, unauthenticated", ctx.Request().Method, h.path)
+		panic(NewHttpError(http.StatusUnauthorized))
+	}
+
+	p, ok := v.(Principal)
+	if !ok {
+		p = anonymous{}
+	}
+
+	for _, policy := range h.policies {
+		if !policy(ctx, p) {
+			log.Ctx(ctx.Context()).Warnf("audit: %s %s denied for %q", ctx.Request().Method, h.path, p.Name())
+			panic(NewHttpError(http.StatusForbidden))
+		}
+	}
+
+	log.Ctx(ctx.Context()).Debugf("audit: %s %s granted for %q", ctx.Request().Method, h.path, p.Name())
+	h.Handler.Invoke(ctx)
+}
+
+// Authorize 设置路由的访问控制策略，所有策略都允许时才能访问，多次调用时策略
+// 累加。策略在所有过滤器之后执行，因此认证过滤器设置的当前用户总是可用的。没有
+// 当前用户时返回 401 ，策略不允许时返回 403 ，并且都会记录审计日志。
+func (m *Mapper) Authorize(policies ...Policy) *Mapper {
+	if h, ok := m.handler.(*authorizedHandler); ok {
+		h.policies = append(h.policies, policies...)
+		return m
+	}
+	m.handler = &authorizedHandler{Handler: m.handler, path: m.path, policies: policies}
+	return m
+}
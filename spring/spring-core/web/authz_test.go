@@ -0,0 +1,91 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestSimplePrincipal(t *testing.T) {
+	p := &web.SimplePrincipal{
+		Username:    "jim",
+		Roles:       []string{"admin"},
+		Permissions: []string{"orders:*", "users:read"},
+	}
+	assert.True(t, p.HasRole("admin"))
+	assert.False(t, p.HasRole("guest"))
+	assert.True(t, p.HasPermission("orders:write"))
+	assert.True(t, p.HasPermission("users:read"))
+	assert.False(t, p.HasPermission("users:write"))
+}
+
+func TestMapper_Authorize(t *testing.T) {
+
+	users := map[string]web.Principal{
+		"admin": &web.SimplePrincipal{Username: "admin", Roles: []string{"admin"}, Permissions: []string{"*"}},
+		"jim":   &web.SimplePrincipal{Username: "jim", Permissions: []string{"orders:read"}},
+	}
+
+	// 根据 X-User 请求头模拟认证过滤器
+	authn := web.FuncFilter(func(ctx web.Context, chain web.FilterChain) {
+		if p, ok := users[ctx.GetHeader("X-User")]; ok {
+			h := new(web.RequestContextHolder)
+			assert.Nil(t, h.SetPrincipal(ctx.Context(), p))
+		}
+		chain.Next(ctx)
+	})
+
+	ok := func(ctx web.Context) { ctx.String("ok") }
+
+	r := web.NewRouter()
+	r.GetMapping("/admin", ok).Authorize(web.RequireRole("admin"))
+	r.GetMapping("/orders", ok).Authorize(web.RequirePermission("orders:read"))
+	r.PostMapping("/orders", ok).Authorize(web.Authenticated).Authorize(web.RequirePermission("orders:write"))
+	r.GetMapping("/weekday", ok).Authorize(func(ctx web.Context, p web.Principal) bool {
+		return ctx.QueryParam("day") != "sunday"
+	})
+	h := web.ToHTTPHandler(r, authn)
+
+	serve := func(method, target, user string) (code int) {
+		defer func() {
+			if r := recover(); r != nil {
+				code = r.(*web.HttpError).Code
+			}
+		}()
+		req := httptest.NewRequest(method, target, nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, serve(http.MethodGet, "/admin", ""), http.StatusUnauthorized)
+	assert.Equal(t, serve(http.MethodGet, "/admin", "jim"), http.StatusForbidden)
+	assert.Equal(t, serve(http.MethodGet, "/admin", "admin"), http.StatusOK)
+	assert.Equal(t, serve(http.MethodGet, "/orders", "jim"), http.StatusOK)
+	assert.Equal(t, serve(http.MethodPost, "/orders", "jim"), http.StatusForbidden)
+	assert.Equal(t, serve(http.MethodPost, "/orders", "admin"), http.StatusOK)
+	assert.Equal(t, serve(http.MethodGet, "/weekday?day=monday", "jim"), http.StatusOK)
+	assert.Equal(t, serve(http.MethodGet, "/weekday?day=sunday", "jim"), http.StatusForbidden)
+}
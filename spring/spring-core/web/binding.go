@@ -15,3 +15,205 @@
  */
 
 package web
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spring/spring-base/cast"
+	"github.com/go-spring/spring-core/validator"
+)
+
+// FieldError 单个字段的绑定或者校验错误。
+type FieldError struct {
+	Field   string `json:"field"`   // 字段名称
+	Source  string `json:"source"`  // 参数来源，header、query、path、cookie
+	Name    string `json:"name"`    // 参数名称
+	Message string `json:"message"` // 错误描述
+}
+
+// BindError 请求参数的绑定错误，汇总了所有字段的错误，对应 400 状态码。
+type BindError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *BindError) Error() string {
+	var sb strings.Builder
+	for i, fe := range e.Errors {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		if fe.Name != "" {
+			sb.WriteString(fmt.Sprintf("%s %q: ", fe.Source, fe.Name))
+		}
+		sb.WriteString(fe.Message)
+	}
+	return sb.String()
+}
+
+// bindSources 支持的参数来源，按照标签名称的顺序进行查找。
+var bindSources = []string{"header", "query", "path", "cookie"}
+
+// valueGetter 返回指定来源和名称的参数值。
+type valueGetter func(source, name string) []string
+
+// BindParams 根据字段上的 header、query、path、cookie 标签将请求头、查询参数、
+// 路径参数和 cookie 绑定到结构体指针 i 的字段上，然后进行参数校验，所有字段的
+// 错误汇总为一个 *BindError 返回。
+func BindParams(ctx Context, i interface{}) error {
+	return bindParams(i, func(source, name string) []string {
+		switch source {
+		case "header":
+			return ctx.Request().Header.Values(name)
+		case "query":
+			return ctx.QueryParams()[name]
+		case "path":
+			if v := ctx.PathParam(name); v != "" {
+				return []string{v}
+			}
+		case "cookie":
+			if c, err := ctx.Cookie(name); err == nil {
+				return []string{c.Value}
+			}
+		}
+		return nil
+	})
+}
+
+func bindParams(i interface{}, get valueGetter) error {
+
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	e := &BindError{}
+	bindStruct(v.Elem(), get, e)
+
+	if err := validator.Validate(i); err != nil {
+		e.Errors = append(e.Errors, FieldError{Message: err.Error()})
+	}
+
+	if len(e.Errors) > 0 {
+		return e
+	}
+	return nil
+}
+
+func bindStruct(v reflect.Value, get valueGetter, e *BindError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if ft.PkgPath != "" { // 未导出的字段
+			continue
+		}
+		fv := v.Field(i)
+		if ft.Anonymous && fv.Kind() == reflect.Struct {
+			bindStruct(fv, get, e)
+			continue
+		}
+		for _, source := range bindSources {
+			name, ok := ft.Tag.Lookup(source)
+			if !ok || name == "" || name == "-" {
+				continue
+			}
+			values := get(source, name)
+			if len(values) == 0 {
+				break
+			}
+			if err := bindField(fv, values); err != nil {
+				e.Errors = append(e.Errors, FieldError{
+					Field:   ft.Name,
+					Source:  source,
+					Name:    name,
+					Message: err.Error(),
+				})
+			}
+			break
+		}
+	}
+}
+
+// bindField 将字符串形式的参数值转换为字段的类型，切片字段接收所有的值。
+func bindField(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, str := range values {
+			if err := bindValue(s.Index(i), str); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return bindValue(v, values[0])
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+func bindValue(v reflect.Value, s string) error {
+
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+		if err := bindValue(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+
+	switch v.Type() {
+	case durationType:
+		d, err := cast.ToDurationE(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := cast.ToTimeE(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
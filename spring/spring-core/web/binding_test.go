@@ -0,0 +1,74 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+)
+
+type bindRequest struct {
+	ApiKey  string        `header:"X-Api-Key"`
+	Page    int           `query:"page"`
+	Tags    []string      `query:"tag"`
+	ID      *int64        `path:"id"`
+	Session string        `cookie:"sid"`
+	Timeout time.Duration `query:"timeout"`
+	Since   time.Time     `query:"since"`
+	Body    string        `json:"body"`
+}
+
+func TestBindParams(t *testing.T) {
+
+	values := map[string][]string{
+		"header:X-Api-Key": {"secret"},
+		"query:page":       {"2"},
+		"query:tag":        {"a", "b"},
+		"query:timeout":    {"3s"},
+		"query:since":      {"2021-06-01"},
+		"path:id":          {"42"},
+		"cookie:sid":       {"abc"},
+	}
+	get := func(source, name string) []string {
+		return values[source+":"+name]
+	}
+
+	var r bindRequest
+	err := bindParams(&r, get)
+	assert.Nil(t, err)
+	assert.Equal(t, r.ApiKey, "secret")
+	assert.Equal(t, r.Page, 2)
+	assert.Equal(t, r.Tags, []string{"a", "b"})
+	assert.Equal(t, *r.ID, int64(42))
+	assert.Equal(t, r.Session, "abc")
+	assert.Equal(t, r.Timeout, 3*time.Second)
+	assert.Equal(t, r.Since, time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	values["query:page"] = []string{"x"}
+	values["path:id"] = []string{"y"}
+	err = bindParams(&bindRequest{}, get)
+	var e *BindError
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, len(e.Errors), 2)
+	assert.Equal(t, e.Errors[0].Field, "Page")
+	assert.Equal(t, e.Errors[1].Source, "path")
+	assert.Equal(t, ErrorStatus(err), http.StatusBadRequest)
+}
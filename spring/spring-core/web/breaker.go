@@ -0,0 +1,243 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-core/conf"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed   = BreakerState(0) // 关闭状态，正常处理请求
+	BreakerOpen     = BreakerState(1) // 熔断状态，拒绝所有请求
+	BreakerHalfOpen = BreakerState(2) // 半开状态，允许少量探测请求
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerMetrics 熔断器当前统计窗口内的指标。
+type BreakerMetrics struct {
+	State     BreakerState `json:"state"`
+	Requests  int          `json:"requests"`   // 请求数
+	Failures  int          `json:"failures"`   // 失败数
+	SlowCalls int          `json:"slow_calls"` // 慢调用数
+	Rejected  int          `json:"rejected"`   // 被拒绝的请求数
+}
+
+// BreakerListener 熔断器状态变化的监听函数。
+type BreakerListener func(from, to BreakerState)
+
+// CircuitBreaker 基于固定统计窗口的熔断器，失败率或者慢调用比例超过阈值时熔断，
+// 熔断一段时间后进入半开状态，探测请求全部成功时恢复，否则重新熔断。CircuitBreaker
+// 既可以作为过滤器保护一组路由，也可以作为 ProxyBreaker 保护代理请求。
+type CircuitBreaker struct {
+	config   conf.CircuitBreakerConfig
+	patterns []string
+
+	mutex     sync.Mutex
+	state     BreakerState
+	metrics   BreakerMetrics
+	start     time.Time // 当前统计窗口的开始时间
+	openedAt  time.Time // 进入熔断状态的时间
+	probes    int       // 半开状态已放行的探测请求数
+	successes int       // 半开状态成功的探测请求数
+	listeners []BreakerListener
+
+	now func() time.Time
+}
+
+// NewCircuitBreaker 返回新的熔断器。
+func NewCircuitBreaker(config conf.CircuitBreakerConfig) *CircuitBreaker {
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 1
+	}
+	b := &CircuitBreaker{config: config, patterns: config.URLPatterns, now: time.Now}
+	if len(b.patterns) == 0 {
+		b.patterns = []string{"/*"}
+	}
+	b.start = b.now()
+	return b
+}
+
+// OnStateChange 添加状态变化的监听函数，监听函数在持有锁的情况下被调用，不应该
+// 再调用熔断器的方法。
+func (b *CircuitBreaker) OnStateChange(fn BreakerListener) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.listeners = append(b.listeners, fn)
+}
+
+// State 返回熔断器当前的状态。
+func (b *CircuitBreaker) State() BreakerState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.refresh()
+	return b.state
+}
+
+// Metrics 返回熔断器当前统计窗口内的指标。
+func (b *CircuitBreaker) Metrics() BreakerMetrics {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.refresh()
+	m := b.metrics
+	m.State = b.state
+	return m
+}
+
+// refresh 统计窗口过期时重置指标，熔断时间到期后进入半开状态。
+func (b *CircuitBreaker) refresh() {
+	now := b.now()
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setState(BreakerHalfOpen)
+	}
+	if b.config.Window > 0 && now.Sub(b.start) >= b.config.Window {
+		b.metrics = BreakerMetrics{}
+		b.start = now
+	}
+}
+
+func (b *CircuitBreaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	from := b.state
+	b.state = state
+	b.probes, b.successes = 0, 0
+	b.metrics = BreakerMetrics{}
+	b.start = b.now()
+	if state == BreakerOpen {
+		b.openedAt = b.start
+	}
+	for _, fn := range b.listeners {
+		fn(from, state)
+	}
+}
+
+// Allow 返回是否允许发送请求。
+func (b *CircuitBreaker) Allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.refresh()
+	switch b.state {
+	case BreakerOpen:
+		b.metrics.Rejected++
+		return false
+	case BreakerHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			b.metrics.Rejected++
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+// Success 请求成功。
+func (b *CircuitBreaker) Success() {
+	b.record(false, 0)
+}
+
+// Failure 请求失败。
+func (b *CircuitBreaker) Failure(err error) {
+	b.record(true, 0)
+}
+
+// record 记录请求结果，d 为请求的耗时。
+func (b *CircuitBreaker) record(failed bool, d time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.refresh()
+
+	slow := b.config.SlowCallDuration > 0 && d >= b.config.SlowCallDuration
+
+	switch b.state {
+	case BreakerOpen:
+		return
+	case BreakerHalfOpen:
+		if failed || slow {
+			b.setState(BreakerOpen)
+			return
+		}
+		if b.successes++; b.successes >= b.config.HalfOpenRequests {
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+
+	b.metrics.Requests++
+	if failed {
+		b.metrics.Failures++
+	}
+	if slow {
+		b.metrics.SlowCalls++
+	}
+
+	if b.metrics.Requests < b.config.MinRequests {
+		return
+	}
+
+	total := float64(b.metrics.Requests)
+	if float64(b.metrics.Failures)*100/total >= b.config.FailureRate {
+		b.setState(BreakerOpen)
+		return
+	}
+	if b.config.SlowCallDuration > 0 && float64(b.metrics.SlowCalls)*100/total >= b.config.SlowCallRate {
+		b.setState(BreakerOpen)
+	}
+}
+
+func (b *CircuitBreaker) URLPatterns() []string {
+	return b.patterns
+}
+
+// Invoke 熔断时返回 503 错误，处理函数 panic 或者返回 5xx 状态码时记为失败。
+func (b *CircuitBreaker) Invoke(ctx Context, chain FilterChain) {
+
+	if !b.Allow() {
+		panic(NewHttpError(http.StatusServiceUnavailable))
+	}
+
+	start := b.now()
+	defer func() {
+		r := recover()
+		failed := r != nil || ctx.ResponseWriter().Status() >= http.StatusInternalServerError
+		b.record(failed, b.now().Sub(start))
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	chain.Next(ctx)
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+
+	b := NewCircuitBreaker(conf.CircuitBreakerConfig{
+		FailureRate:      50,
+		SlowCallRate:     100,
+		SlowCallDuration: time.Second,
+		MinRequests:      4,
+		Window:           10 * time.Second,
+		OpenTimeout:      5 * time.Second,
+		HalfOpenRequests: 2,
+	})
+
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	var events []string
+	b.OnStateChange(func(from, to BreakerState) {
+		events = append(events, from.String()+"->"+to.String())
+	})
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, b.Allow())
+		b.Failure(errors.New("error"))
+	}
+	assert.Equal(t, b.State(), BreakerClosed)
+	assert.Equal(t, b.Metrics().Failures, 3)
+
+	// 统计窗口过期后重新统计
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, b.Metrics().Requests, 0)
+
+	b.Success()
+	b.Success()
+	b.Failure(errors.New("error"))
+	assert.Equal(t, b.State(), BreakerClosed)
+	b.Failure(errors.New("error"))
+	assert.Equal(t, b.State(), BreakerOpen)
+	assert.False(t, b.Allow())
+	assert.Equal(t, b.Metrics().Rejected, 1)
+
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, b.State(), BreakerHalfOpen)
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+
+	b.Success()
+	b.record(false, 2*time.Second)
+	assert.Equal(t, b.State(), BreakerOpen)
+
+	now = now.Add(5 * time.Second)
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	b.Success()
+	b.Success()
+	assert.Equal(t, b.State(), BreakerClosed)
+
+	assert.Equal(t, events, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	})
+}
@@ -0,0 +1,371 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/redis"
+)
+
+// CachedResponse 缓存的响应。
+type CachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	Time   time.Time   `json:"time"` // 写入缓存的时间
+}
+
+// CacheStore 响应缓存的存储接口。
+type CacheStore interface {
+
+	// Get 返回 key 对应的缓存，缓存不存在或者已过期时返回 nil 。
+	Get(ctx context.Context, key string) (*CachedResponse, error)
+
+	// Set 保存缓存，ttl 是缓存的有效期。
+	Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error
+}
+
+// CacheOptions 响应缓存过滤器的选项。
+type CacheOptions struct {
+	TTL         time.Duration // 缓存的有效期，响应头 Cache-Control: max-age 优先
+	Vary        []string      // 参与生成缓存键的请求头
+	URLPatterns []string      // 生效的路由，为空时对所有路由生效
+}
+
+// cacheFilter 响应缓存过滤器。
+type cacheFilter struct {
+	store CacheStore
+	opts  CacheOptions
+}
+
+// CacheFilter 返回响应缓存过滤器，只缓存 GET 和 HEAD 请求的 200 响应，缓存键由
+// 请求方法、请求地址、选项中的 Vary 请求头以及响应头 Vary 列出的请求头组成。请求
+// 头 Cache-Control: no-cache 时跳过缓存读取，no-store 时既不读取也不写入；响应头
+// Cache-Control 包含 no-store 或者 private 时不缓存，带有 Set-Cookie 头的响应属于
+// 特定客户端，同样不缓存。带有 Authorization 或者 Cookie 头的请求只有在响应头
+// Cache-Control 包含 public 或者 s-maxage 时才缓存，响应头 Vary: * 时不缓存。受
+// ResponseWriter 的限制，只有 JSON、XML、文本和 HTML 格式的响应可以被缓存。
+func CacheFilter(store CacheStore, opts CacheOptions) Filter {
+	if len(opts.URLPatterns) == 0 {
+		opts.URLPatterns = []string{"/*"}
+	}
+	return &cacheFilter{store: store, opts: opts}
+}
+
+func (f *cacheFilter) URLPatterns() []string {
+	return f.opts.URLPatterns
+}
+
+// parseCacheControl 解析 Cache-Control 头，返回指令和对应的值。
+func parseCacheControl(s string) map[string]string {
+	m := make(map[string]string)
+	for _, d := range strings.Split(s, ",") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		ss := strings.SplitN(d, "=", 2)
+		k := strings.ToLower(strings.TrimSpace(ss[0]))
+		if len(ss) == 2 {
+			m[k] = strings.Trim(strings.TrimSpace(ss[1]), `"`)
+		} else {
+			m[k] = ""
+		}
+	}
+	return m
+}
+
+// key 返回请求对应的缓存键。
+func (f *cacheFilter) key(r *http.Request) string {
+	return varyKey(r.Method+" "+r.URL.RequestURI(), r, f.opts.Vary)
+}
+
+// varyKey 返回在 key 的基础上加入 vary 请求头的缓存键。
+func varyKey(key string, r *http.Request, vary []string) string {
+	var sb strings.Builder
+	sb.WriteString(key)
+	for _, h := range vary {
+		sb.WriteString("\n")
+		sb.WriteString(h)
+		sb.WriteString(":")
+		sb.WriteString(strings.Join(r.Header.Values(h), ","))
+	}
+	return sb.String()
+}
+
+// responseVary 返回响应头 Vary 列出的请求头，Vary: * 时返回 nil 和 false 。
+func responseVary(h http.Header) ([]string, bool) {
+	var vary []string
+	for _, v := range h.Values(HeaderVary) {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s == "*" {
+				return nil, false
+			} else if s != "" {
+				vary = append(vary, http.CanonicalHeaderKey(s))
+			}
+		}
+	}
+	return vary, true
+}
+
+// isVaryIndex 返回缓存是否是记录响应头 Vary 的索引。响应头 Vary 不为空时，缓存
+// 键对应的是 Status 为 0 、只包含 Vary 头的索引，响应本身保存在加入了 Vary 请求
+// 头的缓存键上。
+func isVaryIndex(resp *CachedResponse) bool {
+	return resp.Status == 0
+}
+
+// load 返回请求对应的缓存。
+func (f *cacheFilter) load(ctx context.Context, key string, r *http.Request) (*CachedResponse, error) {
+	resp, err := f.store.Get(ctx, key)
+	if err != nil || resp == nil || !isVaryIndex(resp) {
+		return resp, err
+	}
+	vary, _ := responseVary(resp.Header)
+	resp, err = f.store.Get(ctx, varyKey(key, r, vary))
+	if err != nil || resp == nil || isVaryIndex(resp) {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (f *cacheFilter) Invoke(ctx Context, chain FilterChain) {
+
+	r := ctx.Request()
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		chain.Next(ctx)
+		return
+	}
+
+	reqCC := parseCacheControl(r.Header.Get(HeaderCacheControl))
+	if _, ok := reqCC["no-store"]; ok {
+		chain.Next(ctx)
+		return
+	}
+
+	key := f.key(r)
+	if _, ok := reqCC["no-cache"]; !ok {
+		resp, err := f.load(ctx.Context(), key, r)
+		if err != nil {
+			log.Ctx(ctx.Context()).Warnf("load cache error: %v", err)
+		} else if resp != nil {
+			f.write(ctx, resp)
+			return
+		}
+	}
+
+	chain.Next(ctx)
+
+	w := ctx.ResponseWriter()
+	if w.Status() != http.StatusOK || !canPrintResponse(w) || isTruncated(w) {
+		return
+	}
+
+	ttl := f.opts.TTL
+	respCC := parseCacheControl(w.Header().Get(HeaderCacheControl))
+	if _, ok := respCC["no-store"]; ok {
+		return
+	}
+	if _, ok := respCC["private"]; ok {
+		return
+	}
+	if len(w.Header().Values(HeaderSetCookie)) > 0 {
+		return
+	}
+	if r.Header.Get(HeaderAuthorization) != "" || r.Header.Get(HeaderCookie) != "" {
+		_, public := respCC["public"]
+		_, sMaxAge := respCC["s-maxage"]
+		if !public && !sMaxAge {
+			return
+		}
+	}
+	vary, ok := responseVary(w.Header())
+	if !ok {
+		return
+	}
+	// 共享缓存优先使用 s-maxage 指定的有效期
+	for _, d := range []string{"s-maxage", "max-age"} {
+		if s, ok := respCC[d]; ok {
+			if n, err := strconv.Atoi(s); err == nil {
+				ttl = time.Duration(n) * time.Second
+				break
+			}
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	resp := &CachedResponse{
+		Status: w.Status(),
+		Header: w.Header().Clone(),
+		Body:   []byte(w.Body()),
+		Time:   time.Now(),
+	}
+	if len(vary) > 0 {
+		index := &CachedResponse{Header: http.Header{HeaderVary: vary}, Time: resp.Time}
+		if err := f.store.Set(ctx.Context(), key, index, ttl); err != nil {
+			log.Ctx(ctx.Context()).Warnf("save cache error: %v", err)
+			return
+		}
+		key = varyKey(key, r, vary)
+	}
+	if err := f.store.Set(ctx.Context(), key, resp, ttl); err != nil {
+		log.Ctx(ctx.Context()).Warnf("save cache error: %v", err)
+	}
+}
+
+// write 将缓存的响应发送给客户端，Age 响应头是缓存已经存在的秒数。
+func (f *cacheFilter) write(ctx Context, resp *CachedResponse) {
+	w := ctx.ResponseWriter()
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	age := int64(time.Since(resp.Time) / time.Second)
+	w.Header().Set(HeaderAge, strconv.FormatInt(age, 10))
+	w.WriteHeader(resp.Status)
+	if ctx.Request().Method != http.MethodHead {
+		_, _ = w.Write(resp.Body)
+	}
+}
+
+// DefaultMemoryCacheEntries MemoryCacheStore 默认的最大缓存条数。
+const DefaultMemoryCacheEntries = 10000
+
+type memoryCacheEntry struct {
+	key    string
+	resp   *CachedResponse
+	expire time.Time
+}
+
+// MemoryCacheStore 将响应缓存在内存中，过期的缓存在读取时删除，缓存条数超过上限
+// 时淘汰最久未被访问的缓存。
+type MemoryCacheStore struct {
+	mutex      sync.Mutex
+	maxEntries int
+	lru        *list.List // 队首是最近访问的缓存
+	entries    map[string]*list.Element
+}
+
+// NewMemoryCacheStore 返回新的 MemoryCacheStore 对象，maxEntries 是最大缓存条数，
+// 小于等于 0 时使用 DefaultMemoryCacheEntries 。
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Len 返回缓存条数，包括已过期但尚未删除的缓存。
+func (m *MemoryCacheStore) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.lru.Len()
+}
+
+func (m *MemoryCacheStore) Get(ctx context.Context, key string) (*CachedResponse, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	e := elem.Value.(*memoryCacheEntry)
+	if time.Now().After(e.expire) {
+		m.remove(elem)
+		return nil, nil
+	}
+	m.lru.MoveToFront(elem)
+	return e.resp, nil
+}
+
+func (m *MemoryCacheStore) Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	expire := time.Now().Add(ttl)
+	if elem, ok := m.entries[key]; ok {
+		e := elem.Value.(*memoryCacheEntry)
+		e.resp, e.expire = resp, expire
+		m.lru.MoveToFront(elem)
+		return nil
+	}
+	e := &memoryCacheEntry{key: key, resp: resp, expire: expire}
+	m.entries[key] = m.lru.PushFront(e)
+	for m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+	return nil
+}
+
+func (m *MemoryCacheStore) remove(elem *list.Element) {
+	m.lru.Remove(elem)
+	delete(m.entries, elem.Value.(*memoryCacheEntry).key)
+}
+
+// RedisCacheStore 将响应以 JSON 格式缓存在 Redis 中，适用于多实例部署。
+type RedisCacheStore struct {
+	client redis.Client
+	prefix string
+}
+
+// NewRedisCacheStore 返回新的 RedisCacheStore 对象，prefix 是键的前缀。
+func NewRedisCacheStore(client redis.Client, prefix string) *RedisCacheStore {
+	if prefix == "" {
+		prefix = "cache:"
+	}
+	return &RedisCacheStore{client: client, prefix: prefix}
+}
+
+func (r *RedisCacheStore) Get(ctx context.Context, key string) (*CachedResponse, error) {
+	s, err := r.client.Get(ctx, r.prefix+key)
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := new(CachedResponse)
+	if err = json.Unmarshal([]byte(s), resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *RedisCacheStore) Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	seconds := int64(ttl / time.Second)
+	if seconds <= 0 {
+		seconds = 1
+	}
+	_, err = r.client.SetEX(ctx, r.prefix+key, string(b), seconds)
+	return err
+}
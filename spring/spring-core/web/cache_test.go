@@ -0,0 +1,233 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	m := parseCacheControl(`public, max-age="60", no-cache`)
+	assert.Equal(t, m, map[string]string{"public": "", "max-age": "60", "no-cache": ""})
+}
+
+func TestCacheFilter_Key(t *testing.T) {
+	f := CacheFilter(NewMemoryCacheStore(0), CacheOptions{Vary: []string{"Accept-Language"}}).(*cacheFilter)
+	r := httptest.NewRequest("GET", "/users?page=1", nil)
+	r.Header.Set("Accept-Language", "zh")
+	assert.Equal(t, f.key(r), "GET /users?page=1\nAccept-Language:zh")
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryCacheStore(0)
+
+	resp, err := s.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, resp == nil)
+
+	assert.Nil(t, s.Set(ctx, "a", &CachedResponse{Status: 200, Body: []byte("hello")}, time.Minute))
+	resp, err = s.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, string(resp.Body), "hello")
+
+	assert.Nil(t, s.Set(ctx, "b", &CachedResponse{Status: 200}, -time.Second))
+	resp, err = s.Get(ctx, "b")
+	assert.Nil(t, err)
+	assert.True(t, resp == nil)
+}
+
+func TestMemoryCacheStore_MaxEntries(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryCacheStore(2)
+
+	assert.Nil(t, s.Set(ctx, "a", &CachedResponse{Status: 200}, time.Minute))
+	assert.Nil(t, s.Set(ctx, "b", &CachedResponse{Status: 200}, time.Minute))
+
+	resp, _ := s.Get(ctx, "a") // a 成为最近访问的缓存
+	assert.True(t, resp != nil)
+
+	assert.Nil(t, s.Set(ctx, "c", &CachedResponse{Status: 200}, time.Minute))
+	assert.Equal(t, s.Len(), 2)
+
+	resp, _ = s.Get(ctx, "b")
+	assert.True(t, resp == nil)
+	resp, _ = s.Get(ctx, "a")
+	assert.True(t, resp != nil)
+	resp, _ = s.Get(ctx, "c")
+	assert.True(t, resp != nil)
+
+	assert.Nil(t, s.Set(ctx, "d", &CachedResponse{Status: 200}, -time.Second))
+	resp, _ = s.Get(ctx, "d") // 读取时删除过期的缓存
+	assert.True(t, resp == nil)
+	assert.Equal(t, s.Len(), 1)
+}
+
+func TestCacheFilter(t *testing.T) {
+
+	count := 0
+	r := NewRouter()
+	r.GetMapping("/public", func(ctx Context) {
+		count++
+		ctx.Header(HeaderCacheControl, "max-age=60")
+		ctx.String(strconv.Itoa(count))
+	})
+	r.GetMapping("/login", func(ctx Context) {
+		count++
+		ctx.SetCookie(&http.Cookie{Name: "sid", Value: strconv.Itoa(count)})
+		ctx.Header(HeaderCacheControl, "max-age=60")
+		ctx.String(strconv.Itoa(count))
+	})
+	r.GetMapping("/private", func(ctx Context) {
+		count++
+		ctx.Header(HeaderCacheControl, "private, max-age=60")
+		ctx.String(strconv.Itoa(count))
+	})
+	r.GetMapping("/no-store", func(ctx Context) {
+		count++
+		ctx.Header(HeaderCacheControl, "no-store")
+		ctx.String(strconv.Itoa(count))
+	})
+
+	store := NewMemoryCacheStore(0)
+	h := ToHTTPHandler(r, CacheFilter(store, CacheOptions{TTL: time.Minute}))
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, w.Code, http.StatusOK)
+		return w
+	}
+
+	w := get("/public")
+	assert.Equal(t, w.Body.String(), "1")
+	w = get("/public")
+	assert.Equal(t, w.Body.String(), "1")
+	assert.Equal(t, w.Header().Get(HeaderAge), "0")
+
+	// 两个客户端访问设置 cookie 的接口，各自拿到自己的 cookie
+	w = get("/login")
+	assert.Equal(t, w.Body.String(), "2")
+	assert.Equal(t, w.Result().Cookies()[0].Value, "2")
+	w = get("/login")
+	assert.Equal(t, w.Body.String(), "3")
+	assert.Equal(t, w.Result().Cookies()[0].Value, "3")
+	assert.Equal(t, w.Header().Get(HeaderAge), "")
+
+	w = get("/private")
+	assert.Equal(t, w.Body.String(), "4")
+	w = get("/private")
+	assert.Equal(t, w.Body.String(), "5")
+
+	w = get("/no-store")
+	assert.Equal(t, w.Body.String(), "6")
+	w = get("/no-store")
+	assert.Equal(t, w.Body.String(), "7")
+
+	assert.Equal(t, store.Len(), 1)
+}
+
+func TestCacheFilter_Authorization(t *testing.T) {
+
+	count := 0
+	r := NewRouter()
+	r.GetMapping("/profile", func(ctx Context) {
+		count++
+		ctx.Header(HeaderCacheControl, "max-age=60")
+		ctx.String(strconv.Itoa(count))
+	})
+	r.GetMapping("/news", func(ctx Context) {
+		count++
+		ctx.Header(HeaderCacheControl, "public, max-age=60")
+		ctx.String(strconv.Itoa(count))
+	})
+	r.GetMapping("/notice", func(ctx Context) {
+		count++
+		ctx.Header(HeaderCacheControl, "s-maxage=60")
+		ctx.String(strconv.Itoa(count))
+	})
+
+	store := NewMemoryCacheStore(0)
+	h := ToHTTPHandler(r, CacheFilter(store, CacheOptions{TTL: time.Minute}))
+	get := func(path, header, value string) string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(header, value)
+		h.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, http.StatusOK)
+		return w.Body.String()
+	}
+
+	// 不同用户访问同一个地址，不能拿到其他用户的响应
+	assert.Equal(t, get("/profile", HeaderAuthorization, "Bearer alice"), "1")
+	assert.Equal(t, get("/profile", HeaderAuthorization, "Bearer bob"), "2")
+	assert.Equal(t, get("/profile", HeaderCookie, "sid=alice"), "3")
+	assert.Equal(t, get("/profile", HeaderCookie, "sid=bob"), "4")
+
+	assert.Equal(t, get("/news", HeaderAuthorization, "Bearer alice"), "5")
+	assert.Equal(t, get("/news", HeaderAuthorization, "Bearer bob"), "5")
+
+	assert.Equal(t, get("/notice", HeaderCookie, "sid=alice"), "6")
+	assert.Equal(t, get("/notice", HeaderCookie, "sid=bob"), "6")
+
+	assert.Equal(t, store.Len(), 2)
+}
+
+func TestCacheFilter_Vary(t *testing.T) {
+
+	count := 0
+	r := NewRouter()
+	r.GetMapping("/hello", func(ctx Context) {
+		count++
+		ctx.Header(HeaderVary, "accept-language")
+		ctx.Header(HeaderCacheControl, "max-age=60")
+		ctx.String(ctx.Request().Header.Get(HeaderAcceptLanguage) + strconv.Itoa(count))
+	})
+	r.GetMapping("/any", func(ctx Context) {
+		count++
+		ctx.Header(HeaderVary, "*")
+		ctx.String(strconv.Itoa(count))
+	})
+
+	store := NewMemoryCacheStore(0)
+	h := ToHTTPHandler(r, CacheFilter(store, CacheOptions{TTL: time.Minute}))
+	get := func(path, lang string) string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(HeaderAcceptLanguage, lang)
+		h.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, http.StatusOK)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, get("/hello", "zh"), "zh1")
+	assert.Equal(t, get("/hello", "en"), "en2")
+	assert.Equal(t, get("/hello", "zh"), "zh1")
+	assert.Equal(t, get("/hello", "en"), "en2")
+
+	assert.Equal(t, get("/any", "zh"), "3")
+	assert.Equal(t, get("/any", "zh"), "4")
+
+	// 一条 Vary 索引加上两个语言的响应
+	assert.Equal(t, store.Len(), 3)
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/util"
+	"github.com/go-spring/spring-core/conf"
+)
+
+// ClientHook HTTP 客户端的钩子，可用于链路追踪和指标统计。
+type ClientHook struct {
+	BeforeDoFunc func(req *http.Request) error
+	AfterDoFunc  func(req *http.Request, resp *http.Response, err error, cost time.Duration)
+}
+
+var clientHook atomic.Value // ClientHook
+
+// SetClientHook 设置 HTTP 客户端的钩子，可以在请求进行的同时调用。
+func SetClientHook(h ClientHook) {
+	clientHook.Store(h)
+}
+
+// getClientHook 返回 HTTP 客户端的钩子。
+func getClientHook() ClientHook {
+	h, _ := clientHook.Load().(ClientHook)
+	return h
+}
+
+// Balancer 客户端负载均衡器，为服务 service 选择一个 host:port 形式的地址，
+// 请求结束后需要调用 done 函数报告请求结果。
+type Balancer interface {
+	Next(ctx context.Context, service string) (address string, done func(err error), err error)
+}
+
+// Client 封装 http.Client 对象，支持失败重试、负载均衡、钩子以及流量录制和回放。
+type Client struct {
+	*http.Client
+	retries  int
+	backoff  time.Duration
+	balancer Balancer
+}
+
+// SetBalancer 设置负载均衡器，设置之后请求 URL 的 host 被当作服务名称，每次
+// 请求 (包括重试) 都会重新选择实例。
+func (c *Client) SetBalancer(b Balancer) {
+	c.balancer = b
+}
+
+// NewClient 创建 HTTP 客户端
+func NewClient(config conf.WebClientConfig) (*Client, error) {
+
+	tlsConfig, err := clientTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if config.Proxy != "" {
+		var u *url.URL
+		if u, err = url.Parse(config.Proxy); err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   time.Duration(config.ConnectTimeout) * time.Millisecond,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:        config.MaxIdleConns,
+		IdleConnTimeout:     time.Duration(config.IdleTimeout) * time.Millisecond,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+
+	return &Client{
+		Client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(config.Timeout) * time.Millisecond,
+		},
+		retries: config.Retries,
+		backoff: time.Duration(config.RetryBackoff) * time.Millisecond,
+	}, nil
+}
+
+func clientTLSConfig(config conf.WebClientConfig) (*tls.Config, error) {
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+
+	if config.CAFile != "" {
+		b, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errors.New("invalid ca file " + config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Do 发送 HTTP 请求，网络错误或者 5xx 响应时按照指数退避的间隔重试，
+// 有请求体的请求需要设置 req.GetBody 才能重试。
+func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
+
+	hook := getClientHook()
+	if hook.BeforeDoFunc != nil {
+		if err = hook.BeforeDoFunc(req); err != nil {
+			return nil, err
+		}
+	}
+
+	if hook.AfterDoFunc != nil {
+		start := util.Now(req.Context())
+		defer func() {
+			cost := util.Now(req.Context()).Sub(start)
+			hook.AfterDoFunc(req, resp, err, cost)
+		}()
+	}
+
+	if fastdev.ReplayMode() && fastdev.GetReplaySessionID(req.Context()) != "" {
+		return replayRequest(req)
+	}
+
+	var timeNow int64
+	if fastdev.Recording(req.Context()) {
+		timeNow = util.Now(req.Context()).UnixNano()
+	}
+
+	var reqDump []byte
+	if fastdev.Recording(req.Context()) {
+		if reqDump, err = httputil.DumpRequestOut(req, true); err != nil {
+			return nil, err
+		}
+	}
+
+	service := req.URL.Host
+	backoff := c.backoff
+	for i := 0; ; i++ {
+		if resp, err = c.do(req, service); errors.Is(err, errNoBalance) {
+			return nil, err
+		}
+		if !c.shouldRetry(req, resp, err, i) {
+			break
+		}
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if fastdev.Recording(req.Context()) && err == nil {
+		var respDump []byte
+		if respDump, err = httputil.DumpResponse(resp, true); err != nil {
+			return nil, err
+		}
+		fastdev.RecordAction(req.Context(), &fastdev.Action{
+			Protocol:  fastdev.HTTP,
+			Request:   string(reqDump),
+			Response:  string(respDump),
+			Timestamp: timeNow,
+			Latency:   util.Now(req.Context()).UnixNano() - timeNow,
+		})
+	}
+	return resp, err
+}
+
+var errNoBalance = errors.New("load balance failed")
+
+// do 发送一次请求，设置了负载均衡器时先为 service 选择实例，然后向实例发送请求
+// 的副本，调用者的 req 保持不变。
+func (c *Client) do(req *http.Request, service string) (*http.Response, error) {
+	if c.balancer == nil {
+		return c.Client.Do(req)
+	}
+	address, done, err := c.balancer.Next(req.Context(), service)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errNoBalance, service, err)
+	}
+	r := req.Clone(req.Context())
+	r.URL.Host, r.Host = address, address
+	resp, err := c.Client.Do(r)
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		done(fmt.Errorf("status code %d", resp.StatusCode))
+	} else {
+		done(err)
+	}
+	return resp, err
+}
+
+func (c *Client) shouldRetry(req *http.Request, resp *http.Response, err error, i int) bool {
+	if i >= c.retries || req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// replayRequest 从回放数据中返回请求对应的响应。
+func replayRequest(req *http.Request) (*http.Response, error) {
+
+	b, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	action := &fastdev.Action{
+		Protocol: fastdev.HTTP,
+		Request:  string(b),
+	}
+
+	ok, err := fastdev.ReplayAction(req.Context(), action)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("replay action not match")
+	}
+
+	s, ok := action.Response.(string)
+	if !ok {
+		return nil, errors.New("replay response should be string")
+	}
+	return http.ReadResponse(bufio.NewReader(strings.NewReader(s)), req)
+}
+
+// Get 发送 GET 请求。
+func (c *Client) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Post 发送 POST 请求。
+func (c *Client) Post(url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(HeaderContentType, contentType)
+	return c.Do(req)
+}
+
+// recordTransport 录制和回放下游 HTTP 请求的 http.RoundTripper 。
+type recordTransport struct {
+	next http.RoundTripper
+}
+
+// RecordTransport 返回录制和回放下游 HTTP 请求的 http.RoundTripper ，用于为
+// 第三方 SDK 使用的 http.Client 打开流量录制，next 为空时使用 http.DefaultTransport 。
+func RecordTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recordTransport{next: next}
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if fastdev.ReplayMode() && fastdev.GetReplaySessionID(req.Context()) != "" {
+		return replayRequest(req)
+	}
+
+	if !fastdev.Recording(req.Context()) {
+		return t.next.RoundTrip(req)
+	}
+
+	reqDump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	start := util.Now(req.Context())
+	resp, err := t.next.RoundTrip(req)
+	latency := util.Now(req.Context()).Sub(start)
+	if err != nil {
+		return nil, err
+	}
+
+	respDump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, err
+	}
+
+	fastdev.RecordAction(req.Context(), &fastdev.Action{
+		Protocol:  fastdev.HTTP,
+		Request:   string(reqDump),
+		Response:  string(respDump),
+		Timestamp: start.UnixNano(),
+		Latency:   int64(latency),
+	})
+	return resp, nil
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/discovery"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestClient_Retry(t *testing.T) {
+
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := web.NewClient(conf.WebClientConfig{Retries: 2, RetryBackoff: 1})
+	assert.Nil(t, err)
+
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), "ok")
+	assert.Equal(t, count, 3)
+
+	count = -10
+	client, err = web.NewClient(conf.WebClientConfig{Retries: 1, RetryBackoff: 1})
+	assert.Nil(t, err)
+
+	resp, err = client.Get(server.URL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusServiceUnavailable)
+	assert.Equal(t, count, -8)
+}
+
+func TestClient_Balancer(t *testing.T) {
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer bad.Close()
+
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Host))
+	}))
+	defer good.Close()
+
+	ctx := context.Background()
+	r := discovery.NewMemoryRegistry()
+	for i, s := range []string{bad.URL, good.URL} {
+		u, _ := url.Parse(s)
+		port, _ := strconv.Atoi(u.Port())
+		err := r.Register(ctx, &discovery.Instance{ID: strconv.Itoa(i), Name: "order", Host: u.Hostname(), Port: port})
+		assert.Nil(t, err)
+	}
+
+	client, err := web.NewClient(conf.WebClientConfig{Retries: 1, RetryBackoff: 1})
+	assert.Nil(t, err)
+	client.SetBalancer(discovery.NewLoadBalancer(r, discovery.NewBalancer(discovery.RoundRobin, discovery.EjectionPolicy{})))
+
+	req, err := http.NewRequest(http.MethodGet, "http://order/api", nil)
+	assert.Nil(t, err)
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, string(b), good.URL[len("http://"):])
+
+	// 调用者的请求不会被修改，可以再次发送
+	assert.Equal(t, req.URL.Host, "order")
+	assert.Equal(t, req.Host, "order")
+
+	_, err = client.Get("http://user/api")
+	assert.Error(t, err, "load balance failed: user: no available instance")
+}
+
+func TestClient_ReplayWithoutSession(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	fastdev.SetReplayMode(true, false)
+	defer fastdev.SetReplayMode(false, false)
+
+	client, err := web.NewClient(conf.WebClientConfig{})
+	assert.Nil(t, err)
+
+	// 没有回放会话的请求 (例如启动阶段的请求) 正常发送
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, string(b), "ok")
+}
+
+func TestRecordTransport(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	fastdev.SetRecordMode(true)
+	defer fastdev.SetRecordMode(false)
+
+	ctx := knife.New(context.Background())
+	err := knife.Set(ctx, fastdev.RecordSessionIDKey, fastdev.NewSessionID())
+	assert.Nil(t, err)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+
+	client := &http.Client{Transport: web.RecordTransport(nil)}
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	session := fastdev.RecordInbound(ctx, &fastdev.Action{})
+	assert.Equal(t, len(session.Actions), 1)
+	assert.Equal(t, session.Actions[0].Protocol, fastdev.HTTP)
+	assert.True(t, session.Actions[0].Latency > 0)
+}
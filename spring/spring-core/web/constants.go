@@ -17,12 +17,30 @@
 package web
 
 const (
+	HeaderAccept             = "Accept"
+	HeaderAcceptLanguage     = "Accept-Language"
+	HeaderAge                = "Age"
+	HeaderAllow              = "Allow"
+	HeaderAuthorization      = "Authorization"
+	HeaderCacheControl       = "Cache-Control"
 	HeaderContentDisposition = "Content-Disposition"
+	HeaderContentLength      = "Content-Length"
 	HeaderContentType        = "Content-Type"
+	HeaderCookie             = "Cookie"
+	HeaderETag               = "ETag"
+	HeaderIfModifiedSince    = "If-Modified-Since"
+	HeaderIfNoneMatch        = "If-None-Match"
+	HeaderLastModified       = "Last-Modified"
+	HeaderSetCookie          = "Set-Cookie"
+	HeaderVary               = "Vary"
+	HeaderXForwardedFor      = "X-Forwarded-For"
+	HeaderXForwardedHost     = "X-Forwarded-Host"
 	HeaderXForwardedProto    = "X-Forwarded-Proto"
 	HeaderXForwardedProtocol = "X-Forwarded-Protocol"
 	HeaderXForwardedSsl      = "X-Forwarded-Ssl"
 	HeaderXUrlScheme         = "X-Url-Scheme"
+	HeaderXRequestID         = "X-Request-Id"
+	HeaderTraceparent        = "traceparent"
 
 	CharsetUTF8 = "charset=UTF-8"
 
@@ -41,10 +59,13 @@ const (
 	MIMETextHTMLCharsetUTF8              = MIMETextHTML + "; " + CharsetUTF8
 	MIMETextPlain                        = "text/plain"
 	MIMETextPlainCharsetUTF8             = MIMETextPlain + "; " + CharsetUTF8
+	MIMETextEventStream                  = "text/event-stream"
 	MIMEMultipartForm                    = "multipart/form-data"
 	MIMEOctetStream                      = "application/octet-stream"
 	MIMEJsonAPI                          = "application/vnd.api+json"
 	MIMEJsonStream                       = "application/x-json-stream"
+	MIMEApplicationProblemJSON           = "application/problem+json"
+	MIMEApplicationNDJSON                = "application/x-ndjson"
 	MIMEImagePng                         = "image/png"
 	MIMEImageJpeg                        = "image/jpeg"
 	MIMEImageGif                         = "image/gif"
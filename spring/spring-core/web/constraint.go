@@ -0,0 +1,141 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-spring/spring-base/knife"
+)
+
+// PathConstraint 路径参数的约束，返回转换后的值，不满足约束时返回错误。
+type PathConstraint func(s string) (interface{}, error)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var pathConstraints = struct {
+	mutex sync.RWMutex
+	items map[string]PathConstraint
+}{
+	items: map[string]PathConstraint{
+		"int": func(s string) (interface{}, error) {
+			return strconv.ParseInt(s, 10, 64)
+		},
+		"uint": func(s string) (interface{}, error) {
+			return strconv.ParseUint(s, 10, 64)
+		},
+		"float": func(s string) (interface{}, error) {
+			return strconv.ParseFloat(s, 64)
+		},
+		"bool": func(s string) (interface{}, error) {
+			return strconv.ParseBool(s)
+		},
+		"alpha": func(s string) (interface{}, error) {
+			for _, c := range s {
+				if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+					return nil, fmt.Errorf("%q isn't alpha", s)
+				}
+			}
+			return s, nil
+		},
+		"uuid": func(s string) (interface{}, error) {
+			if !uuidRegexp.MatchString(s) {
+				return nil, fmt.Errorf("%q isn't uuid", s)
+			}
+			return s, nil
+		},
+	},
+}
+
+// RegisterPathConstraint 注册路径参数的约束，已存在的同名约束会被覆盖。
+func RegisterPathConstraint(name string, c PathConstraint) {
+	pathConstraints.mutex.Lock()
+	defer pathConstraints.mutex.Unlock()
+	pathConstraints.items[name] = c
+}
+
+// ParsePathConstraints 返回路由地址中的路径参数约束，例如 /users/{id:int}
+// 返回 id 对应的 int 约束，约束不存在时返回错误。
+func ParsePathConstraints(path string) (map[string]PathConstraint, error) {
+
+	pathConstraints.mutex.RLock()
+	defer pathConstraints.mutex.RUnlock()
+
+	var ret map[string]PathConstraint
+	for _, s := range strings.Split(path, "/") {
+		if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+			continue
+		}
+		ss := strings.Split(s[1:len(s)-1], ":")
+		if len(ss) < 2 || ss[0] == "*" || ss[1] == "*" {
+			continue
+		}
+		c, ok := pathConstraints.items[ss[1]]
+		if !ok {
+			return nil, fmt.Errorf("path constraint %q not found in %s", ss[1], path)
+		}
+		if ret == nil {
+			ret = make(map[string]PathConstraint)
+		}
+		ret[ss[0]] = c
+	}
+	return ret, nil
+}
+
+const pathValuesKey = "::path-values::"
+
+// PathValue 返回经过约束转换后的路径参数，例如 {id:int} 返回 int64 类型的值。
+func PathValue(ctx Context, name string) (interface{}, bool) {
+	var m map[string]interface{}
+	ok, err := knife.Fetch(ctx.Context(), pathValuesKey, &m)
+	if err != nil || !ok {
+		return nil, false
+	}
+	v, ok := m[name]
+	return v, ok
+}
+
+// constraintFilter 检查路径参数约束的过滤器。
+type constraintFilter struct {
+	constraints map[string]PathConstraint
+}
+
+// PathConstraintFilter 返回检查路径参数约束的过滤器，不满足约束时返回 404 错误。
+func PathConstraintFilter(constraints map[string]PathConstraint) Filter {
+	return &constraintFilter{constraints: constraints}
+}
+
+func (f *constraintFilter) Invoke(ctx Context, chain FilterChain) {
+	m := make(map[string]interface{}, len(f.constraints))
+	for name, c := range f.constraints {
+		v, err := c(ctx.PathParam(name))
+		if err != nil {
+			panic(NewHttpError(http.StatusNotFound))
+		}
+		m[name] = v
+	}
+	if err := knife.Set(ctx.Context(), pathValuesKey, m); err != nil {
+		panic(errors.New("path values already set"))
+	}
+	chain.Next(ctx)
+}
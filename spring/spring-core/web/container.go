@@ -19,9 +19,11 @@ package web
 
 import (
 	"context"
-	"fmt"
+	"net"
 	"net/http"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-spring/spring-base/log"
@@ -69,6 +71,22 @@ type Container interface {
 
 	// Stop 停止 Web 容器
 	Stop(ctx context.Context) error
+
+	// ServeHTTP 直接处理请求，不经过网络，容器启动后才能使用
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+// ContainerFactory 根据配置创建 Web 容器，用于创建 web.servers.* 配置的附加
+// Web 服务器，由 starter-gin 、starter-echo 等提供具体的实现。
+type ContainerFactory interface {
+	NewContainer(config conf.WebServerConfig) Container
+}
+
+// ContainerFactoryFunc 将 Container 的构造函数适配为 ContainerFactory 。
+type ContainerFactoryFunc func(config conf.WebServerConfig) Container
+
+func (f ContainerFactoryFunc) NewContainer(config conf.WebServerConfig) Container {
+	return f(config)
 }
 
 // AbstractContainer 抽象的 Container 实现
@@ -88,7 +106,12 @@ func NewAbstractContainer(config conf.WebServerConfig) *AbstractContainer {
 
 // Address 返回监听地址
 func (c *AbstractContainer) Address() string {
-	return fmt.Sprintf("%s:%d", c.config.IP, c.config.Port)
+	return ListenAddress(c.config)
+}
+
+// Listen 根据容器配置创建监听器，参见 Listen 函数。
+func (c *AbstractContainer) Listen() (net.Listener, error) {
+	return Listen(c.config)
 }
 
 // Config 获取 Web 容器配置
@@ -119,6 +142,34 @@ func (c *AbstractContainer) SetLoggerFilter(filter Filter) {
 	c.logger = filter
 }
 
+// MapperFilters 返回 Mapper 需要执行的过滤器，路由地址带有参数约束时添加
+// 约束检查过滤器，设置了超时时间时添加超时过滤器。
+func (c *AbstractContainer) MapperFilters(mapper *Mapper, filters []Filter) ([]Filter, error) {
+
+	var ret []Filter
+
+	constraints, err := ParsePathConstraints(mapper.Path())
+	if err != nil {
+		return nil, err
+	}
+	if len(constraints) > 0 {
+		ret = append(ret, PathConstraintFilter(constraints))
+	}
+
+	timeout := mapper.Timeout()
+	if timeout == 0 {
+		timeout = c.config.Timeout
+	}
+	if timeout > 0 {
+		ret = append(ret, TimeoutFilter(timeout))
+	}
+
+	if len(ret) == 0 {
+		return filters, nil
+	}
+	return append(ret, filters...), nil
+}
+
 // Swagger 设置与容器绑定的 Swagger 对象
 func (c *AbstractContainer) Swagger(swagger Swagger) {
 	c.swagger = swagger
@@ -153,6 +204,9 @@ func (c *AbstractContainer) Start() error {
 		swaggerHandler(&c.router, c.swagger.ReadDoc())
 	}
 
+	c.mappers = MergeVersionMappers(c.mappers)
+	c.addImplicitMappers()
+
 	for _, mapper := range c.Mappers() {
 		log.Infof("%v :%d %s -> %s:%d %s", func() []interface{} {
 			method := GetMethod(mapper.method)
@@ -164,11 +218,63 @@ func (c *AbstractContainer) Start() error {
 	return nil
 }
 
+// addImplicitMappers 为注册了 GET 但没有注册 HEAD 的路由添加 HEAD 方法，HEAD
+// 请求的响应体由 net/http 丢弃；为没有注册 OPTIONS 的路由添加 OPTIONS 方法，返回
+// 带有 Allow 头的 204 响应。显式注册的 HEAD 和 OPTIONS 方法不会被覆盖。
+func (c *AbstractContainer) addImplicitMappers() {
+
+	mappers := util.NewMultiMap()
+	for _, mapper := range c.Mappers() {
+		mappers.Add(mapper.path, mapper)
+	}
+
+	mappers.Range(func(path string, values []interface{}) bool {
+		var method uint32
+		var m *Mapper
+		for _, v := range values {
+			mapper := v.(*Mapper)
+			method |= mapper.method
+			if mapper.method&MethodGet != 0 && m == nil {
+				m = mapper
+			}
+		}
+		if m != nil && method&MethodHead == 0 {
+			head := NewMapper(MethodHead, path, m.handler)
+			head.timeout = m.timeout
+			c.AddMapper(head)
+			method |= MethodHead
+		}
+		if method&MethodOptions == 0 {
+			allow := GetMethod(method | MethodOptions)
+			sort.Strings(allow)
+			c.AddMapper(NewMapper(MethodOptions, path, optionsHandler(strings.Join(allow, ", "))))
+		}
+		return true
+	})
+}
+
+// optionsHandler 自动添加的 OPTIONS 方法处理函数。
+type optionsHandler string
+
+func (h optionsHandler) Invoke(ctx Context) {
+	ctx.Header(HeaderAllow, string(h))
+	ctx.NoContent(http.StatusNoContent)
+}
+
+func (h optionsHandler) FileLine() (file string, line int, fnName string) {
+	return util.FileLine(optionsHandler.Invoke)
+}
+
 // Stop 停止 Web 容器
 func (c *AbstractContainer) Stop(ctx context.Context) error {
 	panic(util.UnimplementedMethod)
 }
 
+// ServeHTTP 直接处理请求，不经过网络
+func (c *AbstractContainer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	panic(util.UnimplementedMethod)
+}
+
 /////////////////// Invoke Handler //////////////////////
 
 // InvokeHandler 执行 Web 处理函数
@@ -239,6 +345,10 @@ func WrapH(h http.Handler) Handler {
 
 // defaultLoggerFilter 全局的日志过滤器，Container 如果没有设置日志过滤器则会使用全局的日志过滤器
 var defaultLoggerFilter = Filter(FuncFilter(func(ctx Context, chain FilterChain) {
+	if excludeAccessLog(ctx.Request()) {
+		chain.Next(ctx)
+		return
+	}
 	start := time.Now()
 	chain.Next(ctx)
 	w := ctx.ResponseWriter()
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestAbstractContainer_ImplicitMappers(t *testing.T) {
+
+	c := web.NewAbstractContainer(conf.DefaultWebServerConfig())
+	c.GetMapping("/a", func(ctx web.Context) {})
+	c.PostMapping("/a", func(ctx web.Context) {})
+	c.GetMapping("/b", func(ctx web.Context) {})
+	c.RequestMapping(web.MethodHead, "/b", func(ctx web.Context) {})
+	c.RequestMapping(web.MethodOptions, "/b", func(ctx web.Context) {})
+	c.PostMapping("/c", func(ctx web.Context) {})
+	assert.Nil(t, c.Start())
+
+	methods := make(map[string]uint32)
+	for _, m := range c.Mappers() {
+		methods[m.Path()] |= m.Method()
+	}
+	assert.Equal(t, methods["/a"], uint32(web.MethodGet|web.MethodHead|web.MethodPost|web.MethodOptions))
+	assert.Equal(t, methods["/b"], uint32(web.MethodGet|web.MethodHead|web.MethodOptions))
+	assert.Equal(t, methods["/c"], uint32(web.MethodPost|web.MethodOptions))
+	assert.Equal(t, len(c.Mappers()), 9)
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -188,9 +189,14 @@ type Context interface {
 	Cookies() []*http.Cookie
 
 	// Bind binds the request body into provided type `i`. The default binder
-	// does it based on Content-Type header.
+	// does it based on Content-Type header. Then binds header, query, path
+	// and cookie parameters by struct tags and validates `i`, see BindParams.
 	Bind(i interface{}) error
 
+	// Session returns the session of the request, SessionFilter must be
+	// configured, see GetSession.
+	Session() (*Session, error)
+
 	/////////////////////////////////////////
 	// Response Part
 
@@ -249,6 +255,10 @@ type Context interface {
 	// Blob sends a blob response and content type. Maybe panic.
 	Blob(contentType string, b []byte)
 
+	// Respond sends a response in the format negotiated by the Accept
+	// header, see Negotiate. Maybe panic.
+	Respond(i interface{})
+
 	// File sends a response with the content of the file. Maybe panic.
 	File(file string)
 
@@ -269,8 +279,18 @@ type Context interface {
 // BufferedResponseWriter http.ResponseWriter 的一种增强型实现.
 type BufferedResponseWriter struct {
 	http.ResponseWriter
-	buffer bytes.Buffer
+	buffer    bytes.Buffer
+	status    int
+	size      int
+	truncated bool
+	writers   []io.WriteCloser // 改写响应体的写入器，后添加的先执行
+	held      *heldResponse    // 暂存的响应，参见 Hold 方法
+}
+
+// heldResponse 暂存的响应码和响应体。
+type heldResponse struct {
 	status int
+	body   bytes.Buffer
 }
 
 // Status Returns the HTTP response status code of the current request.
@@ -280,14 +300,28 @@ func (w *BufferedResponseWriter) Status() int {
 
 // Size Returns the number of bytes already written into the response http body.
 func (w *BufferedResponseWriter) Size() int {
-	return w.buffer.Len()
+	return w.size
 }
 
-// Body 返回发送给客户端的数据，当前仅支持 MIMEApplicationJSON 格式.
+// Written 返回是否已经发送了响应头或者响应体。
+func (w *BufferedResponseWriter) Written() bool {
+	return w.status != 0 || w.size > 0
+}
+
+// Body 返回发送给客户端的数据，当前仅支持文本格式和流式的 SSE 、NDJSON 格式，
+// 超过 RecordRule.MaxBodySize 的部分被丢弃并且以 TruncatedMarker 结尾。
 func (w *BufferedResponseWriter) Body() string {
+	if w.truncated {
+		return w.buffer.String() + TruncatedMarker
+	}
 	return w.buffer.String()
 }
 
+// Truncated 返回缓存的响应体是否被截断。
+func (w *BufferedResponseWriter) Truncated() bool {
+	return w.truncated
+}
+
 func filterFlags(content string) string {
 	for i, char := range strings.ToLower(content) {
 		if char == ' ' || char == ';' {
@@ -308,15 +342,122 @@ func canPrintResponse(response http.ResponseWriter) bool {
 }
 
 func (w *BufferedResponseWriter) WriteHeader(code int) {
+	if w.held != nil {
+		w.held.status = code
+		w.status = code
+		return
+	}
+	if len(w.writers) > 0 {
+		w.ResponseWriter.Header().Del(HeaderContentLength)
+	}
 	w.ResponseWriter.WriteHeader(code)
 	w.status = code
 }
 
-func (w *BufferedResponseWriter) Write(data []byte) (n int, err error) {
-	if n, err = w.ResponseWriter.Write(data); err == nil {
-		if canPrintResponse(w.ResponseWriter) {
-			w.buffer.Write(data[:n])
+// Transform 使用 fn 返回的写入器改写之后写入的响应体，fn 的参数是下一层的写入
+// 器，多次调用时后添加的写入器先执行。返回的函数用于结束改写，它会关闭 fn 返回
+// 的写入器以便写入剩余的数据，必须按照与添加相反的顺序调用。
+func (w *BufferedResponseWriter) Transform(fn func(w io.Writer) io.WriteCloser) func() error {
+	var next io.Writer = rawWriter{w}
+	if n := len(w.writers); n > 0 {
+		next = w.writers[n-1]
+	}
+	w.writers = append(w.writers, fn(next))
+	return func() error {
+		n := len(w.writers)
+		wc := w.writers[n-1]
+		w.writers = w.writers[:n-1]
+		return wc.Close()
+	}
+}
+
+// Hold 暂存之后写入的响应码和响应体，不发送给客户端，用于需要根据完整的响应体
+// 修改响应头的过滤器，例如计算 ETag 。返回的函数结束暂存，fn 可以检查暂存的响应
+// 体并修改响应头，返回 true 时发送暂存的响应码和响应体，返回 false 时丢弃它们，
+// fn 为 nil 时直接发送。暂存的响应体已经经过 Transform 改写，发送时不会再次改写。
+func (w *BufferedResponseWriter) Hold() func(fn func(body []byte) bool) {
+	held := new(heldResponse)
+	w.held = held
+	return func(fn func(body []byte) bool) {
+		w.held = nil
+		if fn != nil && !fn(held.body.Bytes()) {
+			return
 		}
+		if held.status != 0 {
+			w.WriteHeader(held.status)
+		}
+		if held.body.Len() > 0 {
+			_, _ = w.write(held.body.Bytes())
+		}
+	}
+}
+
+// rawWriter 绕过改写直接写入 BufferedResponseWriter 。
+type rawWriter struct {
+	w *BufferedResponseWriter
+}
+
+func (r rawWriter) Write(data []byte) (int, error) {
+	return r.w.write(data)
+}
+
+// Flush 将缓冲的数据发送给客户端，底层对象不支持时什么也不做。
+func (w *BufferedResponseWriter) Flush() {
+	if w.held != nil {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// canBufferResponse 返回是否缓存响应体，流式响应也会被缓存，以便能够录制。
+func canBufferResponse(response http.ResponseWriter) bool {
+	if canPrintResponse(response) {
+		return true
+	}
+	switch filterFlags(response.Header().Get(HeaderContentType)) {
+	case MIMETextEventStream, MIMEApplicationNDJSON, MIMEJsonStream:
+		return true
+	}
+	return false
+}
+
+func (w *BufferedResponseWriter) Write(data []byte) (n int, err error) {
+	if n := len(w.writers); n > 0 {
+		return w.writers[n-1].Write(data)
+	}
+	return w.write(data)
+}
+
+func (w *BufferedResponseWriter) write(data []byte) (n int, err error) {
+	if w.held != nil {
+		return w.held.body.Write(data)
+	}
+	if len(w.writers) > 0 {
+		w.ResponseWriter.Header().Del(HeaderContentLength)
+	}
+	n, err = w.ResponseWriter.Write(data)
+	w.size += n
+	if n > 0 && canBufferResponse(w.ResponseWriter) {
+		w.truncated = limitWrite(&w.buffer, data[:n], recordMaxBodySize()) || w.truncated
 	}
 	return
 }
+
+// limitWrite 向 buf 写入不超过 limit 字节的数据，limit 小于等于 0 表示不限制，
+// 返回是否有数据被丢弃。
+func limitWrite(buf *bytes.Buffer, data []byte, limit int) bool {
+	if limit <= 0 {
+		buf.Write(data)
+		return false
+	}
+	if remain := limit - buf.Len(); remain < len(data) {
+		if remain > 0 {
+			buf.Write(data[:remain])
+		}
+		return true
+	}
+	buf.Write(data)
+	return false
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"path"
+
+	"github.com/go-spring/spring-base/knife"
+)
+
+type CSRFMode int
+
+const (
+	CSRFDoubleSubmit = CSRFMode(0) // 双重提交 cookie 模式
+	CSRFSynchronizer = CSRFMode(1) // 同步令牌模式，令牌保存在会话中
+)
+
+// CSRFOptions CSRF 过滤器的选项。
+type CSRFOptions struct {
+	Mode       CSRFMode // 工作模式
+	CookieName string   // 双重提交模式下保存令牌的 cookie 名称
+	HeaderName string   // 提交令牌的请求头
+	FormField  string   // 提交令牌的表单字段
+	Exclude    []string // 不做检查的路径，使用 path.Match 进行匹配
+	Secure     bool     // 令牌 cookie 是否只在 https 下发送
+
+	// HttpOnly 令牌 cookie 是否禁止脚本访问，默认允许脚本读取 cookie 并通过
+	// 请求头提交令牌，令牌只通过 CSRFToken 写入页面表单时可以开启。
+	HttpOnly bool
+
+	// SameSite 令牌 cookie 的跨站策略，默认为 http.SameSiteLaxMode 。
+	SameSite http.SameSite
+}
+
+// csrfFilter CSRF 过滤器。
+type csrfFilter struct {
+	opts CSRFOptions
+}
+
+// CSRFFilter 返回 CSRF 过滤器，对于 GET、HEAD、OPTIONS、TRACE 以外的请求，
+// 检查请求头或者表单中提交的令牌是否与 cookie 或者会话中的令牌相同，不同时返回
+// 403 错误。同步令牌模式需要先配置 SessionFilter 过滤器。
+func CSRFFilter(opts CSRFOptions) Filter {
+	if opts.CookieName == "" {
+		opts.CookieName = "XSRF-TOKEN"
+	}
+	if opts.HeaderName == "" {
+		opts.HeaderName = "X-XSRF-TOKEN"
+	}
+	if opts.FormField == "" {
+		opts.FormField = "_csrf"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+	return &csrfFilter{opts: opts}
+}
+
+const (
+	csrfKey        = "::csrf::"
+	csrfSessionKey = "_csrf"
+)
+
+// CSRFToken 返回当前请求的 CSRF 令牌，用于在页面或者响应中下发给客户端。
+func CSRFToken(ctx Context) string {
+	var token string
+	_, _ = knife.Fetch(ctx.Context(), csrfKey, &token)
+	return token
+}
+
+func (f *csrfFilter) Invoke(ctx Context, chain FilterChain) {
+
+	for _, pattern := range f.opts.Exclude {
+		if ok, _ := path.Match(pattern, ctx.Request().URL.Path); ok {
+			chain.Next(ctx)
+			return
+		}
+	}
+
+	token, err := f.loadToken(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	if err = knife.Set(ctx.Context(), csrfKey, token); err != nil {
+		panic(err)
+	}
+
+	switch ctx.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+	default:
+		got := ctx.GetHeader(f.opts.HeaderName)
+		if got == "" {
+			got = ctx.FormValue(f.opts.FormField)
+		}
+		if got == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			panic(NewHttpError(http.StatusForbidden, "invalid csrf token"))
+		}
+	}
+
+	chain.Next(ctx)
+}
+
+// loadToken 加载当前请求的令牌，令牌不存在时生成新的令牌。
+func (f *csrfFilter) loadToken(ctx Context) (string, error) {
+
+	if f.opts.Mode == CSRFSynchronizer {
+		s, err := GetSession(ctx)
+		if err != nil {
+			return "", err
+		}
+		if token, ok := s.Get(csrfSessionKey).(string); ok && token != "" {
+			return token, nil
+		}
+		token := newCSRFToken()
+		s.Set(csrfSessionKey, token)
+		return token, s.Save()
+	}
+
+	if c, err := ctx.Cookie(f.opts.CookieName); err == nil && c.Value != "" {
+		return c.Value, nil
+	}
+
+	token := newCSRFToken()
+	ctx.SetCookie(&http.Cookie{
+		Name:     f.opts.CookieName,
+		Value:    token,
+		Path:     "/",
+		Secure:   f.opts.Secure,
+		HttpOnly: f.opts.HttpOnly,
+		SameSite: f.opts.SameSite,
+	})
+	return token, nil
+}
+
+// newCSRFToken 生成随机的 CSRF 令牌。
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
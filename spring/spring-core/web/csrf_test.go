@@ -0,0 +1,135 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func newCSRFHandler(filters ...web.Filter) http.Handler {
+	r := web.NewRouter()
+	r.GetMapping("/form", func(ctx web.Context) { ctx.String(web.CSRFToken(ctx)) })
+	r.PostMapping("/submit", func(ctx web.Context) { ctx.String("ok") })
+	r.PostMapping("/webhook", func(ctx web.Context) { ctx.String("ok") })
+	return web.ToHTTPHandler(r, append([]web.Filter{web.RecoveryFilter(0)}, filters...)...)
+}
+
+// getCSRFToken 发送 GET 请求获取令牌和令牌 cookie 。
+func getCSRFToken(t *testing.T, h http.Handler) (string, *http.Cookie) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+	assert.Equal(t, w.Code, http.StatusOK)
+	cookies := w.Result().Cookies()
+	assert.Equal(t, len(cookies), 1)
+	return w.Body.String(), cookies[0]
+}
+
+func TestCSRFFilter_DoubleSubmit(t *testing.T) {
+
+	h := newCSRFHandler(web.CSRFFilter(web.CSRFOptions{Exclude: []string{"/webhook"}}))
+	token, cookie := getCSRFToken(t, h)
+	assert.True(t, token != "")
+	assert.Equal(t, cookie.Name, "XSRF-TOKEN")
+	assert.Equal(t, cookie.Value, token)
+	assert.Equal(t, cookie.Path, "/")
+	assert.False(t, cookie.Secure)
+	assert.False(t, cookie.HttpOnly) // 脚本需要读取 cookie 并通过请求头提交令牌
+	assert.Equal(t, cookie.SameSite, http.SameSiteLaxMode)
+
+	post := func(header map[string]string, form url.Values, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(form.Encode()))
+		req.Header.Set(web.HeaderContentType, web.MIMEApplicationForm)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(nil, nil, cookie)
+	assert.Equal(t, w.Code, http.StatusForbidden)
+
+	w = post(map[string]string{"X-XSRF-TOKEN": token + "x"}, nil, cookie)
+	assert.Equal(t, w.Code, http.StatusForbidden)
+
+	w = post(map[string]string{"X-XSRF-TOKEN": token}, nil)
+	assert.Equal(t, w.Code, http.StatusForbidden)
+
+	w = post(map[string]string{"X-XSRF-TOKEN": token}, nil, cookie)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), "ok")
+
+	w = post(nil, url.Values{"_csrf": {token}}, cookie)
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	w = post(nil, url.Values{"_csrf": {"other"}}, cookie)
+	assert.Equal(t, w.Code, http.StatusForbidden)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusOK)
+}
+
+func TestCSRFFilter_CookieOptions(t *testing.T) {
+
+	h := newCSRFHandler(web.CSRFFilter(web.CSRFOptions{
+		CookieName: "csrf",
+		Secure:     true,
+		HttpOnly:   true,
+		SameSite:   http.SameSiteStrictMode,
+	}))
+	_, cookie := getCSRFToken(t, h)
+	assert.Equal(t, cookie.Name, "csrf")
+	assert.True(t, cookie.Secure)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, cookie.SameSite, http.SameSiteStrictMode)
+}
+
+func TestCSRFFilter_Synchronizer(t *testing.T) {
+
+	store := web.NewMemorySessionStore()
+	defer store.Close()
+	session := web.SessionFilter(store, web.SessionOptions{})
+	h := newCSRFHandler(session, web.CSRFFilter(web.CSRFOptions{Mode: web.CSRFSynchronizer}))
+	token, cookie := getCSRFToken(t, h)
+	assert.Equal(t, cookie.Name, "SESSION")
+
+	post := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/submit", nil)
+		req.Header.Set("X-XSRF-TOKEN", token)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, post("").Code, http.StatusForbidden)
+	assert.Equal(t, post("other").Code, http.StatusForbidden)
+	assert.Equal(t, post(token).Code, http.StatusOK)
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag 返回 b 对应的强校验 ETag 值。
+func ETag(b []byte) string {
+	sum := sha1.Sum(b)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// WeakETag 返回 b 对应的弱校验 ETag 值。
+func WeakETag(b []byte) string {
+	return "W/" + ETag(b)
+}
+
+// ETagMatch 判断 If-None-Match 请求头是否与 etag 匹配，使用弱比较算法。
+func ETagMatch(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch = strings.TrimSpace(ifNoneMatch); ifNoneMatch == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, s := range strings.Split(ifNoneMatch, ",") {
+		s = strings.TrimPrefix(strings.TrimSpace(s), "W/")
+		if s == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckNotModified 设置 ETag 和 Last-Modified 响应头，然后根据条件请求头判断
+// 资源是否没有修改，没有修改时返回 304 响应并返回 true，此时处理函数不应该再写
+// 入响应。etag 为空或者 lastModified 为零值时不设置对应的响应头。
+func CheckNotModified(ctx Context, etag string, lastModified time.Time) bool {
+
+	if etag != "" {
+		ctx.Header(HeaderETag, etag)
+	}
+	if !lastModified.IsZero() {
+		ctx.Header(HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	notModified := isNotModified(ctx.Request(), etag, lastModified)
+	if notModified {
+		ctx.NoContent(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// isNotModified 根据条件请求头判断资源是否没有修改，If-None-Match 优先于
+// If-Modified-Since ，只对 GET 和 HEAD 请求有效。
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if s := r.Header.Get(HeaderIfNoneMatch); s != "" {
+		return etag != "" && ETagMatch(s, etag)
+	}
+	if s := r.Header.Get(HeaderIfModifiedSince); s != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(s)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// JSONWithETag 使用响应体生成 ETag 并处理条件请求，资源没有修改时返回 304 响应，
+// 否则返回 JSON 响应。Maybe panic.
+func JSONWithETag(ctx Context, i interface{}) {
+	b, err := json.Marshal(i)
+	if err != nil {
+		panic(err)
+	}
+	if CheckNotModified(ctx, ETag(b), time.Time{}) {
+		return
+	}
+	ctx.JSONBlob(b)
+}
+
+// etagFilter 为响应生成 ETag 并处理条件请求的过滤器。
+type etagFilter struct {
+	urlPatterns []string
+}
+
+// ETagFilter 返回统一处理条件请求的过滤器，处理函数不需要调用 CheckNotModified 。
+// 过滤器暂存 GET 和 HEAD 请求的 200 响应，处理函数没有设置 ETag 时使用响应体生成
+// ETag ，然后根据 If-None-Match 和 If-Modified-Since (需要处理函数设置
+// Last-Modified 响应头) 判断资源是否没有修改，没有修改时返回 304 响应。因为要暂存
+// 整个响应体，不要对 SSE 等流式响应使用该过滤器。urlPatterns 为空时对所有路由生效。
+// Context.ResponseWriter 返回的对象必须实现 Hold 方法，BufferedResponseWriter 已经
+// 实现了该方法。
+func ETagFilter(urlPatterns ...string) Filter {
+	if len(urlPatterns) == 0 {
+		urlPatterns = []string{"/*"}
+	}
+	return &etagFilter{urlPatterns: urlPatterns}
+}
+
+func (f *etagFilter) URLPatterns() []string {
+	return f.urlPatterns
+}
+
+func (f *etagFilter) Invoke(ctx Context, chain FilterChain) {
+
+	r := ctx.Request()
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		chain.Next(ctx)
+		return
+	}
+
+	h, ok := ctx.ResponseWriter().(interface {
+		Hold() func(fn func(body []byte) bool)
+	})
+	if !ok {
+		chain.Next(ctx)
+		return
+	}
+
+	release := h.Hold()
+	done := false
+	defer func() {
+		if !done { // 处理函数 panic 时原样发送已经暂存的数据
+			release(nil)
+		}
+	}()
+
+	chain.Next(ctx)
+	done = true
+
+	notModified := false
+	release(func(body []byte) bool {
+		w := ctx.ResponseWriter()
+		if status := w.Status(); status != 0 && status != http.StatusOK {
+			return true
+		}
+		etag := w.Header().Get(HeaderETag)
+		if etag == "" {
+			etag = ETag(body)
+			w.Header().Set(HeaderETag, etag)
+		}
+		var lastModified time.Time
+		if s := w.Header().Get(HeaderLastModified); s != "" {
+			lastModified, _ = http.ParseTime(s)
+		}
+		notModified = isNotModified(r, etag, lastModified)
+		return !notModified
+	})
+
+	if notModified {
+		w := ctx.ResponseWriter()
+		w.Header().Del(HeaderContentLength)
+		w.WriteHeader(http.StatusNotModified)
+	}
+}
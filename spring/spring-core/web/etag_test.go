@@ -0,0 +1,160 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestETag(t *testing.T) {
+
+	etag := web.ETag([]byte("hello"))
+	assert.Equal(t, etag, `"aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d"`)
+	assert.Equal(t, web.WeakETag([]byte("hello")), "W/"+etag)
+
+	assert.True(t, web.ETagMatch("*", etag))
+	assert.True(t, web.ETagMatch(etag, etag))
+	assert.True(t, web.ETagMatch(`"abc", `+etag, etag))
+	assert.True(t, web.ETagMatch("W/"+etag, etag))
+	assert.True(t, web.ETagMatch(etag, "W/"+etag))
+	assert.False(t, web.ETagMatch(`"abc"`, etag))
+}
+
+func TestCheckNotModified(t *testing.T) {
+
+	etag := web.ETag([]byte("hello"))
+	modified := time.Date(2021, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	r := web.NewRouter()
+	r.GetMapping("/", func(ctx web.Context) {
+		if web.CheckNotModified(ctx, etag, modified) {
+			return
+		}
+		ctx.String("hello")
+	})
+	r.PostMapping("/", func(ctx web.Context) {
+		if web.CheckNotModified(ctx, etag, modified) {
+			return
+		}
+		ctx.String("hello")
+	})
+	h := web.ToHTTPHandler(r)
+
+	serve := func(method string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, nil)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get(web.HeaderETag), etag)
+	assert.Equal(t, w.Header().Get(web.HeaderLastModified), "Fri, 01 Oct 2021 08:00:00 GMT")
+
+	w = serve(http.MethodGet, map[string]string{web.HeaderIfNoneMatch: "W/" + etag})
+	assert.Equal(t, w.Code, http.StatusNotModified)
+	assert.Equal(t, w.Body.String(), "")
+
+	w = serve(http.MethodGet, map[string]string{web.HeaderIfNoneMatch: `"other"`})
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	// If-None-Match 存在时忽略 If-Modified-Since
+	w = serve(http.MethodGet, map[string]string{
+		web.HeaderIfNoneMatch:     `"other"`,
+		web.HeaderIfModifiedSince: "Fri, 01 Oct 2021 09:00:00 GMT",
+	})
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	w = serve(http.MethodGet, map[string]string{web.HeaderIfModifiedSince: "Fri, 01 Oct 2021 08:00:00 GMT"})
+	assert.Equal(t, w.Code, http.StatusNotModified)
+
+	w = serve(http.MethodGet, map[string]string{web.HeaderIfModifiedSince: "Fri, 01 Oct 2021 07:59:59 GMT"})
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	w = serve(http.MethodPost, map[string]string{web.HeaderIfNoneMatch: etag})
+	assert.Equal(t, w.Code, http.StatusOK)
+}
+
+func TestETagFilter(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/users", func(ctx web.Context) {
+		ctx.JSON([]string{"jim", "tom"})
+	})
+	r.GetMapping("/missing", func(ctx web.Context) {
+		ctx.Status(http.StatusNotFound)
+		ctx.String("missing")
+	})
+	r.GetMapping("/custom", func(ctx web.Context) {
+		ctx.Header(web.HeaderETag, `"v1"`)
+		ctx.Header(web.HeaderLastModified, "Fri, 01 Oct 2021 08:00:00 GMT")
+		ctx.String("custom")
+	})
+	r.GetMapping("/panic", func(ctx web.Context) {
+		panic(web.NewHttpError(http.StatusConflict))
+	})
+	h := web.ToHTTPHandler(r, web.RecoveryFilter(0), web.ETagFilter())
+
+	serve := func(target string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/users", nil)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), `["jim","tom"]`)
+	etag := w.Header().Get(web.HeaderETag)
+	assert.Equal(t, etag, web.ETag([]byte(`["jim","tom"]`)))
+
+	w = serve("/users", map[string]string{web.HeaderIfNoneMatch: etag})
+	assert.Equal(t, w.Code, http.StatusNotModified)
+	assert.Equal(t, w.Body.String(), "")
+	assert.Equal(t, w.Header().Get(web.HeaderETag), etag)
+
+	w = serve("/missing", map[string]string{web.HeaderIfNoneMatch: "*"})
+	assert.Equal(t, w.Code, http.StatusNotFound)
+	assert.Equal(t, w.Body.String(), "missing")
+	assert.Equal(t, w.Header().Get(web.HeaderETag), "")
+
+	w = serve("/custom", map[string]string{web.HeaderIfNoneMatch: `"v1"`})
+	assert.Equal(t, w.Code, http.StatusNotModified)
+
+	w = serve("/custom", map[string]string{web.HeaderIfModifiedSince: "Fri, 01 Oct 2021 08:00:00 GMT"})
+	assert.Equal(t, w.Code, http.StatusNotModified)
+
+	w = serve("/custom", map[string]string{web.HeaderIfNoneMatch: `"v0"`})
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), "custom")
+
+	w = serve("/panic", nil)
+	assert.Equal(t, w.Code, http.StatusConflict)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ExcludeRule 不记录访问日志或者指标的请求，例如健康检查和爬虫的请求。Paths 使用
+// path.Match 匹配请求路径，UserAgents 是 User-Agent 请求头的子串，不区分大小写。
+type ExcludeRule struct {
+	Paths      []string
+	UserAgents []string
+}
+
+// Match 返回请求是否匹配规则。
+func (rule *ExcludeRule) Match(r *http.Request) bool {
+	for _, pattern := range rule.Paths {
+		if ok, _ := path.Match(pattern, r.URL.Path); ok {
+			return true
+		}
+	}
+	if len(rule.UserAgents) > 0 {
+		ua := strings.ToLower(r.UserAgent())
+		for _, s := range rule.UserAgents {
+			if s != "" && strings.Contains(ua, strings.ToLower(s)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var excludeRules = struct {
+	mutex     sync.RWMutex
+	accessLog *ExcludeRule
+	metrics   *ExcludeRule
+}{}
+
+// SetAccessLogExclude 设置不记录访问日志的请求，只对默认的日志过滤器生效。
+func SetAccessLogExclude(rule ExcludeRule) {
+	excludeRules.mutex.Lock()
+	defer excludeRules.mutex.Unlock()
+	excludeRules.accessLog = &rule
+}
+
+// SetMetricsExclude 设置 MetricsFilter 不记录指标的请求。
+func SetMetricsExclude(rule ExcludeRule) {
+	excludeRules.mutex.Lock()
+	defer excludeRules.mutex.Unlock()
+	excludeRules.metrics = &rule
+}
+
+// excludeAccessLog 返回请求是否不记录访问日志。
+func excludeAccessLog(r *http.Request) bool {
+	excludeRules.mutex.RLock()
+	rule := excludeRules.accessLog
+	excludeRules.mutex.RUnlock()
+	return rule != nil && rule.Match(r)
+}
+
+// excludeMetrics 返回请求是否不记录指标。
+func excludeMetrics(r *http.Request) bool {
+	excludeRules.mutex.RLock()
+	rule := excludeRules.metrics
+	excludeRules.mutex.RUnlock()
+	return rule != nil && rule.Match(r)
+}
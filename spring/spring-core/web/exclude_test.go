@@ -0,0 +1,67 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/metrics"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestExcludeRule_Match(t *testing.T) {
+	rule := &web.ExcludeRule{
+		Paths:      []string{"/health", "/actuator/*"},
+		UserAgents: []string{"kube-probe", "Googlebot"},
+	}
+	newRequest := func(path, ua string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("User-Agent", ua)
+		return r
+	}
+	assert.True(t, rule.Match(newRequest("/health", "curl/7.0")))
+	assert.True(t, rule.Match(newRequest("/actuator/info", "curl/7.0")))
+	assert.True(t, rule.Match(newRequest("/api/user", "kube-probe/1.22")))
+	assert.True(t, rule.Match(newRequest("/api/user", "Mozilla/5.0 (compatible; googlebot/2.1)")))
+	assert.False(t, rule.Match(newRequest("/api/user", "curl/7.0")))
+}
+
+func TestMetricsExclude(t *testing.T) {
+
+	web.SetMetricsExclude(web.ExcludeRule{Paths: []string{"/excluded/*"}})
+	defer web.SetMetricsExclude(web.ExcludeRule{})
+
+	r := web.NewRouter()
+	r.GetMapping("/excluded/health", func(ctx web.Context) { ctx.String("ok") })
+	r.GetMapping("/included/user", func(ctx web.Context) { ctx.String("ok") })
+	h := web.ToHTTPHandler(r, web.MetricsFilter())
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/excluded/health", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/included/user", nil))
+
+	uris := make(map[string]bool)
+	for _, s := range metrics.Default().Snapshot() {
+		if s.Name == "http.server.requests" {
+			uris[s.Tags["uri"]] = true
+		}
+	}
+	assert.False(t, uris["/excluded/health"])
+	assert.True(t, uris["/included/user"])
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// FieldSet 响应中需要保留的字段，key 为 JSON 字段名，值为 nil 时保留整个字段，
+// 否则只保留子字段中的 FieldSet 。
+type FieldSet map[string]FieldSet
+
+// ParseFields 解析 id,name,owner.name 格式的字段列表，嵌套字段使用 . 分隔，同时
+// 出现父字段和子字段时保留整个父字段。
+func ParseFields(s string) FieldSet {
+	var fs FieldSet
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if fs == nil {
+			fs = make(FieldSet)
+		}
+		m := fs
+		names := strings.Split(field, ".")
+		for i, name := range names {
+			sub, ok := m[name]
+			if i == len(names)-1 {
+				m[name] = nil
+				break
+			}
+			if ok && sub == nil { // 已经保留整个字段
+				break
+			}
+			if sub == nil {
+				sub = make(FieldSet)
+				m[name] = sub
+			}
+			m = sub
+		}
+	}
+	return fs
+}
+
+// Project 只保留 JSON 数据 b 中属于 fs 的字段，字段保持原来的顺序，数组对每个元素
+// 分别进行过滤，不是对象的值保持不变，fs 为空时原样返回。
+func Project(b []byte, fs FieldSet) ([]byte, error) {
+	if len(fs) == 0 {
+		return b, nil
+	}
+	var buf bytes.Buffer
+	if err := project(&buf, bytes.TrimSpace(b), fs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func project(buf *bytes.Buffer, b []byte, fs FieldSet) error {
+
+	if len(b) == 0 {
+		return nil
+	}
+
+	switch b[0] {
+	case '[':
+		var arr []json.RawMessage
+		if err := json.Unmarshal(b, &arr); err != nil {
+			return err
+		}
+		buf.WriteByte('[')
+		for i, v := range arr {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := project(buf, v, fs); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case '{':
+	default:
+		buf.Write(b)
+		return nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	if _, err := d.Token(); err != nil { // {
+		return err
+	}
+	buf.WriteByte('{')
+	first := true
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		var v json.RawMessage
+		if err = d.Decode(&v); err != nil {
+			return err
+		}
+		key := t.(string)
+		sub, ok := fs[key]
+		if !ok {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		e := json.NewEncoder(buf)
+		e.SetEscapeHTML(false)
+		if err = e.Encode(key); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1) // Encode 写入的换行符
+		buf.WriteByte(':')
+		if sub == nil {
+			buf.Write(v)
+		} else if err = project(buf, v, sub); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// SparseFields 返回按照请求参数 param 过滤 JSON 响应体字段的改写函数，例如
+// ?fields=id,name,owner.name 只返回这些字段，列表接口因此不需要手写 DTO 就可以
+// 裁剪响应数据。没有该参数、不是 JSON 响应体或者是错误响应时响应体保持不变，
+// 需要缓存整个 JSON 响应体。
+func SparseFields(param string) ResponseBodyTransformer {
+	return func(ctx Context, w io.Writer) io.WriteCloser {
+		return &fieldsWriter{
+			ctx:    ctx,
+			w:      w,
+			fields: ParseFields(ctx.QueryParam(param)),
+		}
+	}
+}
+
+type fieldsWriter struct {
+	ctx     Context
+	w       io.Writer
+	fields  FieldSet
+	buf     bytes.Buffer
+	started bool // 是否已经开始缓存响应体
+	skipped bool // 不需要过滤的响应体
+}
+
+func (f *fieldsWriter) Write(p []byte) (int, error) {
+	if !f.started && !f.skipped {
+		rw := f.ctx.ResponseWriter()
+		contentType := rw.Header().Get(HeaderContentType)
+		if len(f.fields) == 0 || filterFlags(contentType) != MIMEApplicationJSON ||
+			rw.Status() >= http.StatusBadRequest {
+			f.skipped = true
+		} else {
+			f.started = true
+		}
+	}
+	if f.skipped {
+		return f.w.Write(p)
+	}
+	return f.buf.Write(p)
+}
+
+func (f *fieldsWriter) Close() error {
+	if !f.started {
+		return nil
+	}
+	b, err := Project(f.buf.Bytes(), f.fields)
+	if err != nil { // 响应头已经发送，不是合法的 JSON 时原样返回
+		b = f.buf.Bytes()
+	}
+	_, err = f.w.Write(b)
+	return err
+}
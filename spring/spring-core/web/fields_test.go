@@ -0,0 +1,104 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestParseFields(t *testing.T) {
+	assert.True(t, web.ParseFields("") == nil)
+	assert.Equal(t, web.ParseFields(" id, ,name"), web.FieldSet{"id": nil, "name": nil})
+	assert.Equal(t, web.ParseFields("owner.name,owner.id,items.sku"), web.FieldSet{
+		"owner": {"name": nil, "id": nil},
+		"items": {"sku": nil},
+	})
+	assert.Equal(t, web.ParseFields("owner.name,owner"), web.FieldSet{"owner": nil})
+	assert.Equal(t, web.ParseFields("owner,owner.name"), web.FieldSet{"owner": nil})
+}
+
+func TestProject(t *testing.T) {
+	testcases := []struct {
+		fields string
+		data   string
+		expect string
+	}{
+		{"", `{"id":1}`, `{"id":1}`},
+		{"name,id", `{"id":1,"name":"a","age":3}`, `{"id":1,"name":"a"}`},
+		{"id", `[{"id":1,"name":"a"},{"id":2}]`, `[{"id":1},{"id":2}]`},
+		{"owner.name,tags", ` {"owner":{"id":1,"name":"a"},"tags":["x"],"id":1} `, `{"owner":{"name":"a"},"tags":["x"]}`},
+		{"items.sku", `{"items":[{"sku":"s","qty":1},{"qty":2}]}`, `{"items":[{"sku":"s"},{}]}`},
+		{"owner.name", `{"owner":null}`, `{"owner":null}`},
+		{"a<b", `{"a<b":1}`, `{"a<b":1}`},
+		{"id", `"text"`, `"text"`},
+	}
+	for _, c := range testcases {
+		b, err := web.Project([]byte(c.data), web.ParseFields(c.fields))
+		assert.Nil(t, err)
+		assert.Equal(t, string(b), c.expect)
+	}
+	_, err := web.Project([]byte(`{"id":`), web.ParseFields("id"))
+	assert.Error(t, err, "unexpected EOF")
+}
+
+func TestSparseFields(t *testing.T) {
+
+	type User struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	r := web.NewRouter()
+	r.GetMapping("/users", func(ctx web.Context) {
+		ctx.JSON([]User{{1, "a", "a@x"}, {2, "b", "b@x"}})
+	})
+	r.GetMapping("/text", func(ctx web.Context) {
+		ctx.String(`{"id":1,"name":"a"}`)
+	})
+	r.GetMapping("/error", func(ctx web.Context) {
+		ctx.Status(http.StatusBadRequest)
+		ctx.JSON(map[string]interface{}{"code": 400, "message": "bad"})
+	})
+	h := web.ToHTTPHandler(r,
+		web.ResponseBodyFilter(web.JSONEnvelope("data")),
+		web.ResponseBodyFilter(web.SparseFields("fields")))
+
+	testcases := []struct {
+		url    string
+		expect string
+	}{
+		{"/users", `{"data":[{"id":1,"name":"a","email":"a@x"},{"id":2,"name":"b","email":"b@x"}]}`},
+		{"/users?fields=id,name", `{"data":[{"id":1,"name":"a"},{"id":2,"name":"b"}]}`},
+		{"/text?fields=id", `{"id":1,"name":"a"}`},
+	}
+	for _, c := range testcases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.url, nil))
+		assert.Equal(t, w.Body.String(), c.expect)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/error?fields=id", nil))
+	assert.Equal(t, w.Code, http.StatusBadRequest)
+	assert.Equal(t, w.Body.String(), `{"data":{"code":400,"message":"bad"}}`)
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync/atomic"
+)
+
+type LifecycleState int32
+
+const (
+	StateStarting = LifecycleState(0) // 正在启动
+	StateReady    = LifecycleState(1) // 可以接收流量
+	StateDraining = LifecycleState(2) // 正在关闭，不再接收新的流量
+	StateStopped  = LifecycleState(3) // 已经停止
+)
+
+func (s LifecycleState) String() string {
+	switch s {
+	case StateReady:
+		return "ready"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "starting"
+	}
+}
+
+// Lifecycle 记录 Web 容器的生命周期状态，用于健康检查。
+type Lifecycle struct {
+	state int32
+}
+
+// State 返回当前的生命周期状态。
+func (l *Lifecycle) State() LifecycleState {
+	return LifecycleState(atomic.LoadInt32(&l.state))
+}
+
+// SetState 设置当前的生命周期状态。
+func (l *Lifecycle) SetState(s LifecycleState) {
+	atomic.StoreInt32(&l.state, int32(s))
+}
+
+// Live 返回程序是否存活，停止之前都认为是存活的。
+func (l *Lifecycle) Live() bool {
+	return l.State() != StateStopped
+}
+
+// Ready 返回程序是否可以接收流量。
+func (l *Lifecycle) Ready() bool {
+	return l.State() == StateReady
+}
+
+type healthStatus struct {
+	Status string `json:"status"`
+	State  string `json:"state"`
+}
+
+func writeHealth(ctx Context, ok bool, state LifecycleState) {
+	status := healthStatus{Status: "UP", State: state.String()}
+	if !ok {
+		status.Status = "DOWN"
+		ctx.Status(http.StatusServiceUnavailable)
+	}
+	ctx.JSON(status)
+}
+
+// RegisterHealth 注册 /actuator/health/liveness 和 /actuator/health/readiness
+// 健康检查接口，状态正常时返回 200 ，否则返回 503 。
+func RegisterHealth(r Router, l *Lifecycle) {
+	r.GetMapping("/actuator/health/liveness", func(ctx Context) {
+		writeHealth(ctx, l.Live(), l.State())
+	})
+	r.GetMapping("/actuator/health/readiness", func(ctx Context) {
+		writeHealth(ctx, l.Ready(), l.State())
+	})
+}
+
+// HealthIndicator 组件的健康检查，返回 error 表示组件不可用，details 是组件的
+// 详细信息，例如连接池和命令的统计数据。
+type HealthIndicator interface {
+	Health(ctx context.Context) (details map[string]interface{}, err error)
+}
+
+type componentHealth struct {
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+type aggregateHealth struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentHealth `json:"components,omitempty"`
+}
+
+// checkHealth 按照 names 的顺序执行组件检查，返回汇总的健康状态。
+func checkHealth(ctx context.Context, names []string, indicators map[string]HealthIndicator) aggregateHealth {
+	health := aggregateHealth{Status: "UP"}
+	if len(names) > 0 {
+		health.Components = make(map[string]componentHealth)
+	}
+	for _, name := range names {
+		details, err := indicators[name].Health(ctx)
+		c := componentHealth{Status: "UP", Details: details}
+		if err != nil {
+			c.Status, c.Error = "DOWN", err.Error()
+			health.Status = "DOWN"
+		}
+		health.Components[name] = c
+	}
+	return health
+}
+
+// RegisterHealthIndicators 注册 /actuator/health 健康检查接口，依次执行所有的
+// 组件检查，任意组件不可用时整体状态为 DOWN 并返回 503 。
+func RegisterHealthIndicators(r Router, indicators map[string]HealthIndicator) {
+
+	names := make([]string, 0, len(indicators))
+	for name := range indicators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	r.GetMapping("/actuator/health", func(ctx Context) {
+		health := checkHealth(ctx.Context(), names, indicators)
+		if health.Status != "UP" {
+			ctx.Status(http.StatusServiceUnavailable)
+		}
+		ctx.JSON(health)
+	})
+}
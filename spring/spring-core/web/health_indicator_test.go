@@ -0,0 +1,54 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+)
+
+type fnIndicator func(ctx context.Context) (map[string]interface{}, error)
+
+func (f fnIndicator) Health(ctx context.Context) (map[string]interface{}, error) {
+	return f(ctx)
+}
+
+func TestCheckHealth(t *testing.T) {
+
+	up := fnIndicator(func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"commands": 3}, nil
+	})
+	down := fnIndicator(func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	health := checkHealth(context.Background(), nil, nil)
+	assert.Equal(t, health, aggregateHealth{Status: "UP"})
+
+	indicators := map[string]HealthIndicator{"db": down, "redis": up}
+	health = checkHealth(context.Background(), []string{"db", "redis"}, indicators)
+	assert.Equal(t, health, aggregateHealth{
+		Status: "DOWN",
+		Components: map[string]componentHealth{
+			"db":    {Status: "DOWN", Error: "connection refused"},
+			"redis": {Status: "UP", Details: map[string]interface{}{"commands": 3}},
+		},
+	})
+}
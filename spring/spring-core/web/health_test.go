@@ -0,0 +1,42 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestLifecycle(t *testing.T) {
+	var l web.Lifecycle
+	assert.Equal(t, l.State(), web.StateStarting)
+	assert.True(t, l.Live())
+	assert.False(t, l.Ready())
+
+	l.SetState(web.StateReady)
+	assert.True(t, l.Ready())
+
+	l.SetState(web.StateDraining)
+	assert.True(t, l.Live())
+	assert.False(t, l.Ready())
+	assert.Equal(t, l.State().String(), "draining")
+
+	l.SetState(web.StateStopped)
+	assert.False(t, l.Live())
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/web/i18n"
+)
+
+// HeaderXTenantID 默认的租户请求头。
+const HeaderXTenantID = "X-Tenant-Id"
+
+// requestContextKey 在 context.Context 中保存 requestContext 使用的 Key 。
+const requestContextKey = "::request-context::"
+
+// requestContext 请求范围的数据，同一个请求的多个协程可能同时访问。
+type requestContext struct {
+	mutex     sync.RWMutex
+	principal interface{}
+	tenant    string
+	values    map[string]interface{}
+}
+
+// getRequestContext 返回 ctx 上的 requestContext 对象，不存在时根据 create 决定
+// 是否创建，ctx 不是 knife.New 创建的上下文时返回 nil 。
+func getRequestContext(ctx context.Context, create bool) *requestContext {
+	if v, ok := knife.Get(ctx, requestContextKey); ok {
+		return v.(*requestContext)
+	}
+	if !create {
+		return nil
+	}
+	r := &requestContext{values: make(map[string]interface{})}
+	if err := knife.Set(ctx, requestContextKey, r); err != nil {
+		if v, ok := knife.Get(ctx, requestContextKey); ok {
+			return v.(*requestContext) // 被其他协程抢先创建
+		}
+		return nil
+	}
+	return r
+}
+
+// RequestContextHolder 访问请求范围的数据，例如当前用户、租户和语言。Service
+// 层的 bean 注入 RequestContextHolder 后只需要传递 context.Context 就能获取这些
+// 数据，不需要在每个调用上传递自定义的结构体。ctx 必须是 Web 请求的上下文或者
+// 由它派生的上下文。
+type RequestContextHolder struct{}
+
+// SetPrincipal 设置当前用户，一般由认证过滤器调用。
+func (h *RequestContextHolder) SetPrincipal(ctx context.Context, principal interface{}) error {
+	r := getRequestContext(ctx, true)
+	if r == nil {
+		return knife.ErrUninitialized
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.principal = principal
+	return nil
+}
+
+// Principal 返回当前用户，没有设置时返回 nil 。
+func (h *RequestContextHolder) Principal(ctx context.Context) interface{} {
+	r := getRequestContext(ctx, false)
+	if r == nil {
+		return nil
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.principal
+}
+
+// SetTenant 设置当前租户，一般由 TenantFilter 调用。
+func (h *RequestContextHolder) SetTenant(ctx context.Context, tenant string) error {
+	r := getRequestContext(ctx, true)
+	if r == nil {
+		return knife.ErrUninitialized
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.tenant = tenant
+	return nil
+}
+
+// Tenant 返回当前租户，没有设置时返回空字符串。
+func (h *RequestContextHolder) Tenant(ctx context.Context) string {
+	r := getRequestContext(ctx, false)
+	if r == nil {
+		return ""
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.tenant
+}
+
+// Locale 返回 LocaleFilter 设置的上下文语言。
+func (h *RequestContextHolder) Locale(ctx context.Context) string {
+	return i18n.GetLanguage(ctx)
+}
+
+// RequestID 返回 RequestIDFilter 设置的请求 ID 。
+func (h *RequestContextHolder) RequestID(ctx context.Context) string {
+	return log.GetRequestID(ctx)
+}
+
+// SetValue 设置请求范围的自定义数据，已经存在时覆盖。
+func (h *RequestContextHolder) SetValue(ctx context.Context, key string, val interface{}) error {
+	r := getRequestContext(ctx, true)
+	if r == nil {
+		return knife.ErrUninitialized
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.values[key] = val
+	return nil
+}
+
+// Value 返回请求范围的自定义数据。
+func (h *RequestContextHolder) Value(ctx context.Context, key string) (interface{}, bool) {
+	r := getRequestContext(ctx, false)
+	if r == nil {
+		return nil, false
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	val, ok := r.values[key]
+	return val, ok
+}
+
+// tenantFilter 从请求头中读取租户的过滤器。
+type tenantFilter struct {
+	header string
+}
+
+// TenantFilter 返回从 header 请求头读取当前租户的过滤器，header 为空时使用
+// X-Tenant-Id ，租户可以通过 RequestContextHolder.Tenant 获取。
+func TenantFilter(header string) Filter {
+	if header == "" {
+		header = HeaderXTenantID
+	}
+	return &tenantFilter{header: header}
+}
+
+func (f *tenantFilter) Invoke(ctx Context, chain FilterChain) {
+	if tenant := ctx.GetHeader(f.header); tenant != "" {
+		h := new(RequestContextHolder)
+		if err := h.SetTenant(ctx.Context(), tenant); err != nil {
+			panic(err)
+		}
+	}
+	chain.Next(ctx)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/web"
+	"github.com/go-spring/spring-core/web/i18n"
+)
+
+type accountService struct {
+	Holder *web.RequestContextHolder `autowire:""`
+}
+
+func (s *accountService) describe(ctx context.Context) string {
+	user, _ := s.Holder.Principal(ctx).(string)
+	return user + "@" + s.Holder.Tenant(ctx) + ":" + s.Holder.Locale(ctx)
+}
+
+func TestRequestContextHolder(t *testing.T) {
+
+	h := new(web.RequestContextHolder)
+
+	t.Run("uninitialized", func(t *testing.T) {
+		ctx := context.Background()
+		assert.Equal(t, h.SetPrincipal(ctx, "jim"), knife.ErrUninitialized)
+		assert.Nil(t, h.Principal(ctx))
+		assert.Equal(t, h.Tenant(ctx), "")
+	})
+
+	t.Run("values", func(t *testing.T) {
+		ctx := knife.New(context.Background())
+		ctx = log.WithRequestID(ctx, "req-1")
+		assert.Nil(t, i18n.SetLanguage(ctx, "zh-CN"))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Nil(t, h.SetValue(ctx, "k", "v"))
+			}()
+		}
+		wg.Wait()
+
+		assert.Nil(t, h.SetPrincipal(ctx, "jim"))
+		assert.Equal(t, h.Principal(ctx), "jim")
+		assert.Equal(t, h.Locale(ctx), "zh-CN")
+		assert.Equal(t, h.RequestID(ctx), "req-1")
+
+		v, ok := h.Value(ctx, "k")
+		assert.True(t, ok)
+		assert.Equal(t, v, "v")
+		_, ok = h.Value(ctx, "x")
+		assert.False(t, ok)
+	})
+
+	t.Run("tenant filter", func(t *testing.T) {
+		svc := &accountService{Holder: h}
+		var got string
+		r := web.NewRouter()
+		r.GetMapping("/user", func(ctx web.Context) {
+			assert.Nil(t, h.SetPrincipal(ctx.Context(), "jim"))
+			got = svc.describe(ctx.Context())
+		})
+		handler := web.ToHTTPHandler(r, web.TenantFilter(""))
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		req.Header.Set(web.HeaderXTenantID, "acme")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, got, "jim@acme:")
+	})
+}
@@ -18,7 +18,7 @@ package i18n
 
 import (
 	"context"
-	"errors"
+	"sync"
 
 	"github.com/go-spring/spring-base/knife"
 )
@@ -264,16 +264,35 @@ var _ = []string{
 	"zu-ZA",  //祖鲁语
 }
 
-// languages 语言配置表。
-var languages = make(map[string]map[string]string)
+// defaultSource 默认消息源，RegisterLanguage、Get 和 Message 都从它读写消息。
+var (
+	defaultMutex  sync.RWMutex
+	defaultSource = NewMessageSource("zh-CN")
+)
+
+// Default 返回默认消息源。
+func Default() *MessageSource {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultSource
+}
+
+// SetDefault 替换默认消息源，旧消息源中的消息会合并到 s 中，s 中已存在的同名
+// 消息优先。
+func SetDefault(s *MessageSource) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	if s != defaultSource {
+		s.merge(defaultSource)
+		defaultSource = s
+	}
+}
 
 // RegisterLanguage 注册语言配置表。
+//
+// Deprecated: 使用 Default().Add 代替。
 func RegisterLanguage(language string, data map[string]string) error {
-	if _, ok := languages[language]; ok {
-		return errors.New("duplicate language")
-	}
-	languages[language] = data
-	return nil
+	return Default().register(language, data)
 }
 
 const languageKey = "::language::"
@@ -283,22 +302,29 @@ func SetLanguage(ctx context.Context, language string) error {
 	return knife.Set(ctx, languageKey, language)
 }
 
-// Get 获取语言对应的配置项，从 context.Context 中获取上下文语言。
-func Get(ctx context.Context, key string) string {
-
+// GetLanguage 获取上下文语言，没有设置时返回空字符串。
+func GetLanguage(ctx context.Context) string {
 	var language string
-	ok, err := knife.Fetch(ctx, languageKey, &language)
-	if err != nil || !ok {
-		language = "zh-CN"
-	}
-
-	m, ok := languages[language]
-	if !ok {
+	if ok, err := knife.Fetch(ctx, languageKey, &language); err != nil || !ok {
 		return ""
 	}
-	v, ok := m[key]
-	if !ok {
-		return ""
+	return language
+}
+
+// Get 获取语言对应的配置项，从 context.Context 中获取上下文语言，没有设置时使用
+// zh-CN ，找不到配置项时返回空字符串。
+//
+// Deprecated: 使用 Message 代替。
+func Get(ctx context.Context, key string) string {
+	language := GetLanguage(ctx)
+	if language == "" {
+		language = "zh-CN"
 	}
+	v, _ := Default().get(language, key)
 	return v
 }
+
+// Message 返回上下文语言对应的消息，查找规则参见 MessageSource.Message 。
+func Message(ctx context.Context, key string, args ...interface{}) string {
+	return Default().Message(GetLanguage(ctx), key, args...)
+}
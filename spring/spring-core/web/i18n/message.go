@@ -0,0 +1,166 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-spring/spring-base/conf"
+)
+
+// MessageSource 国际化消息源，按照语言保存消息模板。
+type MessageSource struct {
+	mutex    sync.RWMutex
+	fallback string                       // 找不到上下文语言的消息时使用的语言
+	messages map[string]map[string]string // 语言 -> 消息键 -> 消息模板
+}
+
+// NewMessageSource 返回新的消息源，fallback 是默认语言。
+func NewMessageSource(fallback string) *MessageSource {
+	return &MessageSource{
+		fallback: fallback,
+		messages: make(map[string]map[string]string),
+	}
+}
+
+// Add 添加语言对应的消息，已存在的同名消息会被覆盖。
+func (s *MessageSource) Add(language string, messages map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.add(language, messages)
+}
+
+func (s *MessageSource) add(language string, messages map[string]string) {
+	m, ok := s.messages[language]
+	if !ok {
+		m = make(map[string]string)
+		s.messages[language] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
+
+// register 添加语言对应的消息，语言已存在时返回错误。
+func (s *MessageSource) register(language string, messages map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.messages[language]; ok {
+		return errors.New("duplicate language")
+	}
+	s.add(language, messages)
+	return nil
+}
+
+// merge 将 from 中的消息合并进来，已存在的同名消息不会被覆盖。
+func (s *MessageSource) merge(from *MessageSource) {
+	from.mutex.RLock()
+	defer from.mutex.RUnlock()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for language, messages := range from.messages {
+		m, ok := s.messages[language]
+		if !ok {
+			m = make(map[string]string)
+			s.messages[language] = m
+		}
+		for k, v := range messages {
+			if _, ok = m[k]; !ok {
+				m[k] = v
+			}
+		}
+	}
+}
+
+// LoadDir 加载 dir 目录下的属性文件，例如 basename 为 messages 时，加载
+// messages.properties 作为默认语言的消息，加载 messages_zh-CN.properties
+// 作为 zh-CN 语言的消息。
+func (s *MessageSource) LoadDir(dir string, basename string) error {
+	files, err := filepath.Glob(filepath.Join(dir, basename+"*.properties"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".properties")
+		language := s.fallback
+		if name != basename {
+			if !strings.HasPrefix(name, basename+"_") {
+				continue
+			}
+			language = strings.TrimPrefix(name, basename+"_")
+		}
+		p, err := conf.Load(file)
+		if err != nil {
+			return fmt.Errorf("load %s error: %w", file, err)
+		}
+		m := make(map[string]string)
+		for _, k := range p.Keys() {
+			m[k] = p.Get(k)
+		}
+		s.Add(language, m)
+	}
+	return nil
+}
+
+// Languages 返回消息源支持的语言列表。
+func (s *MessageSource) Languages() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var ret []string
+	for language := range s.messages {
+		ret = append(ret, language)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (s *MessageSource) get(language string, key string) (string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	msg, ok := s.messages[language][key]
+	return msg, ok
+}
+
+// Message 返回语言对应的消息，依次查找 language、language 的主语言 (例如 zh-CN
+// 对应 zh) 以及默认语言，都找不到时返回 key 。args 不为空时使用 fmt.Sprintf 格
+// 式化消息模板。
+func (s *MessageSource) Message(language string, key string, args ...interface{}) string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, l := range []string{language, BaseLanguage(language), s.fallback} {
+		if msg, ok := s.messages[l][key]; ok {
+			if len(args) > 0 {
+				return fmt.Sprintf(msg, args...)
+			}
+			return msg
+		}
+	}
+	return key
+}
+
+// BaseLanguage 返回主语言，例如 zh-CN 返回 zh 。
+func BaseLanguage(language string) string {
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		return language[:i]
+	}
+	return language
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package i18n_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-core/web/i18n"
+)
+
+func TestMessageSource(t *testing.T) {
+
+	s := i18n.NewMessageSource("en")
+	err := s.LoadDir("testdata", "messages")
+	assert.Nil(t, err)
+	assert.Equal(t, s.Languages(), []string{"en", "zh-CN"})
+
+	assert.Equal(t, s.Message("zh-CN", "greeting", "go"), "你好 go")
+	assert.Equal(t, s.Message("zh-CN", "user.not-found"), "user not found")
+	assert.Equal(t, s.Message("en-US", "greeting", "go"), "hello go")
+	assert.Equal(t, s.Message("fr", "unknown"), "unknown")
+}
+
+func TestSetDefault(t *testing.T) {
+
+	s := i18n.NewMessageSource("en")
+	s.Add("en", map[string]string{"message": "a new message", "greeting": "hello %s"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			i18n.Message(context.Background(), "greeting", "go")
+		}()
+	}
+	i18n.SetDefault(s)
+	wg.Wait()
+	assert.Equal(t, i18n.Default(), s)
+
+	ctx := knife.New(context.Background())
+	assert.Equal(t, i18n.Message(ctx, "greeting", "go"), "hello go")
+
+	// RegisterLanguage 注册的消息合并到了新的默认消息源
+	assert.Equal(t, i18n.Get(ctx, "message"), "这是一条消息")
+	assert.Equal(t, i18n.Message(ctx, "message"), "a new message")
+
+	err := i18n.SetLanguage(ctx, "zh-CN")
+	assert.Nil(t, err)
+	assert.Equal(t, i18n.Message(ctx, "message"), "这是一条消息")
+	assert.Equal(t, i18n.Message(ctx, "greeting", "go"), "hello go")
+}
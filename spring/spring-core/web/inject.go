@@ -0,0 +1,79 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-spring/spring-base/util"
+)
+
+// Injector 返回类型 t 对应的注入对象。
+type Injector func(t reflect.Type) (reflect.Value, error)
+
+// Injectable 需要注入参数的处理函数，IoC 容器在启动时为其注入参数。
+type Injectable interface {
+	Inject(injector Injector) error
+}
+
+var contextType = reflect.TypeOf((*Context)(nil)).Elem()
+
+// injectHandler 带有注入参数的 Web 处理接口
+type injectHandler struct {
+	fn      interface{}
+	fnType  reflect.Type
+	fnValue reflect.Value
+	args    []reflect.Value // 注入的参数，不包含第一个 Context 参数
+}
+
+// INJECT 转换成带有注入参数的 Web 处理接口，fn 的形式为 func(web.Context, ...)，
+// 除第一个参数外的其他参数在 IoC 容器启动时注入，每个路由只解析一次。
+func INJECT(fn interface{}) Handler {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.NumIn() < 1 ||
+		fnType.In(0) != contextType || fnType.NumOut() != 0 {
+		panic(errors.New("fn should be func(web.Context, ...)"))
+	}
+	return &injectHandler{fn: fn, fnType: fnType, fnValue: reflect.ValueOf(fn)}
+}
+
+func (h *injectHandler) Inject(injector Injector) error {
+	args := make([]reflect.Value, 0, h.fnType.NumIn()-1)
+	for i := 1; i < h.fnType.NumIn(); i++ {
+		v, err := injector(h.fnType.In(i))
+		if err != nil {
+			return fmt.Errorf("inject %s error: %w", h.fnType.In(i), err)
+		}
+		args = append(args, v)
+	}
+	h.args = args
+	return nil
+}
+
+func (h *injectHandler) Invoke(ctx Context) {
+	if h.args == nil && h.fnType.NumIn() > 1 {
+		panic(errors.New("handler hasn't been injected"))
+	}
+	in := append([]reflect.Value{reflect.ValueOf(&ctx).Elem()}, h.args...)
+	h.fnValue.Call(in)
+}
+
+func (h *injectHandler) FileLine() (file string, line int, fnName string) {
+	return util.FileLine(h.fn)
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+type userService struct {
+	name string
+}
+
+func TestINJECT(t *testing.T) {
+
+	var got *userService
+	h := web.INJECT(func(ctx web.Context, svc *userService) { got = svc })
+
+	svc := &userService{name: "user"}
+	err := h.(web.Injectable).Inject(func(t reflect.Type) (reflect.Value, error) {
+		if t == reflect.TypeOf(svc) {
+			return reflect.ValueOf(svc), nil
+		}
+		return reflect.Value{}, errors.New("not found")
+	})
+	assert.Nil(t, err)
+
+	h.Invoke(nil)
+	assert.Equal(t, got, svc)
+
+	h = web.INJECT(func(ctx web.Context, s string) {})
+	err = h.(web.Injectable).Inject(func(t reflect.Type) (reflect.Value, error) {
+		return reflect.Value{}, errors.New("not found")
+	})
+	assert.Error(t, err, "inject string error: not found")
+
+	assert.Panic(t, func() { web.INJECT(func() {}) }, "fn should be func\\(web.Context, ...\\)")
+}
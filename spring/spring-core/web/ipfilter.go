@@ -0,0 +1,144 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-spring/spring-core/conf"
+)
+
+// ipFilter 基于 IP 的访问控制过滤器。
+type ipFilter struct {
+	patterns []string
+	allow    []*net.IPNet
+	deny     []*net.IPNet
+	proxies  []*net.IPNet
+}
+
+// NewIPFilter 返回基于 IP 的访问控制过滤器，禁止访问时返回 403 错误。
+func NewIPFilter(config conf.IPFilterConfig) (Filter, error) {
+
+	f := &ipFilter{patterns: config.URLPatterns}
+	if len(f.patterns) == 0 {
+		f.patterns = []string{"/*"}
+	}
+
+	var err error
+	if f.allow, err = parseIPNets(config.Allow); err != nil {
+		return nil, err
+	}
+	if f.deny, err = parseIPNets(config.Deny); err != nil {
+		return nil, err
+	}
+	if f.proxies, err = parseIPNets(config.TrustedProxies); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseIPNets 解析 IP 或者 CIDR 列表。
+func parseIPNets(list []string) ([]*net.IPNet, error) {
+	var ret []*net.IPNet
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip %q", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			ret = append(ret, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, ipNet)
+	}
+	return ret, nil
+}
+
+func containsIP(list []*net.IPNet, ip net.IP) bool {
+	for _, n := range list {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ipFilter) URLPatterns() []string {
+	return f.patterns
+}
+
+// clientIP 返回客户端的 IP ，只有直连地址是可信代理时才解析 X-Forwarded-For ，
+// 从右向左跳过可信代理，第一个不可信的地址就是客户端地址。代理追加的地址可能位于
+// 另一行 X-Forwarded-For 头中，因此按顺序合并所有行之后再解析。
+func (f *ipFilter) clientIP(r *http.Request) net.IP {
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(f.proxies, ip) {
+		return ip
+	}
+
+	values := r.Header.Values(HeaderXForwardedFor)
+	forwarded := strings.Split(strings.Join(values, ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		v := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if v == nil {
+			break
+		}
+		ip = v
+		if !containsIP(f.proxies, v) {
+			break
+		}
+	}
+	return ip
+}
+
+// allowed 返回 ip 是否允许访问。
+func (f *ipFilter) allowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if containsIP(f.deny, ip) {
+		return false
+	}
+	return len(f.allow) == 0 || containsIP(f.allow, ip)
+}
+
+func (f *ipFilter) Invoke(ctx Context, chain FilterChain) {
+	if !f.allowed(f.clientIP(ctx.Request())) {
+		panic(NewHttpError(http.StatusForbidden))
+	}
+	chain.Next(ctx)
+}
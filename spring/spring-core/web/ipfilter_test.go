@@ -0,0 +1,61 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+)
+
+func TestIPFilter(t *testing.T) {
+
+	_, err := NewIPFilter(conf.IPFilterConfig{Allow: []string{"abc"}})
+	assert.Error(t, err, "invalid ip \"abc\"")
+
+	filter, err := NewIPFilter(conf.IPFilterConfig{
+		Allow:          []string{"10.0.0.0/8", "192.168.1.1"},
+		Deny:           []string{"10.0.0.1"},
+		TrustedProxies: []string{"172.16.0.0/12"},
+	})
+	assert.Nil(t, err)
+
+	f := filter.(*ipFilter)
+	assert.Equal(t, f.URLPatterns(), []string{"/*"})
+
+	assert.True(t, f.allowed(net.ParseIP("10.1.2.3")))
+	assert.True(t, f.allowed(net.ParseIP("192.168.1.1")))
+	assert.False(t, f.allowed(net.ParseIP("192.168.1.2")))
+	assert.False(t, f.allowed(net.ParseIP("10.0.0.1")))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.1.2.3:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1")
+	assert.Equal(t, f.clientIP(r).String(), "10.1.2.3")
+
+	r.RemoteAddr = "172.16.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 10.1.2.3, 172.16.0.2")
+	assert.Equal(t, f.clientIP(r).String(), "10.1.2.3")
+
+	// 客户端伪造的第一行不能覆盖代理追加在后面的地址
+	r.Header.Set("X-Forwarded-For", "10.0.0.2")
+	r.Header.Add("X-Forwarded-For", "1.1.1.1")
+	assert.Equal(t, f.clientIP(r).String(), "1.1.1.1")
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrBodyTooLarge 请求体超过限制时读取请求体返回的错误，对应 413 状态码。
+var ErrBodyTooLarge = errors.New("http: request body too large")
+
+// limitedBody 限制读取字节数的请求体，与 http.MaxBytesReader 不同的是超出
+// 限制时返回 ErrBodyTooLarge 错误。
+type limitedBody struct {
+	io.ReadCloser
+	n int64 // 剩余可读的字节数
+}
+
+func (l *limitedBody) Read(p []byte) (n int, err error) {
+	if l.n < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err = l.ReadCloser.Read(p)
+	if int64(n) <= l.n {
+		l.n -= int64(n)
+		return n, err
+	}
+	n = int(l.n)
+	l.n = -1
+	return n, ErrBodyTooLarge
+}
+
+// bodyLimitFilter 限制请求体大小的过滤器。
+type bodyLimitFilter struct {
+	maxSize int64
+}
+
+// BodyLimitFilter 返回限制请求体大小的过滤器，maxSize 小于等于 0 时不做限制。
+// 请求头中的 Content-Length 超出限制时直接返回 413 错误，否则在读取请求体时
+// 按需检查，超出限制时返回 ErrBodyTooLarge 错误。
+func BodyLimitFilter(maxSize int64) Filter {
+	return &bodyLimitFilter{maxSize: maxSize}
+}
+
+func (f *bodyLimitFilter) Invoke(ctx Context, chain FilterChain) {
+	r := ctx.Request()
+	if f.maxSize > 0 && r.Body != nil && r.Body != http.NoBody {
+		if r.ContentLength > f.maxSize {
+			panic(NewHttpError(http.StatusRequestEntityTooLarge))
+		}
+		r.Body = &limitedBody{ReadCloser: r.Body, n: f.maxSize}
+	}
+	chain.Next(ctx)
+}
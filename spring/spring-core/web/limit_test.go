@@ -0,0 +1,39 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+)
+
+func TestLimitedBody(t *testing.T) {
+
+	body := &limitedBody{ReadCloser: ioutil.NopCloser(strings.NewReader("hello")), n: 5}
+	b, err := ioutil.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), "hello")
+
+	body = &limitedBody{ReadCloser: ioutil.NopCloser(strings.NewReader("hello world")), n: 5}
+	b, err = ioutil.ReadAll(body)
+	assert.Equal(t, err, ErrBodyTooLarge)
+	assert.Equal(t, string(b), "hello")
+	assert.Equal(t, ErrorStatus(err), 413)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-spring/spring-core/conf"
+)
+
+const (
+	listenUnix    = "unix:"
+	listenSystemd = "systemd"
+	listenFD      = "fd:"
+
+	// systemd socket activation 传入的第一个文件描述符。
+	listenFDsStart = 3
+)
+
+var (
+	inheritedLock      sync.Mutex
+	inheritedListeners = map[int]net.Listener{}
+)
+
+// Listen 根据 web.server.listen 配置创建监听器，为空时监听 IP:Port 。unix socket
+// 的文件已经存在时会先删除，以便进程重启后能够重新监听。systemd socket activation
+// 和 fd:<n> 使用从父进程继承的文件描述符，可以配合 launchd 等进程管理工具实现不停
+// 机的重启，同一个文件描述符只会创建一个监听器。
+func Listen(config conf.WebServerConfig) (net.Listener, error) {
+	listen := config.Listen
+	switch {
+	case listen == "":
+		return net.Listen("tcp", fmt.Sprintf("%s:%d", config.IP, config.Port))
+	case strings.HasPrefix(listen, listenUnix):
+		return listenUnixSocket(strings.TrimPrefix(listen, listenUnix), config.SocketMode)
+	case listen == listenSystemd || strings.HasPrefix(listen, listenSystemd+":"):
+		fd, err := systemdFD(strings.TrimPrefix(strings.TrimPrefix(listen, listenSystemd), ":"))
+		if err != nil {
+			return nil, err
+		}
+		return inheritedListener(fd)
+	case strings.HasPrefix(listen, listenFD):
+		fd, err := strconv.Atoi(strings.TrimPrefix(listen, listenFD))
+		if err != nil {
+			return nil, fmt.Errorf("invalid listen address %q", listen)
+		}
+		return inheritedListener(fd)
+	}
+	return nil, fmt.Errorf("unsupported listen address %q", listen)
+}
+
+// ListenAddress 返回用于日志输出的监听地址。
+func ListenAddress(config conf.WebServerConfig) string {
+	if config.Listen != "" {
+		return config.Listen
+	}
+	return fmt.Sprintf("%s:%d", config.IP, config.Port)
+}
+
+func listenUnixSocket(path, mode string) (net.Listener, error) {
+	path = strings.TrimPrefix(path, "//")
+	if path == "" {
+		return nil, errors.New("unix socket path is empty")
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != "" {
+		m, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			_ = l.Close()
+			return nil, fmt.Errorf("invalid unix socket mode %q", mode)
+		}
+		if err = os.Chmod(path, os.FileMode(m)); err != nil {
+			_ = l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// systemdFD 返回 systemd socket activation 传入的文件描述符，name 为空时返回
+// 第一个文件描述符，否则根据 LISTEN_FDNAMES 查找，对应 socket 单元的
+// FileDescriptorName 配置。
+func systemdFD(name string) (int, error) {
+
+	if pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID")); pid != os.Getpid() {
+		return 0, errors.New("no listeners passed by systemd")
+	}
+
+	n, _ := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if n <= 0 {
+		return 0, errors.New("no listeners passed by systemd")
+	}
+
+	if name == "" {
+		return listenFDsStart, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < n && i < len(names); i++ {
+		if names[i] == name {
+			return listenFDsStart + i, nil
+		}
+	}
+	return 0, fmt.Errorf("systemd listener %q not found", name)
+}
+
+func inheritedListener(fd int) (net.Listener, error) {
+
+	inheritedLock.Lock()
+	defer inheritedLock.Unlock()
+
+	if l, ok := inheritedListeners[fd]; ok {
+		return nil, fmt.Errorf("listener fd %d already in use by %s", fd, l.Addr())
+	}
+
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("listener-%d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("invalid listener fd %d", fd)
+	}
+	defer f.Close()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("listener fd %d error: %w", fd, err)
+	}
+	inheritedListeners[fd] = l
+	return l, nil
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"net"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+)
+
+func TestInheritedListener(t *testing.T) {
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+
+	f, err := l.(*net.TCPListener).File()
+	assert.Nil(t, err)
+	fd, err := syscall.Dup(int(f.Fd())) // 监听器接管 fd 的所有权
+	assert.Nil(t, err)
+	_ = f.Close()
+
+	inherited, err := Listen(conf.WebServerConfig{Listen: "fd:" + strconv.Itoa(fd)})
+	assert.Nil(t, err)
+	defer inherited.Close()
+	assert.Equal(t, inherited.Addr().String(), l.Addr().String())
+
+	_, err = inheritedListener(fd)
+	assert.Error(t, err, "already in use")
+}
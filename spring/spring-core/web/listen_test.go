@@ -0,0 +1,111 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+)
+
+func TestListen(t *testing.T) {
+
+	t.Run("tcp", func(t *testing.T) {
+		config := conf.WebServerConfig{IP: "127.0.0.1", Port: 0}
+		l, err := Listen(config)
+		assert.Nil(t, err)
+		defer l.Close()
+		assert.Equal(t, l.Addr().Network(), "tcp")
+		assert.Equal(t, ListenAddress(config), "127.0.0.1:0")
+	})
+
+	t.Run("unix", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "listen")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "web.sock")
+		assert.Nil(t, ioutil.WriteFile(path, nil, 0644)) // 残留的 socket 文件
+
+		config := conf.WebServerConfig{Listen: "unix:" + path, SocketMode: "0600"}
+		l, err := Listen(config)
+		assert.Nil(t, err)
+		assert.Equal(t, ListenAddress(config), "unix:"+path)
+
+		fi, err := os.Stat(path)
+		assert.Nil(t, err)
+		assert.Equal(t, fi.Mode().Perm(), os.FileMode(0600))
+
+		svr := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})}
+		go func() { _ = svr.Serve(l) }()
+		defer svr.Close()
+
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", path)
+			},
+		}}
+		resp, err := client.Get("http://unix/")
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, string(b), "ok")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := Listen(conf.WebServerConfig{Listen: "tcp4://:80"})
+		assert.Error(t, err, "unsupported listen address \"tcp4://:80\"")
+	})
+}
+
+func TestSystemdFD(t *testing.T) {
+
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	_, err := systemdFD("")
+	assert.Error(t, err, "no listeners passed by systemd")
+
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	os.Setenv("LISTEN_FDS", "2")
+	os.Setenv("LISTEN_FDNAMES", "http:admin")
+
+	fd, err := systemdFD("")
+	assert.Nil(t, err)
+	assert.Equal(t, fd, 3)
+
+	fd, err = systemdFD("admin")
+	assert.Nil(t, err)
+	assert.Equal(t, fd, 4)
+
+	_, err = systemdFD("grpc")
+	assert.Error(t, err, "systemd listener \"grpc\" not found")
+}
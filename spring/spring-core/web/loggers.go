@@ -0,0 +1,112 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+type loggerLevel struct {
+	Name  string `json:"name"`
+	Level string `json:"level"` // 为空时删除命名日志的输出级别
+}
+
+// loggerDescriptor 命名日志的输出级别，格式和 Spring Boot 的 loggers 端点相同。
+type loggerDescriptor struct {
+	ConfiguredLevel string `json:"configuredLevel,omitempty"` // 配置的级别
+	EffectiveLevel  string `json:"effectiveLevel"`            // 实际生效的级别
+}
+
+type loggersResponse struct {
+	Levels  []string                    `json:"levels"`
+	Loggers map[string]loggerDescriptor `json:"loggers"`
+}
+
+func describeLogger(name string, configured map[string]log.Level) loggerDescriptor {
+	d := loggerDescriptor{EffectiveLevel: strings.ToUpper(log.GetLoggerLevel(name).String())}
+	if level, ok := configured[name]; ok {
+		d.ConfiguredLevel = strings.ToUpper(level.String())
+	}
+	return d
+}
+
+// setLoggerLevel 设置命名日志的输出级别，level 为空时删除配置的级别。
+func setLoggerLevel(name string, level string) {
+	if level == "" {
+		if name == "" || name == log.RootLogger {
+			panic(NewHttpError(http.StatusBadRequest, "root logger level required"))
+		}
+		log.RemoveLoggerLevel(name)
+		return
+	}
+	l, err := log.ParseLevel(level)
+	if err != nil {
+		panic(NewHttpError(http.StatusBadRequest, err.Error()))
+	}
+	log.SetLoggerLevel(name, l)
+}
+
+// RegisterLoggers 注册命名日志输出级别的运行时调整端点，和 Spring Boot 的
+// loggers 端点兼容。GET /actuator/loggers 返回所有命名日志的配置级别和生效级别，
+// GET /actuator/loggers/{name} 返回指定命名日志的级别，POST 同样的地址使用
+// {"configuredLevel":"DEBUG"} 修改级别，configuredLevel 为空时删除配置的级别。
+// 另外也可以使用 PUT /actuator/loggers 提交 {"name":"web","level":"debug"} 。
+func RegisterLoggers(r Router) {
+	const path = "/actuator/loggers"
+
+	list := func(ctx Context) {
+		configured := log.GetLoggerLevels()
+		resp := loggersResponse{Loggers: make(map[string]loggerDescriptor)}
+		for level := log.TraceLevel; level <= log.FatalLevel; level++ {
+			resp.Levels = append(resp.Levels, strings.ToUpper(level.String()))
+		}
+		for _, name := range log.GetLoggerNames() {
+			resp.Loggers[name] = describeLogger(name, configured)
+		}
+		ctx.JSON(&resp)
+	}
+
+	r.GetMapping(path, list)
+	r.GetMapping(path+"/:name", func(ctx Context) {
+		ctx.JSON(describeLogger(ctx.PathParam("name"), log.GetLoggerLevels()))
+	})
+
+	r.PostMapping(path+"/:name", func(ctx Context) {
+		var d struct {
+			ConfiguredLevel string `json:"configuredLevel"`
+		}
+		if err := json.NewDecoder(ctx.Request().Body).Decode(&d); err != nil && err != io.EOF {
+			panic(NewHttpError(http.StatusBadRequest, err.Error()))
+		}
+		setLoggerLevel(ctx.PathParam("name"), d.ConfiguredLevel)
+		ctx.NoContent(http.StatusNoContent)
+	})
+
+	r.PutMapping(path, func(ctx Context) {
+		var l loggerLevel
+		if err := json.NewDecoder(ctx.Request().Body).Decode(&l); err != nil {
+			panic(NewHttpError(http.StatusBadRequest, err.Error()))
+		}
+		setLoggerLevel(l.Name, l.Level)
+		list(ctx)
+	})
+}
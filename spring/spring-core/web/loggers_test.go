@@ -0,0 +1,41 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+)
+
+func TestLoggers(t *testing.T) {
+	defer log.Reset()
+
+	setLoggerLevel("web", "debug")
+	d := describeLogger("web.access", log.GetLoggerLevels())
+	assert.Equal(t, d, loggerDescriptor{EffectiveLevel: "DEBUG"})
+	d = describeLogger("web", log.GetLoggerLevels())
+	assert.Equal(t, d, loggerDescriptor{ConfiguredLevel: "DEBUG", EffectiveLevel: "DEBUG"})
+
+	setLoggerLevel("web", "")
+	d = describeLogger("web", log.GetLoggerLevels())
+	assert.Equal(t, d, loggerDescriptor{EffectiveLevel: "INFO"})
+
+	assert.Panic(t, func() { setLoggerLevel("web", "verbose") }, "unknown log level")
+	assert.Panic(t, func() { setLoggerLevel(log.RootLogger, "") }, "root logger level required")
+}
@@ -0,0 +1,29 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+// ManagementContainer 运行管理端点的独立 Web 容器，与业务容器使用不同的端口
+// 和过滤器，业务路由不会注册到该容器上。使用独立的类型是为了避免被当作业务容器
+// 注入到 []web.Container 中。
+type ManagementContainer struct {
+	Container
+}
+
+// NewManagementContainer ManagementContainer 的构造函数
+func NewManagementContainer(c Container) *ManagementContainer {
+	return &ManagementContainer{Container: c}
+}
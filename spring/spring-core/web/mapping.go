@@ -18,6 +18,7 @@ package web
 
 import (
 	"net/http"
+	"time"
 )
 
 const (
@@ -62,6 +63,9 @@ type Mapper struct {
 	path    string    // 路由地址
 	handler Handler   // 处理函数
 	swagger Operation // 描述文档
+	timeout time.Duration
+	server  string // 注册到的 Web 服务器名称
+	version string // API 版本号
 }
 
 // NewMapper Mapper 的构造函数
@@ -84,6 +88,41 @@ func (m *Mapper) Handler() Handler {
 	return m.handler
 }
 
+// Timeout 返回 Mapper 的超时时间
+func (m *Mapper) Timeout() time.Duration {
+	return m.timeout
+}
+
+// SetTimeout 设置 Mapper 的超时时间，0 表示使用 web.server.timeout 的值，
+// 小于 0 表示不设置超时时间。
+func (m *Mapper) SetTimeout(timeout time.Duration) *Mapper {
+	m.timeout = timeout
+	return m
+}
+
+// Server 返回 Mapper 注册到的 Web 服务器名称，为空表示默认的 Web 服务器。
+func (m *Mapper) Server() string {
+	return m.server
+}
+
+// SetServer 设置 Mapper 注册到的 Web 服务器，name 对应 web.servers.<name> 配置
+// 的附加服务器，为空表示默认的 Web 服务器。
+func (m *Mapper) SetServer(name string) *Mapper {
+	m.server = name
+	return m
+}
+
+// Version 返回 Mapper 的 API 版本号，为空表示没有版本号。
+func (m *Mapper) Version() string {
+	return m.version
+}
+
+// SetVersion 设置 Mapper 的 API 版本号，一般通过 Router.Version 设置。
+func (m *Mapper) SetVersion(version string) *Mapper {
+	m.version = version
+	return m
+}
+
 // Operation 设置与 Mapper 绑定的 Operation 对象
 func (m *Mapper) Operation(op Operation) {
 	m.swagger = op
@@ -142,11 +181,17 @@ type Router interface {
 
 	// RequestBinding 注册任意 HTTP 方法处理函数
 	RequestBinding(method uint32, path string, fn interface{}) *Mapper
+
+	// Version 返回注册指定 API 版本号处理函数的 Router ，相同方法和路径的
+	// 多个版本根据请求头或者 Accept 媒体类型选择，参见 RequestVersion 。
+	Version(version string) Router
 }
 
 // router 路由注册接口的默认实现
 type router struct {
 	mappers []*Mapper
+	parent  *router // 带有版本号的 router 将 Mapper 注册到 parent 上
+	version string
 }
 
 // NewRouter router 的构造函数。
@@ -156,14 +201,30 @@ func NewRouter() *router {
 
 // Mappers 返回映射器列表
 func (r *router) Mappers() []*Mapper {
+	if r.parent != nil {
+		return r.parent.Mappers()
+	}
 	return r.mappers
 }
 
 // AddMapper 添加一个 Mapper
 func (r *router) AddMapper(m *Mapper) {
+	if r.parent != nil {
+		m.version = r.version
+		r.parent.AddMapper(m)
+		return
+	}
 	r.mappers = append(r.mappers, m)
 }
 
+// Version 返回注册指定 API 版本号处理函数的 Router
+func (r *router) Version(version string) Router {
+	if r.parent != nil {
+		r = r.parent
+	}
+	return &router{parent: r, version: version}
+}
+
 func (r *router) request(method uint32, path string, h Handler) *Mapper {
 	m := NewMapper(method, path, h)
 	r.AddMapper(m)
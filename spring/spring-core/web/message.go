@@ -0,0 +1,101 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-spring/spring-core/web/i18n"
+)
+
+// T 返回上下文语言对应的消息，上下文语言由 LocaleFilter 设置，消息来自
+// i18n.Default() 。
+func T(ctx Context, key string, args ...interface{}) string {
+	return i18n.Message(ctx.Context(), key, args...)
+}
+
+// localeFilter 解析 Accept-Language 请求头并设置上下文语言的过滤器。
+type localeFilter struct {
+	supported []string
+}
+
+// LocaleFilter 返回解析 Accept-Language 请求头的过滤器，从 supported 中选出客户
+// 端最偏好的语言作为上下文语言，没有匹配的语言时不设置上下文语言。
+func LocaleFilter(supported ...string) Filter {
+	return &localeFilter{supported: supported}
+}
+
+func (f *localeFilter) Invoke(ctx Context, chain FilterChain) {
+	language := ResolveLocale(ctx.GetHeader(HeaderAcceptLanguage), f.supported)
+	if language != "" {
+		if err := i18n.SetLanguage(ctx.Context(), language); err != nil {
+			panic(err)
+		}
+	}
+	chain.Next(ctx)
+}
+
+// ResolveLocale 根据 Accept-Language 请求头从 supported 中选出最匹配的语言，
+// 先按 q 值从高到低排序，每个语言先精确匹配再按主语言匹配。
+func ResolveLocale(acceptLanguage string, supported []string) string {
+
+	type item struct {
+		language string
+		q        float64
+	}
+
+	var items []item
+	for _, s := range strings.Split(acceptLanguage, ",") {
+		ss := strings.Split(strings.TrimSpace(s), ";")
+		if ss[0] == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range ss[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			items = append(items, item{language: ss[0], q: q})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+
+	for _, it := range items {
+		for _, s := range supported {
+			if strings.EqualFold(s, it.language) {
+				return s
+			}
+		}
+		base := i18n.BaseLanguage(it.language)
+		for _, s := range supported {
+			if strings.EqualFold(i18n.BaseLanguage(s), base) {
+				return s
+			}
+		}
+	}
+	return ""
+}
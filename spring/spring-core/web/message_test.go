@@ -0,0 +1,34 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestResolveLocale(t *testing.T) {
+	supported := []string{"en", "zh-CN"}
+	assert.Equal(t, web.ResolveLocale("zh-CN,zh;q=0.9,en;q=0.8", supported), "zh-CN")
+	assert.Equal(t, web.ResolveLocale("fr;q=0.9, en-GB;q=0.5", supported), "en")
+	assert.Equal(t, web.ResolveLocale("zh-TW", supported), "zh-CN")
+	assert.Equal(t, web.ResolveLocale("en;q=0.5, zh;q=0.8", supported), "zh-CN")
+	assert.Equal(t, web.ResolveLocale("fr", supported), "")
+	assert.Equal(t, web.ResolveLocale("", supported), "")
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-spring/spring-core/metrics"
+)
+
+type metricsFilter struct{}
+
+// MetricsFilter 返回记录请求耗时的过滤器，指标为 http.server.requests ，标签
+// method 为请求方法，uri 为路由的路径，status 为响应状态码，处理函数 panic 时
+// 状态码记为 500 。SetMetricsExclude 设置的请求不记录指标。
+func MetricsFilter() Filter {
+	return &metricsFilter{}
+}
+
+func (f *metricsFilter) Invoke(ctx Context, chain FilterChain) {
+	if excludeMetrics(ctx.Request()) {
+		chain.Next(ctx)
+		return
+	}
+	start := time.Now()
+	defer func() {
+		r := recover()
+		status := ctx.ResponseWriter().Status()
+		if r != nil {
+			status = http.StatusInternalServerError
+		}
+		metrics.NewTimer("http.server.requests",
+			"method", ctx.Request().Method,
+			"uri", ctx.Path(),
+			"status", strconv.Itoa(status),
+		).Since(start)
+		if r != nil {
+			panic(r)
+		}
+	}()
+	chain.Next(ctx)
+}
+
+// RegisterMetrics 注册指标端点，GET /actuator/metrics 以 JSON 格式返回所有指标，
+// GET /actuator/prometheus 以 Prometheus 文本格式返回所有指标。
+func RegisterMetrics(r Router) {
+	r.GetMapping("/actuator/metrics", func(ctx Context) {
+		ctx.JSON(metrics.Default().Snapshot())
+	})
+	r.GetMapping("/actuator/prometheus", func(ctx Context) {
+		var buf bytes.Buffer
+		if err := metrics.WritePrometheus(&buf, metrics.Default().Snapshot()); err != nil {
+			panic(err)
+		}
+		ctx.Blob(metrics.PrometheusContentType, buf.Bytes())
+	})
+}
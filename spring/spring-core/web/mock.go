@@ -0,0 +1,134 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/go-spring/spring-base/fastdev"
+)
+
+// MockServer 只使用录制的上游流量响应请求，根据请求方法、路径和请求体的摘要
+// 匹配录制的请求，同一个请求录制了多次时使用最后一次的响应，没有匹配的请求
+// 返回 404 。可以为前端开发和集成测试提供稳定的模拟服务。
+type MockServer struct {
+	mutex     sync.RWMutex
+	responses map[string]*fastdev.HttpResponse
+}
+
+// NewMockServer 返回新的 MockServer 对象。
+func NewMockServer() *MockServer {
+	return &MockServer{responses: make(map[string]*fastdev.HttpResponse)}
+}
+
+// mockKey 返回请求的匹配键，请求体使用规范编码后计算摘要，所以字段顺序不同的
+// JSON 请求体能够匹配。
+func mockKey(req *fastdev.HttpRequest) string {
+	path := req.URL
+	if r, err := req.Request(); err == nil {
+		path = r.URL.Path
+	}
+	sum := sha256.Sum256([]byte(req.Body))
+	return req.Method + " " + path + " " + hex.EncodeToString(sum[:])
+}
+
+// Add 添加录制的会话，会话的上游流量必须是 HTTP 协议。
+func (m *MockServer) Add(session *fastdev.Session) error {
+
+	if session.Inbound == nil || session.Inbound.Protocol != fastdev.HTTP {
+		return nil
+	}
+
+	req, err := fastdev.ToHttpRequest(session.Inbound.Request)
+	if err != nil {
+		return err
+	}
+
+	resp, err := fastdev.ToHttpResponse(session.Inbound.Response)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.responses[mockKey(req)] = resp
+	return nil
+}
+
+// LoadFile 加载 JSON Lines 格式的录制文件，即 fastdev.FileStorage 的输出。
+func (m *MockServer) LoadFile(file string) error {
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			session, e := fastdev.ToSession(line, false)
+			if e != nil {
+				return e
+			}
+			if e = m.Add(session); e != nil {
+				return e
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (m *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mutex.RLock()
+	resp, ok := m.responses[mockKey(fastdev.NewHttpRequest(r, body))]
+	m.mutex.RUnlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	for k, v := range resp.Header {
+		if k != HeaderContentLength {
+			w.Header()[k] = v
+		}
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write([]byte(resp.Body))
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestMockServer(t *testing.T) {
+
+	m := web.NewMockServer()
+	err := m.LoadFile("testdata/mock/record.jsonl")
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("POST", "/users", strings.NewReader(`{"name": "a", "age": 1}`)))
+	assert.Equal(t, w.Code, http.StatusCreated)
+	assert.Equal(t, w.Header().Get(web.HeaderContentType), "application/json")
+	assert.Equal(t, w.Body.String(), `{"id":1}`)
+
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/users/1", nil))
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), "ok")
+
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"b"}`)))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+}
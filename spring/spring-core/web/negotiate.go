@@ -0,0 +1,234 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotSerializable 序列化器不支持该类型的数据，内容协商时会继续尝试下一个可以
+// 接受的序列化器。
+var ErrNotSerializable = errors.New("not serializable")
+
+// Serializer 响应数据的序列化器。
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+}
+
+// SerializerFunc 函数形式的序列化器。
+type SerializerFunc func(v interface{}) ([]byte, error)
+
+func (f SerializerFunc) Marshal(v interface{}) ([]byte, error) {
+	return f(v)
+}
+
+type serializer struct {
+	contentType string // 响应的 Content-Type ，可以带有参数
+	mediaType   string // 用于匹配 Accept 请求头的媒体类型
+	s           Serializer
+}
+
+var serializers = struct {
+	sync.RWMutex
+	list []serializer
+}{}
+
+func init() {
+	RegisterSerializer(MIMEApplicationJSONCharsetUTF8, SerializerFunc(json.Marshal))
+	RegisterSerializer(MIMEApplicationXMLCharsetUTF8, SerializerFunc(xml.Marshal))
+	RegisterSerializer(MIMETextXMLCharsetUTF8, SerializerFunc(xml.Marshal))
+	RegisterSerializer(MIMEApplicationMsgpack, SerializerFunc(marshalMsgpack))
+	RegisterSerializer(MIMEApplicationProtobuf, SerializerFunc(marshalProtobuf))
+}
+
+// RegisterSerializer 注册 Content-Type 为 contentType 的序列化器，媒体类型相同的
+// 序列化器会被覆盖。Accept 请求头对多个序列化器的偏好相同时按照注册的顺序选择，
+// 因此默认使用 JSON 格式。
+func RegisterSerializer(contentType string, s Serializer) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		panic(err)
+	}
+	serializers.Lock()
+	defer serializers.Unlock()
+	for i, v := range serializers.list {
+		if v.mediaType == mediaType {
+			serializers.list[i] = serializer{contentType, mediaType, s}
+			return
+		}
+	}
+	serializers.list = append(serializers.list, serializer{contentType, mediaType, s})
+}
+
+// mediaRange Accept 请求头中的一个媒体类型范围。
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// match 返回媒体类型是否匹配，以及匹配的精确程度，越大越精确。
+func (r mediaRange) match(mediaType string) (int, bool) {
+	ss := strings.SplitN(mediaType, "/", 2)
+	if len(ss) != 2 {
+		return 0, false
+	}
+	switch {
+	case r.typ == "*" && r.subtype == "*":
+		return 0, true
+	case r.typ != ss[0]:
+		return 0, false
+	case r.subtype == "*":
+		return 1, true
+	case r.subtype == ss[1]:
+		return 2, true
+	}
+	return 0, false
+}
+
+// parseAccept 解析 Accept 请求头，请求头为空时表示接受任意类型。
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, s := range strings.Split(accept, ",") {
+		ss := strings.Split(strings.TrimSpace(s), ";")
+		typ := strings.ToLower(strings.TrimSpace(ss[0]))
+		if typ == "" {
+			continue
+		}
+		if typ == "*" {
+			typ = "*/*"
+		}
+		i := strings.Index(typ, "/")
+		if i <= 0 || i == len(typ)-1 {
+			continue
+		}
+		q := 1.0
+		for _, p := range ss[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ[:i], subtype: typ[i+1:], q: q})
+	}
+	if len(ranges) == 0 {
+		ranges = append(ranges, mediaRange{typ: "*", subtype: "*", q: 1})
+	}
+	return ranges
+}
+
+// Negotiate 根据 Accept 请求头选择序列化器对 v 进行序列化，返回响应的 Content-Type
+// 和序列化后的数据。每个序列化器的 q 值取自最精确匹配的媒体类型范围，按照 q 值从高
+// 到低、匹配的精确程度从高到低以及注册的顺序依次尝试，序列化器返回 ErrNotSerializable
+// 时尝试下一个。没有可以接受的序列化器时返回 406 错误。
+func Negotiate(accept string, v interface{}) (string, []byte, error) {
+
+	type candidate struct {
+		serializer
+		q         float64
+		precision int
+	}
+
+	ranges := parseAccept(accept)
+
+	serializers.RLock()
+	list := serializers.list
+	serializers.RUnlock()
+
+	var candidates []candidate
+	for _, s := range list {
+		c := candidate{serializer: s, precision: -1}
+		for _, r := range ranges {
+			if n, ok := r.match(s.mediaType); ok && n > c.precision {
+				c.q, c.precision = r.q, n
+			}
+		}
+		if c.precision >= 0 && c.q > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].q != candidates[j].q {
+			return candidates[i].q > candidates[j].q
+		}
+		return candidates[i].precision > candidates[j].precision
+	})
+
+	for _, c := range candidates {
+		b, err := c.s.Marshal(v)
+		if errors.Is(err, ErrNotSerializable) {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		return c.contentType, b, nil
+	}
+
+	msg := fmt.Sprintf("no acceptable representation for %q", accept)
+	return "", nil, NewHttpError(http.StatusNotAcceptable, msg)
+}
+
+// Respond 根据请求的 Accept 请求头选择序列化格式发送 i ，同一个处理函数因此可以
+// 同时提供 JSON 、XML 等多种格式的响应。Maybe panic.
+func Respond(ctx Context, i interface{}) {
+	contentType, b, err := Negotiate(ctx.GetHeader(HeaderAccept), i)
+	if err != nil {
+		panic(err)
+	}
+	ctx.ResponseWriter().Header().Add(HeaderVary, HeaderAccept)
+	ctx.Blob(contentType, b)
+}
+
+// msgpackMarshaler msgp 等代码生成工具生成的 msgpack 序列化方法。
+type msgpackMarshaler interface {
+	MarshalMsg(b []byte) ([]byte, error)
+}
+
+// marshalMsgpack 序列化实现了 MarshalMsg 方法的数据，需要其他 msgpack 实现时可以
+// 通过 RegisterSerializer 覆盖。
+func marshalMsgpack(v interface{}) ([]byte, error) {
+	if m, ok := v.(msgpackMarshaler); ok {
+		return m.MarshalMsg(nil)
+	}
+	return nil, ErrNotSerializable
+}
+
+// protobufMarshaler gogo/protobuf 、vtprotobuf 等生成的 protobuf 序列化方法。
+type protobufMarshaler interface {
+	Marshal() ([]byte, error)
+}
+
+// marshalProtobuf 序列化实现了 Marshal 方法的 protobuf 消息，使用 google.golang.org/protobuf
+// 时可以通过 RegisterSerializer 注册 proto.Marshal 。
+func marshalProtobuf(v interface{}) ([]byte, error) {
+	if m, ok := v.(protobufMarshaler); ok {
+		return m.Marshal()
+	}
+	return nil, ErrNotSerializable
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+type negotiateModel struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func (m negotiateModel) Marshal() ([]byte, error) {
+	return []byte("pb:" + m.Name), nil
+}
+
+func TestNegotiate(t *testing.T) {
+	testcases := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", web.MIMEApplicationJSONCharsetUTF8, `{"name":"a"}`},
+		{"*/*", web.MIMEApplicationJSONCharsetUTF8, `{"name":"a"}`},
+		{"application/xml", web.MIMEApplicationXMLCharsetUTF8, `<negotiateModel><name>a</name></negotiateModel>`},
+		{"text/*", web.MIMETextXMLCharsetUTF8, `<negotiateModel><name>a</name></negotiateModel>`},
+		{"application/xml;q=0.5, application/json;q=0.8", web.MIMEApplicationJSONCharsetUTF8, `{"name":"a"}`},
+		{"application/xml, */*", web.MIMEApplicationXMLCharsetUTF8, `<negotiateModel><name>a</name></negotiateModel>`},
+		{"application/json;q=0, */*", web.MIMEApplicationXMLCharsetUTF8, `<negotiateModel><name>a</name></negotiateModel>`},
+		{"application/protobuf", web.MIMEApplicationProtobuf, "pb:a"},
+		{"application/msgpack, application/json;q=0.1", web.MIMEApplicationJSONCharsetUTF8, `{"name":"a"}`},
+	}
+	for _, c := range testcases {
+		contentType, b, err := web.Negotiate(c.accept, negotiateModel{Name: "a"})
+		assert.Nil(t, err)
+		assert.Equal(t, contentType, c.contentType)
+		assert.Equal(t, string(b), c.body)
+	}
+	_, _, err := web.Negotiate("application/msgpack", negotiateModel{Name: "a"})
+	assert.Error(t, err, "no acceptable representation for \"application/msgpack\"")
+	_, _, err = web.Negotiate("image/png", negotiateModel{Name: "a"})
+	assert.Error(t, err, "no acceptable representation")
+}
+
+func TestRespond(t *testing.T) {
+
+	web.RegisterSerializer("application/yaml", web.SerializerFunc(func(v interface{}) ([]byte, error) {
+		return []byte("name: " + v.(negotiateModel).Name), nil
+	}))
+
+	router := web.NewRouter()
+	router.GetMapping("/model", func(ctx web.Context) {
+		ctx.Status(http.StatusCreated)
+		ctx.Respond(negotiateModel{Name: "a"})
+	})
+	h := web.ToHTTPHandler(router, web.RecoveryFilter(0))
+
+	testcases := []struct {
+		accept string
+		code   int
+		ctype  string
+		body   string
+	}{
+		{"application/json", http.StatusCreated, web.MIMEApplicationJSONCharsetUTF8, `{"name":"a"}`},
+		{"application/yaml", http.StatusCreated, "application/yaml", "name: a"},
+		{"image/png", http.StatusNotAcceptable, "", ""},
+	}
+	for _, c := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "/model", nil)
+		req.Header.Set(web.HeaderAccept, c.accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, c.code)
+		if c.code == http.StatusCreated {
+			assert.Equal(t, w.Header().Get(web.HeaderContentType), c.ctype)
+			assert.Equal(t, w.Header().Get(web.HeaderVary), web.HeaderAccept)
+			assert.Equal(t, w.Body.String(), c.body)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// pprofHandler 根据请求路径分发 pprof 请求，只注册一个通配符路由可以避免
+// 与部分路由实现中静态路由和通配符路由的冲突。
+func pprofHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	switch name {
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "profile":
+		pprof.Profile(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "trace":
+		pprof.Trace(w, r)
+	default:
+		pprof.Index(w, r)
+	}
+}
+
+// RegisterPprof 注册 /debug/pprof/* 和 /debug/vars 处理函数，这些接口会暴露
+// 程序的运行信息，应该只在受保护的网络或者管理端口上开启。
+func RegisterPprof(r Router) {
+	r.HandleRequest(MethodGetPost, "/debug/pprof/*", WrapF(pprofHandler))
+	r.HandleGet("/debug/vars", WrapH(expvar.Handler()))
+}
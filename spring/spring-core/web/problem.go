@@ -0,0 +1,157 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-base/util"
+)
+
+// Problem RFC 7807 定义的错误响应格式。
+type Problem struct {
+	Type     string `json:"type,omitempty"`     // 问题类型的 URI
+	Title    string `json:"title,omitempty"`    // 问题类型的简短描述
+	Status   int    `json:"status,omitempty"`   // HTTP 状态码
+	Detail   string `json:"detail,omitempty"`   // 本次问题的详细描述
+	Instance string `json:"instance,omitempty"` // 发生问题的请求地址
+}
+
+// errorMapping 错误与 HTTP 状态码的映射关系。
+type errorMapping struct {
+	target error        // 通过 errors.Is 匹配
+	typ    reflect.Type // 通过错误链上的类型匹配
+	code   int
+}
+
+var errorMappings = struct {
+	mutex sync.RWMutex
+	items []errorMapping
+}{
+	items: []errorMapping{
+		{target: context.DeadlineExceeded, code: http.StatusGatewayTimeout},
+		{target: context.Canceled, code: http.StatusServiceUnavailable},
+		{target: ErrBodyTooLarge, code: http.StatusRequestEntityTooLarge},
+		{typ: reflect.TypeOf((*BindError)(nil)), code: http.StatusBadRequest},
+	},
+}
+
+// RegisterErrorStatus 注册错误值对应的 HTTP 状态码，使用 errors.Is 进行匹配。
+func RegisterErrorStatus(target error, code int) {
+	errorMappings.mutex.Lock()
+	defer errorMappings.mutex.Unlock()
+	errorMappings.items = append(errorMappings.items, errorMapping{target: target, code: code})
+}
+
+// RegisterErrorType 注册错误类型对应的 HTTP 状态码，i 可以是 (*MyError)(nil)
+// 或者 MyError{} 形式，错误链上只要有一个错误的类型与之相同即匹配成功。
+func RegisterErrorType(i interface{}, code int) {
+	t := util.TypeOf(i)
+	if t == nil || !t.Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		panic(errors.New("i should be an error type"))
+	}
+	errorMappings.mutex.Lock()
+	defer errorMappings.mutex.Unlock()
+	errorMappings.items = append(errorMappings.items, errorMapping{typ: t, code: code})
+}
+
+// ErrorStatus 返回 err 对应的 HTTP 状态码，先注册的映射优先匹配，没有找到时返回 500 。
+func ErrorStatus(err error) int {
+
+	var e *HttpError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	errorMappings.mutex.RLock()
+	defer errorMappings.mutex.RUnlock()
+
+	for _, m := range errorMappings.items {
+		if m.target != nil {
+			if errors.Is(err, m.target) {
+				return m.code
+			}
+			continue
+		}
+		for x := err; x != nil; x = errors.Unwrap(x) {
+			if reflect.TypeOf(x) == m.typ {
+				return m.code
+			}
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// ToHttpError 将处理函数 panic 抛出的值转换为 *HttpError 对象。
+func ToHttpError(r interface{}) *HttpError {
+	switch e := r.(type) {
+	case *HttpError:
+		return e
+	case HttpError:
+		return &e
+	case error:
+		return &HttpError{Code: ErrorStatus(e), Message: e.Error()}
+	default:
+		code := http.StatusInternalServerError
+		return &HttpError{Code: code, Message: http.StatusText(code), Internal: r}
+	}
+}
+
+// ProblemErrorHandler 以 application/problem+json 格式返回错误信息，
+// 可以通过 web.ErrorHandler = web.ProblemErrorHandler 启用。
+func ProblemErrorHandler(ctx Context, err *HttpError) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Ctx(ctx.Context()).Error(r)
+		}
+	}()
+
+	p := &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(err.Code),
+		Status:   err.Code,
+		Detail:   err.Message,
+		Instance: ctx.Request().URL.Path,
+	}
+
+	if err.Message == p.Title {
+		p.Detail = ""
+	}
+
+	if v, ok := err.Internal.(*Problem); ok {
+		p = v
+		if p.Status == 0 {
+			p.Status = err.Code
+		}
+	}
+
+	b, e := json.Marshal(p)
+	if e != nil {
+		panic(fmt.Errorf("marshal problem error: %w", e))
+	}
+
+	ctx.Status(p.Status)
+	ctx.Blob(MIMEApplicationProblemJSON, b)
+}
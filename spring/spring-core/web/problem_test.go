@@ -0,0 +1,73 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+var errNotFound = errors.New("not found")
+
+type validateError struct {
+	field string
+}
+
+func (e *validateError) Error() string {
+	return "invalid field " + e.field
+}
+
+func TestErrorStatus(t *testing.T) {
+
+	web.RegisterErrorStatus(errNotFound, http.StatusNotFound)
+	web.RegisterErrorType((*validateError)(nil), http.StatusBadRequest)
+
+	assert.Equal(t, web.ErrorStatus(errNotFound), http.StatusNotFound)
+	assert.Equal(t, web.ErrorStatus(fmt.Errorf("user: %w", errNotFound)), http.StatusNotFound)
+	assert.Equal(t, web.ErrorStatus(&validateError{"name"}), http.StatusBadRequest)
+	assert.Equal(t, web.ErrorStatus(fmt.Errorf("user: %w", &validateError{"age"})), http.StatusBadRequest)
+	assert.Equal(t, web.ErrorStatus(errors.New("unknown")), http.StatusInternalServerError)
+	assert.Equal(t, web.ErrorStatus(web.NewHttpError(http.StatusConflict)), http.StatusConflict)
+	assert.Equal(t, web.ErrorStatus(context.DeadlineExceeded), http.StatusGatewayTimeout)
+	assert.Equal(t, web.ErrorStatus(context.Canceled), http.StatusServiceUnavailable)
+
+	assert.Panic(t, func() { web.RegisterErrorType("abc", http.StatusBadRequest) }, "i should be an error type")
+}
+
+func TestToHttpError(t *testing.T) {
+
+	web.RegisterErrorStatus(errNotFound, http.StatusNotFound)
+
+	e := web.ToHttpError(errNotFound)
+	assert.Equal(t, e.Code, http.StatusNotFound)
+	assert.Equal(t, e.Message, "not found")
+	assert.Nil(t, e.Internal) // ErrorHandler 会把 Internal 作为 JSON 响应体
+
+	e = web.ToHttpError(web.HttpError{Code: http.StatusForbidden, Message: "forbidden"})
+	assert.Equal(t, e.Code, http.StatusForbidden)
+
+	e = web.ToHttpError("oops")
+	assert.Equal(t, e.Code, http.StatusInternalServerError)
+	assert.Equal(t, e.Message, http.StatusText(http.StatusInternalServerError))
+	assert.Equal(t, e.Internal, "oops")
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-base/util"
+)
+
+var ErrBreakerOpen = errors.New("circuit breaker is open")
+
+// ProxyBreaker 代理请求的熔断钩子。
+type ProxyBreaker interface {
+
+	// Allow 返回是否允许发送请求。
+	Allow() bool
+
+	// Success 请求成功。
+	Success()
+
+	// Failure 请求失败，err 是网络错误或者 5xx 响应对应的错误。
+	Failure(err error)
+}
+
+type proxyArg struct {
+	retries   int
+	retryAll  bool
+	forward   []string
+	remove    []string
+	breaker   ProxyBreaker
+	transport http.RoundTripper
+}
+
+type ProxyOption func(arg *proxyArg)
+
+// ProxyRetries 设置网络错误时的重试次数，重试时会缓存请求体。默认只重试幂等
+// 的 GET 、HEAD 、OPTIONS 、PUT 和 DELETE 请求。
+func ProxyRetries(retries int) ProxyOption {
+	return func(arg *proxyArg) {
+		arg.retries = retries
+	}
+}
+
+// ProxyRetryNonIdempotent 允许重试 POST 、PATCH 等非幂等请求，后端可能因此
+// 重复执行同一个请求，只有后端能够识别重复请求时才应该开启。
+func ProxyRetryNonIdempotent() ProxyOption {
+	return func(arg *proxyArg) {
+		arg.retryAll = true
+	}
+}
+
+// ProxyForwardHeaders 设置允许转发的请求头，没有设置时转发全部请求头。
+func ProxyForwardHeaders(headers ...string) ProxyOption {
+	return func(arg *proxyArg) {
+		arg.forward = append(arg.forward, headers...)
+	}
+}
+
+// ProxyRemoveHeaders 设置转发前需要删除的请求头。
+func ProxyRemoveHeaders(headers ...string) ProxyOption {
+	return func(arg *proxyArg) {
+		arg.remove = append(arg.remove, headers...)
+	}
+}
+
+// ProxyWithBreaker 设置熔断钩子。
+func ProxyWithBreaker(breaker ProxyBreaker) ProxyOption {
+	return func(arg *proxyArg) {
+		arg.breaker = breaker
+	}
+}
+
+// ProxyTransport 设置发送请求使用的 http.RoundTripper 对象。
+func ProxyTransport(transport http.RoundTripper) ProxyOption {
+	return func(arg *proxyArg) {
+		arg.transport = transport
+	}
+}
+
+// proxyHandler 反向代理处理函数。
+type proxyHandler struct {
+	proxy    *httputil.ReverseProxy
+	retries  int
+	retryAll bool
+}
+
+// Proxy 返回将请求转发到 target 的处理函数，rewrite 用于改写请求路径，为 nil
+// 时保持原路径不变。后端不可用时返回 502 错误，熔断时返回 503 错误。
+func Proxy(target string, rewrite func(path string) string, opts ...ProxyOption) Handler {
+
+	u, err := url.Parse(target)
+	if err != nil {
+		panic(err)
+	}
+
+	arg := proxyArg{transport: http.DefaultTransport}
+	for _, opt := range opts {
+		opt(&arg)
+	}
+
+	forward := make(map[string]bool)
+	for _, h := range arg.forward {
+		forward[http.CanonicalHeaderKey(h)] = true
+	}
+
+	director := func(r *http.Request) {
+
+		path := r.URL.Path
+		if rewrite != nil {
+			path = rewrite(path)
+		}
+
+		if r.TLS != nil {
+			r.Header.Set(HeaderXForwardedProto, "https")
+		} else {
+			r.Header.Set(HeaderXForwardedProto, "http")
+		}
+		r.Header.Set(HeaderXForwardedHost, r.Host)
+
+		if len(forward) > 0 {
+			for k := range r.Header {
+				if !forward[k] && !strings.HasPrefix(k, "X-Forwarded-") {
+					r.Header.Del(k)
+				}
+			}
+		}
+		for _, h := range arg.remove {
+			r.Header.Del(h)
+		}
+
+		r.URL.Scheme = u.Scheme
+		r.URL.Host = u.Host
+		r.URL.Path = singleJoiningSlash(u.Path, path)
+		r.URL.RawPath = ""
+		if u.RawQuery == "" || r.URL.RawQuery == "" {
+			r.URL.RawQuery = u.RawQuery + r.URL.RawQuery
+		} else {
+			r.URL.RawQuery = u.RawQuery + "&" + r.URL.RawQuery
+		}
+		r.Host = u.Host
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Director: director,
+		Transport: &proxyTransport{
+			next:     arg.transport,
+			retries:  arg.retries,
+			retryAll: arg.retryAll,
+			breaker:  arg.breaker,
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Ctx(r.Context()).Errorf("proxy %s error: %v", r.URL, err)
+			if errors.Is(err, ErrBreakerOpen) {
+				panic(NewHttpError(http.StatusServiceUnavailable))
+			}
+			panic(NewHttpError(http.StatusBadGateway))
+		},
+	}
+	return &proxyHandler{proxy: proxy, retries: arg.retries, retryAll: arg.retryAll}
+}
+
+func (h *proxyHandler) Invoke(ctx Context) {
+	r := ctx.Request()
+	retryable := h.retryAll || isIdempotent(r.Method)
+	if h.retries > 0 && retryable && r.Body != nil && r.Body != http.NoBody {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			panic(NewHttpError(http.StatusBadRequest, err.Error()))
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(b))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(b)), nil
+		}
+	}
+	h.proxy.ServeHTTP(ctx.ResponseWriter(), r)
+}
+
+func (h *proxyHandler) FileLine() (file string, line int, fnName string) {
+	return util.FileLine(h.Invoke)
+}
+
+// proxyTransport 支持重试和熔断的 http.RoundTripper 实现。
+type proxyTransport struct {
+	next     http.RoundTripper
+	retries  int
+	retryAll bool
+	breaker  ProxyBreaker
+}
+
+func (t *proxyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	for i := 0; ; i++ {
+
+		if t.breaker != nil && !t.breaker.Allow() {
+			return nil, ErrBreakerOpen
+		}
+
+		resp, err := t.next.RoundTrip(r)
+		if t.breaker != nil {
+			if err != nil {
+				t.breaker.Failure(err)
+			} else if resp.StatusCode >= http.StatusInternalServerError {
+				t.breaker.Failure(fmt.Errorf("proxy response status %d", resp.StatusCode))
+			} else {
+				t.breaker.Success()
+			}
+		}
+
+		if err == nil || i >= t.retries || r.Context().Err() != nil {
+			return resp, err
+		}
+
+		if !t.retryAll && !isIdempotent(r.Method) {
+			return resp, err
+		}
+
+		if r.Body != nil && r.Body != http.NoBody {
+			if r.GetBody == nil {
+				return resp, err
+			}
+			if r.Body, err = r.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// isIdempotent 返回 HTTP 方法是否是幂等的，幂等的请求可以安全地重试。
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func singleJoiningSlash(a, b string) string {
+	aSlash := strings.HasSuffix(a, "/")
+	bSlash := strings.HasPrefix(b, "/")
+	switch {
+	case aSlash && bSlash:
+		return a + b[1:]
+	case !aSlash && !bSlash:
+		return a + "/" + b
+	}
+	return a + b
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+// flakyTransport 前 failures 次请求返回网络错误，之后使用 http.DefaultTransport 。
+type flakyTransport struct {
+	failures int
+	attempts int
+}
+
+func (t *flakyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.attempts++
+	if t.attempts <= t.failures {
+		return nil, errors.New("connection reset")
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+type countBreaker struct {
+	open     bool
+	success  int
+	failures int
+}
+
+func (b *countBreaker) Allow() bool       { return !b.open }
+func (b *countBreaker) Success()          { b.success++ }
+func (b *countBreaker) Failure(err error) { b.failures++ }
+
+// upstreamRequest 后端收到的请求。
+type upstreamRequest struct {
+	uri    string
+	header http.Header
+	body   string
+}
+
+func newUpstream(t *testing.T) (*httptest.Server, *[]upstreamRequest) {
+	var received []upstreamRequest
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		assert.Nil(t, err)
+		received = append(received, upstreamRequest{
+			uri:    r.URL.RequestURI(),
+			header: r.Header,
+			body:   string(b),
+		})
+		if r.URL.Path == "/api/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("upstream"))
+	}))
+	return s, &received
+}
+
+func serveProxy(h web.Handler, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	r := web.NewRouter()
+	r.HandleRequest(web.MethodAny, "/proxy/*", h)
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	web.ToHTTPHandler(r, web.RecoveryFilter(0)).ServeHTTP(w, req)
+	return w
+}
+
+func stripProxy(path string) string {
+	return strings.TrimPrefix(path, "/proxy")
+}
+
+func TestProxy_Path(t *testing.T) {
+
+	s, received := newUpstream(t)
+	defer s.Close()
+
+	w := serveProxy(web.Proxy(s.URL+"/api?key=1", stripProxy), http.MethodGet, "/proxy/users?page=2", "", nil)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), "upstream")
+	assert.Equal(t, (*received)[0].uri, "/api/users?key=1&page=2")
+
+	w = serveProxy(web.Proxy(s.URL+"/api/", stripProxy), http.MethodGet, "/proxy/users", "", nil)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, (*received)[1].uri, "/api/users")
+
+	w = serveProxy(web.Proxy(s.URL, nil), http.MethodGet, "/proxy/users", "", nil)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, (*received)[2].uri, "/proxy/users")
+}
+
+func TestProxy_Header(t *testing.T) {
+
+	s, received := newUpstream(t)
+	defer s.Close()
+
+	header := http.Header{}
+	header.Set("Accept", "text/plain")
+	header.Set("Authorization", "Bearer token")
+	header.Set("Cookie", "session=1")
+	header.Set("X-Trace", "abc")
+
+	h := web.Proxy(s.URL, stripProxy,
+		web.ProxyForwardHeaders("accept", "authorization", "x-trace"),
+		web.ProxyRemoveHeaders("Authorization"))
+	w := serveProxy(h, http.MethodGet, "/proxy/users", "", header)
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	got := (*received)[0].header
+	assert.Equal(t, got.Get("Accept"), "text/plain")
+	assert.Equal(t, got.Get("X-Trace"), "abc")
+	assert.Equal(t, got.Get("Authorization"), "")
+	assert.Equal(t, got.Get("Cookie"), "")
+	assert.Equal(t, got.Get(web.HeaderXForwardedProto), "http")
+	assert.Equal(t, got.Get(web.HeaderXForwardedHost), "example.com")
+	assert.Equal(t, got.Get(web.HeaderXForwardedFor), "192.0.2.1")
+}
+
+func TestProxy_Retries(t *testing.T) {
+
+	s, received := newUpstream(t)
+	defer s.Close()
+
+	t.Run("idempotent", func(t *testing.T) {
+		transport := &flakyTransport{failures: 2}
+		h := web.Proxy(s.URL, stripProxy, web.ProxyRetries(2), web.ProxyTransport(transport))
+		w := serveProxy(h, http.MethodPut, "/proxy/users", "put-body", nil)
+		assert.Equal(t, w.Code, http.StatusOK)
+		assert.Equal(t, transport.attempts, 3)
+		assert.Equal(t, (*received)[len(*received)-1].body, "put-body")
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		transport := &flakyTransport{failures: 3}
+		h := web.Proxy(s.URL, stripProxy, web.ProxyRetries(2), web.ProxyTransport(transport))
+		w := serveProxy(h, http.MethodGet, "/proxy/users", "", nil)
+		assert.Equal(t, w.Code, http.StatusBadGateway)
+		assert.Equal(t, transport.attempts, 3)
+	})
+
+	t.Run("non-idempotent", func(t *testing.T) {
+		transport := &flakyTransport{failures: 1}
+		h := web.Proxy(s.URL, stripProxy, web.ProxyRetries(2), web.ProxyTransport(transport))
+		w := serveProxy(h, http.MethodPost, "/proxy/orders", "order", nil)
+		assert.Equal(t, w.Code, http.StatusBadGateway)
+		assert.Equal(t, transport.attempts, 1)
+	})
+
+	t.Run("opt-in", func(t *testing.T) {
+		transport := &flakyTransport{failures: 1}
+		h := web.Proxy(s.URL, stripProxy, web.ProxyRetries(2),
+			web.ProxyRetryNonIdempotent(), web.ProxyTransport(transport))
+		w := serveProxy(h, http.MethodPost, "/proxy/orders", "order", nil)
+		assert.Equal(t, w.Code, http.StatusOK)
+		assert.Equal(t, transport.attempts, 2)
+		assert.Equal(t, (*received)[len(*received)-1].body, "order")
+	})
+}
+
+func TestProxy_Breaker(t *testing.T) {
+
+	s, received := newUpstream(t)
+	defer s.Close()
+
+	breaker := &countBreaker{}
+	h := web.Proxy(s.URL, nil, web.ProxyWithBreaker(breaker))
+
+	w := serveProxy(h, http.MethodGet, "/proxy/users", "", nil)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, breaker.success, 1)
+
+	h = web.Proxy(s.URL+"/api", stripProxy, web.ProxyWithBreaker(breaker))
+	w = serveProxy(h, http.MethodGet, "/proxy/fail", "", nil)
+	assert.Equal(t, w.Code, http.StatusInternalServerError)
+	assert.Equal(t, breaker.failures, 1)
+
+	breaker.open = true
+	n := len(*received)
+	w = serveProxy(h, http.MethodGet, "/proxy/users", "", nil)
+	assert.Equal(t, w.Code, http.StatusServiceUnavailable)
+	assert.Equal(t, len(*received), n)
+}
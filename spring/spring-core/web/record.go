@@ -18,78 +18,262 @@ package web
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
 	"net/http"
-	"strconv"
+	"path"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-spring/spring-base/cast"
 	"github.com/go-spring/spring-base/fastdev"
 	"github.com/go-spring/spring-base/knife"
-	"github.com/go-spring/spring-base/log"
 	"github.com/go-spring/spring-base/util"
 )
 
-// StartRecord 启动流量录制
+// RecordRule 流量录制的采样和过滤规则。Include 和 Exclude 的规则格式为
+// "GET /api/*" 或者 "/api/*"，省略方法时匹配所有方法，路径使用 path.Match
+// 进行匹配。
+type RecordRule struct {
+	SampleRate  float64  // 采样率，取值范围 0~1
+	Include     []string // 录制的请求，为空时录制所有请求
+	Exclude     []string // 不录制的请求，优先于 Include
+	ForceHeader string   // 请求头不为空时忽略采样率强制录制，Exclude 仍然有效
+
+	// MaxBodySize 录制的请求体和响应体的最大字节数，超过的部分被丢弃并以
+	// TruncatedMarker 结尾，避免 SSE 等长时间的流式响应占用过多内存，0 表示
+	// 不限制。
+	MaxBodySize int
+}
+
+// TruncatedMarker 录制的请求体和响应体被截断时追加的标记。
+const TruncatedMarker = "...(truncated)"
+
+var recordRule = struct {
+	mutex sync.RWMutex
+	rule  *RecordRule
+	rand  func() float64
+}{rand: rand.Float64}
+
+// SetRecordRule 设置流量录制的规则，没有设置规则时录制所有请求。
+func SetRecordRule(rule RecordRule) {
+	recordRule.mutex.Lock()
+	defer recordRule.mutex.Unlock()
+	recordRule.rule = &rule
+}
+
+// SetRecordSampleRate 运行时调整流量录制的采样率，其他规则保持不变。
+func SetRecordSampleRate(rate float64) {
+	recordRule.mutex.Lock()
+	defer recordRule.mutex.Unlock()
+	rule := RecordRule{SampleRate: rate}
+	if recordRule.rule != nil {
+		rule = *recordRule.rule
+		rule.SampleRate = rate
+	}
+	recordRule.rule = &rule
+}
+
+// recordSampleRate 返回当前的采样率，没有设置规则时为 1 。
+func recordSampleRate() float64 {
+	recordRule.mutex.RLock()
+	defer recordRule.mutex.RUnlock()
+	if recordRule.rule == nil {
+		return 1
+	}
+	return recordRule.rule.SampleRate
+}
+
+// recordMaxBodySize 返回录制的请求体和响应体的最大字节数，0 表示不限制。
+func recordMaxBodySize() int {
+	recordRule.mutex.RLock()
+	defer recordRule.mutex.RUnlock()
+	if recordRule.rule == nil {
+		return 0
+	}
+	return recordRule.rule.MaxBodySize
+}
+
+// recordSwitch 流量录制的运行时开关。
+type recordSwitch struct {
+	Enabled    *bool    `json:"enabled,omitempty"`
+	SampleRate *float64 `json:"sample_rate,omitempty"`
+}
+
+// RegisterRecordSwitch 注册流量录制的运行时开关端点，GET 返回当前的开关状态
+// 和采样率，PUT 提交 {"enabled":false,"sample_rate":0.1} 等 JSON 数据进行修改，
+// 省略的字段保持不变。
+func RegisterRecordSwitch(r Router) {
+	const path = "/actuator/fastdev/record"
+	get := func(ctx Context) {
+		enabled, rate := fastdev.RecordEnabled(), recordSampleRate()
+		ctx.JSON(&recordSwitch{Enabled: &enabled, SampleRate: &rate})
+	}
+	r.GetMapping(path, get)
+	r.PutMapping(path, func(ctx Context) {
+		var s recordSwitch
+		if err := json.NewDecoder(ctx.Request().Body).Decode(&s); err != nil {
+			panic(NewHttpError(http.StatusBadRequest, err.Error()))
+		}
+		if s.SampleRate != nil {
+			if *s.SampleRate < 0 || *s.SampleRate > 1 {
+				panic(NewHttpError(http.StatusBadRequest, "sample_rate should be in [0,1]"))
+			}
+			SetRecordSampleRate(*s.SampleRate)
+		}
+		if s.Enabled != nil {
+			fastdev.SetRecordEnabled(*s.Enabled)
+		}
+		get(ctx)
+	})
+}
+
+// matchRecordRule 返回请求是否匹配规则列表中的某一条规则。
+func matchRecordRule(rules []string, r *http.Request) bool {
+	for _, rule := range rules {
+		method, pattern := "*", rule
+		if ss := strings.Fields(rule); len(ss) == 2 {
+			method, pattern = ss[0], ss[1]
+		}
+		if method != "*" && !strings.EqualFold(method, r.Method) {
+			continue
+		}
+		if ok, _ := path.Match(pattern, r.URL.Path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldRecord 根据录制规则判断是否录制请求。
+func shouldRecord(r *http.Request) bool {
+
+	recordRule.mutex.RLock()
+	rule := recordRule.rule
+	recordRule.mutex.RUnlock()
+
+	if rule == nil {
+		return true
+	}
+	if matchRecordRule(rule.Exclude, r) {
+		return false
+	}
+	if len(rule.Include) > 0 && !matchRecordRule(rule.Include, r) {
+		return false
+	}
+	if rule.ForceHeader != "" && r.Header.Get(rule.ForceHeader) != "" {
+		return true
+	}
+	return recordRule.rand() < rule.SampleRate
+}
+
+// recordStartKey 存储上游请求开始录制时间使用的 Key 。
+const recordStartKey = "::record-start::"
+
+// StartRecord 启动流量录制，不满足录制规则的请求不会被录制。
 func StartRecord(ctx Context) {
-	if !fastdev.RecordMode() {
+	if !fastdev.RecordEnabled() || !shouldRecord(ctx.Request()) {
 		return
 	}
 	session := fastdev.NewSessionID()
 	err := knife.Set(ctx.Context(), fastdev.RecordSessionIDKey, session)
 	util.Panic(err).When(err != nil)
+
+	err = knife.Set(ctx.Context(), recordStartKey, util.Now(ctx.Context()))
+	util.Panic(err).When(err != nil)
+
+	// 不提前读取请求体，而是在处理函数读取请求体的同时进行录制，
+	// 避免大文件上传等流式请求被整体缓存在内存中。
+	req := ctx.Request()
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &recordBody{ReadCloser: req.Body, limit: recordMaxBodySize()}
+	}
+}
+
+// recordBody 在读取请求体的同时录制已读取的数据，超过 limit 的部分被丢弃。
+type recordBody struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (r *recordBody) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	if n > 0 {
+		r.truncated = limitWrite(&r.buf, p[:n], r.limit) || r.truncated
+	}
+	return
+}
+
+// Bytes 返回录制的请求体，被截断时以 TruncatedMarker 结尾。
+func (r *recordBody) Bytes() []byte {
+	if r.truncated {
+		return append(r.buf.Bytes(), TruncatedMarker...)
+	}
+	return r.buf.Bytes()
 }
 
 // StopRecord 停止流量录制
 func StopRecord(ctx Context) {
 
-	if !fastdev.RecordMode() {
+	if !fastdev.Recording(ctx.Context()) {
 		return
 	}
 
 	req := ctx.Request()
 	resp := ctx.ResponseWriter()
 
-	var bufReq bytes.Buffer
-	err := req.Write(&bufReq)
-	if err != nil {
-		log.Ctx(ctx.Context()).Error(err)
-		return
+	// 只录制处理函数读取过的请求体
+	var body []byte
+	if b := findRecordBody(req.Body); b != nil {
+		body = b.Bytes()
 	}
 
-	var bufResp bytes.Buffer
-
-	is11 := req.ProtoAtLeast(1, 1)
-	writeStatusLine(&bufResp, is11, resp.Status())
-	err = resp.Header().WriteSubset(&bufResp, nil)
-	if err != nil {
-		log.Ctx(ctx.Context()).Error(err)
-		return
+	// 响应体被截断时录制的长度和实际长度不一致，因此不录制 Content-Length
+	header := resp.Header().Clone()
+	if isTruncated(resp) {
+		header.Del(HeaderContentLength)
+	} else if header.Get(HeaderContentLength) == "" {
+		header.Set(HeaderContentLength, cast.ToString(resp.Size()))
 	}
 
-	if resp.Header().Get("Content-Length") == "" {
-		bufResp.WriteString("Content-Length: ")
-		bufResp.WriteString(cast.ToString(resp.Size()))
-		bufResp.WriteString("\r\n")
+	action := &fastdev.Action{
+		Protocol: fastdev.HTTP,
+		Request:  fastdev.NewHttpRequest(req, body),
+		Response: fastdev.NewHttpResponse(resp.Status(), header, []byte(resp.Body())),
 	}
 
-	bufResp.WriteString("\r\n")
-	bufResp.WriteString(resp.Body())
+	// 录制开始时间，回放时作为处理函数的当前时间
+	var start time.Time
+	if ok, _ := knife.Fetch(ctx.Context(), recordStartKey, &start); ok {
+		action.Timestamp = start.UnixNano()
+		action.Latency = int64(util.Now(ctx.Context()).Sub(start))
+	}
+	fastdev.RecordInbound(ctx.Request().Context(), action)
+}
 
-	fastdev.RecordInbound(ctx.Request().Context(), &fastdev.Action{
-		Protocol: fastdev.HTTP,
-		Request:  bufReq.String(),
-		Response: bufResp.String(),
-	})
+// isTruncated 返回缓存的响应体是否被截断，w 没有实现 Truncated 方法时返回 false 。
+func isTruncated(w ResponseWriter) bool {
+	t, ok := w.(interface{ Truncated() bool })
+	return ok && t.Truncated()
 }
 
-func writeStatusLine(buf *bytes.Buffer, is11 bool, code int) {
-	if is11 {
-		buf.WriteString("HTTP/1.1 ")
-	} else {
-		buf.WriteString("HTTP/1.0 ")
+// findRecordBody 查找被其他过滤器包装过的 recordBody 对象。
+func findRecordBody(body io.ReadCloser) *recordBody {
+	for body != nil {
+		switch b := body.(type) {
+		case *recordBody:
+			return b
+		case *limitedBody:
+			body = b.ReadCloser
+		case *transformedBody:
+			body = b.origin
+		default:
+			return nil
+		}
 	}
-	text := http.StatusText(code)
-	buf.Write(strconv.AppendInt([]byte{}, int64(code), 10))
-	buf.WriteByte(' ')
-	buf.WriteString(text)
-	buf.WriteString("\r\n")
+	return nil
 }
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+)
+
+func TestShouldRecord(t *testing.T) {
+
+	defer func() {
+		recordRule.rule = nil
+	}()
+
+	SetRecordRule(RecordRule{
+		SampleRate:  0.5,
+		Include:     []string{"/api/*", "POST /admin/*"},
+		Exclude:     []string{"GET /api/login"},
+		ForceHeader: "X-Force-Record",
+	})
+
+	var r float64
+	fn := recordRule.rand
+	recordRule.rand = func() float64 { return r }
+	defer func() { recordRule.rand = fn }()
+
+	r = 0.1
+	assert.True(t, shouldRecord(httptest.NewRequest("GET", "/api/user", nil)))
+	assert.True(t, shouldRecord(httptest.NewRequest("POST", "/admin/user", nil)))
+	assert.False(t, shouldRecord(httptest.NewRequest("GET", "/admin/user", nil)))
+	assert.False(t, shouldRecord(httptest.NewRequest("GET", "/api/login", nil)))
+	assert.False(t, shouldRecord(httptest.NewRequest("GET", "/other", nil)))
+
+	r = 0.9
+	assert.False(t, shouldRecord(httptest.NewRequest("GET", "/api/user", nil)))
+
+	req := httptest.NewRequest("GET", "/api/user", nil)
+	req.Header.Set("X-Force-Record", "1")
+	assert.True(t, shouldRecord(req))
+
+	req = httptest.NewRequest("GET", "/api/login", nil)
+	req.Header.Set("X-Force-Record", "1")
+	assert.False(t, shouldRecord(req))
+}
+
+func TestSetRecordSampleRate(t *testing.T) {
+
+	defer func() {
+		recordRule.rule = nil
+	}()
+
+	assert.Equal(t, recordSampleRate(), 1.0)
+	SetRecordSampleRate(0.2)
+	assert.Equal(t, recordSampleRate(), 0.2)
+
+	SetRecordRule(RecordRule{SampleRate: 1, Exclude: []string{"/health"}})
+	SetRecordSampleRate(0.5)
+	assert.Equal(t, *recordRule.rule, RecordRule{SampleRate: 0.5, Exclude: []string{"/health"}})
+}
+
+func TestRecordTruncate(t *testing.T) {
+
+	defer func() {
+		recordRule.rule = nil
+	}()
+
+	SetRecordRule(RecordRule{SampleRate: 1, MaxBodySize: 8})
+
+	t.Run("response", func(t *testing.T) {
+		w := &BufferedResponseWriter{ResponseWriter: httptest.NewRecorder()}
+		w.Header().Set(HeaderContentType, MIMETextEventStream)
+		for i := 0; i < 3; i++ {
+			_, err := w.Write([]byte("data:1\n\n"))
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, w.Size(), 24)
+		assert.True(t, w.Truncated())
+		assert.Equal(t, w.Body(), "data:1\n\n"+TruncatedMarker)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		r := &recordBody{ReadCloser: ioutil.NopCloser(strings.NewReader("hello world")), limit: 8}
+		b, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, string(b), "hello world")
+		assert.Equal(t, string(r.Bytes()), "hello wo"+TruncatedMarker)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		recordRule.rule = nil
+		w := &BufferedResponseWriter{ResponseWriter: httptest.NewRecorder()}
+		w.Header().Set(HeaderContentType, MIMEApplicationJSON)
+		_, err := w.Write([]byte(`{"a":"hello world"}`))
+		assert.Nil(t, err)
+		assert.False(t, w.Truncated())
+		assert.Equal(t, w.Body(), `{"a":"hello world"}`)
+	})
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+// DefaultMaxStackSize 默认捕获的调用栈的最大字节数。
+const DefaultMaxStackSize = 4 << 10
+
+// ReportedTag panic 恢复日志使用的标签，panic 已经通知 PanicReporter ，因此
+// report.LogOutput 不会再次上报带有该标签的日志。
+const ReportedTag = "_report"
+
+// PanicInfo 处理函数 panic 时的现场信息。
+type PanicInfo struct {
+	Value     interface{} // panic 抛出的值
+	Stack     []byte      // 发生 panic 的 goroutine 的调用栈，超过最大长度时被截断
+	RequestID string      // RequestIDFilter 设置的请求 ID
+	Method    string      // 请求方法
+	Path      string      // 请求路径
+	Time      time.Time   // 发生 panic 的时间
+}
+
+// PanicReporter 处理函数 panic 时的告警接口，注册为 bean 后可以接入告警系统或者
+// Sentry 等错误追踪服务。ReportPanic 在处理请求的 goroutine 中同步执行，耗时的操作
+// 应该异步完成。
+type PanicReporter interface {
+	ReportPanic(ctx context.Context, info *PanicInfo)
+}
+
+// recoveryFilter 将处理函数的 panic 转换为错误响应的过滤器。
+type recoveryFilter struct {
+	maxStackSize int
+	reporters    []PanicReporter
+}
+
+// RecoveryFilter 返回 panic 恢复过滤器，处理函数 panic 时通过 ErrorHandler 返回
+// 错误响应，*HttpError 类型的 panic 使用其状态码，其他的 panic 返回 500 错误。服务
+// 端错误 (状态码大于等于 500) 会捕获最多 maxStackSize 字节的调用栈，和请求 ID 一起
+// 输出到日志并通知 reporters 。maxStackSize 小于等于 0 时使用 DefaultMaxStackSize 。
+// http.ErrAbortHandler 会被继续抛出，以便服务器中断连接。
+func RecoveryFilter(maxStackSize int, reporters ...PanicReporter) Filter {
+	if maxStackSize <= 0 {
+		maxStackSize = DefaultMaxStackSize
+	}
+	return &recoveryFilter{maxStackSize: maxStackSize, reporters: reporters}
+}
+
+func (f *recoveryFilter) Invoke(ctx Context, chain FilterChain) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if r == http.ErrAbortHandler {
+			panic(r)
+		}
+		httpE := ToHttpError(r)
+		if httpE.Code >= http.StatusInternalServerError {
+			f.report(ctx, r)
+		}
+		ctx.Status(httpE.Code)
+		ErrorHandler(ctx, httpE)
+	}()
+	chain.Next(ctx)
+}
+
+// report 输出 panic 的现场信息并通知 reporters 。
+func (f *recoveryFilter) report(ctx Context, r interface{}) {
+
+	stack := make([]byte, f.maxStackSize)
+	stack = stack[:runtime.Stack(stack, false)]
+
+	c := ctx.Context()
+	info := &PanicInfo{
+		Value:     r,
+		Stack:     stack,
+		RequestID: log.GetRequestID(c),
+		Method:    ctx.Request().Method,
+		Path:      ctx.Request().URL.Path,
+		Time:      time.Now(),
+	}
+
+	log.Ctx(c).Tag(ReportedTag).Errorf("panic recovered: %v %s %v\n%s", info.Method, info.Path, r, stack)
+
+	for _, reporter := range f.reporters {
+		func() {
+			defer func() {
+				if e := recover(); e != nil {
+					log.Ctx(c).Errorf("report panic error: %v", e)
+				}
+			}()
+			reporter.ReportPanic(c, info)
+		}()
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+type panicReporter struct {
+	infos []*web.PanicInfo
+}
+
+func (r *panicReporter) ReportPanic(ctx context.Context, info *web.PanicInfo) {
+	r.infos = append(r.infos, info)
+}
+
+type brokenReporter struct{}
+
+func (r *brokenReporter) ReportPanic(ctx context.Context, info *web.PanicInfo) {
+	panic("reporter is broken")
+}
+
+func TestRecoveryFilter(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/panic", func(ctx web.Context) { panic(errors.New("boom")) })
+	r.GetMapping("/bad", func(ctx web.Context) { panic(web.NewHttpError(http.StatusBadRequest)) })
+	r.GetMapping("/ok", func(ctx web.Context) { ctx.String("ok") })
+
+	reporter := new(panicReporter)
+	recovery := web.RecoveryFilter(64, new(brokenReporter), reporter)
+	h := web.ToHTTPHandler(r, web.RequestIDFilter(""), recovery)
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(web.HeaderXRequestID, "req-1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/panic")
+	assert.Equal(t, w.Code, http.StatusInternalServerError)
+	assert.Equal(t, len(reporter.infos), 1)
+	info := reporter.infos[0]
+	assert.Error(t, info.Value.(error), "boom")
+	assert.Equal(t, info.RequestID, "req-1")
+	assert.Equal(t, info.Method, http.MethodGet)
+	assert.Equal(t, info.Path, "/panic")
+	assert.True(t, len(info.Stack) <= 64)
+	assert.True(t, strings.HasPrefix(string(info.Stack), "goroutine "))
+
+	w = serve("/bad")
+	assert.Equal(t, w.Code, http.StatusBadRequest)
+	assert.Equal(t, len(reporter.infos), 1)
+
+	w = serve("/ok")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), "ok")
+	assert.Equal(t, len(reporter.infos), 1)
+}
@@ -17,6 +17,15 @@
 package web
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+
 	"github.com/go-spring/spring-base/fastdev"
 	"github.com/go-spring/spring-base/knife"
 	"github.com/go-spring/spring-base/util"
@@ -25,7 +34,8 @@ import (
 // ReplaySessionID 流量回放模式下传递会话 ID 使用的 Header 。
 const ReplaySessionID = "REPLAY-SESSION-ID"
 
-// StartReplay 开始流量回放
+// StartReplay 开始流量回放，处理函数通过 util.Now(ctx.Context()) 得到的当前
+// 时间从上游请求的录制时间开始计时。
 func StartReplay(ctx Context) {
 	session := ctx.GetHeader(ReplaySessionID)
 	if session == "" {
@@ -33,9 +43,151 @@ func StartReplay(ctx Context) {
 	}
 	err := knife.Set(ctx.Context(), fastdev.ReplaySessionIDKey, session)
 	util.Panic(err).When(err != nil)
+	// 使用录制时间作为当前时间，保证 util.Now 的结果和录制时一致
+	req := ctx.Request()
+	ctx.SetRequest(req.WithContext(fastdev.MockReplayTime(req.Context())))
 }
 
 // StopReplay 停止流量回放
 func StopReplay(ctx Context) {
 
 }
+
+// ReplayIgnoreHeaders 比较回放结果时忽略的响应头。
+var ReplayIgnoreHeaders = []string{"Date", "Content-Length"}
+
+// ReplayDiffOptions 比较 JSON 格式的响应体时使用的选项，路径相对于响应体的
+// 根节点，例如忽略 "data.timestamp"、"request_id" 等易变字段。
+var ReplayDiffOptions fastdev.DiffOptions
+
+// ReplayResult 流量回放的结果。
+type ReplayResult struct {
+	Session string   `json:"session"`           // 会话 ID
+	Status  int      `json:"status"`            // 实际的响应状态码
+	Body    string   `json:"body"`              // 实际的响应体
+	Diffs   []string `json:"diffs,omitempty"`   // 实际响应和录制响应的差异
+	Missing []string `json:"missing,omitempty"` // 没有被回放的下游请求
+
+	// Mismatches 响应体的逐项差异，路径以 body 开头，便于程序处理。
+	Mismatches []fastdev.Mismatch `json:"mismatches,omitempty"`
+}
+
+// Match 返回实际响应和录制响应是否一致，并且所有下游请求都被回放。
+func (r *ReplayResult) Match() bool {
+	return len(r.Diffs) == 0 && len(r.Missing) == 0
+}
+
+// Replay 将录制的上游请求交给 h 处理，处理过程中的下游请求使用录制的响应，
+// 最后比较实际响应和录制响应的差异。h 通常是 Web 容器，需要打开回放模式。
+func Replay(h http.Handler, session *fastdev.Session) (*ReplayResult, error) {
+
+	if !fastdev.ReplayMode() {
+		return nil, errors.New("replay mode not enabled")
+	}
+
+	if session.Inbound == nil || session.Inbound.Protocol != fastdev.HTTP {
+		return nil, errors.New("inbound isn't http")
+	}
+
+	inReq, err := fastdev.ToHttpRequest(session.Inbound.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	inResp, err := fastdev.ToHttpResponse(session.Inbound.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := inReq.Request()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(ReplaySessionID, session.Session)
+
+	fastdev.Store(session)
+	defer fastdev.Delete(session.Session)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	ret := &ReplayResult{
+		Session: session.Session,
+		Status:  w.Code,
+		Body:    w.Body.String(),
+	}
+
+	for _, action := range fastdev.UnmatchedActions(session.Session) {
+		ret.Missing = append(ret.Missing, fmt.Sprintf("%s %v", action.Protocol, action.Request))
+	}
+
+	ret.Diffs, ret.Mismatches = diffResponse(inResp.Status, http.Header(inResp.Header), []byte(inResp.Body),
+		w.Code, w.Result().Header, w.Body.Bytes())
+	return ret, nil
+}
+
+// diffResponse 比较录制响应和实际响应，JSON 格式的响应体按照语义进行比较。
+func diffResponse(expectStatus int, expectHeader http.Header, expectBody []byte,
+	actualStatus int, actualHeader http.Header, actualBody []byte) ([]string, []fastdev.Mismatch) {
+
+	var diffs, headerDiffs []string
+	if expectStatus != actualStatus {
+		diffs = append(diffs, fmt.Sprintf("status: expect %d but got %d", expectStatus, actualStatus))
+	}
+
+	for k, v := range expectHeader {
+		if ignoreReplayHeader(k) {
+			continue
+		}
+		if got := actualHeader[k]; !reflect.DeepEqual(got, v) {
+			headerDiffs = append(headerDiffs, fmt.Sprintf("header %s: expect %q but got %q", k, v, got))
+		}
+	}
+	for k, v := range actualHeader {
+		if _, ok := expectHeader[k]; !ok && !ignoreReplayHeader(k) {
+			headerDiffs = append(headerDiffs, fmt.Sprintf("header %s: unexpected %q", k, v))
+		}
+	}
+	sort.Strings(headerDiffs)
+	diffs = append(diffs, headerDiffs...)
+
+	mismatches := diffBody(expectBody, actualBody)
+	if len(mismatches) > 0 {
+		diffs = append(diffs, fmt.Sprintf("body: expect %q but got %q", expectBody, actualBody))
+	}
+	return diffs, mismatches
+}
+
+func ignoreReplayHeader(k string) bool {
+	for _, s := range ReplayIgnoreHeaders {
+		if strings.EqualFold(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffBody 使用 ReplayDiffOptions 比较 JSON 格式的响应体，其他格式的响应体
+// 按照字节进行比较。
+func diffBody(expect, actual []byte) []fastdev.Mismatch {
+	if bytes.Equal(expect, actual) {
+		return nil
+	}
+	mismatches, err := fastdev.DiffJSON(expect, actual, ReplayDiffOptions)
+	if err != nil {
+		return []fastdev.Mismatch{{
+			Path:   "body",
+			Kind:   fastdev.MismatchChanged,
+			Expect: string(expect),
+			Actual: string(actual),
+		}}
+	}
+	for i := range mismatches {
+		if mismatches[i].Path == "" {
+			mismatches[i].Path = "body"
+		} else {
+			mismatches[i].Path = "body." + mismatches[i].Path
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestReplay(t *testing.T) {
+
+	fastdev.SetReplayMode(true, false)
+	defer fastdev.SetReplayMode(false, false)
+
+	session := &fastdev.Session{
+		Session: fastdev.NewSessionID(),
+		Inbound: &fastdev.Action{
+			Protocol: fastdev.HTTP,
+			Request:  "GET /users/1 HTTP/1.1\r\nHost: localhost\r\n\r\n",
+			Response: "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 19\r\n\r\n{\"id\":1,\"name\":\"a\"}",
+		},
+		Actions: []*fastdev.Action{
+			{Protocol: fastdev.REDIS, Request: "GET user:1", Response: "a"},
+		},
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get(web.ReplaySessionID), session.Session)
+		w.Header().Set(web.HeaderContentType, web.MIMEApplicationJSON)
+		_, _ = w.Write([]byte(`{"name":"a", "id":1}`))
+	})
+
+	ret, err := web.Replay(h, session)
+	assert.Nil(t, err)
+	assert.Equal(t, ret.Status, http.StatusOK)
+	assert.Equal(t, len(ret.Diffs), 0)
+	assert.Equal(t, ret.Missing, []string{"redis GET user:1"})
+	assert.False(t, ret.Match())
+
+	h = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	ret, err = web.Replay(h, session)
+	assert.Nil(t, err)
+	assert.Equal(t, ret.Diffs, []string{
+		"status: expect 200 but got 500",
+		`header Content-Type: expect ["application/json"] but got []`,
+		`body: expect "{\"id\":1,\"name\":\"a\"}" but got ""`,
+	})
+}
+
+func TestReplay_Structured(t *testing.T) {
+
+	fastdev.SetReplayMode(true, false)
+	defer fastdev.SetReplayMode(false, false)
+
+	data := `{
+		"session": "` + fastdev.NewSessionID() + `",
+		"inbound": {
+			"protocol": "http",
+			"request": {"method": "POST", "url": "/users?debug=1", "host": "localhost", "body": "{\"name\":\"a\"}"},
+			"response": {"status": 201, "header": {"Content-Type": ["application/json"]}, "body": "{\"id\":1}"}
+		}
+	}`
+	session, err := fastdev.ToSession([]byte(data), false)
+	assert.Nil(t, err)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, string(b), `{"name":"a"}`)
+		assert.Equal(t, r.URL.Query().Get("debug"), "1")
+		w.Header().Set(web.HeaderContentType, web.MIMEApplicationJSON)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	})
+
+	ret, err := web.Replay(h, session)
+	assert.Nil(t, err)
+	assert.True(t, ret.Match())
+}
+
+func TestReplay_DiffOptions(t *testing.T) {
+
+	fastdev.SetReplayMode(true, false)
+	defer fastdev.SetReplayMode(false, false)
+
+	web.ReplayDiffOptions = fastdev.DiffOptions{IgnorePaths: []string{"request_id"}}
+	defer func() { web.ReplayDiffOptions = fastdev.DiffOptions{} }()
+
+	session := &fastdev.Session{
+		Session: fastdev.NewSessionID(),
+		Inbound: &fastdev.Action{
+			Protocol: fastdev.HTTP,
+			Request:  &fastdev.HttpRequest{Method: "GET", URL: "/users/1"},
+			Response: &fastdev.HttpResponse{
+				Status: http.StatusOK,
+				Header: map[string][]string{"Content-Type": {"application/json"}},
+				Body:   `{"id":1,"name":"a","request_id":"x"}`,
+			},
+		},
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(web.HeaderContentType, web.MIMEApplicationJSON)
+		_, _ = w.Write([]byte(`{"id":1,"name":"b","request_id":"y"}`))
+	})
+
+	ret, err := web.Replay(h, session)
+	assert.Nil(t, err)
+	assert.Equal(t, ret.Mismatches, []fastdev.Mismatch{
+		{Path: "body.name", Kind: fastdev.MismatchChanged, Expect: "a", Actual: "b"},
+	})
+	assert.False(t, ret.Match())
+}
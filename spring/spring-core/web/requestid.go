@@ -0,0 +1,78 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+// maxRequestIDLength 请求 ID 的最大长度，超过时重新生成，避免日志被注入超长内容。
+const maxRequestIDLength = 128
+
+type requestIDFilter struct {
+	header string
+}
+
+// RequestIDFilter 返回请求 ID 过滤器，从 header 请求头读取请求 ID ，没有时生成
+// 新的请求 ID ，并设置到响应头上。请求 ID 和 traceparent 请求头中的链路追踪 ID
+// 保存到请求的 context.Context 对象的 MDC 中，使用 log.Ctx(ctx.Context()) 输出
+// 的日志会自动带上这些字段。header 为空时使用 X-Request-Id 。
+func RequestIDFilter(header string) Filter {
+	if header == "" {
+		header = HeaderXRequestID
+	}
+	return &requestIDFilter{header: header}
+}
+
+func (f *requestIDFilter) Invoke(ctx Context, chain FilterChain) {
+	r := ctx.Request()
+	requestID, c := f.withMDC(r)
+	ctx.Header(f.header, requestID)
+	ctx.SetRequest(r.WithContext(c))
+	chain.Next(ctx)
+}
+
+// withMDC 返回请求 ID 以及在 MDC 中保存了请求 ID 和链路追踪 ID 的 context 。
+func (f *requestIDFilter) withMDC(r *http.Request) (string, context.Context) {
+	requestID := r.Header.Get(f.header)
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		requestID = newSessionID()
+	}
+	c := log.WithRequestID(r.Context(), requestID)
+	if traceID := parseTraceparent(r.Header.Get(HeaderTraceparent)); traceID != "" {
+		c = log.WithTraceID(c, traceID)
+	}
+	return requestID, c
+}
+
+// parseTraceparent 从 W3C Trace Context 格式的 traceparent 请求头中解析出链路
+// 追踪 ID ，格式为 "version-traceid-parentid-flags"，格式错误时返回空字符串。
+func parseTraceparent(s string) string {
+	ss := strings.Split(strings.TrimSpace(s), "-")
+	if len(ss) < 4 || len(ss[1]) != 32 {
+		return ""
+	}
+	traceID := strings.ToLower(ss[1])
+	if strings.Trim(traceID, "0123456789abcdef") != "" || strings.Trim(traceID, "0") == "" {
+		return ""
+	}
+	return traceID
+}
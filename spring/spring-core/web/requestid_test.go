@@ -0,0 +1,46 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+)
+
+func TestRequestIDFilter(t *testing.T) {
+
+	f := RequestIDFilter("").(*requestIDFilter)
+	assert.Equal(t, f.header, HeaderXRequestID)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(HeaderXRequestID, "abc")
+	r.Header.Set(HeaderTraceparent, "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")
+	id, ctx := f.withMDC(r)
+	assert.Equal(t, id, "abc")
+	assert.Equal(t, log.GetRequestID(ctx), "abc")
+	assert.Equal(t, log.GetTraceID(ctx), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(HeaderTraceparent, "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	id, ctx = f.withMDC(r)
+	assert.Equal(t, len(id), 32)
+	assert.Equal(t, log.GetRequestID(ctx), id)
+	assert.Equal(t, log.GetTraceID(ctx), "")
+}
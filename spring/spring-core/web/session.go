@@ -0,0 +1,466 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-base/util"
+	"github.com/go-spring/spring-core/redis"
+)
+
+// DefaultSessionMaxAge 会话默认的有效期。
+const DefaultSessionMaxAge = 30 * time.Minute
+
+var ErrInvalidSession = errors.New("invalid session")
+
+// SessionStore 会话数据的存储。cookie 中保存的值由存储决定，可以是会话 ID
+// 也可以是会话数据本身。
+type SessionStore interface {
+
+	// Load 根据 cookie 的值加载会话，会话不存在或者已过期时返回空的 id 。
+	Load(ctx context.Context, value string) (id string, values map[string]interface{}, err error)
+
+	// Save 保存会话数据，返回需要写入 cookie 的值。
+	Save(ctx context.Context, id string, values map[string]interface{}, maxAge time.Duration) (value string, err error)
+
+	// Delete 删除会话数据。
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionOptions 会话 cookie 的选项。
+type SessionOptions struct {
+	Name     string        // cookie 名称
+	Path     string        // cookie 路径
+	Domain   string        // cookie 域名
+	MaxAge   time.Duration // 会话有效期，小于等于 0 时使用 DefaultSessionMaxAge
+	Secure   bool          // 是否只在 https 下发送
+	HttpOnly bool          // 是否禁止脚本访问
+	SameSite http.SameSite // 跨站策略
+	Rolling  bool          // 是否每次请求都为会话续期
+}
+
+// DefaultSessionOptions 返回默认的会话选项。
+func DefaultSessionOptions() SessionOptions {
+	return SessionOptions{
+		Name:     "SESSION",
+		Path:     "/",
+		MaxAge:   DefaultSessionMaxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Session 一次请求对应的会话，修改会话数据后需要在写入响应之前调用 Save 方法。
+type Session struct {
+	ctx     Context
+	store   SessionStore
+	opts    *SessionOptions
+	id      string
+	values  map[string]interface{}
+	isNew   bool
+	invalid bool
+}
+
+// ID 返回会话 ID 。
+func (s *Session) ID() string {
+	return s.id
+}
+
+// IsNew 返回会话是否在本次请求中新建。
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Get 返回 key 对应的会话数据。
+func (s *Session) Get(key string) interface{} {
+	return s.values[key]
+}
+
+// Set 设置 key 对应的会话数据。
+func (s *Session) Set(key string, val interface{}) {
+	s.values[key] = val
+}
+
+// Delete 删除 key 对应的会话数据。
+func (s *Session) Delete(key string) {
+	delete(s.values, key)
+}
+
+// Invalidate 使会话失效，删除存储中的会话数据并清除客户端的 cookie 。
+func (s *Session) Invalidate() error {
+	s.invalid = true
+	s.values = make(map[string]interface{})
+	if err := s.store.Delete(s.ctx.Context(), s.id); err != nil {
+		return err
+	}
+	s.ctx.SetCookie(s.cookie("", -1))
+	return nil
+}
+
+// Regenerate 为会话生成新的 ID 并保留会话数据，删除存储中旧 ID 对应的数据，然后
+// 保存会话。登录等权限变化之后应该调用该方法，防止会话固定攻击。
+func (s *Session) Regenerate() error {
+	if s.invalid {
+		return ErrInvalidSession
+	}
+	if err := s.store.Delete(s.ctx.Context(), s.id); err != nil {
+		return err
+	}
+	s.id = newSessionID()
+	return s.Save()
+}
+
+// Save 保存会话数据，同时刷新客户端 cookie 的有效期。
+func (s *Session) Save() error {
+	if s.invalid {
+		return ErrInvalidSession
+	}
+	maxAge := s.opts.MaxAge
+	value, err := s.store.Save(s.ctx.Context(), s.id, s.values, maxAge)
+	if err != nil {
+		return err
+	}
+	s.ctx.SetCookie(s.cookie(value, int(maxAge/time.Second)))
+	return nil
+}
+
+func (s *Session) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.opts.Name,
+		Value:    value,
+		Path:     s.opts.Path,
+		Domain:   s.opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.opts.Secure,
+		HttpOnly: s.opts.HttpOnly,
+		SameSite: s.opts.SameSite,
+	}
+}
+
+const sessionKey = "::session::"
+
+// GetSession 返回当前请求的会话，需要先配置 SessionFilter 过滤器，也可以使用
+// Context 的 Session 方法。
+func GetSession(ctx Context) (*Session, error) {
+	var s *Session
+	ok, err := knife.Fetch(ctx.Context(), sessionKey, &s)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("session filter not configured")
+	}
+	return s, nil
+}
+
+// sessionFilter 为请求加载会话的过滤器。
+type sessionFilter struct {
+	store SessionStore
+	opts  SessionOptions
+}
+
+// SessionFilter 返回为请求加载会话的过滤器，会话通过 Context 的 Session 方法或者
+// GetSession 获取。
+func SessionFilter(store SessionStore, opts SessionOptions) Filter {
+	if opts.Name == "" {
+		opts.Name = "SESSION"
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = DefaultSessionMaxAge
+	}
+	return &sessionFilter{store: store, opts: opts}
+}
+
+func (f *sessionFilter) Invoke(ctx Context, chain FilterChain) {
+
+	s := &Session{ctx: ctx, store: f.store, opts: &f.opts}
+
+	if cookie, err := ctx.Cookie(f.opts.Name); err == nil && cookie.Value != "" {
+		id, values, err := f.store.Load(ctx.Context(), cookie.Value)
+		if err != nil {
+			log.Ctx(ctx.Context()).Warnf("load session error: %v", err)
+		} else if id != "" {
+			s.id, s.values = id, values
+		}
+	}
+
+	if s.id == "" {
+		s.id = newSessionID()
+		s.values = make(map[string]interface{})
+		s.isNew = true
+	}
+
+	if err := knife.Set(ctx.Context(), sessionKey, s); err != nil {
+		panic(err)
+	}
+
+	if f.opts.Rolling && !s.isNew {
+		if err := s.Save(); err != nil {
+			panic(err)
+		}
+	}
+
+	chain.Next(ctx)
+}
+
+// newSessionID 生成随机的会话 ID 。
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// memorySession 内存中保存的会话数据。
+type memorySession struct {
+	values   map[string]interface{}
+	expireAt time.Time
+}
+
+// memorySessionSweepInterval 清理过期会话的间隔。
+const memorySessionSweepInterval = time.Minute
+
+// MemorySessionStore 将会话数据保存在内存中，适用于单实例部署。过期的会话在读取
+// 时删除，不再被读取的过期会话由后台协程定期清理，不再使用时需要调用 Close 方法
+// 停止后台协程。
+type MemorySessionStore struct {
+	mutex    sync.Mutex
+	sessions map[string]*memorySession
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewMemorySessionStore 返回新的 MemorySessionStore 对象。
+func NewMemorySessionStore() *MemorySessionStore {
+	m := &MemorySessionStore{
+		sessions: make(map[string]*memorySession),
+		stop:     make(chan struct{}),
+	}
+	go m.sweep(memorySessionSweepInterval)
+	return m
+}
+
+// sweep 每隔 interval 清理一次过期的会话，直到调用 Close 方法。
+func (m *MemorySessionStore) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.deleteExpired(now)
+		}
+	}
+}
+
+// deleteExpired 删除在 now 时已经过期的会话。
+func (m *MemorySessionStore) deleteExpired(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for k, s := range m.sessions {
+		if !now.Before(s.expireAt) {
+			delete(m.sessions, k)
+		}
+	}
+}
+
+// Len 返回保存的会话数量，包括已经过期但是还没有被清理的会话。
+func (m *MemorySessionStore) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.sessions)
+}
+
+// Close 停止清理过期会话的后台协程。
+func (m *MemorySessionStore) Close() error {
+	m.once.Do(func() { close(m.stop) })
+	return nil
+}
+
+func (m *MemorySessionStore) Load(ctx context.Context, value string) (string, map[string]interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s, ok := m.sessions[value]
+	if !ok {
+		return "", nil, nil
+	}
+	if !util.Now(ctx).Before(s.expireAt) {
+		delete(m.sessions, value)
+		return "", nil, nil
+	}
+	return value, copySessionValues(s.values), nil
+}
+
+func (m *MemorySessionStore) Save(ctx context.Context, id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	expireAt := util.Now(ctx).Add(maxAge)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sessions[id] = &memorySession{values: copySessionValues(values), expireAt: expireAt}
+	return id, nil
+}
+
+func (m *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func copySessionValues(values map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		m[k] = v
+	}
+	return m
+}
+
+// cookieSession cookie 中保存的会话数据。
+type cookieSession struct {
+	ID       string                 `json:"id"`
+	Values   map[string]interface{} `json:"values"`
+	ExpireAt int64                  `json:"expire_at"`
+}
+
+// CookieSessionStore 将会话数据签名后保存在 cookie 中，数据经过 JSON
+// 序列化，因此读取到的数据是 JSON 解码后的类型，而且数据没有加密。
+type CookieSessionStore struct {
+	secret []byte
+}
+
+// NewCookieSessionStore 返回新的 CookieSessionStore 对象，secret 是签名密钥。
+func NewCookieSessionStore(secret []byte) *CookieSessionStore {
+	if len(secret) == 0 {
+		panic(errors.New("secret can't be empty"))
+	}
+	return &CookieSessionStore{secret: secret}
+}
+
+func (c *CookieSessionStore) sign(data string) string {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (c *CookieSessionStore) Load(ctx context.Context, value string) (string, map[string]interface{}, error) {
+
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 {
+		return "", nil, ErrInvalidSession
+	}
+
+	data, sig := value[:i], value[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(c.sign(data))) {
+		return "", nil, ErrInvalidSession
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var s cookieSession
+	if err = json.Unmarshal(b, &s); err != nil {
+		return "", nil, err
+	}
+
+	if util.Now(ctx).Unix() >= s.ExpireAt {
+		return "", nil, nil
+	}
+	if s.Values == nil {
+		s.Values = make(map[string]interface{})
+	}
+	return s.ID, s.Values, nil
+}
+
+func (c *CookieSessionStore) Save(ctx context.Context, id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	s := &cookieSession{
+		ID:       id,
+		Values:   values,
+		ExpireAt: util.Now(ctx).Add(maxAge).Unix(),
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	data := base64.RawURLEncoding.EncodeToString(b)
+	return data + "." + c.sign(data), nil
+}
+
+func (c *CookieSessionStore) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// RedisSessionStore 将会话数据以 JSON 格式保存在 Redis 中，适用于多实例部署。
+type RedisSessionStore struct {
+	client redis.Client
+	prefix string
+}
+
+// NewRedisSessionStore 返回新的 RedisSessionStore 对象，prefix 是键的前缀。
+func NewRedisSessionStore(client redis.Client, prefix string) *RedisSessionStore {
+	if prefix == "" {
+		prefix = "session:"
+	}
+	return &RedisSessionStore{client: client, prefix: prefix}
+}
+
+func (r *RedisSessionStore) Load(ctx context.Context, value string) (string, map[string]interface{}, error) {
+	s, err := r.client.Get(ctx, r.prefix+value)
+	if err == redis.ErrNil {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	values := make(map[string]interface{})
+	if err = json.Unmarshal([]byte(s), &values); err != nil {
+		return "", nil, err
+	}
+	return value, values, nil
+}
+
+func (r *RedisSessionStore) Save(ctx context.Context, id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	if _, err = r.client.SetEX(ctx, r.prefix+id, string(b), int64(maxAge/time.Second)); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *RedisSessionStore) Delete(ctx context.Context, id string) error {
+	_, err := r.client.Del(ctx, r.prefix+id)
+	return err
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/util"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestMemorySessionStore(t *testing.T) {
+
+	ctx := context.Background()
+	store := web.NewMemorySessionStore()
+	defer store.Close()
+
+	value, err := store.Save(ctx, "abc", map[string]interface{}{"user": "jim"}, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, value, "abc")
+
+	id, values, err := store.Load(ctx, value)
+	assert.Nil(t, err)
+	assert.Equal(t, id, "abc")
+	assert.Equal(t, values, map[string]interface{}{"user": "jim"})
+
+	expired := util.MockNow(ctx, time.Now().Add(2*time.Minute))
+	id, _, err = store.Load(expired, value)
+	assert.Nil(t, err)
+	assert.Equal(t, id, "")
+	assert.Equal(t, store.Len(), 0)
+
+	_, err = store.Save(ctx, "abc", map[string]interface{}{}, time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Delete(ctx, "abc"))
+	id, _, err = store.Load(ctx, "abc")
+	assert.Nil(t, err)
+	assert.Equal(t, id, "")
+}
+
+func TestCookieSessionStore(t *testing.T) {
+
+	ctx := context.Background()
+	store := web.NewCookieSessionStore([]byte("secret"))
+
+	value, err := store.Save(ctx, "abc", map[string]interface{}{"user": "jim"}, time.Minute)
+	assert.Nil(t, err)
+
+	id, values, err := store.Load(ctx, value)
+	assert.Nil(t, err)
+	assert.Equal(t, id, "abc")
+	assert.Equal(t, values, map[string]interface{}{"user": "jim"})
+
+	_, _, err = store.Load(ctx, value+"x")
+	assert.Equal(t, err, web.ErrInvalidSession)
+
+	_, _, err = web.NewCookieSessionStore([]byte("other")).Load(ctx, value)
+	assert.Equal(t, err, web.ErrInvalidSession)
+
+	expired := util.MockNow(ctx, time.Now().Add(2*time.Minute))
+	id, _, err = store.Load(expired, value)
+	assert.Nil(t, err)
+	assert.Equal(t, id, "")
+}
+
+func TestSession_Regenerate(t *testing.T) {
+
+	store := web.NewMemorySessionStore()
+	defer store.Close()
+
+	r := web.NewRouter()
+	r.GetMapping("/visit", func(ctx web.Context) {
+		s, err := ctx.Session()
+		assert.Nil(t, err)
+		s.Set("cart", "apple")
+		assert.Nil(t, s.Save())
+	})
+	r.GetMapping("/login", func(ctx web.Context) {
+		s, err := ctx.Session()
+		assert.Nil(t, err)
+		s.Set("user", "jim")
+		assert.Nil(t, s.Regenerate())
+	})
+	h := web.ToHTTPHandler(r, web.SessionFilter(store, web.SessionOptions{}))
+
+	get := func(path string, cookie *http.Cookie) *http.Cookie {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, http.StatusOK)
+		return w.Result().Cookies()[0]
+	}
+
+	before := get("/visit", nil)
+	after := get("/login", before)
+	assert.True(t, after.Value != before.Value)
+
+	ctx := context.Background()
+	id, _, err := store.Load(ctx, before.Value)
+	assert.Nil(t, err)
+	assert.Equal(t, id, "")
+
+	id, values, err := store.Load(ctx, after.Value)
+	assert.Nil(t, err)
+	assert.Equal(t, id, after.Value)
+	assert.Equal(t, values, map[string]interface{}{"cart": "apple", "user": "jim"})
+}
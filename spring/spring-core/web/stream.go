@@ -0,0 +1,66 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NDJSONWriter 以 NDJSON 格式逐行写入 JSON 对象，每写入一个对象就发送给客户端。
+type NDJSONWriter struct {
+	w   http.ResponseWriter
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter 返回 NDJSON 格式的写入器，并设置响应的 Content-Type 。
+func NewNDJSONWriter(w http.ResponseWriter) *NDJSONWriter {
+	w.Header().Set(HeaderContentType, MIMEApplicationNDJSON)
+	return &NDJSONWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+// Write 写入一个 JSON 对象，json.Encoder 会在对象后面追加换行符。
+func (w *NDJSONWriter) Write(i interface{}) error {
+	if err := w.enc.Encode(i); err != nil {
+		return err
+	}
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// JSONStream 以 NDJSON 格式逐个写入 ch 中的对象，直到 ch 被关闭。只有上一个对象
+// 发送完成后才会从 ch 读取下一个对象，因此客户端读取缓慢时生产者会被阻塞。客户端
+// 断开连接时返回请求上下文的错误，生产者应该同时监听请求上下文以便及时退出。
+func JSONStream(ctx Context, ch <-chan interface{}) error {
+	w := NewNDJSONWriter(ctx.ResponseWriter())
+	done := ctx.Context().Done()
+	for {
+		select {
+		case <-done:
+			return ctx.Context().Err()
+		case i, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := w.Write(i); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	r := httptest.NewRecorder()
+	w := web.NewNDJSONWriter(r)
+	assert.Nil(t, w.Write(map[string]int{"a": 1}))
+	assert.Nil(t, w.Write([]int{1, 2}))
+	assert.Equal(t, r.Header().Get(web.HeaderContentType), web.MIMEApplicationNDJSON)
+	assert.Equal(t, r.Body.String(), "{\"a\":1}\n[1,2]\n")
+	assert.True(t, r.Flushed)
+}
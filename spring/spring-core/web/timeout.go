@@ -0,0 +1,74 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+// timeoutFilter 为请求设置超时时间的过滤器。
+type timeoutFilter struct {
+	timeout time.Duration
+}
+
+// TimeoutFilter 返回为请求设置超时时间的过滤器，超时时间通过 ctx.Context()
+// 传递给下游，下游应该在 context 取消后尽快返回。
+//
+// 超时取消是协作式的：过滤器不会中断处理函数，忽略 ctx.Context() 的处理函数会
+// 一直执行到结束。处理函数返回时已经超时并且没有写入响应时返回 504 错误，处理
+// 函数因为 context 取消而 panic 时，context.DeadlineExceeded 返回 504 错误，
+// context.Canceled 返回 503 错误。已经写入部分响应的请求无法再修改状态码，只会
+// 输出一条警告日志。
+func TimeoutFilter(timeout time.Duration) Filter {
+	return &timeoutFilter{timeout: timeout}
+}
+
+func (f *timeoutFilter) Invoke(ctx Context, chain FilterChain) {
+
+	if f.timeout <= 0 {
+		chain.Next(ctx)
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx.Context(), f.timeout)
+	defer cancel()
+
+	ctx.SetRequest(ctx.Request().WithContext(c))
+	chain.Next(ctx)
+
+	if c.Err() == context.DeadlineExceeded {
+		w := ctx.ResponseWriter()
+		if !isWritten(w) {
+			panic(NewHttpError(http.StatusGatewayTimeout))
+		}
+		r := ctx.Request()
+		log.Ctx(c).Warnf("%s %s timeout after %s, response already written", r.Method, r.URL.Path, f.timeout)
+	}
+}
+
+// isWritten 返回是否已经发送了响应头或者响应体。gin 等框架的响应对象在发送之前
+// Status 就返回默认的 200 ，因此优先使用响应对象的 Written 方法进行判断。
+func isWritten(w ResponseWriter) bool {
+	if x, ok := w.(interface{ Written() bool }); ok {
+		return x.Written()
+	}
+	return w.Size() > 0
+}
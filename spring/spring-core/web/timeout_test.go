@@ -0,0 +1,101 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestTimeoutFilter(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/fast", func(ctx web.Context) {
+		ctx.String("fast")
+	})
+	r.GetMapping("/wait", func(ctx web.Context) {
+		<-ctx.Context().Done()
+	})
+	r.GetMapping("/error", func(ctx web.Context) {
+		<-ctx.Context().Done()
+		panic(ctx.Context().Err())
+	})
+	r.GetMapping("/partial", func(ctx web.Context) {
+		ctx.String("partial")
+		<-ctx.Context().Done()
+	})
+
+	h := web.ToHTTPHandler(r, web.RecoveryFilter(0), web.TimeoutFilter(20*time.Millisecond))
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := serve("/fast")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), "fast")
+
+	w = serve("/wait")
+	assert.Equal(t, w.Code, http.StatusGatewayTimeout)
+
+	w = serve("/error")
+	assert.Equal(t, w.Code, http.StatusGatewayTimeout)
+
+	w = serve("/partial")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), "partial")
+}
+
+func TestMapperTimeout(t *testing.T) {
+
+	config := conf.DefaultWebServerConfig()
+	config.Timeout = time.Hour
+	c := web.NewAbstractContainer(config)
+
+	// deadline 返回 Mapper 的处理函数看到的剩余超时时间，没有超时时间时返回 0 。
+	deadline := func(m *web.Mapper) time.Duration {
+		var remain time.Duration
+		m = web.NewMapper(m.Method(), m.Path(), web.FUNC(func(ctx web.Context) {
+			if d, ok := ctx.Context().Deadline(); ok {
+				remain = time.Until(d)
+			}
+		})).SetTimeout(m.Timeout())
+		filters, err := c.MapperFilters(m, nil)
+		assert.Nil(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx := web.NewHTTPContext(httptest.NewRecorder(), req, m.Handler(), m.Path(), nil, nil)
+		web.InvokeHandler(ctx, m.Handler(), filters)
+		return remain
+	}
+
+	m := web.NewMapper(web.MethodGet, "/", nil)
+	d := deadline(m)
+	assert.True(t, d > 59*time.Minute && d <= time.Hour)
+
+	d = deadline(m.SetTimeout(time.Minute))
+	assert.True(t, d > 59*time.Second && d <= time.Minute)
+
+	d = deadline(m.SetTimeout(-1))
+	assert.Equal(t, d, time.Duration(0))
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RequestBodyTransformer 改写请求体，返回的 io.ReadCloser 在处理函数读取请求体
+// 时按需读取 body ，因此可以流式地解密、解压请求体，关闭时需要关闭 body 。
+type RequestBodyTransformer func(ctx Context, body io.ReadCloser) (io.ReadCloser, error)
+
+// ResponseBodyTransformer 改写响应体，处理函数写入的数据经过返回的写入器处理后
+// 写入 w ，处理函数返回后调用 Close 方法以便写入剩余的数据。
+type ResponseBodyTransformer func(ctx Context, w io.Writer) io.WriteCloser
+
+// transformedBody 改写过的请求体，保留原始的请求体以便流量录制能够找到它。
+type transformedBody struct {
+	io.ReadCloser
+	origin io.ReadCloser
+}
+
+// requestBodyFilter 改写请求体的过滤器。
+type requestBodyFilter struct {
+	fn RequestBodyTransformer
+}
+
+// RequestBodyFilter 返回在处理函数之前改写请求体的过滤器，改写后请求体的长度
+// 未知，所以会删除 Content-Length 请求头。没有请求体时不会调用 fn 。
+func RequestBodyFilter(fn RequestBodyTransformer) Filter {
+	return &requestBodyFilter{fn: fn}
+}
+
+func (f *requestBodyFilter) Invoke(ctx Context, chain FilterChain) {
+	r := ctx.Request()
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := f.fn(ctx, r.Body)
+		if err != nil {
+			panic(NewHttpError(http.StatusBadRequest, err.Error()))
+		}
+		r.Body = &transformedBody{ReadCloser: body, origin: r.Body}
+		r.ContentLength = -1
+		r.Header.Del(HeaderContentLength)
+	}
+	chain.Next(ctx)
+}
+
+// responseBodyFilter 改写响应体的过滤器。
+type responseBodyFilter struct {
+	fn ResponseBodyTransformer
+}
+
+// ResponseBodyFilter 返回在处理函数之后改写响应体的过滤器，处理函数写入的数据
+// 经过改写后才发送给客户端，改写后响应体的长度未知，所以会删除 Content-Length
+// 响应头。Context.ResponseWriter 返回的对象必须实现 Transform 方法，
+// BufferedResponseWriter 已经实现了该方法。
+func ResponseBodyFilter(fn ResponseBodyTransformer) Filter {
+	return &responseBodyFilter{fn: fn}
+}
+
+func (f *responseBodyFilter) Invoke(ctx Context, chain FilterChain) {
+	t, ok := ctx.ResponseWriter().(interface {
+		Transform(fn func(w io.Writer) io.WriteCloser) func() error
+	})
+	if !ok {
+		chain.Next(ctx)
+		return
+	}
+	end := t.Transform(func(w io.Writer) io.WriteCloser {
+		return f.fn(ctx, w)
+	})
+	chain.Next(ctx)
+	if err := end(); err != nil {
+		panic(err)
+	}
+}
+
+// GunzipRequestBody 解压 Content-Encoding 为 gzip 的请求体，其他请求体保持不变。
+func GunzipRequestBody(ctx Context, body io.ReadCloser) (io.ReadCloser, error) {
+	r := ctx.Request()
+	if !strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		return body, nil
+	}
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Del("Content-Encoding")
+	return &gzipBody{Reader: zr, body: body}, nil
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.body.Close()
+}
+
+// JSONEnvelope 返回将 JSON 响应体包装为 {"<field>":<body>} 的改写函数，例如把
+// 处理函数返回的数据统一包装到 data 字段中，其他类型的响应体保持不变。响应体
+// 按照写入的顺序流式地包装，不会缓存整个响应体。
+func JSONEnvelope(field string) ResponseBodyTransformer {
+	prefix := []byte("{" + strconv.Quote(field) + ":")
+	return func(ctx Context, w io.Writer) io.WriteCloser {
+		return &envelopeWriter{ctx: ctx, w: w, prefix: prefix}
+	}
+}
+
+type envelopeWriter struct {
+	ctx     Context
+	w       io.Writer
+	prefix  []byte
+	started bool // 是否已经写入前缀
+	skipped bool // 不是 JSON 响应体
+}
+
+func (e *envelopeWriter) Write(p []byte) (int, error) {
+	if !e.started && !e.skipped {
+		contentType := e.ctx.ResponseWriter().Header().Get(HeaderContentType)
+		if filterFlags(contentType) != MIMEApplicationJSON {
+			e.skipped = true
+		} else {
+			if _, err := e.w.Write(e.prefix); err != nil {
+				return 0, err
+			}
+			e.started = true
+		}
+	}
+	return e.w.Write(p)
+}
+
+func (e *envelopeWriter) Close() error {
+	if e.started {
+		_, err := e.w.Write([]byte("}"))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestRequestBodyFilter(t *testing.T) {
+
+	key := []byte("0123456789abcdef")
+	iv := make([]byte, aes.BlockSize)
+
+	// 使用 AES-CTR 流式解密请求体
+	decrypt := web.RequestBodyFilter(func(ctx web.Context, body io.ReadCloser) (io.ReadCloser, error) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		r := &cipher.StreamReader{S: cipher.NewCTR(block, iv), R: body}
+		return ioutil.NopCloser(r), nil
+	})
+
+	r := web.NewRouter()
+	r.PostMapping("/echo", func(ctx web.Context) {
+		b, err := ctx.GetRawData()
+		assert.Nil(t, err)
+		ctx.String("%s %d", b, ctx.Request().ContentLength)
+	})
+	h := web.ToHTTPHandler(r, web.RequestBodyFilter(web.GunzipRequestBody), decrypt)
+
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+	plain := []byte("hello world")
+	encrypted := make([]byte, len(plain))
+	cipher.NewCTR(block, iv).XORKeyStream(encrypted, plain)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err = zw.Write(encrypted)
+	assert.Nil(t, err)
+	assert.Nil(t, zw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, w.Body.String(), "hello world -1")
+}
+
+func TestResponseBodyFilter(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/user", func(ctx web.Context) {
+		ctx.Header(web.HeaderContentLength, "13")
+		ctx.JSON(map[string]string{"name": "jim"})
+	})
+	r.GetMapping("/text", func(ctx web.Context) {
+		ctx.String("hello")
+	})
+	h := web.ToHTTPHandler(r, web.ResponseBodyFilter(web.JSONEnvelope("data")))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user", nil))
+	assert.Equal(t, w.Body.String(), `{"data":{"name":"jim"}}`)
+	assert.Equal(t, w.Header().Get(web.HeaderContentLength), "")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/text", nil))
+	assert.Equal(t, w.Body.String(), "hello")
+}
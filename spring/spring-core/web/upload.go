@@ -0,0 +1,167 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxMultipartMemory 解析 multipart 表单时默认使用的最大内存，超出
+// 部分会被写入临时文件。
+const DefaultMaxMultipartMemory = 32 << 20 // 32 MB
+
+// errInvalidFilename 上传文件的文件名不能作为本地文件名使用。
+var errInvalidFilename = errors.New("web: invalid upload filename")
+
+// SaveUploadedFile 以流的方式将上传的文件保存到 dst，目标目录不存在时自动创建。
+// dst 以路径分隔符结尾或者是已经存在的目录时，文件保存在该目录下，文件名取上传
+// 文件名的最后一段，客户端传入的目录部分 (例如 ../../etc/passwd) 会被丢弃。
+func SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+
+	isDir := strings.HasSuffix(dst, "/") || strings.HasSuffix(dst, string(filepath.Separator))
+	dst = filepath.Clean(dst)
+	if !isDir {
+		if fi, err := os.Stat(dst); err == nil && fi.IsDir() {
+			isDir = true
+		}
+	}
+	if isDir {
+		name, err := uploadFilename(file.Filename)
+		if err != nil {
+			return err
+		}
+		dst = filepath.Join(dst, name)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
+// uploadFilename 返回上传文件名的最后一段，兼容 Windows 客户端的反斜杠路径。
+func uploadFilename(filename string) (string, error) {
+	name := path.Base(strings.Replace(filename, "\\", "/", -1))
+	switch name {
+	case "", ".", "..", "/":
+		return "", errInvalidFilename
+	}
+	return name, nil
+}
+
+// multipartFilter 限制 multipart 请求的大小并提前解析表单。
+type multipartFilter struct {
+	maxMemory    int64
+	maxSize      int64
+	allowedTypes []string
+}
+
+// MultipartFilter 返回限制 multipart 请求的过滤器，maxMemory 是解析表单时
+// 使用的最大内存，超出部分写入临时文件，maxSize 是请求体的最大字节数，小于等于
+// 0 时不做限制，超出限制时返回 413 错误。allowedTypes 不为空时上传文件的类型必须
+// 是其中之一，支持 image/* 形式的通配，否则返回 415 错误。
+func MultipartFilter(maxMemory, maxSize int64, allowedTypes ...string) Filter {
+	if maxMemory <= 0 {
+		maxMemory = DefaultMaxMultipartMemory
+	}
+	return &multipartFilter{
+		maxMemory:    maxMemory,
+		maxSize:      maxSize,
+		allowedTypes: allowedTypes,
+	}
+}
+
+func (f *multipartFilter) Invoke(ctx Context, chain FilterChain) {
+
+	if !strings.HasPrefix(ctx.ContentType(), MIMEMultipartForm) {
+		chain.Next(ctx)
+		return
+	}
+
+	r := ctx.Request()
+	if f.maxSize > 0 {
+		if r.ContentLength > f.maxSize {
+			panic(NewHttpError(http.StatusRequestEntityTooLarge))
+		}
+		r.Body = &limitedBody{ReadCloser: r.Body, n: f.maxSize}
+	}
+
+	if err := r.ParseMultipartForm(f.maxMemory); err != nil {
+		if errors.Is(err, ErrBodyTooLarge) {
+			panic(NewHttpError(http.StatusRequestEntityTooLarge))
+		}
+		panic(NewHttpError(http.StatusBadRequest, err.Error()))
+	}
+
+	defer func() {
+		if r.MultipartForm != nil {
+			_ = r.MultipartForm.RemoveAll()
+		}
+	}()
+
+	if len(f.allowedTypes) > 0 && r.MultipartForm != nil {
+		for _, files := range r.MultipartForm.File {
+			for _, fh := range files {
+				if !f.allowed(fh.Header.Get(HeaderContentType)) {
+					panic(NewHttpError(http.StatusUnsupportedMediaType))
+				}
+			}
+		}
+	}
+
+	chain.Next(ctx)
+}
+
+// allowed 返回上传文件的类型是否在允许的类型列表中。
+func (f *multipartFilter) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range f.allowedTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == mediaType {
+			return true
+		}
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
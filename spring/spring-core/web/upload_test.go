@@ -0,0 +1,138 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+// newUploadRequest 返回上传一个文件的 multipart 请求。
+func newUploadRequest(t *testing.T, filename, contentType, content string) *http.Request {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	h.Set("Content-Type", contentType)
+	part, err := w.CreatePart(h)
+	assert.Nil(t, err)
+	_, err = part.Write([]byte(content))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set(web.HeaderContentType, w.FormDataContentType())
+	return req
+}
+
+func TestMultipartFilter(t *testing.T) {
+
+	r := web.NewRouter()
+	r.PostMapping("/upload", func(ctx web.Context) {
+		fh, err := ctx.FormFile("file")
+		if err != nil {
+			panic(err)
+		}
+		ctx.String("%s %d", fh.Filename, fh.Size)
+	})
+
+	serve := func(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("size limit", func(t *testing.T) {
+		h := web.ToHTTPHandler(r, web.RecoveryFilter(0), web.MultipartFilter(0, 256))
+
+		w := serve(h, newUploadRequest(t, "a.txt", "text/plain", "hello"))
+		assert.Equal(t, w.Code, http.StatusOK)
+		assert.Equal(t, w.Body.String(), "a.txt 5")
+
+		content := string(bytes.Repeat([]byte("x"), 1024))
+
+		req := newUploadRequest(t, "a.txt", "text/plain", content)
+		w = serve(h, req)
+		assert.Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+
+		// 没有 Content-Length 时在读取请求体的过程中检查大小
+		req = newUploadRequest(t, "a.txt", "text/plain", content)
+		req.ContentLength = -1
+		w = serve(h, req)
+		assert.Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("allowed types", func(t *testing.T) {
+		h := web.ToHTTPHandler(r, web.RecoveryFilter(0), web.MultipartFilter(0, 0, "image/*", "application/pdf"))
+
+		w := serve(h, newUploadRequest(t, "a.png", "image/png", "png"))
+		assert.Equal(t, w.Code, http.StatusOK)
+
+		w = serve(h, newUploadRequest(t, "a.pdf", "application/pdf; name=a.pdf", "pdf"))
+		assert.Equal(t, w.Code, http.StatusOK)
+
+		w = serve(h, newUploadRequest(t, "a.sh", "text/x-shellscript", "rm -rf /"))
+		assert.Equal(t, w.Code, http.StatusUnsupportedMediaType)
+	})
+}
+
+func TestSaveUploadedFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "upload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	formFile := func(filename string) *multipart.FileHeader {
+		req := newUploadRequest(t, filename, "text/plain", "hello")
+		_, fh, err := req.FormFile("file")
+		assert.Nil(t, err)
+		fh.Filename = filename // multipart 会去掉路径，这里模拟不规范的客户端
+		return fh
+	}
+
+	readFile := func(name string) string {
+		b, err := ioutil.ReadFile(name)
+		assert.Nil(t, err)
+		return string(b)
+	}
+
+	// dst 为文件路径时原样使用，目录不存在时自动创建
+	dst := filepath.Join(dir, "sub", "..", "a", "b.txt")
+	assert.Nil(t, web.SaveUploadedFile(formFile("x.txt"), dst))
+	assert.Equal(t, readFile(filepath.Join(dir, "a", "b.txt")), "hello")
+
+	// dst 为目录时丢弃上传文件名中的目录部分
+	assert.Nil(t, web.SaveUploadedFile(formFile("../../evil.txt"), dir+"/"))
+	assert.Equal(t, readFile(filepath.Join(dir, "evil.txt")), "hello")
+	_, err = os.Stat(filepath.Join(dir, "..", "..", "evil.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, web.SaveUploadedFile(formFile(`..\..\win.txt`), filepath.Join(dir, "a")))
+	assert.Equal(t, readFile(filepath.Join(dir, "a", "win.txt")), "hello")
+
+	err = web.SaveUploadedFile(formFile(".."), dir)
+	assert.Error(t, err, "invalid upload filename")
+}
@@ -26,7 +26,8 @@ import (
 // /a/:b/c/:d/*e 这种是 gin 风格；
 // /a/{b}/c/{e:*} 这种是 {} 风格；
 // /a/{b}/c/{*:e} 这也是 {} 风格;
-// /a/{b}/c/{*} 这种也是 {} 风格。
+// /a/{b}/c/{*} 这种也是 {} 风格；
+// /a/{b:int}/c/{*} 这种是带约束的 {} 风格，转换成其他风格时会去掉约束。
 
 type PathStyleEnum int
 
@@ -161,7 +162,7 @@ func ToPathStyle(path string, style PathStyleEnum) (string, string) {
 				} else if ss[1] == "*" {
 					p.addWildCard(ss[0])
 				} else {
-					panic(errors.New("error url path"))
+					p.addNamedPath(ss[0])
 				}
 			} else if s[1] == '*' {
 				p.addWildCard(s[2 : len(s)-1])
@@ -132,4 +132,36 @@ func TestToPathStyle(t *testing.T) {
 		assert.Equal(t, newPath, "/{a}/b/{c}/{*:e}")
 		assert.Equal(t, wildCardName, "e")
 	})
+	t.Run("/{a:int}/b/{c:uuid}/{e:*}", func(t *testing.T) {
+		newPath, wildCardName := web.ToPathStyle("/{a:int}/b/{c:uuid}/{e:*}", web.EchoPathStyle)
+		assert.Equal(t, newPath, "/:a/b/:c/*")
+		assert.Equal(t, wildCardName, "e")
+		newPath, wildCardName = web.ToPathStyle("/{a:int}/b/{c:uuid}/{e:*}", web.GinPathStyle)
+		assert.Equal(t, newPath, "/:a/b/:c/*e")
+		assert.Equal(t, wildCardName, "e")
+		newPath, wildCardName = web.ToPathStyle("/{a:int}/b/{c:uuid}/{e:*}", web.JavaPathStyle)
+		assert.Equal(t, newPath, "/{a}/b/{c}/{*:e}")
+		assert.Equal(t, wildCardName, "e")
+	})
+}
+
+func TestParsePathConstraints(t *testing.T) {
+
+	m, err := web.ParsePathConstraints("/users/{id:int}/files/{path:*}")
+	assert.Nil(t, err)
+	assert.Equal(t, len(m), 1)
+
+	v, err := m["id"]("123")
+	assert.Nil(t, err)
+	assert.Equal(t, v, int64(123))
+
+	_, err = m["id"]("abc")
+	assert.Error(t, err, "invalid syntax")
+
+	m, err = web.ParsePathConstraints("/users/{id}")
+	assert.Nil(t, err)
+	assert.Equal(t, len(m), 0)
+
+	_, err = web.ParsePathConstraints("/users/{id:unknown}")
+	assert.Error(t, err, "path constraint \"unknown\" not found in /users/{id:unknown}")
 }
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HeaderXApiVersion 默认携带 API 版本号的请求头。
+const HeaderXApiVersion = "X-Api-Version"
+
+var versionHeader = HeaderXApiVersion
+
+// SetVersionHeader 设置携带 API 版本号的请求头，默认为 X-Api-Version 。
+func SetVersionHeader(header string) {
+	versionHeader = header
+}
+
+// RequestVersion 返回请求的 API 版本号，优先使用版本号请求头，然后是 Accept 请求
+// 头中 vnd 媒体类型的版本号，例如 application/vnd.app.v2+json ，最后是媒体类型
+// 的 version 参数，例如 application/json;version=2 ，都没有时返回空字符串。
+func RequestVersion(r *http.Request) string {
+	if v := r.Header.Get(versionHeader); v != "" {
+		return v
+	}
+	for _, s := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(s))
+		if err != nil {
+			continue
+		}
+		if v := vndVersion(mediaType); v != "" {
+			return v
+		}
+		if v := params["version"]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// vndVersion 返回 application/vnd.app.v2+json 格式的媒体类型中的版本号。
+func vndVersion(mediaType string) string {
+	i := strings.Index(mediaType, "/vnd.")
+	if i < 0 {
+		return ""
+	}
+	s := mediaType[i+len("/vnd."):]
+	if j := strings.IndexByte(s, '+'); j >= 0 {
+		s = s[:j]
+	}
+	for _, part := range strings.Split(s, ".") {
+		if len(part) > 1 && (part[0] == 'v' || part[0] == 'V') {
+			if _, err := strconv.Atoi(part[1:]); err == nil {
+				return part
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeVersion 去掉版本号的 v 前缀，这样 v2 和 2 是同一个版本。
+func normalizeVersion(v string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(v), "v"), "V")
+}
+
+// compareVersion 按照点分隔的数字比较两个版本号，不是数字的部分按照字符串比较。
+func compareVersion(a, b string) int {
+	as := strings.Split(normalizeVersion(a), ".")
+	bs := strings.Split(normalizeVersion(b), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		m, err1 := strconv.Atoi(x)
+		n, err2 := strconv.Atoi(y)
+		if err1 == nil && err2 == nil {
+			if m != n {
+				if m < n {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionHandler 根据请求的 API 版本号选择处理函数。
+type versionHandler struct {
+	handlers map[string]Handler // 版本号到处理函数的映射
+	fallback Handler            // 请求没有携带版本号时使用的处理函数
+}
+
+func (h *versionHandler) Invoke(ctx Context) {
+	ctx.ResponseWriter().Header().Add("Vary", "Accept, "+versionHeader)
+	v := RequestVersion(ctx.Request())
+	if v == "" {
+		h.fallback.Invoke(ctx)
+		return
+	}
+	if fn, ok := h.handlers[normalizeVersion(v)]; ok {
+		fn.Invoke(ctx)
+		return
+	}
+	panic(NewHttpError(http.StatusNotAcceptable, fmt.Sprintf("unsupported api version %q", v)))
+}
+
+func (h *versionHandler) FileLine() (file string, line int, fnName string) {
+	return h.fallback.FileLine()
+}
+
+// MergeVersionMappers 将方法和路径都相同但是版本号不同的 Mapper 合并成一个根据
+// 请求的版本号选择处理函数的 Mapper ，请求没有携带版本号时使用没有版本号的
+// Mapper ，不存在时使用最新的版本。请求的版本号不存在时返回 406 。
+func MergeVersionMappers(mappers []*Mapper) []*Mapper {
+
+	type group struct {
+		mappers   []*Mapper
+		versioned bool
+	}
+
+	var keys []string
+	groups := make(map[string]*group)
+	for _, m := range mappers {
+		key := fmt.Sprintf("%d %s", m.method, m.path)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.mappers = append(g.mappers, m)
+		if m.version != "" {
+			g.versioned = true
+		}
+	}
+
+	var ret []*Mapper
+	for _, key := range keys {
+		g := groups[key]
+		if !g.versioned {
+			ret = append(ret, g.mappers...)
+			continue
+		}
+		ms := g.mappers
+		sort.SliceStable(ms, func(i, j int) bool {
+			return compareVersion(ms[i].version, ms[j].version) < 0
+		})
+		fallback := ms[len(ms)-1]
+		h := &versionHandler{handlers: make(map[string]Handler)}
+		for _, m := range ms {
+			if m.version == "" {
+				fallback = m
+				continue
+			}
+			h.handlers[normalizeVersion(m.version)] = m.handler
+		}
+		h.fallback = fallback.handler
+		m := NewMapper(fallback.method, fallback.path, h)
+		m.timeout = fallback.timeout
+		m.server = fallback.server
+		ret = append(ret, m)
+	}
+	return ret
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/web"
+)
+
+func TestRequestVersion(t *testing.T) {
+	testcases := []struct {
+		header string
+		accept string
+		expect string
+	}{
+		{"", "", ""},
+		{"3", "application/vnd.app.v2+json", "3"},
+		{"", "application/vnd.app.v2+json", "v2"},
+		{"", "text/html, application/vnd.app.v10+json;q=0.9", "v10"},
+		{"", "application/json; version=2", "2"},
+		{"", "application/json", ""},
+	}
+	for _, c := range testcases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.header != "" {
+			r.Header.Set(web.HeaderXApiVersion, c.header)
+		}
+		if c.accept != "" {
+			r.Header.Set("Accept", c.accept)
+		}
+		assert.Equal(t, web.RequestVersion(r), c.expect)
+	}
+}
+
+func TestRouter_Version(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/users", func(ctx web.Context) { ctx.String("v1") })
+	r.Version("v2").GetMapping("/users", func(ctx web.Context) { ctx.String("v2") })
+	r.Version("v10").GetMapping("/users", func(ctx web.Context) { ctx.String("v10") })
+	r.Version("v2").GetMapping("/orders", func(ctx web.Context) { ctx.String("orders v2") })
+	r.Version("v3").GetMapping("/orders", func(ctx web.Context) { ctx.String("orders v3") })
+	assert.Equal(t, len(r.Mappers()), 5)
+	assert.Equal(t, len(web.MergeVersionMappers(r.Mappers())), 2)
+
+	h := web.ToHTTPHandler(r)
+	serve := func(path, header, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set(web.HeaderXApiVersion, header)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, serve("/users", "", "").Body.String(), "v1")
+	assert.Equal(t, serve("/users", "2", "").Body.String(), "v2")
+	assert.Equal(t, serve("/users", "", "application/vnd.app.v10+json").Body.String(), "v10")
+	assert.Equal(t, serve("/orders", "", "").Body.String(), "orders v3")
+	assert.Equal(t, serve("/orders", "v2", "").Header().Get("Vary"), "Accept, X-Api-Version")
+
+	assert.Panic(t, func() { serve("/users", "v5", "") }, "unsupported api version \"v5\"")
+}
@@ -19,6 +19,7 @@ package SpringEcho
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"runtime/debug"
@@ -69,8 +70,11 @@ func (c *Container) Start() error {
 		return err
 	}
 
+	cfg := c.Config()
 	loggerFilter := c.GetLoggerFilter()
 	recoveryFilter := new(recoveryFilter)
+	bodyLimitFilter := web.BodyLimitFilter(cfg.MaxBodySize)
+	multipartFilter := web.MultipartFilter(cfg.MaxMultipartMemory, cfg.MaxUploadSize, cfg.AllowedUploadTypes...)
 
 	// 添加容器级别的过滤器，这样在路由不存在时也会调用这些过滤器
 	c.echoServer.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -99,6 +103,8 @@ func (c *Container) Start() error {
 			chain := web.NewDefaultFilterChain([]web.Filter{
 				loggerFilter,
 				recoveryFilter,
+				bodyLimitFilter,
+				multipartFilter,
 				web.HandlerFilter(Handler(next)),
 			})
 			chain.Next(WebContext(echoCtx))
@@ -114,16 +120,35 @@ func (c *Container) Start() error {
 	// 映射 Web 处理函数
 	for _, mapper := range c.Mappers() {
 		path, wildCardName := web.ToPathStyle(mapper.Path(), web.EchoPathStyle)
-		fn := HandlerWrapper(mapper.Handler(), wildCardName, urlPatterns.Get(mapper.Path()))
+		filters, err := c.MapperFilters(mapper, urlPatterns.Get(mapper.Path()))
+		if err != nil {
+			return err
+		}
+		fn := HandlerWrapper(mapper.Handler(), wildCardName, filters)
 		for _, method := range web.GetMethod(mapper.Method()) {
 			c.echoServer.Add(method, path, fn)
 			c.routes[method+path] = route{fn: mapper.Handler(), wildCardName: wildCardName}
 		}
 	}
 
-	if cfg := c.Config(); cfg.EnableSSL {
+	ln, err := c.Listen()
+	if err != nil {
+		return err
+	}
+
+	// 预先设置监听器，echo 会使用它而不是根据地址重新监听
+	if cfg.EnableSSL {
+		var cert tls.Certificate
+		cert, err = tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+		tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+		c.echoServer.TLSListener = tls.NewListener(ln, tlsConfig)
 		err = c.echoServer.StartTLS(c.Address(), cfg.CertFile, cfg.KeyFile)
 	} else {
+		c.echoServer.Listener = ln
 		err = c.echoServer.Start(c.Address())
 	}
 
@@ -138,6 +163,11 @@ func (c *Container) Stop(ctx context.Context) error {
 	return err
 }
 
+// ServeHTTP 直接处理请求，不经过网络
+func (c *Container) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.echoServer.ServeHTTP(w, r)
+}
+
 // HandlerWrapper Web 处理函数包装器
 func HandlerWrapper(fn web.Handler, wildCardName string, filters []web.Filter) echo.HandlerFunc {
 	return func(echoCtx echo.Context) error {
@@ -199,10 +229,9 @@ func (f *recoveryFilter) Invoke(ctx web.Context, chain web.FilterChain) {
 			ctxLogger := log.Ctx(ctx.Context())
 			ctxLogger.Error(err, "\n", string(debug.Stack()))
 
-			httpE := web.HttpError{Code: http.StatusInternalServerError}
-			switch e := err.(type) {
-			case *echo.HTTPError:
-				httpE.Code = e.Code
+			var httpE *web.HttpError
+			if e, ok := err.(*echo.HTTPError); ok {
+				httpE = &web.HttpError{Code: e.Code, Internal: e.Internal}
 				if e.Code == http.StatusNotFound {
 					httpE.Message = "404 page not found"
 				} else if e.Code == http.StatusMethodNotAllowed {
@@ -210,16 +239,8 @@ func (f *recoveryFilter) Invoke(ctx web.Context, chain web.FilterChain) {
 				} else {
 					httpE.Message = fmt.Sprintf("%v", e.Message)
 				}
-				httpE.Internal = e.Internal
-			case *web.HttpError:
-				httpE = *e
-			case web.HttpError:
-				httpE = e
-			case error:
-				httpE.Message = e.Error()
-			default:
-				httpE.Message = http.StatusText(httpE.Code)
-				httpE.Internal = err
+			} else {
+				httpE = web.ToHttpError(err)
 			}
 
 			echoCtx := EchoContext(ctx)
@@ -229,7 +250,7 @@ func (f *recoveryFilter) Invoke(ctx web.Context, chain web.FilterChain) {
 						ctxLogger.Error(err)
 					}
 				} else {
-					web.ErrorHandler(ctx, &httpE)
+					web.ErrorHandler(ctx, httpE)
 				}
 			}
 		}
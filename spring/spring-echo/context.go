@@ -20,16 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/url"
-	"os"
 
 	"github.com/go-spring/spring-base/knife"
 	"github.com/go-spring/spring-base/util"
-	"github.com/go-spring/spring-core/validator"
 	"github.com/go-spring/spring-core/web"
 	"github.com/labstack/echo/v4"
 )
@@ -210,22 +207,7 @@ func (ctx *Context) FormFile(name string) (*multipart.FileHeader, error) {
 
 // SaveUploadedFile uploads the form file to specific dst.
 func (ctx *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
-	// NOTE: 这一段逻辑使用 gin 的实现
-
-	src, err := file.Open()
-	if err != nil {
-		return err
-	}
-	defer src.Close()
-
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, src)
-	return err
+	return web.SaveUploadedFile(file, dst)
 }
 
 // MultipartForm returns the multipart form.
@@ -253,7 +235,12 @@ func (ctx *Context) Bind(i interface{}) error {
 	if err := ctx.echoContext.Bind(i); err != nil {
 		return err
 	}
-	return validator.Validate(i)
+	return web.BindParams(ctx, i)
+}
+
+// Session returns the session of the request.
+func (ctx *Context) Session() (*web.Session, error) {
+	return web.GetSession(ctx)
 }
 
 // ResponseWriter returns `http.ResponseWriter`.
@@ -381,6 +368,11 @@ func (ctx *Context) Blob(contentType string, b []byte) {
 	}
 }
 
+// Respond sends a response in the format negotiated by the Accept header.
+func (ctx *Context) Respond(i interface{}) {
+	web.Respond(ctx, i)
+}
+
 // File sends a response with the content of the file.
 func (ctx *Context) File(file string) {
 	if err := ctx.echoContext.File(file); err != nil {
@@ -95,10 +95,13 @@ func (c *Container) Start() error {
 		ginCtx.Next()
 	})
 
+	cfg := c.Config()
 	loggerFilter := c.GetLoggerFilter()
 	recoveryFilter := new(recoveryFilter)
+	bodyLimitFilter := web.BodyLimitFilter(cfg.MaxBodySize)
+	multipartFilter := web.MultipartFilter(cfg.MaxMultipartMemory, cfg.MaxUploadSize, cfg.AllowedUploadTypes...)
 
-	for _, filter := range []web.Filter{loggerFilter, recoveryFilter} {
+	for _, filter := range []web.Filter{loggerFilter, recoveryFilter, bodyLimitFilter, multipartFilter} {
 		f := filter // 避免延迟绑定
 		c.ginEngine.Use(func(ginCtx *gin.Context) {
 			f.Invoke(WebContext(ginCtx), &ginFilterChain{ginCtx})
@@ -113,7 +116,10 @@ func (c *Container) Start() error {
 
 	// 映射 Web 处理函数
 	for _, mapper := range c.Mappers() {
-		filters := urlPatterns.Get(mapper.Path())
+		filters, err := c.MapperFilters(mapper, urlPatterns.Get(mapper.Path()))
+		if err != nil {
+			return err
+		}
 		path, wildCardName := web.ToPathStyle(mapper.Path(), web.GinPathStyle)
 		handlers := HandlerWrapper(mapper.Handler(), wildCardName, filters)
 		for _, method := range web.GetMethod(mapper.Method()) {
@@ -125,7 +131,6 @@ func (c *Container) Start() error {
 		}
 	}
 
-	cfg := c.Config()
 	c.httpServer = &http.Server{
 		Addr:         c.Address(),
 		Handler:      c.ginEngine,
@@ -133,12 +138,17 @@ func (c *Container) Start() error {
 		WriteTimeout: cfg.WriteTimeout,
 	}
 
+	ln, err := c.Listen()
+	if err != nil {
+		return err
+	}
+
 	log.Info("⇨ http server started on ", c.Address())
 
 	if cfg.EnableSSL {
-		err = c.httpServer.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+		err = c.httpServer.ServeTLS(ln, cfg.CertFile, cfg.KeyFile)
 	} else {
-		err = c.httpServer.ListenAndServe()
+		err = c.httpServer.Serve(ln)
 	}
 
 	log.Infof("exit gin server on %s return %s", c.Address(), cast.ToString(err))
@@ -152,6 +162,11 @@ func (c *Container) Stop(ctx context.Context) error {
 	return err
 }
 
+// ServeHTTP 直接处理请求，不经过网络
+func (c *Container) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.ginEngine.ServeHTTP(w, r)
+}
+
 // HandlerWrapper Web 处理函数包装器
 func HandlerWrapper(fn web.Handler, wildCardName string, filters []web.Filter) []gin.HandlerFunc {
 	var handlers []gin.HandlerFunc
@@ -246,20 +261,7 @@ func (f *recoveryFilter) Invoke(webCtx web.Context, chain web.FilterChain) {
 				return
 			}
 
-			httpE := web.HttpError{Code: http.StatusInternalServerError}
-			switch e := err.(type) {
-			case *web.HttpError:
-				httpE = *e
-			case web.HttpError:
-				httpE = e
-			case error:
-				httpE.Message = e.Error()
-			default:
-				httpE.Message = http.StatusText(httpE.Code)
-				httpE.Internal = err
-			}
-
-			web.ErrorHandler(webCtx, &httpE)
+			web.ErrorHandler(webCtx, web.ToHttpError(err))
 		}
 	}()
 
@@ -200,3 +200,34 @@ func TestFilter_Abort(t *testing.T) {
 	testFunc("http://127.0.0.1:8080/index?filter=p1", "p1", 200)
 	testFunc("http://127.0.0.1:8080/index?filter=p2", "p2", 200)
 }
+
+func TestTimeoutFilter(t *testing.T) {
+	c := SpringGin.NewContainer(conf.WebServerConfig{Port: 8080})
+	c.AddFilter(web.TimeoutFilter(20 * time.Millisecond))
+	c.GetMapping("/wait", func(webCtx web.Context) {
+		<-webCtx.Context().Done()
+	})
+	c.GetMapping("/partial", func(webCtx web.Context) {
+		webCtx.String("partial")
+		<-webCtx.Context().Done()
+	})
+	go c.Start()
+	defer c.Stop(context.Background())
+	time.Sleep(10 * time.Millisecond)
+
+	response, err := http.Get("http://127.0.0.1:8080/wait")
+	if err != nil {
+		panic(err)
+	}
+	defer response.Body.Close()
+	assert.Equal(t, response.StatusCode, http.StatusGatewayTimeout)
+
+	response, err = http.Get("http://127.0.0.1:8080/partial")
+	if err != nil {
+		panic(err)
+	}
+	defer response.Body.Close()
+	b, _ := ioutil.ReadAll(response.Body)
+	assert.Equal(t, response.StatusCode, http.StatusOK)
+	assert.Equal(t, string(b), "partial")
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -28,7 +29,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-spring/spring-base/knife"
-	"github.com/go-spring/spring-core/validator"
 	"github.com/go-spring/spring-core/web"
 )
 
@@ -63,6 +63,22 @@ func (w *responseWriter) Body() string {
 	return w.writer.Body()
 }
 
+func (w *responseWriter) Truncated() bool {
+	return w.writer.Truncated()
+}
+
+func (w *responseWriter) Transform(fn func(w io.Writer) io.WriteCloser) func() error {
+	return w.writer.Transform(fn)
+}
+
+func (w *responseWriter) Hold() func(fn func(body []byte) bool) {
+	return w.writer.Hold()
+}
+
+func (w *responseWriter) Flush() {
+	w.writer.Flush()
+}
+
 func (w *responseWriter) Write(data []byte) (n int, err error) {
 	return w.writer.Write(data)
 }
@@ -287,7 +303,7 @@ func (ctx *Context) FormFile(name string) (*multipart.FileHeader, error) {
 
 // SaveUploadedFile uploads the form file to specific dst.
 func (ctx *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
-	return ctx.ginContext.SaveUploadedFile(file, dst)
+	return web.SaveUploadedFile(file, dst)
 }
 
 // MultipartForm returns the multipart form.
@@ -311,7 +327,12 @@ func (ctx *Context) Bind(i interface{}) error {
 	if err != nil {
 		return err
 	}
-	return validator.Validate(i)
+	return web.BindParams(ctx, i)
+}
+
+// Session returns the session of the request.
+func (ctx *Context) Session() (*web.Session, error) {
+	return web.GetSession(ctx)
 }
 
 // ResponseWriter returns `http.ResponseWriter`.
@@ -476,6 +497,11 @@ func (ctx *Context) Blob(contentType string, b []byte) {
 	ctx.ginContext.Data(statusCode, contentType, b)
 }
 
+// Respond sends a response in the format negotiated by the Accept header.
+func (ctx *Context) Respond(i interface{}) {
+	web.Respond(ctx, i)
+}
+
 // File sends a response with the content of the file.
 func (ctx *Context) File(file string) {
 	ctx.ginContext.File(file)
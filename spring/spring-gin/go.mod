@@ -4,8 +4,10 @@ go 1.14
 
 require (
 	github.com/gin-gonic/gin v1.7.4
-	github.com/go-spring/spring-base  v1.1.0-rc2
-	github.com/go-spring/spring-core  v1.1.0-rc2
+	github.com/go-spring/spring-base v1.1.0-rc2
+	github.com/go-spring/spring-core v1.1.0-rc2
+	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
+	golang.org/x/sys v0.0.0-20210910150752-751e447fb3d0
 )
 
 replace (
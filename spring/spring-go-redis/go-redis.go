@@ -73,3 +73,8 @@ func (c *client) do(ctx context.Context, args ...interface{}) (interface{}, erro
 	}
 	return cmd.Val(), nil
 }
+
+// Close 关闭客户端的连接池。
+func (c *client) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,7 @@
+module github.com/go-spring/spring-slog
+
+go 1.21
+
+require github.com/go-spring/spring-base v1.1.0-rc2
+
+replace github.com/go-spring/spring-base => ../spring-base
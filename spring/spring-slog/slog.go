@@ -0,0 +1,86 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package SpringSlog 将框架日志输出到标准库的 log/slog 。
+package SpringSlog
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/go-spring/spring-base/log"
+)
+
+const (
+	LevelTrace = slog.LevelDebug - 4 // TRACE 级别
+	LevelPanic = slog.LevelError + 4 // PANIC 级别
+	LevelFatal = slog.LevelError + 8 // FATAL 级别
+)
+
+// Driver 使用 slog 输出日志的 log.Driver 。
+type Driver struct {
+	handler slog.Handler
+}
+
+// NewDriver 返回使用 logger 输出日志的 Driver 。
+func NewDriver(logger *slog.Logger) *Driver {
+	return &Driver{handler: logger.Handler()}
+}
+
+func toLevel(level log.Level) slog.Level {
+	switch level {
+	case log.TraceLevel:
+		return LevelTrace
+	case log.DebugLevel:
+		return slog.LevelDebug
+	case log.InfoLevel:
+		return slog.LevelInfo
+	case log.WarnLevel:
+		return slog.LevelWarn
+	case log.ErrorLevel:
+		return slog.LevelError
+	case log.PanicLevel:
+		return LevelPanic
+	}
+	return LevelFatal
+}
+
+func (d *Driver) Log(level log.Level, e *log.Entry) {
+
+	ctx := e.GetCtx()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	l := toLevel(level)
+	if !d.handler.Enabled(ctx, l) {
+		return
+	}
+
+	r := slog.NewRecord(e.GetTime(), l, e.GetMsg(), 0)
+	r.AddAttrs(slog.String("caller", e.GetFile()+":"+strconv.Itoa(e.GetLine())))
+	if name := e.GetLogger(); name != "" {
+		r.AddAttrs(slog.String("logger", name))
+	}
+	if tag := e.GetTag(); tag != "" {
+		r.AddAttrs(slog.String("tag", tag))
+	}
+	for _, f := range e.GetFields() {
+		r.AddAttrs(slog.Any(f.Key, f.Value))
+	}
+	_ = d.handler.Handle(ctx, r)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringSlog_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/log"
+	SpringSlog "github.com/go-spring/spring-slog"
+)
+
+func TestDriver(t *testing.T) {
+
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "caller" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	log.SetDriver(SpringSlog.NewDriver(slog.New(h)))
+	log.SetLevel(log.TraceLevel)
+	defer log.Reset()
+
+	ctx := log.WithRequestID(context.Background(), "r-1")
+	log.GetLogger("web").Ctx(ctx).Tag("_com_request_in").With(log.Err(errors.New("timeout"))).Error("failed")
+	log.Trace("dropped by handler")
+	log.Fatal("no exit")
+
+	assert.Equal(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), []string{
+		`level=ERROR msg=failed logger=web tag=_com_request_in request_id=r-1 error=timeout`,
+		`level=ERROR+8 msg="no exit"`,
+	})
+}
@@ -0,0 +1,10 @@
+module github.com/go-spring/spring-zap
+
+go 1.14
+
+require (
+	github.com/go-spring/spring-base v1.1.0-rc2
+	go.uber.org/zap v1.19.1
+)
+
+replace github.com/go-spring/spring-base => ../spring-base
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package SpringZap 将框架日志输出到 zap 。
+package SpringZap
+
+import (
+	"github.com/go-spring/spring-base/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Driver 使用 zap 输出日志的 log.Driver 。
+type Driver struct {
+	logger *zap.Logger
+}
+
+// NewDriver 返回使用 logger 输出日志的 Driver 。
+func NewDriver(logger *zap.Logger) *Driver {
+	return &Driver{logger: logger}
+}
+
+// toLevel 转换日志级别，zap 的 PANIC 和 FATAL 级别会导致 panic 和退出程序，
+// 所以都转换为 ERROR 级别。
+func toLevel(level log.Level) zapcore.Level {
+	switch level {
+	case log.TraceLevel, log.DebugLevel:
+		return zapcore.DebugLevel
+	case log.InfoLevel:
+		return zapcore.InfoLevel
+	case log.WarnLevel:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+func (d *Driver) Log(level log.Level, e *log.Entry) {
+
+	ce := d.logger.Check(toLevel(level), e.GetMsg())
+	if ce == nil {
+		return
+	}
+
+	ce.Time = e.GetTime()
+	ce.Caller = zapcore.NewEntryCaller(0, e.GetFile(), e.GetLine(), true)
+	if name := e.GetLogger(); name != "" {
+		ce.LoggerName = name
+	}
+
+	var fields []zap.Field
+	if tag := e.GetTag(); tag != "" {
+		fields = append(fields, zap.String("tag", tag))
+	}
+	for _, f := range e.GetFields() {
+		if err, ok := f.Value.(error); ok {
+			fields = append(fields, zap.NamedError(f.Key, err))
+			continue
+		}
+		fields = append(fields, zap.Any(f.Key, f.Value))
+	}
+	ce.Write(fields...)
+}
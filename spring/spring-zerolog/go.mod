@@ -0,0 +1,10 @@
+module github.com/go-spring/spring-zerolog
+
+go 1.14
+
+require (
+	github.com/go-spring/spring-base v1.1.0-rc2
+	github.com/rs/zerolog v1.26.1
+)
+
+replace github.com/go-spring/spring-base => ../spring-base
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package SpringZerolog 将框架日志输出到 zerolog 。
+package SpringZerolog
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/rs/zerolog"
+)
+
+// Driver 使用 zerolog 输出日志的 log.Driver 。
+type Driver struct {
+	logger zerolog.Logger
+}
+
+// NewDriver 返回使用 logger 输出日志的 Driver 。
+func NewDriver(logger zerolog.Logger) *Driver {
+	return &Driver{logger: logger}
+}
+
+func toLevel(level log.Level) zerolog.Level {
+	switch level {
+	case log.TraceLevel:
+		return zerolog.TraceLevel
+	case log.DebugLevel:
+		return zerolog.DebugLevel
+	case log.InfoLevel:
+		return zerolog.InfoLevel
+	case log.WarnLevel:
+		return zerolog.WarnLevel
+	case log.ErrorLevel:
+		return zerolog.ErrorLevel
+	case log.PanicLevel:
+		return zerolog.PanicLevel
+	}
+	return zerolog.FatalLevel
+}
+
+func (d *Driver) Log(level log.Level, e *log.Entry) {
+
+	// WithLevel 输出 PANIC 和 FATAL 级别的日志时不会 panic 或者退出程序。
+	ev := d.logger.WithLevel(toLevel(level))
+	if ev == nil {
+		return
+	}
+
+	ev = ev.Str(zerolog.CallerFieldName, e.GetFile()+":"+strconv.Itoa(e.GetLine()))
+	if name := e.GetLogger(); name != "" {
+		ev = ev.Str("logger", name)
+	}
+	if tag := e.GetTag(); tag != "" {
+		ev = ev.Str("tag", tag)
+	}
+
+	for _, f := range e.GetFields() {
+		switch v := f.Value.(type) {
+		case string:
+			ev = ev.Str(f.Key, v)
+		case int64:
+			ev = ev.Int64(f.Key, v)
+		case float64:
+			ev = ev.Float64(f.Key, v)
+		case bool:
+			ev = ev.Bool(f.Key, v)
+		case time.Duration:
+			ev = ev.Dur(f.Key, v)
+		case time.Time:
+			ev = ev.Time(f.Key, v)
+		case error:
+			ev = ev.AnErr(f.Key, v)
+		default:
+			ev = ev.Interface(f.Key, v)
+		}
+	}
+	ev.Msg(e.GetMsg())
+}
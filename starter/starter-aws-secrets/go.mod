@@ -0,0 +1,16 @@
+module github.com/go-spring/starter-aws-secrets
+
+go 1.14
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.26.1
+	github.com/aws/aws-sdk-go-v2/config v1.27.7
+	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.28.6
+	github.com/go-spring/spring-base v1.1.0-rc2
+	github.com/go-spring/spring-core v1.1.0-rc2
+)
+
+replace (
+	github.com/go-spring/spring-base => ../../spring/spring-base
+	github.com/go-spring/spring-core => ../../spring/spring-core
+)
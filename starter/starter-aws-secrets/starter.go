@@ -0,0 +1,146 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterAwsSecrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/go-spring/spring-base/cast"
+	"github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/secret"
+)
+
+// Prefix AWS Secrets Manager 密钥引用的前缀，例如 ${aws-secrets:prod/db#password} 。
+const Prefix = "aws-secrets"
+
+func init() {
+	gs.OnProperty("aws.secrets", func(config Config) {
+		p, err := NewProvider(config)
+		if err != nil {
+			log.Errorf("create aws secrets manager client error: %v", err)
+			return
+		}
+		cache = secret.NewCache(p, config.CacheTTL)
+		refreshInterval = config.RefreshInterval
+		secret.Register(Prefix, cache)
+	})
+	gs.Object(new(Starter)).Export((*gs.AppEvent)(nil))
+}
+
+// Config AWS Secrets Manager 客户端配置，配置了任意 aws.secrets.* 属性之后生效，
+// 访问凭证使用 AWS SDK 默认的查找方式。
+type Config struct {
+	Region          string        `value:"${region:=}"`             // 区域，为空时使用 AWS_REGION 环境变量
+	Endpoint        string        `value:"${endpoint:=}"`           // 自定义服务地址
+	CacheTTL        time.Duration `value:"${cache-ttl:=5m}"`        // 密钥的缓存时间，0 表示永不过期
+	RefreshInterval time.Duration `value:"${refresh-interval:=1m}"` // 检查缓存是否需要刷新的间隔
+}
+
+var (
+	cache           *secret.Cache
+	refreshInterval time.Duration
+)
+
+// Provider 基于 AWS Secrets Manager 的密钥管理服务。值为 JSON 对象的密钥按照
+// 字段读取，否则密钥只有一个名为 value 的字段。
+type Provider struct {
+	client *secretsmanager.Client
+}
+
+// NewProvider 返回新的 *Provider 对象。
+func NewProvider(config Config) (*Provider, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if config.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(config.Region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.Endpoint)
+		}
+	})
+	return &Provider{client: client}, nil
+}
+
+// Read 读取名为 path 的密钥的当前版本。
+func (p *Provider) Read(ctx context.Context, path string) (*secret.Secret, error) {
+
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(path),
+	})
+	if err != nil {
+		var e *types.ResourceNotFoundException
+		if errors.As(err, &e) {
+			return nil, fmt.Errorf("aws secret %s %w", path, conf.ErrNotExist)
+		}
+		return nil, err
+	}
+
+	var value string
+	if out.SecretString != nil {
+		value = *out.SecretString
+	} else {
+		value = string(out.SecretBinary)
+	}
+
+	s := &secret.Secret{Data: make(map[string]string)}
+	var m map[string]interface{}
+	if json.Unmarshal([]byte(value), &m) != nil {
+		s.Data["value"] = value
+		return s, nil
+	}
+	for k, v := range m {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			s.Data[k] = string(b)
+		default:
+			s.Data[k] = cast.ToString(v)
+		}
+	}
+	return s, nil
+}
+
+// Starter 在应用运行期间定时刷新过期的密钥。
+type Starter struct{}
+
+func (s *Starter) OnAppStart(ctx gs.Context) {
+	if cache == nil || refreshInterval <= 0 {
+		return
+	}
+	ctx.Go(func(ctx context.Context) {
+		cache.Watch(ctx, refreshInterval)
+	})
+}
+
+func (s *Starter) OnAppStop(ctx context.Context) {}
@@ -0,0 +1,20 @@
+module github.com/go-spring/starter-consul
+
+go 1.14
+
+require (
+	github.com/armon/go-metrics v0.3.3 // indirect
+	github.com/go-spring/spring-base v1.1.0-rc2
+	github.com/go-spring/spring-core v1.1.0-rc2
+	github.com/hashicorp/consul/api v1.20.0
+	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
+	github.com/hashicorp/go-hclog v0.14.0 // indirect
+	github.com/hashicorp/go-immutable-radix v1.2.0 // indirect
+	github.com/mattn/go-colorable v0.1.7 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+)
+
+replace (
+	github.com/go-spring/spring-base => ../../spring/spring-base
+	github.com/go-spring/spring-core => ../../spring/spring-core
+)
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterConsul
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-core/discovery"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/web"
+	"github.com/hashicorp/consul/api"
+)
+
+func init() {
+	gs.Provide(newRegistry).
+		On(cond.OnMissingBean((*discovery.Registry)(nil))).
+		Export((*discovery.Registry)(nil), (*discovery.Resolver)(nil))
+	gs.Provide(discovery.NewRegistrar).
+		On(cond.OnBean((*Registry)(nil))).
+		Export((*gs.AppEvent)(nil))
+	gs.Provide(discovery.NewPicker).
+		On(cond.OnBean((*Registry)(nil)))
+	gs.Provide(discovery.NewLoadBalancerFromConfig).
+		On(cond.OnBean((*Registry)(nil))).
+		Export((*web.Balancer)(nil))
+}
+
+// Config Consul 客户端配置。
+type Config struct {
+	Address         string        `value:"${consul.address:=127.0.0.1:8500}"`    // Agent 地址
+	Scheme          string        `value:"${consul.scheme:=http}"`               // 协议
+	Token           string        `value:"${consul.token:=}"`                    // ACL Token
+	CheckInterval   time.Duration `value:"${consul.check.interval:=10s}"`        // 健康检查的间隔
+	CheckTimeout    time.Duration `value:"${consul.check.timeout:=5s}"`          // 健康检查的超时时间
+	DeregisterAfter time.Duration `value:"${consul.check.deregister-after:=1m}"` // 健康检查失败多久之后自动注销
+}
+
+// Registry 基于 Consul 的注册中心。
+type Registry struct {
+	client *api.Client
+	config Config
+}
+
+func newRegistry(config Config) (*Registry, error) {
+	client, err := api.NewClient(&api.Config{
+		Address: config.Address,
+		Scheme:  config.Scheme,
+		Token:   config.Token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Registry{client: client, config: config}, nil
+}
+
+// Register 注册服务实例，设置了 HealthCheckURL 时使用 HTTP 检查，否则使用 TCP
+// 检查。
+func (r *Registry) Register(ctx context.Context, i *discovery.Instance) error {
+	check := &api.AgentServiceCheck{
+		Interval:                       r.config.CheckInterval.String(),
+		Timeout:                        r.config.CheckTimeout.String(),
+		DeregisterCriticalServiceAfter: r.config.DeregisterAfter.String(),
+	}
+	if i.HealthCheckURL != "" {
+		check.HTTP = i.HealthCheckURL
+	} else {
+		check.TCP = i.Address()
+	}
+	return r.client.Agent().ServiceRegister(&api.AgentServiceRegistration{
+		ID:      i.ID,
+		Name:    i.Name,
+		Address: i.Host,
+		Port:    i.Port,
+		Meta:    i.Metadata,
+		Check:   check,
+	})
+}
+
+func (r *Registry) Deregister(ctx context.Context, i *discovery.Instance) error {
+	return r.client.Agent().ServiceDeregister(i.ID)
+}
+
+// Resolve 返回服务 name 所有通过健康检查的实例。
+func (r *Registry) Resolve(ctx context.Context, name string) ([]*discovery.Instance, error) {
+	q := (&api.QueryOptions{}).WithContext(ctx)
+	entries, _, err := r.client.Health().Service(name, "", true, q)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*discovery.Instance
+	for _, e := range entries {
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		ret = append(ret, &discovery.Instance{
+			ID:       e.Service.ID,
+			Name:     e.Service.Service,
+			Host:     host,
+			Port:     e.Service.Port,
+			Metadata: e.Service.Meta,
+		})
+	}
+	return ret, nil
+}
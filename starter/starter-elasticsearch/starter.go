@@ -0,0 +1,163 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterElasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/web"
+)
+
+func init() {
+	gs.Provide(newClient).
+		On(cond.OnProperty("elasticsearch.addresses").
+			OnMissingBean((*elasticsearch.Client)(nil))).
+		Destroy(closeClient)
+	gs.Provide(newHealthIndicator).
+		Name("elasticsearch").
+		On(cond.OnBean((*elasticsearch.Client)(nil))).
+		Export((*web.HealthIndicator)(nil))
+}
+
+// Config Elasticsearch 客户端配置。
+type Config struct {
+	Addresses           []string      `value:"${elasticsearch.addresses}"`                   // 节点地址列表
+	Username            string        `value:"${elasticsearch.username:=}"`                  // 用户名
+	Password            string        `value:"${elasticsearch.password:=}"`                  // 密码
+	APIKey              string        `value:"${elasticsearch.api-key:=}"`                   // API Key ，优先于用户名和密码
+	Sniff               bool          `value:"${elasticsearch.sniff.enabled:=false}"`        // 启动时是否嗅探集群节点
+	SniffInterval       time.Duration `value:"${elasticsearch.sniff.interval:=0}"`           // 定时嗅探集群节点的间隔，0 表示不定时嗅探
+	MaxIdleConnsPerHost int           `value:"${elasticsearch.max-idle-conns-per-host:=10}"` // 每个节点的最大空闲连接数
+}
+
+// Metrics Elasticsearch 请求的执行统计。
+type Metrics struct {
+	Requests int64         `json:"requests"` // 请求数
+	Errors   int64         `json:"errors"`   // 失败的请求数，包括状态码大于等于 400 的请求
+	Latency  time.Duration `json:"latency"`  // 请求的累计耗时
+}
+
+var metrics struct {
+	requests int64
+	errors   int64
+	latency  int64
+}
+
+// GetMetrics 返回 Elasticsearch 请求的执行统计。
+func GetMetrics() Metrics {
+	return Metrics{
+		Requests: atomic.LoadInt64(&metrics.requests),
+		Errors:   atomic.LoadInt64(&metrics.errors),
+		Latency:  time.Duration(atomic.LoadInt64(&metrics.latency)),
+	}
+}
+
+// transport 统计请求的执行情况。
+type transport struct {
+	*http.Transport
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.Transport.RoundTrip(r)
+	atomic.AddInt64(&metrics.requests, 1)
+	atomic.AddInt64(&metrics.latency, int64(time.Since(start)))
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		atomic.AddInt64(&metrics.errors, 1)
+	}
+	return resp, err
+}
+
+// clients 记录客户端使用的 transport ，程序退出时关闭空闲连接。
+var clients = make(map[*elasticsearch.Client]*transport)
+
+func newClient(config Config) (*elasticsearch.Client, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	tr := &transport{Transport: t}
+	c, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses:             config.Addresses,
+		Username:              config.Username,
+		Password:              config.Password,
+		APIKey:                config.APIKey,
+		Transport:             tr,
+		DiscoverNodesOnStart:  config.Sniff,
+		DiscoverNodesInterval: config.SniffInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clients[c] = tr
+	log.Infof("open elasticsearch %v", config.Addresses)
+	return c, nil
+}
+
+// closeClient 程序退出时关闭客户端的空闲连接，正在执行的请求不受影响。
+func closeClient(c *elasticsearch.Client) {
+	log.Info("close elasticsearch")
+	if tr, ok := clients[c]; ok {
+		tr.CloseIdleConnections()
+		delete(clients, c)
+	}
+}
+
+// healthIndicator 使用集群健康接口检查 Elasticsearch 是否可用，集群状态为
+// red 时认为不可用，详细信息中包含集群状态和请求的执行统计。
+type healthIndicator struct {
+	client *elasticsearch.Client
+}
+
+func newHealthIndicator(c *elasticsearch.Client) *healthIndicator {
+	return &healthIndicator{client: c}
+}
+
+func (h *healthIndicator) Health(ctx context.Context) (map[string]interface{}, error) {
+	m := GetMetrics()
+	details := map[string]interface{}{
+		"requests": m.Requests,
+		"errors":   m.Errors,
+		"latency":  m.Latency.String(),
+	}
+	resp, err := h.client.Cluster.Health(h.client.Cluster.Health.WithContext(ctx))
+	if err != nil {
+		return details, err
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return details, errors.New(resp.String())
+	}
+	var r struct {
+		Status string `json:"status"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return details, err
+	}
+	details["cluster_status"] = r.Status
+	if r.Status == "red" {
+		return details, errors.New("cluster status is red")
+	}
+	return details, nil
+}
@@ -0,0 +1,18 @@
+module github.com/go-spring/starter-etcd
+
+go 1.14
+
+require (
+	github.com/coreos/go-semver v0.3.1 // indirect
+	github.com/coreos/go-systemd/v22 v22.4.0 // indirect
+	github.com/go-spring/spring-core v1.1.0-rc2
+	go.etcd.io/etcd/client/v3 v3.5.9
+	go.uber.org/zap v1.19.1 // indirect
+	golang.org/x/net v0.17.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+)
+
+replace (
+	github.com/go-spring/spring-base => ../../spring/spring-base
+	github.com/go-spring/spring-core => ../../spring/spring-core
+)
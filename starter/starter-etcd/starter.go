@@ -0,0 +1,147 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterEtcd
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/lock"
+	"go.etcd.io/etcd/client/v3"
+)
+
+func init() {
+	gs.Provide(newClient).
+		On(cond.OnMissingBean((*clientv3.Client)(nil))).
+		Destroy(closeClient)
+	gs.Provide(newLocker, "", "${etcd.lock.prefix:=/lock/}").
+		On(cond.OnMissingBean((*lock.Locker)(nil))).
+		Export((*lock.Locker)(nil))
+}
+
+// Config etcd 客户端配置。
+type Config struct {
+	Endpoints   []string      `value:"${etcd.endpoints:=127.0.0.1:2379}"` // 服务地址列表
+	DialTimeout time.Duration `value:"${etcd.dial-timeout:=5s}"`          // 连接超时
+	Username    string        `value:"${etcd.username:=}"`                // 用户名
+	Password    string        `value:"${etcd.password:=}"`                // 密码
+}
+
+func newClient(config Config) (*clientv3.Client, error) {
+	return clientv3.New(clientv3.Config{
+		Endpoints:   config.Endpoints,
+		DialTimeout: config.DialTimeout,
+		Username:    config.Username,
+		Password:    config.Password,
+	})
+}
+
+// closeClient 程序退出时关闭客户端。
+func closeClient(c *clientv3.Client) error {
+	return c.Close()
+}
+
+// Locker 基于 etcd 租约的分布式锁，锁的 key 绑定到租约上，fencing token 为
+// key 的创建版本号。
+type Locker struct {
+	client *clientv3.Client
+	prefix string
+}
+
+func newLocker(client *clientv3.Client, prefix string) *Locker {
+	return &Locker{client: client, prefix: prefix}
+}
+
+// seconds 返回 ttl 对应的秒数，不足 1 秒按 1 秒处理。
+func seconds(ttl time.Duration) int64 {
+	n := int64((ttl + time.Second - 1) / time.Second)
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (l *Locker) TryLock(ctx context.Context, key string, ttl time.Duration) (lock.Lock, error) {
+
+	lease, err := l.client.Grant(ctx, seconds(ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	k := l.prefix + key
+	resp, err := l.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(k), "=", 0)).
+		Then(clientv3.OpPut(k, "", clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		_, _ = l.client.Revoke(context.Background(), lease.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, lock.ErrNotObtained
+	}
+
+	return &etcdLock{
+		client: l.client,
+		key:    key,
+		k:      k,
+		lease:  lease.ID,
+		token:  resp.Header.Revision,
+	}, nil
+}
+
+type etcdLock struct {
+	client *clientv3.Client
+	key    string
+	k      string
+	lease  clientv3.LeaseID
+	token  int64
+}
+
+func (r *etcdLock) Key() string {
+	return r.key
+}
+
+func (r *etcdLock) Token() int64 {
+	return r.token
+}
+
+// Refresh 续期租约，租约的有效期固定为获得锁时的 ttl 。
+func (r *etcdLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	resp, err := r.client.KeepAliveOnce(ctx, r.lease)
+	if err != nil || resp.TTL <= 0 {
+		return lock.ErrNotHeld
+	}
+	return nil
+}
+
+func (r *etcdLock) Unlock(ctx context.Context) error {
+	resp, err := r.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.LeaseValue(r.k), "=", r.lease)).
+		Then(clientv3.OpDelete(r.k)).
+		Commit()
+	_, _ = r.client.Revoke(ctx, r.lease)
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return lock.ErrNotHeld
+	}
+	return nil
+}
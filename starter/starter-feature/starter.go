@@ -0,0 +1,86 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterFeature
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/feature"
+	"github.com/go-spring/spring-core/gs"
+)
+
+func init() {
+	gs.Provide(newManager)
+	gs.Object(new(Starter)).Export((*gs.AppEvent)(nil))
+}
+
+// Config 功能开关的动态属性源配置。
+type Config struct {
+	File            string        `value:"${feature.source.file:=}"`                // 动态属性文件，为空时只使用应用配置中的开关
+	RefreshInterval time.Duration `value:"${feature.source.refresh-interval:=10s}"` // 读取动态属性文件的间隔
+}
+
+// newManager 使用应用配置中 feature.flags. 前缀的属性初始化包级别的 Manager 。
+func newManager(ctx gs.Context) *feature.Manager {
+	m := feature.Default()
+	m.Update(properties(ctx))
+	return m
+}
+
+func properties(ctx gs.Context) map[string]string {
+	props := make(map[string]string)
+	for _, k := range ctx.Keys() {
+		if strings.HasPrefix(k, feature.Prefix) {
+			props[k] = ctx.Prop(k)
+		}
+	}
+	return props
+}
+
+// Starter 在应用启动后监听动态属性文件，文件中的开关覆盖应用配置中的开关。
+type Starter struct {
+	Manager *feature.Manager `autowire:""`
+	Config  Config           `value:"${}"`
+}
+
+func (s *Starter) OnAppStart(ctx gs.Context) {
+	if s.Config.File == "" {
+		return
+	}
+	static := properties(ctx)
+	source := feature.NewFileSource(s.Config.File, s.Config.RefreshInterval)
+	ctx.Go(func(ctx context.Context) {
+		err := source.Watch(ctx, func(props map[string]string) {
+			m := make(map[string]string)
+			for k, v := range static {
+				m[k] = v
+			}
+			for k, v := range props {
+				m[k] = v
+			}
+			s.Manager.Update(m)
+		})
+		if err != nil {
+			log.Errorf("watch %s error: %v", s.Config.File, err)
+		}
+	})
+}
+
+func (s *Starter) OnAppStop(ctx context.Context) {}
@@ -17,11 +17,26 @@
 package StarterGin
 
 import (
+	"github.com/go-spring/spring-core/conf"
 	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/web"
 	"github.com/go-spring/spring-gin"
 	_ "github.com/go-spring/starter-web"
 )
 
 func init() {
 	gs.Provide(SpringGin.NewContainer).Name("WebContainer")
+	gs.Provide(newContainerFactory)
+	gs.Provide(newManagementContainer).Name("ManagementContainer").On(cond.OnProperty("management.server.port"))
+}
+
+// newManagementContainer 创建运行管理端点的独立容器。
+func newManagementContainer(config conf.ManagementServerConfig) *web.ManagementContainer {
+	return web.NewManagementContainer(SpringGin.NewContainer(config.WebServerConfig()))
+}
+
+// newContainerFactory 创建 web.servers.* 配置的附加 Web 服务器使用的容器工厂。
+func newContainerFactory() web.ContainerFactory {
+	return web.ContainerFactoryFunc(SpringGin.NewContainer)
 }
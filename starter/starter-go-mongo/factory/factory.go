@@ -26,16 +26,25 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-// NewClient 创建 MongoDB 客户端
+// NewClient 创建 MongoDB 客户端，客户端的命令会被统计，录制模式下会被录制。
 func NewClient(config conf.MongoClientConfig) (*mongo.Client, error) {
 	log.Info("open mongo db ", config.Url)
 	ctx := context.Background()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(config.Url))
+	opts := options.Client().
+		ApplyURI(config.Url).
+		SetConnectTimeout(config.ConnectTimeout).
+		SetMaxPoolSize(config.MaxPoolSize).
+		SetMonitor(NewCommandMonitor())
+
+	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	if !config.Ping {
+		return client, nil
+	}
 	if err = client.Ping(ctx, readpref.Primary()); err != nil {
 		return nil, err
 	}
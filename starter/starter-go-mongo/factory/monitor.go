@@ -0,0 +1,112 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package factory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/util"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// Metrics MongoDB 命令的执行统计。
+type Metrics struct {
+	Commands int64         `json:"commands"` // 执行的命令数
+	Errors   int64         `json:"errors"`   // 执行失败的命令数
+	Latency  time.Duration `json:"latency"`  // 命令的累计耗时
+}
+
+var metrics struct {
+	commands int64
+	errors   int64
+	latency  int64
+}
+
+// GetMetrics 返回 MongoDB 命令的执行统计。
+func GetMetrics() Metrics {
+	return Metrics{
+		Commands: atomic.LoadInt64(&metrics.commands),
+		Errors:   atomic.LoadInt64(&metrics.errors),
+		Latency:  time.Duration(atomic.LoadInt64(&metrics.latency)),
+	}
+}
+
+// MongoRequest 录制的 MongoDB 命令。
+type MongoRequest struct {
+	Database string `json:"database"`
+	Command  string `json:"command"`
+	Body     string `json:"body"` // 扩展 JSON 格式的命令内容
+}
+
+// pending 已经开始但是还没有结束的录制命令，key 为命令的 RequestID 。
+var pending sync.Map
+
+type pendingAction struct {
+	ctx    context.Context
+	action *fastdev.Action
+}
+
+func finished(ctx context.Context, e *event.CommandFinishedEvent, resp interface{}, failed bool) {
+	atomic.AddInt64(&metrics.commands, 1)
+	atomic.AddInt64(&metrics.latency, e.DurationNanos)
+	if failed {
+		atomic.AddInt64(&metrics.errors, 1)
+	}
+	v, ok := pending.Load(e.RequestID)
+	if !ok {
+		return
+	}
+	pending.Delete(e.RequestID)
+	p := v.(*pendingAction)
+	p.action.Response = resp
+	p.action.Latency = e.DurationNanos
+	fastdev.RecordAction(p.ctx, p.action)
+}
+
+// NewCommandMonitor 返回统计命令执行情况的监视器，录制模式下同时录制上下文中
+// 绑定了录制会话 ID 的命令。
+func NewCommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if !fastdev.Recording(ctx) {
+				return
+			}
+			pending.Store(e.RequestID, &pendingAction{
+				ctx: ctx,
+				action: &fastdev.Action{
+					Protocol: fastdev.MONGO,
+					Request: &MongoRequest{
+						Database: e.DatabaseName,
+						Command:  e.CommandName,
+						Body:     e.Command.String(),
+					},
+					Timestamp: util.Now(ctx).UnixNano(),
+				},
+			})
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			finished(ctx, &e.CommandFinishedEvent, e.Reply.String(), false)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			finished(ctx, &e.CommandFinishedEvent, "(err) "+e.Failure, true)
+		},
+	}
+}
@@ -17,10 +17,42 @@
 package StarterGoMongo
 
 import (
+	"context"
+
 	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/web"
 	"github.com/go-spring/starter-go-mongo/factory"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 func init() {
-	gs.Provide(factory.NewClient).Destroy(factory.CloseClient)
+	gs.Provide(factory.NewClient).
+		On(cond.OnMissingBean((*mongo.Client)(nil))).
+		Destroy(factory.CloseClient)
+	gs.Provide(newHealthIndicator).
+		Name("mongo").
+		On(cond.OnBean((*mongo.Client)(nil))).
+		Export((*web.HealthIndicator)(nil))
+}
+
+// healthIndicator 使用 Ping 检查 MongoDB 是否可用，详细信息中包含命令的执行
+// 统计。
+type healthIndicator struct {
+	client *mongo.Client
+}
+
+func newHealthIndicator(c *mongo.Client) *healthIndicator {
+	return &healthIndicator{client: c}
+}
+
+func (h *healthIndicator) Health(ctx context.Context) (map[string]interface{}, error) {
+	m := factory.GetMetrics()
+	details := map[string]interface{}{
+		"commands": m.Commands,
+		"errors":   m.Errors,
+		"latency":  m.Latency.String(),
+	}
+	return details, h.client.Ping(ctx, readpref.Primary())
 }
@@ -17,12 +17,69 @@
 package StarterGoRedis
 
 import (
+	"context"
+	"io"
+
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-core/cache"
 	"github.com/go-spring/spring-core/gs"
 	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/lock"
 	"github.com/go-spring/spring-core/redis"
+	"github.com/go-spring/spring-core/web"
 	"github.com/go-spring/spring-go-redis"
 )
 
 func init() {
-	gs.Provide(SpringGoRedis.NewClient).On(cond.OnMissingBean((*redis.Client)(nil)))
+	gs.Provide(SpringGoRedis.NewClient).
+		On(cond.OnMissingBean((*redis.Client)(nil))).
+		Destroy(closeClient)
+	gs.Provide(newHealthIndicator).
+		Name("redis").
+		On(cond.OnBean((*redis.Client)(nil))).
+		Export((*web.HealthIndicator)(nil))
+	gs.Provide(cache.NewRedisCache, "", "${redis.cache.prefix:=}").
+		On(cond.OnBean((*redis.Client)(nil)).OnMissingBean((*cache.Cache)(nil))).
+		Init(setDefaultCache).
+		Export((*cache.Cache)(nil))
+	gs.Provide(lock.NewRedisLocker, "", "${redis.lock.prefix:=lock:}").
+		On(cond.OnBean((*redis.Client)(nil)).OnMissingBean((*lock.Locker)(nil))).
+		Export((*lock.Locker)(nil))
+}
+
+// setDefaultCache 把 Redis 缓存设置为 cache.Through 使用的默认缓存。
+func setDefaultCache(c *cache.RedisCache) {
+	cache.SetDefault(c)
+}
+
+// closeClient 程序退出时关闭客户端的连接池。
+func closeClient(c redis.Client) error {
+	if closer, ok := c.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// healthIndicator 使用 PING 命令检查 Redis 是否可用，详细信息中包含命令的执
+// 行统计。
+type healthIndicator struct {
+	client redis.Client
+}
+
+func newHealthIndicator(c redis.Client) *healthIndicator {
+	return &healthIndicator{client: c}
+}
+
+func (h *healthIndicator) Health(ctx context.Context) (map[string]interface{}, error) {
+	m := redis.GetMetrics()
+	details := map[string]interface{}{
+		"commands": m.Commands,
+		"errors":   m.Errors,
+		"latency":  m.Latency.String(),
+	}
+	if fastdev.ReplayMode() {
+		return details, nil
+	}
+	_, err := h.client.String(ctx, "PING")
+	return details, err
 }
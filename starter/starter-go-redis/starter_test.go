@@ -0,0 +1,63 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterGoRedis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/redis"
+)
+
+type closableClient struct {
+	redis.BaseClient
+	closed bool
+}
+
+func (c *closableClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestHealthIndicator(t *testing.T) {
+
+	c := &redis.BaseClient{}
+	c.DoFunc = func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		assert.Equal(t, args, []interface{}{"PING"})
+		return "PONG", nil
+	}
+	before := redis.GetMetrics()
+	details, err := newHealthIndicator(c).Health(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, details["commands"], before.Commands)
+	assert.Equal(t, redis.GetMetrics().Commands, before.Commands+1)
+
+	c.DoFunc = func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		return nil, errors.New("connection refused")
+	}
+	_, err = newHealthIndicator(c).Health(context.Background())
+	assert.Error(t, err, "connection refused")
+}
+
+func TestCloseClient(t *testing.T) {
+	c := &closableClient{}
+	assert.Nil(t, closeClient(c))
+	assert.True(t, c.closed)
+	assert.Nil(t, closeClient(&redis.BaseClient{}))
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package factory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/discovery"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DiscoveryScheme 通过注册中心解析的 target 前缀，形如 discovery:///order 。
+	DiscoveryScheme = "discovery"
+
+	// BalancerName 使用 discovery.Balancer 选择实例的 gRPC 负载均衡器名称。
+	BalancerName = "spring_discovery"
+)
+
+// RefreshInterval 定时刷新服务实例的间隔。
+var RefreshInterval = 10 * time.Second
+
+type balancerKey struct{}
+
+type instanceKey struct{}
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(BalancerName, pickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+// isDiscoveryTarget target 是否需要通过注册中心解析。
+func isDiscoveryTarget(target string) bool {
+	return strings.HasPrefix(target, DiscoveryScheme+"://")
+}
+
+// resolverBuilder 使用 discovery.LoadBalancer 的 Resolver 解析服务实例。
+type resolverBuilder struct {
+	lb *discovery.LoadBalancer
+}
+
+func (b *resolverBuilder) Scheme() string {
+	return DiscoveryScheme
+}
+
+func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &discoveryResolver{
+		lb:     b.lb,
+		name:   target.Endpoint(),
+		cc:     cc,
+		ctx:    ctx,
+		cancel: cancel,
+		now:    make(chan struct{}, 1),
+	}
+	r.wg.Add(1)
+	go r.watch()
+	return r, nil
+}
+
+type discoveryResolver struct {
+	lb     *discovery.LoadBalancer
+	name   string
+	cc     resolver.ClientConn
+	ctx    context.Context
+	cancel context.CancelFunc
+	now    chan struct{}
+	wg     sync.WaitGroup
+}
+
+func (r *discoveryResolver) watch() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(RefreshInterval)
+	defer ticker.Stop()
+	for {
+		r.resolve()
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.now:
+		}
+	}
+}
+
+func (r *discoveryResolver) resolve() {
+	instances, err := r.lb.Resolver().Resolve(r.ctx, r.name)
+	if err != nil {
+		log.Ctx(r.ctx).Errorf("resolve %s error: %v", r.name, err)
+		r.cc.ReportError(err)
+		return
+	}
+	var addrs []resolver.Address
+	for _, i := range instances {
+		addrs = append(addrs, resolver.Address{
+			Addr:       i.Address(),
+			Attributes: attributes.New(balancerKey{}, r.lb.Balancer()).WithValue(instanceKey{}, i),
+		})
+	}
+	_ = r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+func (r *discoveryResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
+
+func (r *discoveryResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// pickerBuilder 使用地址属性中的 discovery.Balancer 创建 Picker 。
+type pickerBuilder struct{}
+
+func (pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &picker{subConns: make(map[string]balancer.SubConn)}
+	for sc, scInfo := range info.ReadySCs {
+		attrs := scInfo.Address.Attributes
+		i, ok := attrs.Value(instanceKey{}).(*discovery.Instance)
+		if !ok {
+			continue
+		}
+		p.balancer, _ = attrs.Value(balancerKey{}).(*discovery.Balancer)
+		p.instances = append(p.instances, i)
+		p.subConns[i.ID] = sc
+	}
+	if p.balancer == nil {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	return p
+}
+
+type picker struct {
+	balancer  *discovery.Balancer
+	instances []*discovery.Instance
+	subConns  map[string]balancer.SubConn
+}
+
+func (p *picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	i, done := p.balancer.Pick(p.instances)
+	if i == nil {
+		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
+	}
+	return balancer.PickResult{
+		SubConn: p.subConns[i.ID],
+		Done:    func(info balancer.DoneInfo) { done(failure(info.Err)) },
+	}, nil
+}
+
+// failure 只把实例不可用和超时作为实例失败，业务错误不影响实例的摘除。
+func failure(err error) error {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return err
+	}
+	return nil
+}
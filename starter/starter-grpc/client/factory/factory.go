@@ -17,11 +17,119 @@
 package factory
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
 	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/discovery"
+	"github.com/go-spring/starter-grpc/interceptor"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 )
 
 // NewClient 根据配置创建 grpc.ClientConnInterface 对象
 func NewClient(config conf.GrpcEndpointConfig) (grpc.ClientConnInterface, error) {
-	return grpc.Dial(config.Address, grpc.WithInsecure())
+	return grpc.Dial(config.Address, grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(interceptor.UnaryClientInterceptor()))
+}
+
+// NewClientConn 根据 grpc.client.<name>.* 配置创建 grpc.ClientConnInterface
+// 对象，支持 TLS、keepalive 和重试策略，下游请求支持流量录制和回放。target
+// 为 discovery:///<service> 形式时通过 lb 解析服务实例并进行负载均衡。
+func NewClientConn(config conf.GrpcClientConfig, lb *discovery.LoadBalancer) (grpc.ClientConnInterface, error) {
+
+	opts := []grpc.DialOption{grpc.WithUnaryInterceptor(interceptor.UnaryClientInterceptor())}
+
+	if config.TLS {
+		c, err := newTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(c)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	if config.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    config.KeepaliveTime,
+			Timeout: config.KeepaliveTimeout,
+		}))
+	}
+
+	balanced := isDiscoveryTarget(config.Target)
+	if balanced {
+		if lb == nil {
+			return nil, fmt.Errorf("no load balancer for target %s", config.Target)
+		}
+		opts = append(opts, grpc.WithResolvers(&resolverBuilder{lb}))
+	}
+
+	if balanced || config.MaxAttempts > 1 {
+		s, err := serviceConfig(config, balanced)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithDefaultServiceConfig(s))
+	}
+
+	return grpc.Dial(config.Target, opts...)
+}
+
+// CloseClient 程序退出时关闭客户端连接。
+func CloseClient(c grpc.ClientConnInterface) error {
+	if closer, ok := c.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func newTLSConfig(config conf.GrpcClientConfig) (*tls.Config, error) {
+	c := &tls.Config{ServerName: config.ServerName}
+	if config.CAFile == "" {
+		return c, nil
+	}
+	b, err := ioutil.ReadFile(config.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	c.RootCAs = x509.NewCertPool()
+	if !c.RootCAs.AppendCertsFromPEM(b) {
+		return nil, errors.New("invalid ca file " + config.CAFile)
+	}
+	return c, nil
+}
+
+// serviceConfig 返回所有方法都使用配置的重试策略的 service config ，balanced
+// 为 true 时使用 spring_discovery 负载均衡器。
+func serviceConfig(config conf.GrpcClientConfig, balanced bool) (string, error) {
+	m := make(map[string]interface{})
+	if balanced {
+		m["loadBalancingConfig"] = []interface{}{
+			map[string]interface{}{BalancerName: map[string]interface{}{}},
+		}
+	}
+	if config.MaxAttempts > 1 {
+		policy := map[string]interface{}{
+			"maxAttempts":          config.MaxAttempts,
+			"initialBackoff":       fmt.Sprintf("%gs", config.InitialBackoff.Seconds()),
+			"maxBackoff":           fmt.Sprintf("%gs", config.MaxBackoff.Seconds()),
+			"backoffMultiplier":    2,
+			"retryableStatusCodes": config.RetryableCodes,
+		}
+		m["methodConfig"] = []interface{}{
+			map[string]interface{}{
+				"name":        []interface{}{map[string]interface{}{}},
+				"retryPolicy": policy,
+			},
+		}
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
 }
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package factory_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/starter-grpc/client/factory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// startHealthServer 启动只提供健康检查服务的 gRPC 服务器，返回服务器地址。
+func startHealthServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(s.Stop)
+	return l.Addr().String()
+}
+
+func TestNewClientConn_Record(t *testing.T) {
+
+	fastdev.SetRecordMode(true)
+	defer fastdev.SetRecordMode(false)
+
+	conn, err := factory.NewClientConn(conf.GrpcClientConfig{Target: startHealthServer(t)}, nil)
+	assert.Nil(t, err)
+	defer factory.CloseClient(conn)
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	// 没有绑定会话 ID 的请求不录制
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+
+	ctx := knife.New(context.Background())
+	err = knife.Set(ctx, fastdev.RecordSessionIDKey, fastdev.NewSessionID())
+	assert.Nil(t, err)
+
+	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, resp.Status, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	session := fastdev.RecordInbound(ctx, &fastdev.Action{Protocol: fastdev.GRPC})
+	assert.Equal(t, len(session.Actions), 1)
+
+	action := session.Actions[0]
+	assert.Equal(t, action.Protocol, fastdev.GRPC)
+	req, ok := action.Request.(*fastdev.GrpcRequest)
+	assert.True(t, ok)
+	assert.Equal(t, req.Method, "/grpc.health.v1.Health/Check")
+	r, ok := action.Response.(*fastdev.GrpcResponse)
+	assert.True(t, ok)
+	assert.Equal(t, r.Code, uint32(0))
+	assert.True(t, strings.Contains(r.Message, "SERVING"))
+}
@@ -26,7 +26,12 @@ import (
 func init() {
 	gs.OnProperty("grpc.endpoint", func(endpoints map[string]conf.GrpcEndpointConfig) {
 		for endpoint, config := range endpoints {
-			gs.Provide(factory.NewClient, arg.Value(config)).Name(endpoint)
+			gs.Provide(factory.NewClient, arg.Value(config)).Name(endpoint).Destroy(factory.CloseClient)
+		}
+	})
+	gs.OnProperty("grpc.client", func(clients map[string]conf.GrpcClientConfig) {
+		for name, config := range clients {
+			gs.Provide(factory.NewClientConn, arg.Value(config), arg.Optional(nil)).Name(name).Destroy(factory.CloseClient)
 		}
 	})
 }
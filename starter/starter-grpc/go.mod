@@ -5,9 +5,13 @@ go 1.14
 require (
 	github.com/go-spring/spring-base v1.1.0-rc2
 	github.com/go-spring/spring-core v1.1.0-rc2
-	google.golang.org/grpc v1.41.0
+	golang.org/x/net v0.17.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/grpc v1.54.0
+	google.golang.org/protobuf v1.30.0
 )
 
 replace (
+	github.com/go-spring/spring-base => ../../spring/spring-base
 	github.com/go-spring/spring-core => ../../spring/spring-core
 )
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package interceptor 提供 gRPC 流量录制和回放的拦截器。
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/knife"
+	"github.com/go-spring/spring-base/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// replaySessionID 回放模式下传递会话 ID 使用的 metadata 键。
+var replaySessionID = strings.ToLower(fastdev.ReplaySessionIDKey)
+
+// encodeMessage 返回消息的 JSON 格式和 protobuf 编码，不是 protobuf 消息时
+// 只返回 JSON 格式。
+func encodeMessage(m interface{}) (js []byte, pb []byte) {
+	if pm, ok := m.(proto.Message); ok {
+		js, _ = protojson.Marshal(pm)
+		pb, _ = proto.Marshal(pm)
+		return
+	}
+	js, _ = json.Marshal(m)
+	return
+}
+
+// decodeMessage 将录制的响应反序列化到 reply ，优先使用 protobuf 编码。
+func decodeMessage(r *fastdev.GrpcResponse, reply interface{}) error {
+	pm, ok := reply.(proto.Message)
+	if !ok {
+		return json.Unmarshal([]byte(r.Message), reply)
+	}
+	if len(r.Proto) > 0 {
+		return proto.Unmarshal(r.Proto, pm)
+	}
+	return protojson.Unmarshal([]byte(r.Message), pm)
+}
+
+func newRequest(method string, md metadata.MD, req interface{}) *fastdev.GrpcRequest {
+	js, pb := encodeMessage(req)
+	return fastdev.NewGrpcRequest(method, md, js, pb)
+}
+
+func newResponse(resp interface{}, err error) *fastdev.GrpcResponse {
+	if err != nil {
+		s := status.Convert(err)
+		return fastdev.NewGrpcResponse(uint32(s.Code()), s.Message(), nil, nil)
+	}
+	js, pb := encodeMessage(resp)
+	return fastdev.NewGrpcResponse(uint32(codes.OK), "", js, pb)
+}
+
+// UnaryServerInterceptor 录制模式下为每个上游请求分配会话 ID 并录制请求和
+// 响应；回放模式下从 metadata 中读取会话 ID ，处理过程中的下游请求使用录制的
+// 响应。
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		if fastdev.ReplayMode() {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if ss := md.Get(replaySessionID); len(ss) > 0 {
+				ctx = knife.New(ctx)
+				if err := knife.Set(ctx, fastdev.ReplaySessionIDKey, ss[0]); err != nil {
+					return nil, err
+				}
+				ctx = fastdev.MockReplayTime(ctx)
+			}
+			return handler(ctx, req)
+		}
+
+		if !fastdev.RecordEnabled() {
+			return handler(ctx, req)
+		}
+
+		ctx = knife.New(ctx)
+		if err := knife.Set(ctx, fastdev.RecordSessionIDKey, fastdev.NewSessionID()); err != nil {
+			return nil, err
+		}
+
+		start := util.Now(ctx)
+		resp, err := handler(ctx, req)
+		md, _ := metadata.FromIncomingContext(ctx)
+		fastdev.RecordInbound(ctx, &fastdev.Action{
+			Protocol:  fastdev.GRPC,
+			Request:   newRequest(info.FullMethod, md, req),
+			Response:  newResponse(resp, err),
+			Timestamp: start.UnixNano(),
+			Latency:   int64(util.Now(ctx).Sub(start)),
+		})
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor 录制模式下录制绑定了会话 ID 的下游请求；回放模式下
+// 根据方法名和请求消息匹配录制的请求，直接返回录制的响应。
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		if fastdev.ReplayMode() && fastdev.GetReplaySessionID(ctx) != "" {
+			return replay(ctx, method, req, reply)
+		}
+
+		if !fastdev.Recording(ctx) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		start := util.Now(ctx)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		md, _ := metadata.FromOutgoingContext(ctx)
+		fastdev.RecordAction(ctx, &fastdev.Action{
+			Protocol:  fastdev.GRPC,
+			Request:   newRequest(method, md, req),
+			Response:  newResponse(reply, err),
+			Timestamp: start.UnixNano(),
+			Latency:   int64(util.Now(ctx).Sub(start)),
+		})
+		return err
+	}
+}
+
+func replay(ctx context.Context, method string, req, reply interface{}) error {
+
+	js, _ := encodeMessage(req)
+	r := fastdev.NewGrpcRequest(method, nil, js, nil)
+	v, ok, err := fastdev.ReplayMatch(ctx, fastdev.GRPC, r.Match)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("replay action not match")
+	}
+
+	resp, err := fastdev.ToGrpcResponse(v)
+	if err != nil {
+		return err
+	}
+	if codes.Code(resp.Code) != codes.OK {
+		return status.Error(codes.Code(resp.Code), resp.Error)
+	}
+	return decodeMessage(resp, reply)
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	SpringGrpc "github.com/go-spring/spring-core/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryInterceptor 捕获处理过程中的 panic ，请求以 Internal 状态失败。
+func RecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("%s panic: %v", info.FullMethod, r)
+				err = status.Errorf(codes.Internal, "%v", r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// AccessLogInterceptor 使用 grpc.access 日志记录请求的方法、耗时和状态。
+func AccessLogInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		log.GetLogger("grpc.access").Infof("%s %s %v", info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// MetricsInterceptor 记录每个方法的执行统计，参见 SpringGrpc.GetMetrics 。
+func MetricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		SpringGrpc.RecordMetrics(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// AuthInterceptor 使用 a 认证请求，认证失败时请求以 Unauthenticated 状态失败。
+func AuthInterceptor(a SpringGrpc.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		ctx, err := a.Authenticate(ctx, info.FullMethod)
+		if err != nil {
+			if _, ok := status.FromError(err); !ok {
+				err = status.Error(codes.Unauthenticated, err.Error())
+			}
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
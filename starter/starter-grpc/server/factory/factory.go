@@ -22,42 +22,92 @@ import (
 	"net"
 	"reflect"
 	"runtime"
+	"time"
 
 	"github.com/go-spring/spring-base/log"
 	"github.com/go-spring/spring-base/util"
 	"github.com/go-spring/spring-core/conf"
 	SpringGrpc "github.com/go-spring/spring-core/grpc"
 	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/starter-grpc/interceptor"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
-// Starter gRPC 服务器启动器
+// Starter gRPC 服务器启动器，注册 gs.GrpcServer 声明的服务以及导出为 bean 的
+// *SpringGrpc.Server 服务，应用停止时优雅停止服务器。
 type Starter struct {
-	config  conf.GrpcServerConfig
-	server  *grpc.Server
-	Servers *gs.GrpcServers `autowire:""`
+	config   conf.GrpcServerConfig
+	server   *grpc.Server
+	Servers  *gs.GrpcServers          `autowire:""`
+	Services []*SpringGrpc.Server     `autowire:"${grpc.server.services:=*?}"`
+	Auth     SpringGrpc.Authenticator `autowire:"?"`
 }
 
 // NewStarter Starter 的构造函数
 func NewStarter(config conf.GrpcServerConfig) *Starter {
-	return &Starter{
-		config: config,
-		server: grpc.NewServer(),
+	return &Starter{config: config}
+}
+
+// interceptors 返回服务器的拦截器链，依次为 recovery、访问日志、执行统计、认证
+// 和流量录制。
+func (starter *Starter) interceptors() []grpc.UnaryServerInterceptor {
+	r := []grpc.UnaryServerInterceptor{interceptor.RecoveryInterceptor()}
+	if starter.config.AccessLog {
+		r = append(r, interceptor.AccessLogInterceptor())
+	}
+	r = append(r, interceptor.MetricsInterceptor())
+	if starter.Auth != nil {
+		r = append(r, interceptor.AuthInterceptor(starter.Auth))
+	}
+	return append(r, interceptor.UnaryServerInterceptor())
+}
+
+func (starter *Starter) newServer() (*grpc.Server, error) {
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(starter.interceptors()...)}
+	if starter.config.CertFile != "" {
+		c, err := credentials.NewServerTLSFromFile(starter.config.CertFile, starter.config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(c))
+	}
+	return grpc.NewServer(opts...), nil
+}
+
+// register 注册服务，返回新增的服务名称。
+func (starter *Starter) register(rpcServer *SpringGrpc.Server) []string {
+	before := starter.server.GetServiceInfo()
+	fn := reflect.ValueOf(rpcServer.Register)
+	fn.Call([]reflect.Value{reflect.ValueOf(starter.server), reflect.ValueOf(rpcServer.Service)})
+	var names []string
+	for name := range starter.server.GetServiceInfo() {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
 	}
+	return names
 }
 
 func (starter *Starter) OnAppStart(ctx gs.Context) {
 
-	server := reflect.ValueOf(starter.server)
+	server, err := starter.newServer()
+	util.Panic(err).When(err != nil)
+	starter.server = server
+
 	srvMap := make(map[string]reflect.Value)
 
 	starter.Servers.ForEach(func(serviceName string, rpcServer *SpringGrpc.Server) {
-		service := reflect.ValueOf(rpcServer.Service)
-		srvMap[serviceName] = service
-		fn := reflect.ValueOf(rpcServer.Register)
-		fn.Call([]reflect.Value{server, service})
+		starter.register(rpcServer)
+		srvMap[serviceName] = reflect.ValueOf(rpcServer.Service)
 	})
 
+	for _, rpcServer := range starter.Services {
+		for _, name := range starter.register(rpcServer) {
+			srvMap[name] = reflect.ValueOf(rpcServer.Service)
+		}
+	}
+
 	for service, info := range starter.server.GetServiceInfo() {
 		srv := srvMap[service]
 		for _, method := range info.Methods {
@@ -80,6 +130,20 @@ func (starter *Starter) OnAppStart(ctx gs.Context) {
 	})
 }
 
+// OnAppStop 优雅停止服务器，超过 ShutdownTimeout 后强制停止。
 func (starter *Starter) OnAppStop(ctx context.Context) {
-	starter.server.GracefulStop()
+	if starter.server == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		starter.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(starter.config.ShutdownTimeout):
+		log.Warnf("grpc server graceful stop timeout after %v", starter.config.ShutdownTimeout)
+		starter.server.Stop()
+	}
 }
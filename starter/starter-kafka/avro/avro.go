@@ -0,0 +1,101 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package avro 提供基于 hamba/avro 的 Avro 编解码器，导入该包时注册名为 avro 的
+// 编解码器，消息类型对应的 schema 通过 Register 注册。
+package avro
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/go-spring/spring-core/mq"
+	"github.com/hamba/avro"
+)
+
+// Name 编解码器的名称，发送消息时通过 mq.Encode(topic, avro.Name, v) 指定。
+const Name = "avro"
+
+var defaultCodec = NewCodec()
+
+func init() {
+	mq.RegisterCodec(Name, defaultCodec)
+}
+
+// Register 向名为 avro 的编解码器注册 v 的类型使用的 schema 。
+func Register(v interface{}, schema string) error {
+	return defaultCodec.Register(v, schema)
+}
+
+// Codec 按照消息的类型选择 schema 的 Avro 编解码器。
+type Codec struct {
+	mutex   sync.RWMutex
+	schemas map[reflect.Type]avro.Schema
+}
+
+// NewCodec 返回没有注册任何 schema 的编解码器。
+func NewCodec() *Codec {
+	return &Codec{schemas: make(map[reflect.Type]avro.Schema)}
+}
+
+// messageType 返回去掉指针之后的类型，结构体和结构体指针使用同一个 schema 。
+func messageType(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Register 注册 v 的类型使用的 schema ，v 可以是结构体或者结构体指针，同一类型
+// 的 schema 会被覆盖。
+func (c *Codec) Register(v interface{}, schema string) error {
+	s, err := avro.Parse(schema)
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.schemas[messageType(v)] = s
+	return nil
+}
+
+func (c *Codec) schema(v interface{}) (avro.Schema, error) {
+	t := messageType(v)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if s, ok := c.schemas[t]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("avro schema of %v not found", t)
+}
+
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	s, err := c.schema(v)
+	if err != nil {
+		return nil, err
+	}
+	return avro.Marshal(s, v)
+}
+
+func (c *Codec) Unmarshal(data []byte, v interface{}) error {
+	s, err := c.schema(v)
+	if err != nil {
+		return err
+	}
+	return avro.Unmarshal(s, data, v)
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package avro_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/mq"
+	"github.com/go-spring/starter-kafka/avro"
+)
+
+type order struct {
+	ID    int64  `avro:"id"`
+	Owner string `avro:"owner"`
+}
+
+const orderSchema = `{
+	"type": "record",
+	"name": "order",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "owner", "type": "string"}
+	]
+}`
+
+func TestCodec(t *testing.T) {
+
+	c := avro.NewCodec()
+	_, err := c.Marshal(&order{})
+	assert.Error(t, err, "avro schema of avro_test.order not found")
+
+	err = c.Register(order{}, `{"type": "record"}`)
+	assert.Error(t, err, "avro: name key required")
+
+	err = c.Register(order{}, orderSchema)
+	assert.Nil(t, err)
+
+	b, err := c.Marshal(&order{ID: 3, Owner: "jim"})
+	assert.Nil(t, err)
+
+	var o order
+	err = c.Unmarshal(b, &o)
+	assert.Nil(t, err)
+	assert.Equal(t, o, order{ID: 3, Owner: "jim"})
+}
+
+func TestRegister(t *testing.T) {
+
+	err := avro.Register(&order{}, orderSchema)
+	assert.Nil(t, err)
+
+	var orders []order
+	c := mq.Bind(func(ctx context.Context, o *order) error {
+		orders = append(orders, *o)
+		return nil
+	}, "order")
+
+	msg, err := mq.Encode("order", avro.Name, &order{ID: 1, Owner: "tom"})
+	assert.Nil(t, err)
+	assert.Equal(t, msg.Extra()[mq.CodecKey], avro.Name)
+	assert.Nil(t, c.Consume(context.Background(), msg))
+	assert.Equal(t, orders, []order{{ID: 1, Owner: "tom"}})
+}
@@ -0,0 +1,15 @@
+module github.com/go-spring/starter-kafka
+
+go 1.14
+
+require (
+	github.com/Shopify/sarama v1.29.1
+	github.com/go-spring/spring-base v1.1.0-rc2
+	github.com/go-spring/spring-core v1.1.0-rc2
+	github.com/hamba/avro v1.6.6
+)
+
+replace (
+	github.com/go-spring/spring-base => ../../spring/spring-base
+	github.com/go-spring/spring-core => ../../spring/spring-core
+)
@@ -0,0 +1,210 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterKafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/mq"
+)
+
+func init() {
+	gs.Provide(newProducer).
+		On(cond.OnProperty("mq.driver", cond.HavingValue("kafka"), cond.MatchIfMissing()).
+			OnMissingBean((*mq.Producer)(nil))).
+		Destroy(closeProducer).
+		Export((*mq.Producer)(nil))
+	gs.Object(new(Starter)).
+		On(cond.OnProperty("mq.driver", cond.HavingValue("kafka"), cond.MatchIfMissing())).
+		Export((*gs.AppEvent)(nil), (*mq.Subscriber)(nil))
+}
+
+// Config Kafka 配置。
+type Config struct {
+	Brokers     []string      `value:"${kafka.brokers:=127.0.0.1:9092}"`       // broker 地址列表
+	GroupID     string        `value:"${kafka.consumer.group-id:=go-spring}"`  // 消费组
+	Oldest      bool          `value:"${kafka.consumer.oldest:=false}"`        // 没有提交的位点时是否从最早的消息开始消费
+	MaxAttempts int           `value:"${kafka.consumer.max-attempts:=1}"`      // 最大消费次数
+	Backoff     time.Duration `value:"${kafka.consumer.backoff:=1s}"`          // 两次消费之间的间隔
+	DeadLetter  string        `value:"${kafka.consumer.dead-letter-suffix:=}"` // 死信主题的后缀，为空时不使用死信主题
+}
+
+func newConfig(config Config) *sarama.Config {
+	c := sarama.NewConfig()
+	c.Producer.Return.Successes = true
+	if config.Oldest {
+		c.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
+	return c
+}
+
+// Producer 使用同步方式发送消息，消息的额外信息作为 Kafka 消息头发送。
+type Producer struct {
+	producer sarama.SyncProducer
+}
+
+func newProducer(config Config) (*Producer, error) {
+	p, err := sarama.NewSyncProducer(config.Brokers, newConfig(config))
+	if err != nil {
+		return nil, err
+	}
+	return &Producer{producer: p}, nil
+}
+
+// closeProducer 程序退出时关闭生产者。
+func closeProducer(p *Producer) error {
+	return p.producer.Close()
+}
+
+func (p *Producer) SendMessage(ctx context.Context, msg mq.Message) error {
+	return mq.Send(ctx, msg, func(ctx context.Context) error {
+		m := &sarama.ProducerMessage{
+			Topic: msg.Topic(),
+			Value: sarama.ByteEncoder(msg.Body()),
+		}
+		if id := msg.ID(); id != "" {
+			m.Key = sarama.StringEncoder(id)
+		}
+		for k, v := range msg.Extra() {
+			m.Headers = append(m.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+		}
+		_, _, err := p.producer.SendMessage(m)
+		return err
+	})
+}
+
+// Starter 在应用启动后使用消费组消费 gs.Consume 注册的以及导出为 mq.Consumer
+// 的消费者，在应用停止时关闭消费组。
+type Starter struct {
+	Config   Config        `value:"${}"`
+	Producer mq.Producer   `autowire:"?"`
+	Bind     *gs.Consumers `autowire:""`
+	Others   []mq.Consumer `autowire:"?"`
+
+	subscribed []mq.Consumer
+	group      sarama.ConsumerGroup
+	cancel     context.CancelFunc
+}
+
+// Subscribe 订阅消息，需要在应用启动之前调用。
+func (s *Starter) Subscribe(c mq.Consumer) error {
+	if s.group != nil {
+		return errors.New("kafka consumer group already started")
+	}
+	s.subscribed = append(s.subscribed, c)
+	return nil
+}
+
+func (s *Starter) OnAppStart(ctx gs.Context) {
+
+	consumers := make(map[string][]mq.Consumer)
+	add := func(c mq.Consumer) {
+		for _, topic := range c.Topics() {
+			tc := c
+			if s.Config.DeadLetter != "" {
+				policy := mq.RetryPolicy{
+					MaxAttempts: s.Config.MaxAttempts,
+					Backoff:     s.Config.Backoff,
+					DeadLetter:  topic + s.Config.DeadLetter,
+				}
+				tc = mq.WithRetry(c, policy, s.Producer)
+			} else if s.Config.MaxAttempts > 1 {
+				policy := mq.RetryPolicy{
+					MaxAttempts: s.Config.MaxAttempts,
+					Backoff:     s.Config.Backoff,
+				}
+				tc = mq.WithRetry(c, policy, nil)
+			}
+			consumers[topic] = append(consumers[topic], tc)
+		}
+	}
+	s.Bind.ForEach(add)
+	for _, c := range s.Others {
+		add(c)
+	}
+	for _, c := range s.subscribed {
+		add(c)
+	}
+	if len(consumers) == 0 {
+		return
+	}
+
+	group, err := sarama.NewConsumerGroup(s.Config.Brokers, s.Config.GroupID, newConfig(s.Config))
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	s.group = group
+
+	var topics []string
+	for topic := range consumers {
+		topics = append(topics, topic)
+	}
+
+	var c context.Context
+	c, s.cancel = context.WithCancel(ctx.Context())
+	h := &handler{consumers: consumers}
+	ctx.Go(func(_ context.Context) {
+		for c.Err() == nil {
+			if err := group.Consume(c, topics, h); err != nil {
+				log.Error(err)
+				time.Sleep(time.Second)
+			}
+		}
+	})
+}
+
+func (s *Starter) OnAppStop(ctx context.Context) {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.group != nil {
+		if err := s.group.Close(); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// handler 把 Kafka 消息分发给订阅该主题的消费者，消费完成后提交位点。
+type handler struct {
+	consumers map[string][]mq.Consumer
+}
+
+func (h *handler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *handler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *handler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for m := range claim.Messages() {
+		msg := mq.NewMessage().WithTopic(m.Topic).WithID(string(m.Key)).WithBody(m.Value)
+		for _, header := range m.Headers {
+			msg.WithExtra(string(header.Key), string(header.Value))
+		}
+		for _, c := range h.consumers[m.Topic] {
+			if err := mq.Consume(sess.Context(), c, msg); err != nil {
+				log.Errorf("consume message of %s error: %v", m.Topic, err)
+			}
+		}
+		sess.MarkMessage(m, "")
+	}
+	return nil
+}
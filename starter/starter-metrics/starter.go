@@ -0,0 +1,63 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterMetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/metrics"
+)
+
+func init() {
+	gs.Provide(metrics.Default)
+	gs.Provide(newStatsdExporter).
+		On(cond.OnProperty("metrics.statsd.address")).
+		Destroy(func(e *metrics.StatsdExporter) error { return e.Close() }).
+		Export((*metrics.Exporter)(nil))
+	gs.Object(new(Starter)).Export((*gs.AppEvent)(nil))
+}
+
+// StatsdConfig statsd 导出配置。
+type StatsdConfig struct {
+	Address string `value:"${metrics.statsd.address}"`  // statsd 服务地址，例如 127.0.0.1:8125
+	Prefix  string `value:"${metrics.statsd.prefix:=}"` // 指标名称的前缀
+}
+
+func newStatsdExporter(config StatsdConfig) (*metrics.StatsdExporter, error) {
+	return metrics.NewStatsdExporter(config.Address, config.Prefix)
+}
+
+// Starter 在应用启动后定时把指标推送到所有导出为 metrics.Exporter 的对象。
+type Starter struct {
+	Registry  *metrics.Registry  `autowire:""`
+	Exporters []metrics.Exporter `autowire:"?"`
+	Interval  time.Duration      `value:"${metrics.export.interval:=10s}"`
+}
+
+func (s *Starter) OnAppStart(ctx gs.Context) {
+	for _, exporter := range s.Exporters {
+		e := exporter
+		ctx.Go(func(ctx context.Context) {
+			metrics.Push(ctx, s.Registry, e, s.Interval)
+		})
+	}
+}
+
+func (s *Starter) OnAppStop(ctx context.Context) {}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterMQ
+
+import (
+	"context"
+
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/mq"
+)
+
+func init() {
+	gs.Provide(mq.NewMemoryBus).
+		On(cond.OnProperty("mq.driver", cond.HavingValue("memory"))).
+		Export((*mq.Publisher)(nil), (*mq.Subscriber)(nil))
+	gs.Object(new(Starter)).
+		On(cond.OnProperty("mq.driver", cond.HavingValue("memory"))).
+		Export((*gs.AppEvent)(nil))
+}
+
+// Starter 在应用启动时把 gs.Consume 注册的以及导出为 mq.Consumer 的消费者订阅
+// 到进程内的消息总线。
+type Starter struct {
+	Bus    *mq.MemoryBus `autowire:""`
+	Bind   *gs.Consumers `autowire:""`
+	Others []mq.Consumer `autowire:"?"`
+}
+
+func (s *Starter) OnAppStart(ctx gs.Context) {
+	s.Bind.ForEach(func(c mq.Consumer) {
+		_ = s.Bus.Subscribe(c)
+	})
+	for _, c := range s.Others {
+		_ = s.Bus.Subscribe(c)
+	}
+}
+
+func (s *Starter) OnAppStop(ctx context.Context) {}
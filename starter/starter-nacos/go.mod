@@ -0,0 +1,14 @@
+module github.com/go-spring/starter-nacos
+
+go 1.14
+
+require (
+	github.com/go-spring/spring-base v1.1.0-rc2
+	github.com/go-spring/spring-core v1.1.0-rc2
+	github.com/nacos-group/nacos-sdk-go v1.0.8
+)
+
+replace (
+	github.com/go-spring/spring-base => ../../spring/spring-base
+	github.com/go-spring/spring-core => ../../spring/spring-core
+)
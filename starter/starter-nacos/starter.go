@@ -0,0 +1,140 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterNacos
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/go-spring/spring-core/discovery"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/web"
+	"github.com/nacos-group/nacos-sdk-go/clients"
+	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+)
+
+func init() {
+	gs.Provide(newRegistry).
+		On(cond.OnMissingBean((*discovery.Registry)(nil))).
+		Export((*discovery.Registry)(nil), (*discovery.Resolver)(nil))
+	gs.Provide(discovery.NewRegistrar).
+		On(cond.OnBean((*Registry)(nil))).
+		Export((*gs.AppEvent)(nil))
+	gs.Provide(discovery.NewPicker).
+		On(cond.OnBean((*Registry)(nil)))
+	gs.Provide(discovery.NewLoadBalancerFromConfig).
+		On(cond.OnBean((*Registry)(nil))).
+		Export((*web.Balancer)(nil))
+}
+
+// Config Nacos 客户端配置。
+type Config struct {
+	Servers   []string `value:"${nacos.servers:=127.0.0.1:8848}"` // 服务端地址列表
+	Namespace string   `value:"${nacos.namespace:=}"`             // 命名空间
+	Group     string   `value:"${nacos.group:=DEFAULT_GROUP}"`    // 分组
+	Username  string   `value:"${nacos.username:=}"`              // 用户名
+	Password  string   `value:"${nacos.password:=}"`              // 密码
+	TimeoutMs uint64   `value:"${nacos.timeout-ms:=5000}"`        // 请求超时，毫秒
+}
+
+// Registry 基于 Nacos 的注册中心，实例为临时实例，由客户端心跳维持健康状态。
+type Registry struct {
+	client naming_client.INamingClient
+	group  string
+}
+
+func newRegistry(config Config) (*Registry, error) {
+	var servers []constant.ServerConfig
+	for _, s := range config.Servers {
+		host, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return nil, err
+		}
+		p, err := strconv.ParseUint(port, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, constant.ServerConfig{IpAddr: host, Port: p})
+	}
+	client, err := clients.NewNamingClient(vo.NacosClientParam{
+		ClientConfig: &constant.ClientConfig{
+			NamespaceId:         config.Namespace,
+			TimeoutMs:           config.TimeoutMs,
+			NotLoadCacheAtStart: true,
+			Username:            config.Username,
+			Password:            config.Password,
+		},
+		ServerConfigs: servers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Registry{client: client, group: config.Group}, nil
+}
+
+func (r *Registry) Register(ctx context.Context, i *discovery.Instance) error {
+	_, err := r.client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip:          i.Host,
+		Port:        uint64(i.Port),
+		ServiceName: i.Name,
+		GroupName:   r.group,
+		Weight:      1,
+		Enable:      true,
+		Healthy:     true,
+		Ephemeral:   true,
+		Metadata:    i.Metadata,
+	})
+	return err
+}
+
+func (r *Registry) Deregister(ctx context.Context, i *discovery.Instance) error {
+	_, err := r.client.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          i.Host,
+		Port:        uint64(i.Port),
+		ServiceName: i.Name,
+		GroupName:   r.group,
+		Ephemeral:   true,
+	})
+	return err
+}
+
+// Resolve 返回服务 name 所有健康的实例。
+func (r *Registry) Resolve(ctx context.Context, name string) ([]*discovery.Instance, error) {
+	instances, err := r.client.SelectInstances(vo.SelectInstancesParam{
+		ServiceName: name,
+		GroupName:   r.group,
+		HealthyOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ret []*discovery.Instance
+	for _, i := range instances {
+		ret = append(ret, &discovery.Instance{
+			ID:       i.InstanceId,
+			Name:     name,
+			Host:     i.Ip,
+			Port:     int(i.Port),
+			Metadata: i.Metadata,
+		})
+	}
+	return ret, nil
+}
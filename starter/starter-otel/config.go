@@ -0,0 +1,45 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterOtel
+
+import (
+	"time"
+)
+
+// Config OpenTelemetry 配置，属性名称参考 OpenTelemetry SDK 的环境变量。
+type Config struct {
+	ServiceName     string        `value:"${otel.service.name:=}"`                         // 服务名称，为空时使用 spring.application.name
+	AppName         string        `value:"${spring.application.name:=unknown_service}"`    // 应用名称
+	Attributes      []string      `value:"${otel.resource.attributes:=}"`                  // 资源属性，k=v 形式的列表
+	TracesExporter  string        `value:"${otel.traces.exporter:=none}"`                  // 链路导出器，otlp、stdout 或 none
+	Sampler         string        `value:"${otel.traces.sampler:=parentbased_always_on}"`  // 采样器
+	SamplerArg      float64       `value:"${otel.traces.sampler.arg:=1}"`                  // traceidratio 采样器的采样率
+	MetricsExporter string        `value:"${otel.metrics.exporter:=none}"`                 // 指标导出器，otlp 或 none
+	MetricsInterval time.Duration `value:"${otel.metrics.export.interval:=60s}"`           // 指标导出的间隔
+	Propagators     []string      `value:"${otel.propagators:=tracecontext,baggage}"`      // 上下文传播格式，tracecontext 或 baggage
+	Endpoint        string        `value:"${otel.exporter.otlp.endpoint:=127.0.0.1:4317}"` // OTLP gRPC 接收端地址
+	Insecure        bool          `value:"${otel.exporter.otlp.insecure:=true}"`           // 是否不使用 TLS
+	Headers         []string      `value:"${otel.exporter.otlp.headers:=}"`                // 导出请求的头部，k=v 形式的列表
+}
+
+// serviceName 返回服务名称。
+func (c Config) serviceName() string {
+	if c.ServiceName != "" {
+		return c.ServiceName
+	}
+	return c.AppName
+}
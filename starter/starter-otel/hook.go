@@ -0,0 +1,162 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterOtel
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/mq"
+	"github.com/go-spring/spring-core/web"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 框架创建的 Tracer 的名称。
+const instrumentationName = "github.com/go-spring/starter-otel"
+
+// withTraceID 把 span 的链路追踪 ID 保存到 MDC 中，使日志和链路关联。
+func withTraceID(ctx context.Context, span trace.Span) context.Context {
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		return log.WithTraceID(ctx, sc.TraceID().String())
+	}
+	return ctx
+}
+
+// endSpan 根据 err 设置 span 的状态并结束 span 。
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// serverFilter 为每个请求创建服务端 span ，从请求头中提取上游的链路上下文。
+type serverFilter struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func newServerFilter(tp trace.TracerProvider, p propagation.TextMapPropagator) *serverFilter {
+	return &serverFilter{tracer: tp.Tracer(instrumentationName), propagator: p}
+}
+
+func (f *serverFilter) Invoke(ctx web.Context, chain web.FilterChain) {
+
+	r := ctx.Request()
+	c := f.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	c, span := f.tracer.Start(c, r.Method+" "+ctx.Path(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(r.Method),
+			semconv.HTTPTargetKey.String(r.URL.RequestURI()),
+			semconv.HTTPRouteKey.String(ctx.Path()),
+			semconv.HTTPClientIPKey.String(ctx.ClientIP()),
+		))
+	ctx.SetRequest(r.WithContext(withTraceID(c, span)))
+
+	defer func() {
+		p := recover()
+		status := ctx.ResponseWriter().Status()
+		if p != nil {
+			status = http.StatusInternalServerError
+		}
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	chain.Next(ctx)
+}
+
+// installHooks 为 web.Client 和消息队列安装链路追踪的钩子。
+func installHooks(tracer trace.Tracer, p propagation.TextMapPropagator) {
+
+	var spans sync.Map // *http.Request -> trace.Span
+
+	web.SetClientHook(web.ClientHook{
+		BeforeDoFunc: func(req *http.Request) error {
+			c, span := tracer.Start(req.Context(), "HTTP "+req.Method,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.HTTPMethodKey.String(req.Method),
+					semconv.HTTPURLKey.String(req.URL.String()),
+				))
+			p.Inject(c, propagation.HeaderCarrier(req.Header))
+			spans.Store(req, span)
+			return nil
+		},
+		AfterDoFunc: func(req *http.Request, resp *http.Response, err error, cost time.Duration) {
+			v, ok := spans.Load(req)
+			if !ok {
+				return
+			}
+			spans.Delete(req)
+			span := v.(trace.Span)
+			if resp != nil {
+				span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+				if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, resp.Status)
+				}
+			}
+			endSpan(span, err)
+		},
+	})
+
+	mq.SetHook(mq.Hook{
+		SendFunc: func(ctx context.Context, msg mq.Message, send func(ctx context.Context) error) error {
+			c, span := tracer.Start(ctx, msg.Topic()+" send",
+				trace.WithSpanKind(trace.SpanKindProducer),
+				trace.WithAttributes(
+					semconv.MessagingDestinationKey.String(msg.Topic()),
+					semconv.MessagingMessageIDKey.String(msg.ID()),
+				))
+			if extra := msg.Extra(); extra != nil {
+				p.Inject(c, propagation.MapCarrier(extra))
+			}
+			err := send(c)
+			endSpan(span, err)
+			return err
+		},
+		ConsumeFunc: func(ctx context.Context, msg mq.Message, consume func(ctx context.Context) error) error {
+			if extra := msg.Extra(); extra != nil {
+				ctx = p.Extract(ctx, propagation.MapCarrier(extra))
+			}
+			c, span := tracer.Start(ctx, msg.Topic()+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					semconv.MessagingDestinationKey.String(msg.Topic()),
+					semconv.MessagingMessageIDKey.String(msg.ID()),
+					semconv.MessagingOperationProcess,
+				))
+			err := consume(withTraceID(c, span))
+			endSpan(span, err)
+			return err
+		},
+	})
+}
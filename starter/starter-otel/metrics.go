@@ -0,0 +1,132 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterOtel
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/go-spring/spring-core/metrics"
+)
+
+// MetricsExporter 把框架 metrics 包统计的指标转换为 OpenTelemetry 的指标数据，
+// 通过 OTLP 导出，和 MeterProvider 使用相同的资源属性和接收端地址。计数器导出为
+// 单调递增的 Sum ，仪表导出为 Gauge ，直方图和计时器导出为 Histogram ，都使用
+// 累计的时间性。otel.metrics.exporter 为 otlp 时注册，由 starter-metrics 定时推送。
+type MetricsExporter struct {
+	exporter sdkmetric.Exporter
+	resource *resource.Resource
+	start    time.Time
+}
+
+func newMetricsExporter(config Config, res *resource.Resource) (*MetricsExporter, error) {
+	exp, err := newOTLPMetricExporter(config)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricsExporter{exporter: exp, resource: res, start: time.Now()}, nil
+}
+
+func (e *MetricsExporter) Export(samples []metrics.Sample) error {
+	rm := &metricdata.ResourceMetrics{
+		Resource: e.resource,
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope:   instrumentation.Scope{Name: instrumentationName},
+			Metrics: toMetrics(samples, e.start, time.Now()),
+		}},
+	}
+	return e.exporter.Export(context.Background(), rm)
+}
+
+// Close 关闭 OTLP 导出器。
+func (e *MetricsExporter) Close() error {
+	return e.exporter.Shutdown(context.Background())
+}
+
+// toMetrics 把指标转换为 OpenTelemetry 的指标数据，同名指标的不同标签作为同一个
+// 指标的多个数据点。
+func toMetrics(samples []metrics.Sample, start, now time.Time) []metricdata.Metrics {
+	var ret []metricdata.Metrics
+	index := make(map[string]int)
+	for _, s := range samples {
+		i, ok := index[s.Name]
+		if !ok {
+			i = len(ret)
+			index[s.Name] = i
+			ret = append(ret, metricdata.Metrics{Name: s.Name})
+		}
+		m := &ret[i]
+		attrs := toAttributes(s.Tags)
+		switch s.Type {
+		case metrics.CounterType:
+			data, _ := m.Data.(metricdata.Sum[float64])
+			data.Temporality = metricdata.CumulativeTemporality
+			data.IsMonotonic = true
+			data.DataPoints = append(data.DataPoints, metricdata.DataPoint[float64]{
+				Attributes: attrs, StartTime: start, Time: now, Value: s.Value,
+			})
+			m.Data = data
+		case metrics.GaugeType:
+			data, _ := m.Data.(metricdata.Gauge[float64])
+			data.DataPoints = append(data.DataPoints, metricdata.DataPoint[float64]{
+				Attributes: attrs, Time: now, Value: s.Value,
+			})
+			m.Data = data
+		default:
+			data, _ := m.Data.(metricdata.Histogram[float64])
+			data.Temporality = metricdata.CumulativeTemporality
+			data.DataPoints = append(data.DataPoints, metricdata.HistogramDataPoint[float64]{
+				Attributes:   attrs,
+				StartTime:    start,
+				Time:         now,
+				Count:        s.Count,
+				Bounds:       s.Buckets,
+				BucketCounts: bucketCounts(s),
+				Sum:          s.Value,
+			})
+			m.Data = data
+		}
+	}
+	return ret
+}
+
+// bucketCounts 把直方图每个桶的累计观测次数转换为 OpenTelemetry 每个桶各自的
+// 观测次数，最后一个桶对应 +Inf 。
+func bucketCounts(s metrics.Sample) []uint64 {
+	counts := make([]uint64, len(s.Counts)+1)
+	var last uint64
+	for i, c := range s.Counts {
+		counts[i] = c - last
+		last = c
+	}
+	counts[len(s.Counts)] = s.Count - last
+	return counts
+}
+
+func toAttributes(tags map[string]string) attribute.Set {
+	var kvs []attribute.KeyValue
+	for k, v := range tags {
+		kvs = append(kvs, attribute.String(k, v))
+	}
+	return attribute.NewSet(kvs...)
+}
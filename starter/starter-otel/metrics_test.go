@@ -0,0 +1,67 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterOtel
+
+import (
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/go-spring/spring-base/assert"
+	"github.com/go-spring/spring-core/metrics"
+)
+
+func TestToMetrics(t *testing.T) {
+
+	r := metrics.NewRegistry(1, 2)
+	r.Counter("requests", "method", "GET").Add(3)
+	r.Counter("requests", "method", "POST").Inc()
+	r.Gauge("beans").Set(5)
+	h := r.Histogram("latency")
+	h.Observe(0.5)
+	h.Observe(1.5)
+	h.Observe(3)
+
+	start := time.Now()
+	now := start.Add(time.Minute)
+	ms := toMetrics(r.Snapshot(), start, now)
+	assert.Equal(t, len(ms), 3)
+
+	assert.Equal(t, ms[0].Name, "beans")
+	gauge := ms[0].Data.(metricdata.Gauge[float64])
+	assert.Equal(t, gauge.DataPoints[0].Value, 5.0)
+
+	assert.Equal(t, ms[1].Name, "latency")
+	hist := ms[1].Data.(metricdata.Histogram[float64])
+	assert.Equal(t, hist.Temporality, metricdata.CumulativeTemporality)
+	assert.Equal(t, hist.DataPoints[0].Count, uint64(3))
+	assert.Equal(t, hist.DataPoints[0].Sum, 5.0)
+	assert.Equal(t, hist.DataPoints[0].Bounds, []float64{1, 2})
+	assert.Equal(t, hist.DataPoints[0].BucketCounts, []uint64{1, 1, 1})
+
+	assert.Equal(t, ms[2].Name, "requests")
+	sum := ms[2].Data.(metricdata.Sum[float64])
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, len(sum.DataPoints), 2)
+	v, _ := sum.DataPoints[0].Attributes.Value(attribute.Key("method"))
+	assert.Equal(t, v.AsString(), "GET")
+	assert.Equal(t, sum.DataPoints[0].Value, 3.0)
+	assert.Equal(t, sum.DataPoints[1].Value, 1.0)
+	assert.Equal(t, sum.DataPoints[1].StartTime, start)
+}
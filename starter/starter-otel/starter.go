@@ -0,0 +1,218 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterOtel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/metrics"
+	"github.com/go-spring/spring-core/web"
+)
+
+func init() {
+	gs.Provide(newResource)
+	gs.Provide(newPropagator)
+	gs.Provide(newTracerProvider).
+		Destroy(shutdownTracerProvider).
+		Export((*trace.TracerProvider)(nil))
+	gs.Provide(newMeterProvider).
+		Destroy(shutdownMeterProvider).
+		Export((*metric.MeterProvider)(nil))
+	gs.Provide(newMetricsExporter).
+		On(cond.OnProperty("otel.metrics.exporter", cond.HavingValue("otlp"))).
+		Destroy(func(e *MetricsExporter) error { return e.Close() }).
+		Export((*metrics.Exporter)(nil))
+	gs.Provide(newServerFilter).Export((*web.Filter)(nil))
+}
+
+// pairs 解析 k=v 形式的列表。
+func pairs(ss []string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, s := range ss {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid key-value pair %q", s)
+		}
+		m[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return m, nil
+}
+
+// newResource 返回包含服务名称和 otel.resource.attributes 属性的资源。
+func newResource(config Config) (*resource.Resource, error) {
+	m, err := pairs(config.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []attribute.KeyValue{semconv.ServiceNameKey.String(config.serviceName())}
+	for k, v := range m {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+}
+
+// newPropagator 根据 otel.propagators 属性创建上下文传播器，并设置为全局的传播器。
+func newPropagator(config Config) (propagation.TextMapPropagator, error) {
+	var propagators []propagation.TextMapPropagator
+	for _, s := range config.Propagators {
+		switch s {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		default:
+			return nil, fmt.Errorf("unknown propagator %q", s)
+		}
+	}
+	p := propagation.NewCompositeTextMapPropagator(propagators...)
+	otel.SetTextMapPropagator(p)
+	return p, nil
+}
+
+func newSampler(config Config) (sdktrace.Sampler, error) {
+	switch config.Sampler {
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(config.SamplerArg), nil
+	case "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SamplerArg)), nil
+	}
+	return nil, fmt.Errorf("unknown sampler %q", config.Sampler)
+}
+
+// newTracerProvider 根据 otel.traces.* 属性创建 TracerProvider ，设置为全局的
+// TracerProvider ，并为 HTTP 客户端和消息队列安装链路追踪的钩子。
+func newTracerProvider(config Config, res *resource.Resource, p propagation.TextMapPropagator) (*sdktrace.TracerProvider, error) {
+
+	sampler, err := newSampler(config)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	}
+
+	switch config.TracesExporter {
+	case "otlp":
+		headers, err := pairs(config.Headers)
+		if err != nil {
+			return nil, err
+		}
+		traceOpts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(config.Endpoint),
+			otlptracegrpc.WithHeaders(headers),
+		}
+		if config.Insecure {
+			traceOpts = append(traceOpts, otlptracegrpc.WithInsecure())
+		}
+		exp, err := otlptracegrpc.New(context.Background(), traceOpts...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case "stdout":
+		exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q", config.TracesExporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	installHooks(tp.Tracer(instrumentationName), p)
+	return tp, nil
+}
+
+// shutdownTracerProvider 程序退出时导出剩余的链路数据。
+func shutdownTracerProvider(tp *sdktrace.TracerProvider) error {
+	return tp.Shutdown(context.Background())
+}
+
+// newMeterProvider 根据 otel.metrics.* 属性创建 MeterProvider ，并设置为全局的
+// MeterProvider 。
+func newMeterProvider(config Config, res *resource.Resource) (*sdkmetric.MeterProvider, error) {
+
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+
+	switch config.MetricsExporter {
+	case "otlp":
+		exp, err := newOTLPMetricExporter(config)
+		if err != nil {
+			return nil, err
+		}
+		reader := sdkmetric.NewPeriodicReader(exp, sdkmetric.WithInterval(config.MetricsInterval))
+		opts = append(opts, sdkmetric.WithReader(reader))
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown metrics exporter %q", config.MetricsExporter)
+	}
+
+	mp := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(mp)
+	return mp, nil
+}
+
+// newOTLPMetricExporter 根据 otel.exporter.otlp.* 属性创建 OTLP 指标导出器。
+func newOTLPMetricExporter(config Config) (sdkmetric.Exporter, error) {
+	headers, err := pairs(config.Headers)
+	if err != nil {
+		return nil, err
+	}
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(config.Endpoint),
+		otlpmetricgrpc.WithHeaders(headers),
+	}
+	if config.Insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	return otlpmetricgrpc.New(context.Background(), opts...)
+}
+
+// shutdownMeterProvider 程序退出时导出剩余的指标数据。
+func shutdownMeterProvider(mp *sdkmetric.MeterProvider) error {
+	return mp.Shutdown(context.Background())
+}
@@ -22,16 +22,26 @@ import (
 	"github.com/go-spring/spring-base/log"
 	"github.com/go-spring/spring-base/util"
 	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
 	"github.com/go-spring/spring-core/mq"
 	"github.com/go-spring/starter-rabbit/server"
 )
 
 func init() {
-	gs.Object(new(Starter)).Export((*gs.AppEvent)(nil))
+	gs.Object(new(Starter)).
+		On(cond.OnProperty("mq.driver", cond.HavingValue("rabbit"), cond.MatchIfMissing())).
+		Export((*gs.AppEvent)(nil), (*mq.Subscriber)(nil))
 }
 
 type Starter struct {
-	Server *StarterRabbitServer.AMQPServer `autowire:""`
+	Server     *StarterRabbitServer.AMQPServer `autowire:""`
+	subscribed []mq.Consumer
+}
+
+// Subscribe 订阅消息，需要在应用启动之前调用。
+func (starter *Starter) Subscribe(c mq.Consumer) error {
+	starter.subscribed = append(starter.subscribed, c)
+	return nil
 }
 
 func (starter *Starter) OnAppStart(ctx gs.Context) {
@@ -49,6 +59,7 @@ func (starter *Starter) OnAppStart(ctx gs.Context) {
 		bindConsumers.ForEach(func(c mq.Consumer) {
 			consumers = append(consumers, c)
 		})
+		consumers = append(consumers, starter.subscribed...)
 
 		for _, consumer := range consumers {
 			for _, topic := range consumer.Topics() {
@@ -75,8 +86,13 @@ func (starter *Starter) OnAppStart(ctx gs.Context) {
 			}
 			d := <-delivery
 			msg := mq.NewMessage().WithBody(d.Body).WithTopic(topic)
+			for k, v := range d.Headers {
+				if s, ok := v.(string); ok {
+					msg.WithExtra(k, s)
+				}
+			}
 			for _, c := range consumers {
-				c.Consume(context.TODO(), msg)
+				mq.Consume(context.TODO(), c, msg)
 			}
 		}
 	}()
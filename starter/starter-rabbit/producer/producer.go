@@ -20,13 +20,16 @@ import (
 	"context"
 
 	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
 	"github.com/go-spring/spring-core/mq"
 	"github.com/go-spring/starter-rabbit/server"
 	"github.com/streadway/amqp"
 )
 
 func init() {
-	gs.Object(new(Sender)).Export((*mq.Producer)(nil))
+	gs.Object(new(Sender)).
+		On(cond.OnProperty("mq.driver", cond.HavingValue("rabbit"), cond.MatchIfMissing())).
+		Export((*mq.Producer)(nil))
 }
 
 type Sender struct {
@@ -34,13 +37,20 @@ type Sender struct {
 }
 
 func (sender *Sender) SendMessage(ctx context.Context, msg mq.Message) error {
-	return sender.Server.Channel.Publish(
-		"",          // exchange
-		msg.Topic(), // routing key
-		false,       // mandatory
-		false,       // immediate
-		amqp.Publishing{
-			ContentType: "text/plain",
-			Body:        msg.Body(),
-		})
+	return mq.Send(ctx, msg, func(ctx context.Context) error {
+		headers := amqp.Table{}
+		for k, v := range msg.Extra() {
+			headers[k] = v
+		}
+		return sender.Server.Channel.Publish(
+			"",          // exchange
+			msg.Topic(), // routing key
+			false,       // mandatory
+			false,       // immediate
+			amqp.Publishing{
+				Headers:     headers,
+				ContentType: "text/plain",
+				Body:        msg.Body(),
+			})
+	})
 }
@@ -19,11 +19,14 @@ package StarterRabbitServer
 
 import (
 	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
 	"github.com/streadway/amqp"
 )
 
 func init() {
-	gs.Provide(CreateServer).Destroy(DestroyServer)
+	gs.Provide(CreateServer).
+		On(cond.OnProperty("mq.driver", cond.HavingValue("rabbit"), cond.MatchIfMissing())).
+		Destroy(DestroyServer)
 }
 
 type AMQPServerConfig struct {
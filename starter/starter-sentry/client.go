@@ -0,0 +1,221 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterSentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/report"
+)
+
+// Client 通过 HTTP API 将错误上报到 Sentry ，事件在后台 goroutine 中异步发送，
+// 队列已满时丢弃新的事件。
+type Client struct {
+	config   Config
+	client   *http.Client
+	endpoint string
+	auth     string
+	events   chan *event
+	wg       sync.WaitGroup
+	mutex    sync.RWMutex
+	closed   bool
+}
+
+// NewClient 返回新的 *Client 对象，DSN 的格式为 {scheme}://{key}@{host}/{project} 。
+func NewClient(config Config) (*Client, error) {
+
+	u, err := url.Parse(config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("sentry dsn %q: %w", config.DSN, err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("sentry dsn %q: missing public key", config.DSN)
+	}
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project := path[i+1:]
+	if project == "" {
+		return nil, fmt.Errorf("sentry dsn %q: missing project id", config.DSN)
+	}
+
+	if config.ServerName == "" {
+		config.ServerName, _ = os.Hostname()
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1
+	}
+
+	prefix := ""
+	if i > 0 {
+		prefix = "/" + path[:i]
+	}
+
+	c := &Client{
+		config:   config,
+		client:   &http.Client{Timeout: config.Timeout},
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		auth: fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=go-spring",
+			u.User.Username()),
+		events: make(chan *event, config.QueueSize),
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for e := range c.events {
+			if err := c.send(e); err != nil {
+				log.Tag(report.Tag).Errorf("sentry send event %s error: %v", e.EventID, err)
+			}
+		}
+	}()
+	return c, nil
+}
+
+type exception struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger,omitempty"`
+	Platform    string                 `json:"platform"`
+	Message     string                 `json:"message,omitempty"`
+	Exception   []exception            `json:"exception,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Report 将 e 转换为 Sentry 事件并加入发送队列，队列已满或者客户端已关闭时丢弃。
+func (c *Client) Report(ctx context.Context, e *report.Event) {
+
+	ev := &event{
+		EventID:     newEventID(),
+		Timestamp:   e.Time.UTC().Format(time.RFC3339Nano),
+		Level:       e.Level,
+		Logger:      e.Logger,
+		Platform:    "go",
+		Message:     e.Message,
+		Tags:        e.Tags,
+		Environment: c.config.Environment,
+		Release:     c.config.Release,
+		ServerName:  c.config.ServerName,
+	}
+
+	if e.Value != nil {
+		ev.Exception = []exception{{
+			Type:  fmt.Sprintf("%T", e.Value),
+			Value: fmt.Sprint(e.Value),
+		}}
+	}
+	if len(e.Stack) > 0 {
+		ev.Extra = map[string]interface{}{"stack": string(e.Stack)}
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.events <- ev:
+	default:
+		log.Ctx(ctx).Tag(report.Tag).Warnf("sentry queue is full, drop event %s", ev.EventID)
+	}
+}
+
+// Close 停止接收新的事件，等待队列中的事件发送完成。
+func (c *Client) Close() {
+	c.mutex.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.events)
+	}
+	c.mutex.Unlock()
+	c.wg.Wait()
+}
+
+func (c *Client) send(e *event) error {
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"event_id": e.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return err
+	}
+	item := fmt.Sprintf(`{"type":"event","length":%d}`, len(b))
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteByte('\n')
+	buf.WriteString(item)
+	buf.WriteByte('\n')
+	buf.Write(b)
+	buf.WriteByte('\n')
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", c.auth)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+// newEventID 返回 32 位十六进制字符串形式的事件 ID 。
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterSentry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/report"
+	"github.com/go-spring/spring-core/web"
+)
+
+func init() {
+	gs.OnProperty("sentry", func(config Config) {
+		if config.DSN == "" {
+			return
+		}
+		c, err := NewClient(config)
+		if err != nil {
+			panic(err)
+		}
+		client = c
+		report.Register(client)
+	})
+	gs.Provide(report.WebReporter).
+		On(cond.OnProperty("sentry.dsn")).
+		Export((*web.PanicReporter)(nil))
+	gs.Object(new(Starter)).Export((*gs.AppEvent)(nil))
+}
+
+// Config Sentry 客户端配置，配置了 sentry.dsn 属性之后生效。
+type Config struct {
+	DSN         string        `value:"${dsn:=}"`                                    // 项目的 DSN
+	Environment string        `value:"${environment:=${spring.profiles.active:=}}"` // 环境，默认使用激活的 profile
+	Release     string        `value:"${release:=}"`                                // 发布版本
+	ServerName  string        `value:"${server-name:=}"`                            // 服务器名称，默认使用主机名
+	Timeout     time.Duration `value:"${timeout:=3s}"`                              // 请求超时
+	QueueSize   int           `value:"${queue-size:=100}"`                          // 发送队列的长度，队列已满时丢弃新的事件
+}
+
+var client *Client
+
+// Starter 在应用运行期间上报 ERROR 级别的日志和 gs.Go 启动的 goroutine 的 panic ，
+// 应用退出时发送队列中剩余的事件。
+type Starter struct {
+	output  log.Output
+	handler func(r interface{})
+}
+
+func (s *Starter) OnAppStart(ctx gs.Context) {
+	if client == nil {
+		return
+	}
+	s.output = log.GetOutput()
+	s.handler = gs.GoPanicHandler
+	log.SetOutput(report.LogOutput(s.output))
+	gs.GoPanicHandler = report.PanicHandler(s.handler)
+}
+
+func (s *Starter) OnAppStop(ctx context.Context) {
+	if client == nil {
+		return
+	}
+	log.SetOutput(s.output)
+	gs.GoPanicHandler = s.handler
+	report.Unregister(client)
+	client.Close()
+}
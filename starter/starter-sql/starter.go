@@ -0,0 +1,111 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterSQL
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	bconf "github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/database"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/tx"
+	"github.com/go-spring/spring-core/web"
+)
+
+func init() {
+	gs.Provide(newDataSources).Destroy(closeDataSources)
+	gs.Provide(defaultDB).
+		On(cond.OnProperty("datasource.url").OnMissingBean((*sql.DB)(nil)))
+	gs.Provide(tx.NewTxManager).
+		On(cond.OnBean((*sql.DB)(nil)).OnMissingBean((*tx.TxManager)(nil))).
+		Init(tx.SetDefault)
+	gs.Provide(newHealthIndicator).
+		Name("db").
+		Export((*web.HealthIndicator)(nil))
+}
+
+// newDataSources 打开所有配置的数据源，默认数据源使用 datasource.* 属性，其他
+// 数据源使用 datasource.<name>.* 属性。
+func newDataSources(ctx gs.Context) (*database.DataSources, error) {
+	configs := make(map[string]conf.DataSourceConfig)
+	for _, key := range ctx.Keys() {
+		if !strings.HasPrefix(key, "datasource.") || !strings.HasSuffix(key, ".url") {
+			continue
+		}
+		prefix := strings.TrimSuffix(key, ".url")
+		name := strings.TrimPrefix(prefix, "datasource")
+		if name == "" {
+			name = database.DefaultDataSource
+		} else if name = name[1:]; strings.Contains(name, ".") {
+			continue
+		}
+		var config conf.DataSourceConfig
+		if err := ctx.Bind(&config, bconf.Key(prefix)); err != nil {
+			return nil, err
+		}
+		configs[name] = config
+	}
+	return database.OpenDataSources(configs)
+}
+
+// closeDataSources 程序退出时关闭所有数据源。
+func closeDataSources(ds *database.DataSources) error {
+	return ds.Close()
+}
+
+// defaultDB 返回默认数据源。
+func defaultDB(ds *database.DataSources) *sql.DB {
+	return ds.Get(database.DefaultDataSource)
+}
+
+// healthIndicator 使用 Ping 检查所有数据源是否可用，详细信息中包含每个数据源
+// 的执行统计。
+type healthIndicator struct {
+	ds *database.DataSources
+}
+
+func newHealthIndicator(ds *database.DataSources) *healthIndicator {
+	return &healthIndicator{ds: ds}
+}
+
+func (h *healthIndicator) Health(ctx context.Context) (map[string]interface{}, error) {
+	var ret error
+	details := make(map[string]interface{})
+	for _, name := range h.ds.Names() {
+		m := database.GetMetrics(name)
+		detail := map[string]interface{}{
+			"status":  "UP",
+			"queries": m.Queries,
+			"errors":  m.Errors,
+			"slow":    m.Slow,
+			"latency": m.Latency.String(),
+		}
+		if err := h.ds.Get(name).PingContext(ctx); err != nil {
+			detail["status"] = "DOWN"
+			detail["error"] = err.Error()
+			if ret == nil {
+				ret = err
+			}
+		}
+		details[name] = detail
+	}
+	return details, ret
+}
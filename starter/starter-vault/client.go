@@ -0,0 +1,163 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterVault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-spring/spring-base/cast"
+	"github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-core/secret"
+)
+
+// Client 通过 HTTP API 访问 Vault ，同时支持 KV v1 和 KV v2 引擎以及动态密钥。
+type Client struct {
+	config Config
+	client *http.Client
+}
+
+// NewClient 返回新的 *Client 对象。
+func NewClient(config Config) *Client {
+	return &Client{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+type response struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int64                  `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Errors        []string               `json:"errors"`
+}
+
+// Read 读取 path 对应的密钥，path 不存在时返回 conf.ErrNotExist 。
+func (c *Client) Read(ctx context.Context, path string) (*secret.Secret, error) {
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := resp.Data
+	if m, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok = data["metadata"]; ok { // KV v2 引擎
+			data = m
+		}
+	}
+
+	s := toSecret(resp)
+	s.Data = make(map[string]string)
+	for k, v := range data {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			s.Data[k] = string(b)
+		default:
+			s.Data[k] = cast.ToString(v)
+		}
+	}
+	return s, nil
+}
+
+// Renew 对动态密钥的租约进行续期，续期的时长为原租约的有效期。
+func (c *Client) Renew(ctx context.Context, s *secret.Secret) (*secret.Secret, error) {
+	body := map[string]interface{}{
+		"lease_id":  s.LeaseID,
+		"increment": int64(s.LeaseDuration / time.Second),
+	}
+	resp, err := c.do(ctx, http.MethodPut, "sys/leases/renew", body)
+	if err != nil {
+		return nil, err
+	}
+	return toSecret(resp), nil
+}
+
+func toSecret(resp *response) *secret.Secret {
+	return &secret.Secret{
+		LeaseID:       resp.LeaseID,
+		LeaseDuration: time.Duration(resp.LeaseDuration) * time.Second,
+		Renewable:     resp.Renewable,
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}) (*response, error) {
+
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	url := strings.TrimSuffix(c.config.Address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.config.Token != "" {
+		req.Header.Set("X-Vault-Token", c.config.Token)
+	}
+	if c.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.config.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret response
+	if len(b) > 0 {
+		if err = json.Unmarshal(b, &ret); err != nil {
+			return nil, fmt.Errorf("vault %s %s: %w", method, path, err)
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("vault %s %w", path, conf.ErrNotExist)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("vault %s %s: status %d %s", method, path,
+			resp.StatusCode, strings.Join(ret.Errors, "; "))
+	}
+	return &ret, nil
+}
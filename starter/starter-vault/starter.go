@@ -0,0 +1,66 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterVault
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/secret"
+)
+
+// Prefix Vault 密钥引用的前缀，例如 ${vault:secret/data/db#password} 。
+const Prefix = "vault"
+
+func init() {
+	gs.OnProperty("vault", func(config Config) {
+		cache = secret.NewCache(NewClient(config), config.CacheTTL)
+		renewInterval = config.RenewInterval
+		secret.Register(Prefix, cache)
+	})
+	gs.Object(new(Starter)).Export((*gs.AppEvent)(nil))
+}
+
+// Config Vault 客户端配置，配置了任意 vault.* 属性之后生效。
+type Config struct {
+	Address       string        `value:"${address:=http://127.0.0.1:8200}"` // 服务端地址
+	Token         string        `value:"${token:=${VAULT_TOKEN:=}}"`        // 访问令牌，默认使用 VAULT_TOKEN 环境变量
+	Namespace     string        `value:"${namespace:=}"`                    // 命名空间 (企业版)
+	Timeout       time.Duration `value:"${timeout:=5s}"`                    // 请求超时
+	CacheTTL      time.Duration `value:"${cache-ttl:=0s}"`                  // 没有租约的密钥的缓存时间，0 表示永不过期
+	RenewInterval time.Duration `value:"${renew-interval:=1m}"`             // 检查租约是否需要续期的间隔
+}
+
+var (
+	cache         *secret.Cache
+	renewInterval time.Duration
+)
+
+// Starter 在应用运行期间对密钥的租约进行续期。
+type Starter struct{}
+
+func (s *Starter) OnAppStart(ctx gs.Context) {
+	if cache == nil || renewInterval <= 0 {
+		return
+	}
+	ctx.Go(func(ctx context.Context) {
+		cache.Watch(ctx, renewInterval)
+	})
+}
+
+func (s *Starter) OnAppStop(ctx context.Context) {}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterWeb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/web"
+)
+
+type beanInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	FileLine string `json:"fileLine"`
+	Wired    bool   `json:"wired"`
+	Failed   bool   `json:"failed,omitempty"` // 是否因为初始化失败而不可用
+	Error    string `json:"error,omitempty"`  // 初始化失败的原因
+}
+
+type mappingInfo struct {
+	Methods  []string `json:"methods"`
+	Path     string   `json:"path"`
+	Handler  string   `json:"handler"`
+	FileLine string   `json:"fileLine"`
+}
+
+// actuator 暴露属性、bean 、路由和后台任务信息的管理端点，因为应用启动完成之后容器会清
+// 除这些元数据，所以需要在启动阶段保存一份快照。
+type actuator struct {
+	ctx      gs.Context
+	env      map[string]string
+	props    []conf.Property
+	beans    []beanInfo
+	mappings []mappingInfo
+}
+
+func newActuator(ctx gs.Context) *actuator {
+
+	a := &actuator{ctx: ctx, env: make(map[string]string)}
+	a.props = gs.ConfigProps(ctx)
+	for _, p := range a.props {
+		a.env[p.Key] = p.Value
+	}
+
+	for _, b := range ctx.Beans() {
+		info := beanInfo{
+			Name:     b.BeanName(),
+			Type:     b.Type().String(),
+			FileLine: b.FileLine(),
+			Wired:    b.Wired(),
+			Failed:   b.Failed(),
+		}
+		if err := b.Err(); err != nil {
+			info.Error = err.Error()
+		}
+		a.beans = append(a.beans, info)
+	}
+	return a
+}
+
+// register 注册管理端点，mappers 是应用的业务路由。
+func (a *actuator) register(r web.Router, mappers []*web.Mapper) {
+
+	r.GetMapping("/actuator/env", func(ctx web.Context) { ctx.JSON(a.env) })
+	r.GetMapping("/actuator/configprops", func(ctx web.Context) { ctx.JSON(a.configProps(ctx.QueryParam("prefix"))) })
+	r.GetMapping("/actuator/beans", func(ctx web.Context) { ctx.JSON(a.beans) })
+	r.GetMapping("/actuator/mappings", func(ctx web.Context) { ctx.JSON(a.mappings) })
+	r.GetMapping("/actuator/tasks", func(ctx web.Context) { ctx.JSON(a.ctx.Tasks()) })
+
+	all := append(append([]*web.Mapper{}, mappers...), r.Mappers()...)
+	for _, m := range all {
+		methods := web.GetMethod(m.Method())
+		sort.Strings(methods)
+		file, line, fnName := m.Handler().FileLine()
+		a.mappings = append(a.mappings, mappingInfo{
+			Methods:  methods,
+			Path:     m.Path(),
+			Handler:  fnName,
+			FileLine: file + ":" + strconv.Itoa(line),
+		})
+	}
+
+	sort.Slice(a.mappings, func(i, j int) bool {
+		return a.mappings[i].Path < a.mappings[j].Path
+	})
+}
+
+// configProps 返回以 prefix 开头的属性的生效值和来源，prefix 为空时返回所有属性。
+func (a *actuator) configProps(prefix string) []conf.Property {
+	ret := make([]conf.Property, 0, len(a.props))
+	for _, p := range a.props {
+		if strings.HasPrefix(p.Key, prefix) {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// beansHealth 根据初始化失败而不可用的 bean 报告容器的健康状态，只有在
+// spring.bean.failure-policy 不是 fail-fast 时才会出现不可用的 bean 。
+type beansHealth struct {
+	ctx gs.Context
+}
+
+func (h *beansHealth) Health(ctx context.Context) (map[string]interface{}, error) {
+	details := map[string]interface{}{"policy": string(h.ctx.FailurePolicy())}
+	failed := h.ctx.FailedBeans()
+	if len(failed) == 0 {
+		return details, nil
+	}
+	unavailable := make(map[string]string)
+	for _, b := range failed {
+		unavailable[b.BeanName()] = b.Err().Error()
+	}
+	details["unavailable"] = unavailable
+	return details, fmt.Errorf("%d beans unavailable", len(failed))
+}
@@ -2,8 +2,12 @@ module github.com/go-spring/starter-web
 
 go 1.14
 
-require github.com/go-spring/spring-core v1.1.0-rc2
+require (
+	github.com/go-spring/spring-base v1.1.0-rc2
+	github.com/go-spring/spring-core v1.1.0-rc2
+)
 
 replace (
+	github.com/go-spring/spring-base => ../../spring/spring-base
 	github.com/go-spring/spring-core => ../../spring/spring-core
 )
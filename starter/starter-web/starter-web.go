@@ -18,41 +18,483 @@ package StarterWeb
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	bconf "github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-base/fastdev"
+	"github.com/go-spring/spring-base/log"
+	"github.com/go-spring/spring-core/conf"
 	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/gs/cond"
+	"github.com/go-spring/spring-core/mq"
+	"github.com/go-spring/spring-core/redis"
 	"github.com/go-spring/spring-core/web"
+	"github.com/go-spring/spring-core/web/i18n"
 )
 
 func init() {
 	gs.Object(new(Starter)).Export((*gs.AppEvent)(nil))
+	gs.Object(new(web.RequestContextHolder))
+	gs.Provide(newClient).On(cond.OnMissingBean((*web.Client)(nil)))
+	gs.Provide(newMessageSource).On(cond.OnProperty("web.i18n.dir"))
+}
+
+// newClient 创建 HTTP 客户端，web.client.load-balanced 为 true 时使用容器中的
+// web.Balancer 对象把请求的 host 解析为服务实例地址。
+func newClient(ctx gs.Context, config conf.WebClientConfig) (*web.Client, error) {
+	c, err := web.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.LoadBalanced {
+		var b web.Balancer
+		if err = ctx.Get(&b); err != nil {
+			return nil, err
+		}
+		c.SetBalancer(b)
+	}
+	return c, nil
+}
+
+// newMessageSource 从 web.i18n.dir 目录加载国际化消息。
+func newMessageSource(config conf.MessageSourceConfig) (*i18n.MessageSource, error) {
+	s := i18n.NewMessageSource(config.Fallback)
+	if err := s.LoadDir(config.Dir, config.Basename); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 // Starter Web 服务器启动器
 type Starter struct {
-	Containers []web.Container `autowire:""`
-	Filters    []web.Filter    `autowire:"${web.server.filters:=*?}"`
-	Router     web.Router      `autowire:""`
+	Containers []web.Container          `autowire:""`
+	Filters    []web.Filter             `autowire:"${web.server.filters:=*?}"`
+	Router     web.Router               `autowire:""`
+	Management *web.ManagementContainer `autowire:"?"`
+	Factory    web.ContainerFactory     `autowire:"?"`
+	Messages   *i18n.MessageSource      `autowire:"?"`
+
+	// HealthIndicators 组件的健康检查，key 为 bean 名称。
+	HealthIndicators map[string]web.HealthIndicator `autowire:"${web.management.health.indicators:=*?}"`
+
+	EnablePprof     bool `value:"${web.management.pprof.enabled:=false}"`
+	EnableEndpoints bool `value:"${web.management.endpoints.enabled:=false}"`
+	EnableHealth    bool `value:"${web.management.health.enabled:=false}"`
+
+	// EnableRequestID 是否为每个请求设置请求 ID ，请求 ID 和链路追踪 ID 会通过
+	// MDC 自动添加到 log.Ctx 输出的日志中。
+	EnableRequestID bool   `value:"${web.server.request-id.enabled:=true}"`
+	RequestIDHeader string `value:"${web.server.request-id.header:=X-Request-Id}"`
+
+	// TenantHeader 读取当前租户的请求头，为空时不读取租户，租户可以通过
+	// web.RequestContextHolder 获取。
+	TenantHeader string `value:"${web.server.tenant-header:=}"`
+
+	// EnableMetrics 是否记录请求耗时指标，参见 web.MetricsFilter 。
+	EnableMetrics bool `value:"${web.server.metrics.enabled:=true}"`
+
+	// EnableRecovery 是否将处理函数的 panic 转换为错误响应，服务端错误会通知
+	// PanicReporters ，参见 web.RecoveryFilter 。
+	EnableRecovery       bool                `value:"${web.server.recovery.enabled:=true}"`
+	RecoveryMaxStackSize int                 `value:"${web.server.recovery.max-stack-size:=4096}"`
+	PanicReporters       []web.PanicReporter `autowire:"*?"`
+
+	// FieldsParam 过滤 JSON 响应体字段的请求参数，例如 fields 表示支持
+	// ?fields=id,owner.name 形式的请求，为空时不过滤，参见 web.SparseFields 。
+	FieldsParam string `value:"${web.server.fields-param:=}"`
+
+	// DrainDelay 关闭时先将 readiness 置为 DOWN ，等待一段时间再停止容器，
+	// 以便负载均衡摘除流量。
+	DrainDelay time.Duration `value:"${web.management.health.drain-delay:=0}"`
+
+	lifecycle web.Lifecycle
+	servers   []*namedServer
+	closers   []io.Closer
+	mocking   bool
+}
+
+// namedServer web.servers.<name>.* 配置的附加 Web 服务器。
+type namedServer struct {
+	name      string
+	container web.Container
+	filters   []web.Filter
 }
 
 // OnAppStart 应用程序启动事件。
 func (starter *Starter) OnAppStart(ctx gs.Context) {
 
+	if starter.startMockServers(ctx) {
+		return
+	}
+
+	if fastdev.RecordMode() {
+		starter.recordRule(ctx)
+		starter.recordMaskers(ctx)
+		starter.recordStorage(ctx)
+	}
+
+	starter.excludeRules(ctx)
+
+	var requestIDFilters []web.Filter
+	if starter.EnableRequestID {
+		requestIDFilters = append(requestIDFilters, web.RequestIDFilter(starter.RequestIDHeader))
+	}
+
+	var tenantFilters []web.Filter
+	if starter.TenantHeader != "" {
+		tenantFilters = append(tenantFilters, web.TenantFilter(starter.TenantHeader))
+	}
+
+	var metricsFilters []web.Filter
+	if starter.EnableMetrics {
+		metricsFilters = append(metricsFilters, web.MetricsFilter())
+	}
+
+	var recoveryFilters []web.Filter
+	if starter.EnableRecovery {
+		recovery := web.RecoveryFilter(starter.RecoveryMaxStackSize, starter.PanicReporters...)
+		recoveryFilters = append(recoveryFilters, recovery)
+	}
+
+	ipFilters := starter.ipFilters(ctx)
+	breakers := starter.circuitBreakers(ctx)
+
+	var localeFilters []web.Filter
+	if starter.Messages != nil {
+		i18n.SetDefault(starter.Messages)
+		localeFilters = append(localeFilters, web.LocaleFilter(starter.Messages.Languages()...))
+	}
+
+	// 字段过滤需要在用户过滤器改写响应体 (例如 web.JSONEnvelope) 之前进行，
+	// 因此放在最后。
+	var fieldsFilters []web.Filter
+	if starter.FieldsParam != "" {
+		fieldsFilters = append(fieldsFilters, web.ResponseBodyFilter(web.SparseFields(starter.FieldsParam)))
+	}
+
+	starter.createServers(ctx)
+
 	for _, c := range starter.Containers {
+		c.AddFilter(requestIDFilters...)
+		c.AddFilter(tenantFilters...)
+		c.AddFilter(metricsFilters...)
+		c.AddFilter(recoveryFilters...)
+		c.AddFilter(ipFilters...)
+		c.AddFilter(breakers...)
+		c.AddFilter(localeFilters...)
 		c.AddFilter(starter.Filters...)
+		c.AddFilter(fieldsFilters...)
+	}
+
+	for _, s := range starter.servers {
+		s.container.AddFilter(requestIDFilters...)
+		s.container.AddFilter(tenantFilters...)
+		s.container.AddFilter(metricsFilters...)
+		s.container.AddFilter(recoveryFilters...)
+		s.container.AddFilter(ipFilters...)
+		s.container.AddFilter(breakers...)
+		s.container.AddFilter(localeFilters...)
+		s.container.AddFilter(s.filters...)
+		s.container.AddFilter(fieldsFilters...)
 	}
 
 	for _, m := range starter.Router.Mappers() {
 		for _, c := range starter.getContainers(m) {
-			c.AddMapper(web.NewMapper(m.Method(), m.Path(), m.Handler()))
+			c.AddMapper(copyMapper(m))
+		}
+	}
+
+	// 配置了管理端口时管理端点注册到独立的容器上，否则注册到业务容器上。
+	for _, m := range starter.managementMappers(ctx) {
+		if starter.Management != nil {
+			starter.Management.AddMapper(copyMapper(m))
+			continue
+		}
+		for _, c := range starter.getContainers(m) {
+			c.AddMapper(copyMapper(m))
 		}
 	}
 
 	starter.startContainers(ctx)
+	starter.lifecycle.SetState(web.StateReady)
+}
+
+// createServers 根据 web.servers.<name>.* 配置创建附加的 Web 服务器，使用
+// Mapper.SetServer 指定了服务器名称的路由只会注册到对应的服务器上。
+func (starter *Starter) createServers(ctx gs.Context) {
+
+	const key = "web.servers"
+	if !ctx.Has(key) {
+		return
+	}
+
+	if starter.Factory == nil {
+		panic(errors.New("no web.ContainerFactory found for web.servers"))
+	}
+
+	var m map[string]conf.NamedServerConfig
+	if err := ctx.Bind(&m, bconf.Key(key)); err != nil {
+		panic(err)
+	}
+
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		config := m[name]
+		filters := starter.Filters
+		if len(config.Filters) > 0 {
+			var selectors []gs.BeanSelector
+			for _, s := range config.Filters {
+				selectors = append(selectors, s)
+			}
+			filters = nil
+			if err := ctx.Get(&filters, selectors...); err != nil {
+				panic(fmt.Errorf("web server %q filters error: %w", name, err))
+			}
+		}
+		starter.servers = append(starter.servers, &namedServer{
+			name:      name,
+			container: starter.Factory.NewContainer(config.WebServerConfig()),
+			filters:   filters,
+		})
+	}
+}
+
+// excludeRules 根据 web.access-log.exclude.* 和 web.metrics.exclude.* 配置
+// 设置不记录访问日志和请求指标的请求，例如健康检查和爬虫的请求。
+func (starter *Starter) excludeRules(ctx gs.Context) {
+
+	const accessLogKey = "web.access-log.exclude"
+	if ctx.Has(accessLogKey) {
+		var config conf.ExcludeConfig
+		if err := ctx.Bind(&config, bconf.Key(accessLogKey)); err != nil {
+			panic(err)
+		}
+		web.SetAccessLogExclude(web.ExcludeRule{
+			Paths:      config.Paths,
+			UserAgents: config.UserAgents,
+		})
+	}
+
+	const metricsKey = "web.metrics.exclude"
+	if ctx.Has(metricsKey) {
+		var config conf.ExcludeConfig
+		if err := ctx.Bind(&config, bconf.Key(metricsKey)); err != nil {
+			panic(err)
+		}
+		web.SetMetricsExclude(web.ExcludeRule{
+			Paths:      config.Paths,
+			UserAgents: config.UserAgents,
+		})
+	}
+}
+
+// recordRule 根据 fastdev.record.* 配置设置流量录制的开关、采样和过滤规则，
+// 开关和采样率在运行时可以通过 /actuator/fastdev/record 端点修改。
+func (starter *Starter) recordRule(ctx gs.Context) {
+	var config conf.RecordRuleConfig
+	if err := ctx.Bind(&config); err != nil {
+		panic(err)
+	}
+	fastdev.SetRecordEnabled(config.Enabled)
+	web.SetRecordRule(web.RecordRule{
+		SampleRate:  config.SampleRate,
+		Include:     config.Include,
+		Exclude:     config.Exclude,
+		ForceHeader: config.ForceHeader,
+		MaxBodySize: config.MaxBodySize,
+	})
+}
+
+// recordMaskers 根据 fastdev.masks.<name>.* 配置添加录制数据的脱敏规则，规则
+// 按照名称的顺序执行。
+func (starter *Starter) recordMaskers(ctx gs.Context) {
+
+	const key = "fastdev.masks"
+	if !ctx.Has(key) {
+		return
+	}
+
+	var m map[string]conf.MaskRuleConfig
+	if err := ctx.Bind(&m, bconf.Key(key)); err != nil {
+		panic(err)
+	}
+
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		config := m[name]
+		rule := &fastdev.MaskRule{
+			Protocol: config.Protocol,
+			Headers:  config.Headers,
+			Cookies:  config.Cookies,
+			Fields:   config.Fields,
+			Replace:  config.Replace,
+		}
+		if config.Regex != "" {
+			rule.Regex = regexp.MustCompile(config.Regex)
+		}
+		fastdev.AddMasker(rule)
+	}
+}
+
+// recordStorage 根据 fastdev.storage.* 配置设置录制数据的存储，redis 和 mq
+// 类型分别使用容器中的 redis.Client 和 mq.Producer 对象。
+func (starter *Starter) recordStorage(ctx gs.Context) {
+
+	var config conf.RecordStorageConfig
+	if err := ctx.Bind(&config); err != nil {
+		panic(err)
+	}
+
+	switch config.Type {
+	case "", "console":
+		return
+	case "file":
+		s, err := fastdev.NewFileStorage(fastdev.FileStorageConfig{
+			Path:       config.FilePath,
+			MaxSize:    config.FileMaxSize,
+			MaxBackups: config.FileMaxBackups,
+			MaxAge:     config.FileMaxAge,
+		})
+		if err != nil {
+			panic(err)
+		}
+		starter.closers = append(starter.closers, s)
+		fastdev.SetStorage(s)
+	case "redis":
+		var client redis.Client
+		if err := ctx.Get(&client); err != nil {
+			panic(err)
+		}
+		fastdev.SetStorage(redis.NewRecordStorage(client, config.RedisKey, config.RedisMaxLen))
+	case "mq":
+		var producer mq.Producer
+		if err := ctx.Get(&producer); err != nil {
+			panic(err)
+		}
+		fastdev.SetStorage(mq.NewRecordStorage(producer, config.MQTopic))
+	default:
+		panic(fmt.Errorf("unsupported record storage type %q", config.Type))
+	}
+}
+
+// ipFilters 根据 web.server.ip-filters.<name>.* 配置创建 IP 访问控制过滤器，
+// 每个 name 对应一组路由的访问控制规则。
+func (starter *Starter) ipFilters(ctx gs.Context) []web.Filter {
+
+	const key = "web.server.ip-filters"
+	if !ctx.Has(key) {
+		return nil
+	}
+
+	var m map[string]conf.IPFilterConfig
+	if err := ctx.Bind(&m, bconf.Key(key)); err != nil {
+		panic(err)
+	}
+
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var filters []web.Filter
+	for _, name := range names {
+		f, err := web.NewIPFilter(m[name])
+		if err != nil {
+			panic(fmt.Errorf("ip filter %q error: %w", name, err))
+		}
+		filters = append(filters, f)
+	}
+	return filters
+}
+
+// circuitBreakers 根据 web.server.circuit-breakers.<name>.* 配置创建熔断器，
+// 每个 name 对应一组路由的熔断规则，熔断器状态变化时打印日志。
+func (starter *Starter) circuitBreakers(ctx gs.Context) []web.Filter {
+
+	const key = "web.server.circuit-breakers"
+	if !ctx.Has(key) {
+		return nil
+	}
+
+	var m map[string]conf.CircuitBreakerConfig
+	if err := ctx.Bind(&m, bconf.Key(key)); err != nil {
+		panic(err)
+	}
+
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var filters []web.Filter
+	for _, name := range names {
+		b := web.NewCircuitBreaker(m[name])
+		breakerName := name
+		b.OnStateChange(func(from, to web.BreakerState) {
+			log.Warnf("circuit breaker %s changed from %s to %s", breakerName, from, to)
+		})
+		filters = append(filters, b)
+	}
+	return filters
+}
+
+// managementMappers 返回管理端点的映射器列表。
+func (starter *Starter) managementMappers(ctx gs.Context) []*web.Mapper {
+	r := web.NewRouter()
+	if starter.EnablePprof {
+		web.RegisterPprof(r)
+	}
+	if starter.EnableEndpoints {
+		newActuator(ctx).register(r, starter.Router.Mappers())
+		web.RegisterLoggers(r)
+		web.RegisterMetrics(r)
+	}
+	if starter.EnableHealth {
+		web.RegisterHealth(r, &starter.lifecycle)
+		indicators := map[string]web.HealthIndicator{"beans": &beansHealth{ctx}}
+		for name, indicator := range starter.HealthIndicators {
+			indicators[name] = indicator
+		}
+		web.RegisterHealthIndicators(r, indicators)
+	}
+	if starter.EnableEndpoints && fastdev.RecordMode() {
+		web.RegisterRecordSwitch(r)
+	}
+	return r.Mappers()
+}
+
+func copyMapper(m *web.Mapper) *web.Mapper {
+	return web.NewMapper(m.Method(), m.Path(), m.Handler()).SetTimeout(m.Timeout()).SetServer(m.Server()).SetVersion(m.Version())
 }
 
 func (starter *Starter) getContainers(mapper *web.Mapper) []web.Container {
+	if name := mapper.Server(); name != "" {
+		for _, s := range starter.servers {
+			if s.name == name {
+				return []web.Container{s.container}
+			}
+		}
+		panic(fmt.Errorf("web server %q not found for %s", name, mapper.Path()))
+	}
 	var ret []web.Container
 	for _, c := range starter.Containers {
 		if strings.HasPrefix(mapper.Path(), c.Config().BasePath) {
@@ -62,8 +504,21 @@ func (starter *Starter) getContainers(mapper *web.Mapper) []web.Container {
 	return ret
 }
 
+// allContainers 返回业务容器、附加服务器和管理容器。
+func (starter *Starter) allContainers() []web.Container {
+	var ret []web.Container
+	if starter.Management != nil {
+		ret = append(ret, starter.Management)
+	}
+	ret = append(ret, starter.Containers...)
+	for _, s := range starter.servers {
+		ret = append(ret, s.container)
+	}
+	return ret
+}
+
 func (starter *Starter) startContainers(ctx gs.Context) {
-	for _, container := range starter.Containers {
+	for _, container := range starter.allContainers() {
 		c := container
 		ctx.Go(func(_ context.Context) {
 			if err := c.Start(); err != nil && err != http.ErrServerClosed {
@@ -73,9 +528,55 @@ func (starter *Starter) startContainers(ctx gs.Context) {
 	}
 }
 
+// startMockServers 配置了 fastdev.mock.file 时在业务容器的地址上启动模拟服务，
+// 只使用录制的流量响应请求，不再启动 Web 容器。
+func (starter *Starter) startMockServers(ctx gs.Context) bool {
+
+	var config conf.MockServerConfig
+	if err := ctx.Bind(&config); err != nil {
+		panic(err)
+	}
+	if config.File == "" {
+		return false
+	}
+
+	m := web.NewMockServer()
+	if err := m.LoadFile(config.File); err != nil {
+		panic(err)
+	}
+
+	starter.mocking = true
+	for _, c := range starter.Containers {
+		cfg := c.Config()
+		ln, err := web.Listen(cfg)
+		if err != nil {
+			panic(err)
+		}
+		svr := &http.Server{Addr: web.ListenAddress(cfg), Handler: m}
+		starter.closers = append(starter.closers, svr)
+		ctx.Go(func(_ context.Context) {
+			log.Infof("mock server started on %s", svr.Addr)
+			if err := svr.Serve(ln); err != nil && err != http.ErrServerClosed {
+				gs.ShutDown(err.Error())
+			}
+		})
+	}
+	return true
+}
+
 // OnAppStop 应用程序结束事件。
 func (starter *Starter) OnAppStop(ctx context.Context) {
-	for _, c := range starter.Containers {
-		_ = c.Stop(ctx)
+	starter.lifecycle.SetState(web.StateDraining)
+	if starter.DrainDelay > 0 {
+		time.Sleep(starter.DrainDelay)
+	}
+	if !starter.mocking {
+		for _, c := range starter.allContainers() {
+			_ = c.Stop(ctx)
+		}
+	}
+	starter.lifecycle.SetState(web.StateStopped)
+	for _, c := range starter.closers {
+		_ = c.Close()
 	}
 }
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package StarterWeb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spring/spring-base/assert"
+	bconf "github.com/go-spring/spring-base/conf"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/gs"
+	"github.com/go-spring/spring-core/web"
+)
+
+// appContext 避免嵌入字段的名称与 gs.Context 的 Context 方法冲突。
+type appContext = gs.Context
+
+// testContext 只实现 OnAppStart 用到的 gs.Context 方法。
+type testContext struct {
+	appContext
+	p       *bconf.Properties
+	filters map[string]web.Filter // 按照 bean 名称查找的过滤器
+}
+
+func newTestContext(t *testing.T, props map[string]interface{}) *testContext {
+	p := bconf.New()
+	for k, v := range props {
+		assert.Nil(t, p.Set(k, v))
+	}
+	return &testContext{p: p}
+}
+
+func (c *testContext) Has(key string) bool {
+	return c.p.Has(key)
+}
+
+func (c *testContext) Bind(i interface{}, opts ...bconf.BindOption) error {
+	return c.p.Bind(i, opts...)
+}
+
+func (c *testContext) Get(i interface{}, selectors ...gs.BeanSelector) error {
+	filters := i.(*[]web.Filter)
+	for _, s := range selectors {
+		f, ok := c.filters[s.(string)]
+		if !ok {
+			return fmt.Errorf("filter %v not found", s)
+		}
+		*filters = append(*filters, f)
+	}
+	return nil
+}
+
+func (c *testContext) Go(fn func(ctx context.Context)) {
+	fn(context.Background())
+}
+
+// testContainer 不监听端口，通过 ServeHTTP 直接处理请求的 Web 容器。
+type testContainer struct {
+	*web.AbstractContainer
+	handler http.Handler
+}
+
+func newTestContainer(config conf.WebServerConfig) web.Container {
+	return &testContainer{AbstractContainer: web.NewAbstractContainer(config)}
+}
+
+func (c *testContainer) Start() error {
+	if err := c.AbstractContainer.Start(); err != nil {
+		return err
+	}
+	c.handler = web.ToHTTPHandler(c, c.GetFilters()...)
+	return nil
+}
+
+func (c *testContainer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.handler.ServeHTTP(w, r)
+}
+
+func serve(c web.Container, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestStarter_Pprof(t *testing.T) {
+
+	t.Run("disabled", func(t *testing.T) {
+		c := newTestContainer(conf.DefaultWebServerConfig())
+		starter := &Starter{Containers: []web.Container{c}, Router: web.NewRouter()}
+		starter.OnAppStart(newTestContext(t, nil))
+		assert.Equal(t, serve(c, "/debug/vars").Code, http.StatusNotFound)
+		assert.Equal(t, serve(c, "/debug/pprof/cmdline").Code, http.StatusNotFound)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		c := newTestContainer(conf.DefaultWebServerConfig())
+		starter := &Starter{Containers: []web.Container{c}, Router: web.NewRouter(), EnablePprof: true}
+		starter.OnAppStart(newTestContext(t, nil))
+		w := serve(c, "/debug/vars")
+		assert.Equal(t, w.Code, http.StatusOK)
+		assert.True(t, len(w.Body.String()) > 0)
+		assert.Equal(t, serve(c, "/debug/pprof/cmdline").Code, http.StatusOK)
+	})
+}
+
+// headerFilter 为响应设置 X-Filter 头，用于检查过滤器注册到了哪个服务器。
+func headerFilter(name string) web.Filter {
+	return web.FuncFilter(func(ctx web.Context, chain web.FilterChain) {
+		ctx.Header("X-Filter", name)
+		chain.Next(ctx)
+	})
+}
+
+func TestStarter_Management(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/api/users", func(ctx web.Context) { ctx.String("users") })
+
+	c := newTestContainer(conf.DefaultWebServerConfig())
+	m := web.NewManagementContainer(newTestContainer(conf.ManagementServerConfig{Port: 9090}.WebServerConfig()))
+	starter := &Starter{
+		Containers:  []web.Container{c},
+		Filters:     []web.Filter{headerFilter("api")},
+		Router:      r,
+		Management:  m,
+		EnablePprof: true,
+	}
+	starter.OnAppStart(newTestContext(t, nil))
+
+	w := serve(c, "/api/users")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("X-Filter"), "api")
+	assert.Equal(t, serve(c, "/debug/vars").Code, http.StatusNotFound)
+
+	w = serve(m, "/debug/vars")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("X-Filter"), "")
+	assert.Equal(t, serve(m, "/api/users").Code, http.StatusNotFound)
+}
+
+func TestStarter_Servers(t *testing.T) {
+
+	r := web.NewRouter()
+	r.GetMapping("/api/users", func(ctx web.Context) { ctx.String("users") })
+	r.GetMapping("/internal/stats", func(ctx web.Context) { ctx.String("stats") }).SetServer("internal")
+	r.GetMapping("/public/ping", func(ctx web.Context) { ctx.String("pong") }).SetServer("public")
+
+	ctx := newTestContext(t, map[string]interface{}{
+		"web.servers.internal.port":    9001,
+		"web.servers.internal.filters": "internalFilter",
+		"web.servers.public.port":      9002,
+	})
+	ctx.filters = map[string]web.Filter{"internalFilter": headerFilter("internal")}
+
+	c := newTestContainer(conf.DefaultWebServerConfig())
+	starter := &Starter{
+		Containers: []web.Container{c},
+		Filters:    []web.Filter{headerFilter("api")},
+		Router:     r,
+		Factory:    web.ContainerFactoryFunc(newTestContainer),
+	}
+	starter.OnAppStart(ctx)
+
+	assert.Equal(t, len(starter.servers), 2)
+	internal, public := starter.servers[0].container, starter.servers[1].container
+	assert.Equal(t, internal.Config().Port, 9001)
+	assert.Equal(t, public.Config().Port, 9002)
+
+	w := serve(c, "/api/users")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("X-Filter"), "api")
+	assert.Equal(t, serve(c, "/internal/stats").Code, http.StatusNotFound)
+	assert.Equal(t, serve(c, "/public/ping").Code, http.StatusNotFound)
+
+	w = serve(internal, "/internal/stats")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("X-Filter"), "internal")
+	assert.Equal(t, serve(internal, "/api/users").Code, http.StatusNotFound)
+
+	// 没有配置 filters 时使用 web.server.filters 的过滤器
+	w = serve(public, "/public/ping")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("X-Filter"), "api")
+	assert.Equal(t, serve(public, "/internal/stats").Code, http.StatusNotFound)
+}